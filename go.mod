@@ -25,6 +25,7 @@ require (
 	github.com/openshift/custom-resource-status v0.0.0-20190801200128-4c95b3a336cd
 	github.com/operator-framework/api v0.3.22
 	github.com/operator-framework/operator-lib v0.2.0
+	github.com/prometheus/client_golang v1.8.0
 	github.com/rook/rook v1.5.3
 	github.com/sirupsen/logrus v1.6.0
 	github.com/spf13/cobra v1.0.0
@@ -0,0 +1,474 @@
+// apigen generates typed nb.Client API calls from the noobaa-core API schemas.
+//
+// The schemas are the json form of the noobaa-core src/api/*_api.js modules,
+// which can be exported from a noobaa-core checkout with:
+//
+//	node -e 'console.log(JSON.stringify(require("./src/api/bucket_api"), null, 2))'
+//
+// Every method of every api in the schema dir generates Params/Reply types,
+// an RPCClient method and a GeneratedClient interface entry that nb.Client embeds,
+// and every definition generates a type, like the SystemInfo, BucketInfo and PoolInfo
+// that pkg/nb declares as aliases of the read_system definitions.
+// Definitions that already have a hand-written type in pkg/nb are mapped by typeOverrides
+// so the generated layer and the hand-written layer share the same types.
+//
+// The checked in schemas keep only the methods and properties that the operator uses.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+// typeOverrides maps schema references to hand-written types in pkg/nb
+var typeOverrides = map[string]string{
+	"common_api#/definitions/bigint":                  "BigInt",
+	"common_api#/definitions/storage_info":            "StorageInfo",
+	"common_api#/definitions/endpoint_type":           "EndpointType",
+	"common_api#/definitions/cloud_auth_method":       "CloudAuthMethod",
+	"account_api#/definitions/account_info":           "AccountInfo",
+	"bucket_api#/definitions/namespace_bucket_config": "NamespaceBucketInfo",
+	"pool_api#/definitions/namespace_resource_info":   "NamespaceResourceInfo",
+	"pool_api#/definitions/pool_hosts_info":           "PoolHostsInfo",
+	"tier_api#/definitions/tier_info":                 "TierInfo",
+	"tiering_policy_api#/definitions/tiering_policy":  "TieringPolicyInfo",
+}
+
+// initialisms are kept upper case when converting schema names to go names
+var initialisms = map[string]string{
+	"api":  "API",
+	"id":   "ID",
+	"ip":   "IP",
+	"s3":   "S3",
+	"url":  "URL",
+	"uri":  "URI",
+	"http": "HTTP",
+	"kms":  "KMS",
+	"md5":  "MD5",
+	"tls":  "TLS",
+}
+
+// Schema is the subset of json-schema (with noobaa-core extensions) that the generator supports
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	IDate                bool               `json:"idate,omitempty"`
+	ObjectID             bool               `json:"objectid,omitempty"`
+	Binary               interface{}        `json:"binary,omitempty"`
+	Wrapper              interface{}        `json:"wrapper,omitempty"`
+	Description          string             `json:"description,omitempty"`
+}
+
+// MethodSchema describes a single api method
+type MethodSchema struct {
+	Method string  `json:"method"`
+	Params *Schema `json:"params,omitempty"`
+	Reply  *Schema `json:"reply,omitempty"`
+	Doc    string  `json:"doc,omitempty"`
+}
+
+// APISchema describes a single api module
+type APISchema struct {
+	ID          string                   `json:"$id"`
+	Methods     map[string]*MethodSchema `json:"methods"`
+	Definitions map[string]*Schema       `json:"definitions"`
+}
+
+// Generator holds the loaded schemas and writes the generated code
+type Generator struct {
+	APIs  []*APISchema
+	Out   strings.Builder
+	Iface strings.Builder
+	// ParamRefs are the definitions that are sent to the server in the params of a method
+	ParamRefs map[string]bool
+	// Reply is set while writing a type that is only received from the server
+	Reply bool
+}
+
+func main() {
+
+	util.InitLogger()
+
+	src := os.Args[1]
+	out := os.Args[2]
+	logrus.Printf("apigen schemas in %s writing to %s\n", src, out)
+
+	code, err := GenerateCode(src)
+	fatal(err)
+	fatal(ioutil.WriteFile(out, code, 0644))
+	logrus.Printf("apigen - done.\n")
+}
+
+// GenerateCode loads the api schemas from the src dir and returns the formatted generated code
+func GenerateCode(src string) ([]byte, error) {
+	g := &Generator{}
+	g.Load(src)
+	g.Generate()
+	code, err := format.Source([]byte(g.Out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("apigen: generated code is not valid go: %s\n%s", err, g.Out.String())
+	}
+	return code, nil
+}
+
+// Load reads all the api schema files from the src dir sorted by api name
+func (g *Generator) Load(src string) {
+	files, err := filepath.Glob(filepath.Join(src, "*_api.json"))
+	fatal(err)
+	sort.Strings(files)
+	for _, file := range files {
+		bytes, err := ioutil.ReadFile(filepath.Clean(file))
+		fatal(err)
+		api := &APISchema{}
+		fatal(json.Unmarshal(bytes, api))
+		if api.ID == "" {
+			api.ID = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		logrus.Printf("apigen load %s methods:%d definitions:%d\n", api.ID, len(api.Methods), len(api.Definitions))
+		g.APIs = append(g.APIs, api)
+	}
+}
+
+// Generate writes the definitions, methods and the client interface
+func (g *Generator) Generate() {
+	g.writef("// Code generated by pkg/apigen from pkg/nb/schema. DO NOT EDIT.\n\n")
+	g.writef("package nb\n\n")
+
+	g.Iface.WriteString("// GeneratedClient is the interface of the api calls generated from the noobaa-core api schemas\n")
+	g.Iface.WriteString("type GeneratedClient interface {\n")
+
+	g.ParamRefs = map[string]bool{}
+	for _, api := range g.APIs {
+		for _, name := range sortedKeys(api.Methods) {
+			g.AddParamRefs(api, api.Methods[name].Params)
+		}
+	}
+
+	for _, api := range g.APIs {
+		for _, name := range sortedKeys(api.Definitions) {
+			ref := api.ID + "#/definitions/" + name
+			if _, ok := typeOverrides[ref]; ok {
+				continue
+			}
+			typeName := g.DefinitionTypeName(api.ID, name)
+			def := api.Definitions[name]
+			g.Reply = !g.ParamRefs[ref]
+			g.writef("// %s is the %s definition of %s\n", typeName, name, api.ID)
+			g.writef("type %s %s\n\n", typeName, g.GoType(api, def, 0))
+		}
+	}
+	g.Reply = false
+
+	for _, api := range g.APIs {
+		for _, name := range sortedKeys(api.Methods) {
+			g.GenerateMethod(api, name, api.Methods[name])
+		}
+	}
+
+	g.Iface.WriteString("}\n")
+	g.Out.WriteString(g.Iface.String())
+}
+
+// GenerateMethod writes the params/reply types and the RPCClient method of a single api method
+func (g *Generator) GenerateMethod(api *APISchema, name string, m *MethodSchema) {
+	funcName := goName(strings.TrimSuffix(api.ID, "_api")) + "API" + goName(name)
+	paramsType := ""
+	replyType := ""
+
+	if m.Params != nil {
+		paramsType = funcName + "Params"
+		g.writef("// %s is the params of %s.%s()\n", paramsType, api.ID, name)
+		g.writef("type %s %s\n\n", paramsType, g.GoType(api, m.Params, 0))
+	}
+	if m.Reply != nil {
+		replyType = funcName + "Reply"
+		g.Reply = true
+		g.writef("// %s is the reply of %s.%s()\n", replyType, api.ID, name)
+		g.writef("type %s %s\n\n", replyType, g.GoType(api, m.Reply, 0))
+		g.Reply = false
+	}
+
+	args := ""
+	reqParams := ""
+	if paramsType != "" {
+		args = "params " + paramsType
+		reqParams = ", Params: params"
+	}
+	rets := "error"
+	if replyType != "" {
+		rets = "(" + replyType + ", error)"
+	}
+
+	fmt.Fprintf(&g.Iface, "\t%s(%s) %s\n", funcName, strings.TrimPrefix(args, "params "), rets)
+
+	g.writef("// %s calls %s.%s()\n", funcName, api.ID, name)
+	g.writef("func (c *RPCClient) %s(%s) %s {\n", funcName, args, rets)
+	g.writef("\treq := &RPCMessage{API: %q, Method: %q%s}\n", api.ID, name, reqParams)
+	if replyType == "" {
+		g.writef("\treturn c.Call(req, nil)\n")
+	} else {
+		g.writef("\tres := &struct {\n")
+		g.writef("\t\tRPCMessage `json:\",inline\"`\n")
+		g.writef("\t\tReply %s `json:\"reply\"`\n", replyType)
+		g.writef("\t}{}\n")
+		g.writef("\terr := c.Call(req, res)\n")
+		g.writef("\treturn res.Reply, err\n")
+	}
+	g.writef("}\n\n")
+}
+
+// GoType returns the go type expression for a schema
+func (g *Generator) GoType(api *APISchema, s *Schema, depth int) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		return g.RefTypeName(api, s.Ref)
+	}
+	if s.IDate {
+		return "int64"
+	}
+	if s.ObjectID {
+		return "string"
+	}
+	if s.Binary != nil {
+		return "[]byte"
+	}
+	if s.Wrapper != nil || len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		return "interface{}"
+	}
+	if len(s.Enum) > 0 && s.Type == nil {
+		return "string"
+	}
+
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.GoType(api, s.Items, depth)
+	case "object":
+		if len(s.Properties) == 0 {
+			if additional, ok := s.AdditionalProperties.(map[string]interface{}); ok {
+				bytes, err := json.Marshal(additional)
+				fatal(err)
+				valueSchema := &Schema{}
+				fatal(json.Unmarshal(bytes, valueSchema))
+				return "map[string]" + g.GoType(api, valueSchema, depth)
+			}
+			return "map[string]interface{}"
+		}
+		return g.StructType(api, s, depth)
+	}
+	return "interface{}"
+}
+
+// StructType returns an inline struct type for an object schema
+// optional scalar fields are pointers so that unset values are not sent to the server,
+// and in reply types only optional struct fields are pointers so that callers can check if they were received
+func (g *Generator) StructType(api *APISchema, s *Schema, depth int) string {
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	indent := strings.Repeat("\t", depth+1)
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, prop := range sortedKeys(s.Properties) {
+		ps := s.Properties[prop]
+		fieldType := g.GoType(api, ps, depth+1)
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+			if (g.Reply && g.IsStruct(api, ps)) || (!g.Reply && g.IsPointable(api, ps)) {
+				fieldType = "*" + fieldType
+			}
+		}
+		fmt.Fprintf(&b, "%s%s %s `json:%q`\n", indent, goName(prop), fieldType, tag)
+	}
+	b.WriteString(strings.Repeat("\t", depth) + "}")
+	return b.String()
+}
+
+// RefTypeName resolves a $ref to the go type name of the referenced definition
+func (g *Generator) RefTypeName(api *APISchema, ref string) string {
+	if strings.HasPrefix(ref, "#") {
+		ref = api.ID + ref
+	}
+	if override, ok := typeOverrides[ref]; ok {
+		return override
+	}
+	parts := strings.SplitN(ref, "#/definitions/", 2)
+	if len(parts) != 2 {
+		logrus.Fatalf("apigen: unsupported $ref %q in %s", ref, api.ID)
+	}
+	if !g.HasDefinition(parts[0], parts[1]) {
+		logrus.Fatalf("apigen: missing definition for $ref %q in %s", ref, api.ID)
+	}
+	return g.DefinitionTypeName(parts[0], parts[1])
+}
+
+// HasDefinition checks if the definition was loaded from the schemas
+func (g *Generator) HasDefinition(apiID string, name string) bool {
+	_, ok := g.FindAPI(apiID).Definitions[name]
+	return ok
+}
+
+// DefinitionTypeName returns the go type name for a definition
+func (g *Generator) DefinitionTypeName(apiID string, name string) string {
+	return goName(strings.TrimSuffix(apiID, "_api")) + "API" + goName(name)
+}
+
+// IsPointable returns true for types where the zero value can be confused with an unset value
+// which are scalars and structs, but not slices, maps or interfaces that are already nilable
+func (g *Generator) IsPointable(api *APISchema, s *Schema) bool {
+	if s.Ref != "" {
+		ref := s.Ref
+		if strings.HasPrefix(ref, "#") {
+			ref = api.ID + ref
+		}
+		if _, ok := typeOverrides[ref]; ok {
+			return true
+		}
+		parts := strings.SplitN(ref, "#/definitions/", 2)
+		refAPI := g.FindAPI(parts[0])
+		return g.IsPointable(refAPI, refAPI.Definitions[parts[1]])
+	}
+	t := g.GoType(api, s, 0)
+	return !strings.HasPrefix(t, "[]") &&
+		!strings.HasPrefix(t, "map[") &&
+		t != "interface{}"
+}
+
+// IsStruct returns true for object schemas and for references to them.
+// Overrides are hand-written structs, like BigInt, except for the string enums.
+func (g *Generator) IsStruct(api *APISchema, s *Schema) bool {
+	if s.Ref != "" {
+		refAPI, def := g.LookupRef(api, s.Ref)
+		if _, ok := typeOverrides[refAPI.ID+"#/definitions/"+refName(s.Ref)]; ok || def == nil {
+			return def == nil || def.Type != "string"
+		}
+		return g.IsStruct(refAPI, def)
+	}
+	return strings.HasPrefix(g.GoType(api, s, 0), "struct")
+}
+
+// LookupRef returns the api and the loaded definition of a $ref.
+// The api of a schema that is not checked in has only its id and no definitions.
+func (g *Generator) LookupRef(api *APISchema, ref string) (*APISchema, *Schema) {
+	if strings.HasPrefix(ref, "#") {
+		return api, api.Definitions[refName(ref)]
+	}
+	apiID := strings.SplitN(ref, "#", 2)[0]
+	for _, a := range g.APIs {
+		if a.ID == apiID {
+			return a, a.Definitions[refName(ref)]
+		}
+	}
+	return &APISchema{ID: apiID}, nil
+}
+
+// refName returns the definition name of a $ref
+func refName(ref string) string {
+	parts := strings.SplitN(ref, "#/definitions/", 2)
+	return parts[len(parts)-1]
+}
+
+// AddParamRefs adds the definitions that a params schema references to ParamRefs
+func (g *Generator) AddParamRefs(api *APISchema, s *Schema) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		ref := s.Ref
+		if strings.HasPrefix(ref, "#") {
+			ref = api.ID + ref
+		}
+		if g.ParamRefs[ref] {
+			return
+		}
+		g.ParamRefs[ref] = true
+		if refAPI, def := g.LookupRef(api, ref); def != nil {
+			g.AddParamRefs(refAPI, def)
+		}
+		return
+	}
+	for _, ps := range s.Properties {
+		g.AddParamRefs(api, ps)
+	}
+	g.AddParamRefs(api, s.Items)
+	for _, o := range append(s.OneOf, s.AnyOf...) {
+		g.AddParamRefs(api, o)
+	}
+}
+
+// FindAPI returns the loaded api schema by id
+func (g *Generator) FindAPI(apiID string) *APISchema {
+	for _, api := range g.APIs {
+		if api.ID == apiID {
+			return api
+		}
+	}
+	logrus.Fatalf("apigen: missing api schema %q", apiID)
+	return nil
+}
+
+func (g *Generator) writef(format string, args ...interface{}) {
+	fmt.Fprintf(&g.Out, format, args...)
+}
+
+// goName converts snake_case schema names to exported go names
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if upper, ok := initialisms[part]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch v := m.(type) {
+	case map[string]*Schema:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*MethodSchema:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fatal(err error) {
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedUpToDate uses the checked in pkg/nb/api_generated.go as the golden file of the checked in schemas
+func TestGeneratedUpToDate(t *testing.T) {
+	code, err := GenerateCode("../nb/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := ioutil.ReadFile("../nb/api_generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(code) != string(golden) {
+		t.Fatalf("pkg/nb/api_generated.go is out of date with pkg/nb/schema, run: make pkg/nb/api_generated.go\n%s", code)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"bucket_api":        "BucketAPI",
+		"get_bucket_policy": "GetBucketPolicy",
+		"s3_url":            "S3URL",
+		"node.id":           "NodeID",
+		"md5-b64":           "MD5B64",
+	} {
+		if got := goName(name); got != expected {
+			t.Errorf("goName(%q): expected %q got %q", name, expected, got)
+		}
+	}
+}
+
+func TestGoTypes(t *testing.T) {
+	common := &APISchema{
+		ID: "common_api",
+		Definitions: map[string]*Schema{
+			"bigint":      {Type: "integer"},
+			"bucket_name": {Type: "string"},
+			"tags":        {Type: "array", Items: &Schema{Type: "string"}},
+		},
+	}
+	g := &Generator{APIs: []*APISchema{common}}
+	obj := &Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: map[string]*Schema{
+			"name":    {Ref: "common_api#/definitions/bucket_name"},
+			"size":    {Ref: "#/definitions/bigint"},
+			"count":   {Type: "integer"},
+			"tags":    {Ref: "common_api#/definitions/tags"},
+			"create":  {IDate: true},
+			"mode":    {Enum: []interface{}{"OPTIMAL", "DELETING"}},
+			"any":     {OneOf: []*Schema{{Type: "string"}, {Type: "integer"}}},
+			"labels":  {Type: "object", AdditionalProperties: map[string]interface{}{"type": "string"}},
+			"enabled": {Type: "boolean"},
+		},
+	}
+	code := g.GoType(common, obj, 0)
+	for _, field := range []string{
+		"Name CommonAPIBucketName `json:\"name\"`",
+		"Size *BigInt `json:\"size,omitempty\"`",
+		"Count *int64 `json:\"count,omitempty\"`",
+		"Tags CommonAPITags `json:\"tags,omitempty\"`",
+		"Create *int64 `json:\"create,omitempty\"`",
+		"Mode *string `json:\"mode,omitempty\"`",
+		"Any interface{} `json:\"any,omitempty\"`",
+		"Labels map[string]string `json:\"labels,omitempty\"`",
+		"Enabled *bool `json:\"enabled,omitempty\"`",
+	} {
+		if !strings.Contains(code, field) {
+			t.Errorf("expected field %s in:\n%s", field, code)
+		}
+	}
+}
+
+func TestReplyGoTypes(t *testing.T) {
+	common := &APISchema{
+		ID: "common_api",
+		Definitions: map[string]*Schema{
+			"bigint":        {OneOf: []*Schema{{Type: "integer"}, {Type: "object"}}},
+			"endpoint_type": {Type: "string", Enum: []interface{}{"AWS", "AZURE"}},
+		},
+	}
+	g := &Generator{APIs: []*APISchema{common}, Reply: true}
+	obj := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"mode":          {Type: "string"},
+			"count":         {Type: "integer"},
+			"size":          {Ref: "common_api#/definitions/bigint"},
+			"endpoint_type": {Ref: "common_api#/definitions/endpoint_type"},
+			"tier":          {Ref: "tier_api#/definitions/tier_info"},
+			"hosts":         {Type: "object", Properties: map[string]*Schema{"count": {Type: "integer"}}},
+		},
+	}
+	code := g.GoType(common, obj, 0)
+	for _, field := range []string{
+		"Mode string `json:\"mode,omitempty\"`",
+		"Count int64 `json:\"count,omitempty\"`",
+		"Size *BigInt `json:\"size,omitempty\"`",
+		"EndpointType EndpointType `json:\"endpoint_type,omitempty\"`",
+		"Tier *TierInfo `json:\"tier,omitempty\"`",
+		"Hosts *struct {",
+	} {
+		if !strings.Contains(code, field) {
+			t.Errorf("expected field %s in:\n%s", field, code)
+		}
+	}
+}
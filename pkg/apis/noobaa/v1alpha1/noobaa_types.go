@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -130,6 +131,28 @@ type NooBaaSpec struct {
 	// +optional
 	Endpoints *EndpointsSpec `json:"endpoints,omitempty"`
 
+	// EndpointGroups (optional) adds endpoint deployments on top of the main endpoint deployment,
+	// each with its own region, autoscaling bounds, scheduling and resources.
+	// This allows to run endpoints in several zones with different node pools.
+	// +optional
+	EndpointGroups []EndpointGroupSpec `json:"endpointGroups,omitempty"`
+
+	// Config (optional) overrides the configuration of the noobaa-core processes
+	// in the core, endpoint and pv-pool agent pods. Changes roll out the affected pods.
+	// +optional
+	Config *ConfigSpec `json:"config,omitempty"`
+
+	// Logging (optional) sets the log levels of the system components and the log format of the operator.
+	// Core and endpoint levels are applied to the running processes without restarting the pods.
+	// +optional
+	Logging *LoggingSpec `json:"logging,omitempty"`
+
+	// Upgrade (optional) sets how changes of the core image are rolled out.
+	// The operator takes a snapshot of the DB volume, upgrades core, then the endpoints and then the pv-pool agents,
+	// and reverts to the previous image if a step does not become healthy in time.
+	// +optional
+	Upgrade *UpgradeSpec `json:"upgrade,omitempty"`
+
 	// JoinSecret (optional) instructs the operator to join another cluster
 	// and point to a secret that holds the join information
 	// +optional
@@ -174,6 +197,145 @@ type EndpointsSpec struct {
 	// Resources (optional) overrides the default resource requirements for every endpoint pod
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Autoscaling (optional) sets the metrics and the scaling behavior of the endpoints autoscalers.
+	// By default the endpoints are scaled on 80% average cpu utilization.
+	// +optional
+	Autoscaling *EndpointsAutoscalingSpec `json:"autoscaling,omitempty"`
+}
+
+// EndpointsAutoscalingSpec defines the autoscaling policy of the endpoint deployments.
+// Memory, custom and external metrics and the behavior require the autoscaling/v2beta2 api,
+// and clusters without it scale only on the cpu metric.
+// +k8s:openapi-gen=true
+type EndpointsAutoscalingSpec struct {
+	// Metrics (optional) are the metrics to scale on, such as cpu and memory utilization,
+	// or the S3 requests rate of the endpoints exposed by a metrics adapter as a pods or external metric.
+	// The autoscaler computes the desired number of endpoints for every metric and uses the highest.
+	// +optional
+	Metrics []autoscalingv2beta2.MetricSpec `json:"metrics,omitempty"`
+
+	// Behavior (optional) sets the stabilization windows and the policies of scaling up and down
+	// +optional
+	Behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// ConfigSpec holds typed settings for commonly tuned noobaa-core configuration,
+// and a passthrough of config.js overrides and environment variables for the rest.
+// +k8s:openapi-gen=true
+type ConfigSpec struct {
+	// DisableCompression (optional) disables the compression of the data chunks
+	// +optional
+	DisableCompression *bool `json:"disableCompression,omitempty"`
+
+	// ChunkSplitAvgChunk (optional) sets the average size in bytes of the chunks that objects are split to
+	// +kubebuilder:validation:Minimum=1048576
+	// +optional
+	ChunkSplitAvgChunk *int64 `json:"chunkSplitAvgChunk,omitempty"`
+
+	// ConfigJS (optional) overrides noobaa-core config.js keys, for example MAX_OBJECT_PART_SIZE: "104857600".
+	// Keys are upper case config.js names, and values are parsed by noobaa-core by the type of the key.
+	// +optional
+	ConfigJS map[string]string `json:"configJS,omitempty"`
+
+	// Env (optional) adds environment variables to the noobaa-core containers.
+	// Variables that are managed by the operator are not allowed.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// LoggingSpec defines the log levels of the system components
+// +k8s:openapi-gen=true
+type LoggingSpec struct {
+	// CoreLevel (optional) sets the log level of the core server processes
+	// +optional
+	CoreLevel LogLevel `json:"coreLevel,omitempty"`
+
+	// EndpointLevel (optional) sets the log level of the S3 endpoint processes
+	// +optional
+	EndpointLevel LogLevel `json:"endpointLevel,omitempty"`
+
+	// OperatorLevel (optional) sets the log level of the operator logs of reconciling this system
+	// +optional
+	OperatorLevel LogLevel `json:"operatorLevel,omitempty"`
+
+	// OperatorFormat (optional) sets the log format of the operator logs of reconciling this system
+	// +optional
+	// +kubebuilder:validation:Enum=text;json
+	OperatorFormat LogFormat `json:"operatorFormat,omitempty"`
+}
+
+// LoggingStatus reports the log levels that were applied to the running noobaa-core processes,
+// so that the levels are sent to the processes only when they change
+type LoggingStatus struct {
+	// CoreLevel is the log level that was applied to the core server processes
+	// +optional
+	CoreLevel LogLevel `json:"coreLevel,omitempty"`
+
+	// EndpointLevel is the log level that was applied to the S3 endpoint processes
+	// +optional
+	EndpointLevel LogLevel `json:"endpointLevel,omitempty"`
+
+	// AppliedTime is the last time the levels were applied, processes that became ready
+	// later run with the default level until the levels are applied again
+	// +optional
+	AppliedTime *metav1.Time `json:"appliedTime,omitempty"`
+}
+
+// UpgradeSpec defines how the operator upgrades the core image of the system
+// +k8s:openapi-gen=true
+type UpgradeSpec struct {
+	// DisableDBSnapshot (optional) skips the snapshot of the DB volume before the upgrade
+	// +optional
+	DisableDBSnapshot bool `json:"disableDBSnapshot,omitempty"`
+
+	// DBSnapshotClass (optional) is the VolumeSnapshotClass of the DB volume snapshot,
+	// the default snapshot class of the cluster is used when not set
+	// +optional
+	DBSnapshotClass string `json:"dbSnapshotClass,omitempty"`
+
+	// StepTimeout (optional) is the time that every upgrade step has to become healthy
+	// before the upgrade is rolled back, 10 minutes by default
+	// +optional
+	StepTimeout *metav1.Duration `json:"stepTimeout,omitempty"`
+}
+
+// EndpointGroupSpec defines the desired state of an additional endpoint deployment
+// +k8s:openapi-gen=true
+type EndpointGroupSpec struct {
+	// Name of the group, used as a suffix for the names of the group deployment and autoscaler
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=32
+	Name string `json:"name"`
+
+	// Region (optional) provide a region for the location info
+	// of the endpoints in the group
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// MinCount, the number of endpoint instances (pods)
+	// to be used as the lower bound when autoscaling
+	MinCount int32 `json:"minCount,omitempty"`
+
+	// MaxCount, the number of endpoint instances (pods)
+	// to be used as the upper bound when autoscaling
+	MaxCount int32 `json:"maxCount,omitempty"`
+
+	// NodeSelector (optional) schedules the group pods on the matching nodes
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations (optional) overrides the system tolerations for the group pods
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity (optional) overrides the system affinity for the group pods
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Resources (optional) overrides the default resource requirements for every endpoint pod in the group
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
 // NooBaaStatus defines the observed state of System
@@ -203,6 +365,11 @@ type NooBaaStatus struct {
 	// +optional
 	ActualImage string `json:"actualImage,omitempty"`
 
+	// Compatibility reports the decision of the operator about the requested core image
+	// according to the compatibility matrix of the operator version
+	// +optional
+	Compatibility *CompatibilityStatus `json:"compatibility,omitempty"`
+
 	// Accounts reports accounts info for the admin account
 	// +optional
 	Accounts *AccountsStatus `json:"accounts,omitempty"`
@@ -216,10 +383,23 @@ type NooBaaStatus struct {
 	// +optional
 	Endpoints *EndpointsStatus `json:"endpoints,omitempty"`
 
+	// Logging reports the core and endpoint log levels that were applied to the running processes
+	// +optional
+	Logging *LoggingStatus `json:"logging,omitempty"`
+
 	// Upgrade reports the status of the ongoing upgrade process
 	// +optional
 	UpgradePhase UpgradePhase `json:"upgradePhase,omitempty"`
 
+	// Upgrade reports the progress of the ongoing upgrade of the core image,
+	// or the failed upgrade that holds the system on the previous image until the image is changed
+	// +optional
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
+
+	// UpgradeHistory lists the recent upgrades of the core image, oldest first
+	// +optional
+	UpgradeHistory []UpgradeHistoryEntry `json:"upgradeHistory,omitempty"`
+
 	// Readme is a user readable string with explanations on the system
 	// +optional
 	Readme string `json:"readme,omitempty"`
@@ -337,6 +517,17 @@ type ServiceStatus struct {
 type EndpointsStatus struct {
 	ReadyCount   int32    `json:"readyCount"`
 	VirtualHosts []string `json:"virtualHosts"`
+
+	// Groups reports the actual number of endpoints of every endpoint group
+	// +optional
+	Groups []EndpointGroupStatus `json:"groups,omitempty"`
+}
+
+// EndpointGroupStatus is the status of an endpoint group deployment
+type EndpointGroupStatus struct {
+	Name       string `json:"name"`
+	Region     string `json:"region,omitempty"`
+	ReadyCount int32  `json:"readyCount"`
 }
 
 // UpgradePhase is a string enum type for upgrade phases
@@ -353,6 +544,138 @@ const (
 	UpgradePhaseClean UpgradePhase = "Cleanning"
 
 	UpgradePhaseFinished UpgradePhase = "DoneUpgrade"
+
+	// UpgradePhaseBackupDB means the operator takes a snapshot of the DB volume before upgrading the core image
+	UpgradePhaseBackupDB UpgradePhase = "BackingUpDB"
+
+	// UpgradePhaseCore means the core statefulset is rolling to the new image
+	UpgradePhaseCore UpgradePhase = "UpgradingCore"
+
+	// UpgradePhaseEndpoints means the endpoint deployments are rolling to the new image
+	UpgradePhaseEndpoints UpgradePhase = "UpgradingEndpoints"
+
+	// UpgradePhaseAgents means the pv-pool agents are rolling to the new image
+	UpgradePhaseAgents UpgradePhase = "UpgradingAgents"
+
+	// UpgradePhaseRollback means a step failed and the system is reverting to the previous image
+	UpgradePhaseRollback UpgradePhase = "RollingBack"
+)
+
+// UpgradeStatus reports the progress of an upgrade of the core image
+type UpgradeStatus struct {
+
+	// FromImage is the image that the system ran before the upgrade
+	FromImage string `json:"fromImage"`
+
+	// ToImage is the image that the system is upgraded to
+	ToImage string `json:"toImage"`
+
+	// StartTime is the time the upgrade started
+	StartTime metav1.Time `json:"startTime"`
+
+	// StepStartTime is the time the current step of the upgrade started
+	StepStartTime metav1.Time `json:"stepStartTime"`
+
+	// DBSnapshot is the name of the VolumeSnapshot of the DB volume taken before the upgrade
+	// +optional
+	DBSnapshot string `json:"dbSnapshot,omitempty"`
+
+	// PreviousUpgradePhase is the upgrade phase of the DB migration to restore when the upgrade completes
+	// +optional
+	PreviousUpgradePhase UpgradePhase `json:"previousUpgradePhase,omitempty"`
+
+	// Result is set when the upgrade failed and the system is held on FromImage until the image is changed
+	// +optional
+	Result UpgradeResult `json:"result,omitempty"`
+
+	// Message explains the result or the current step
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// UpgradeHistoryEntry is a completed upgrade of the core image
+type UpgradeHistoryEntry struct {
+
+	// FromImage is the image that the system ran before the upgrade
+	FromImage string `json:"fromImage"`
+
+	// ToImage is the image that the system was upgraded to
+	ToImage string `json:"toImage"`
+
+	// StartTime is the time the upgrade started
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is the time the upgrade completed, succeeded or not
+	CompletionTime metav1.Time `json:"completionTime"`
+
+	// Result of the upgrade
+	Result UpgradeResult `json:"result"`
+
+	// DBSnapshot is the name of the VolumeSnapshot of the DB volume taken before the upgrade
+	// +optional
+	DBSnapshot string `json:"dbSnapshot,omitempty"`
+
+	// Message explains the result
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// UpgradeResult is a string enum type for the results of upgrades
+type UpgradeResult string
+
+// These are the valid upgrade results:
+const (
+	// UpgradeResultSucceeded means all the components run the new image
+	UpgradeResultSucceeded UpgradeResult = "Succeeded"
+
+	// UpgradeResultRolledBack means a step failed and all the components were reverted to the previous image
+	UpgradeResultRolledBack UpgradeResult = "RolledBack"
+
+	// UpgradeResultFailed means the upgrade failed before it changed the components,
+	// or the rollback did not complete in time
+	UpgradeResultFailed UpgradeResult = "Failed"
+)
+
+// CompatibilityStatus reports the compatibility of the requested core image with the operator
+type CompatibilityStatus struct {
+
+	// Decision is the result of the compatibility check of the requested core image
+	Decision CompatibilityDecision `json:"decision"`
+
+	// OperatorVersion is the version of the operator that checked the image
+	OperatorVersion string `json:"operatorVersion"`
+
+	// CoreVersion is the version of the requested core image, parsed from its tag
+	// +optional
+	CoreVersion string `json:"coreVersion,omitempty"`
+
+	// RunningCoreVersion is the version of the core image that is currently running, parsed from its tag
+	// +optional
+	RunningCoreVersion string `json:"runningCoreVersion,omitempty"`
+
+	// Message explains the decision
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// CompatibilityDecision is a string enum type for the compatibility decisions
+type CompatibilityDecision string
+
+// These are the valid compatibility decisions:
+const (
+	// CompatibilityDecisionSupported means the core image is in the compatibility matrix of the operator
+	CompatibilityDecisionSupported CompatibilityDecision = "Supported"
+
+	// CompatibilityDecisionOverridden means the core image is not supported
+	// but it was allowed explicitly with an annotation on the system
+	CompatibilityDecisionOverridden CompatibilityDecision = "Overridden"
+
+	// CompatibilityDecisionRejected means the core image is not supported and the system is rejected
+	CompatibilityDecisionRejected CompatibilityDecision = "Rejected"
+
+	// CompatibilityDecisionUnknown means the version of the core image could not be determined,
+	// like for custom images, and the image is used without checking it
+	CompatibilityDecisionUnknown CompatibilityDecision = "Unknown"
 )
 
 // CleanupPolicySpec specifies the cleanup policy
@@ -374,6 +697,36 @@ const (
 	DeleteOBCConfirmation CleanupConfirmationProperty = "yes-really-destroy-obc"
 )
 
+// LogLevel is a string enum type for the log levels of the system components.
+// noobaa-core always logs errors and warnings, so error, warn and info are the same level for core and endpoints.
+// +kubebuilder:validation:Enum=error;warn;info;debug;trace
+type LogLevel string
+
+// These are the valid log levels:
+const (
+	// LogLevelError logs only errors
+	LogLevelError LogLevel = "error"
+	// LogLevelWarn logs errors and warnings
+	LogLevelWarn LogLevel = "warn"
+	// LogLevelInfo is the default level
+	LogLevelInfo LogLevel = "info"
+	// LogLevelDebug adds debug logs
+	LogLevelDebug LogLevel = "debug"
+	// LogLevelTrace logs everything
+	LogLevelTrace LogLevel = "trace"
+)
+
+// LogFormat is a string enum type for the log formats of the operator.
+type LogFormat string
+
+// These are the valid log formats:
+const (
+	// LogFormatText is human readable lines
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON is a json object per line
+	LogFormatJSON LogFormat = "json"
+)
+
 // DBTypes is a string enum type for specify the types of DB that are supported.
 type DBTypes string
 
@@ -89,6 +89,10 @@ func CmdCreateAWSS3() *cobra.Command {
 		"region", "",
 		"The AWS bucket region",
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -123,6 +127,10 @@ func CmdCreateS3Compatible() *cobra.Command {
 		"signature-version", "v4",
 		"The S3 signature version v4|v2",
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -153,6 +161,10 @@ func CmdCreateIBMCos() *cobra.Command {
 		"endpoint", "",
 		"The target IBM Cos endpoint",
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -179,6 +191,10 @@ func CmdCreateAzureBlob() *cobra.Command {
 		"secret-name", "",
 		`The name of a secret for authentication - should have AccountName and AccountKey properties`,
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -201,6 +217,10 @@ func CmdCreateGoogleCloudStorage() *cobra.Command {
 		"secret-name", "",
 		`The name of a secret for authentication - should have GoogleServiceAccountPrivateKeyJson property`,
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -243,6 +263,7 @@ func CmdStatus() *cobra.Command {
 		Short: "Status backing store",
 		Run:   RunStatus,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -263,6 +284,7 @@ func CmdList() *cobra.Command {
 		Short: "List backing stores",
 		Run:   RunList,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -315,6 +337,13 @@ func createCommon(cmd *cobra.Command, args []string, storeType nbv1.StoreType, p
 
 	populate(backStore, secret)
 
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		allowUnreachable, _ := cmd.Flags().GetBool("allow-unreachable")
+		dryRunCreate(backStore, secret, secretName, allowUnreachable)
+		return
+	}
+
 	// Create backing store CR
 	util.Panic(controllerutil.SetControllerReference(sys, backStore, scheme.Scheme))
 	if !util.KubeCreateSkipExisting(backStore) {
@@ -340,6 +369,33 @@ func createCommon(cmd *cobra.Command, args []string, storeType nbv1.StoreType, p
 	}
 }
 
+// dryRunCreate checks the connection and target bucket of a backing store without creating it
+func dryRunCreate(backStore *nbv1.BackingStore, secret *corev1.Secret, secretName string, allowUnreachable bool) {
+	log := util.Logger()
+	if GetBackingStoreSecret(backStore) == nil {
+		log.Fatalf(`❌ Dry run is not supported for BackingStore type %q`, backStore.Spec.Type)
+	}
+	if secretName != "" && !util.KubeCheck(secret) {
+		log.Fatalf(`❌ Could not get Secret %q in namespace %q`, secret.Name, secret.Namespace)
+	}
+	r := &Reconciler{BackingStore: backStore, Secret: secret}
+	conn, err := r.MakeExternalConnectionParams()
+	if err != nil {
+		log.Fatalf(`❌ Invalid BackingStore %q: %s`, backStore.Name, err)
+	}
+	switch system.RunConnectionDryRun(conn, GetBackingStoreTargetBucket(backStore), true) {
+	case system.DryRunFailed:
+		log.Fatalf(`❌ Dry run failed, BackingStore %q was not created`, backStore.Name)
+	case system.DryRunNotVerified:
+		if !allowUnreachable {
+			log.Fatalf(`❌ Dry run could not verify the target bucket from this host, BackingStore %q was not created (run again with --allow-unreachable to accept it)`, backStore.Name)
+		}
+		log.Printf(`⚠️  Dry run passed without verifying the target bucket, BackingStore %q was not created (run again without --dry-run to create it)`, backStore.Name)
+		return
+	}
+	log.Printf(`✅ Dry run passed, BackingStore %q was not created (run again without --dry-run to create it)`, backStore.Name)
+}
+
 // RunCreateAWSS3 runs a CLI command
 func RunCreateAWSS3(cmd *cobra.Command, args []string) {
 	createCommon(cmd, args, nbv1.StoreTypeAWSS3, func(backStore *nbv1.BackingStore, secret *corev1.Secret) {
@@ -673,6 +729,7 @@ func isPodinNoobaa(pod *corev1.Pod, hostsInfo *[]nb.HostInfo) bool {
 // RunStatus runs a CLI command
 func RunStatus(cmd *cobra.Command, args []string) {
 	log := util.Logger()
+	out := util.GetOutput(cmd)
 
 	if len(args) != 1 || args[0] == "" {
 		log.Fatalf(`❌ Missing expected arguments: <backing-store-name> %s`, cmd.UsageString())
@@ -692,6 +749,10 @@ func RunStatus(cmd *cobra.Command, args []string) {
 			backStore.Name, backStore.Namespace)
 	}
 
+	if out.Print(backStore) {
+		return
+	}
+
 	secretRef := GetBackingStoreSecret(backStore)
 	if secretRef != nil {
 		secret.Name = secretRef.Name
@@ -781,32 +842,44 @@ func CheckPhase(backStore *nbv1.BackingStore) {
 
 // RunList runs a CLI command
 func RunList(cmd *cobra.Command, args []string) {
+	out := util.GetOutput(cmd)
 	list := &nbv1.BackingStoreList{
 		TypeMeta: metav1.TypeMeta{Kind: "BackingStoreList"},
 	}
 	if !util.KubeList(list, &client.ListOptions{Namespace: options.Namespace}) {
 		return
 	}
+	if out.Print(list) {
+		return
+	}
 	if len(list.Items) == 0 {
 		fmt.Printf("No backing stores found.\n")
 		return
 	}
-	table := (&util.PrintTable{}).AddRow(
+	headers := []string{
 		"NAME",
 		"TYPE",
 		"TARGET-BUCKET",
 		"PHASE",
 		"AGE",
-	)
+	}
+	if out.IsWide() {
+		headers = append(headers, "MODE", "MODE-TIME")
+	}
+	table := (&util.PrintTable{}).AddRow(headers...)
 	for i := range list.Items {
 		bs := &list.Items[i]
-		table.AddRow(
+		row := []string{
 			bs.Name,
 			string(bs.Spec.Type),
 			GetBackingStoreTargetBucket(bs),
 			string(bs.Status.Phase),
 			time.Since(bs.CreationTimestamp.Time).Round(time.Second).String(),
-		)
+		}
+		if out.IsWide() {
+			row = append(row, bs.Status.Mode.ModeCode, bs.Status.Mode.TimeStamp)
+		}
+		table.AddRow(row...)
 	}
 	fmt.Print(table.String())
 }
@@ -876,3 +949,29 @@ func GetBackingStoreTargetBucket(bs *nbv1.BackingStore) string {
 		return ""
 	}
 }
+
+// MapSystemToPVPoolBackingStores returns the pv-pool backing stores of the system to reconcile,
+// so that their agents are rolled when the actual image of the system changes
+func MapSystemToPVPoolBackingStores(sys types.NamespacedName) []reconcile.Request {
+	log := util.Logger()
+	backingStoreList := &nbv1.BackingStoreList{
+		TypeMeta: metav1.TypeMeta{Kind: "BackingStoreList"},
+	}
+	if !util.KubeList(backingStoreList, &client.ListOptions{Namespace: sys.Namespace}) {
+		log.Infof("did not find backing stores in namespace %q", sys.Namespace)
+		return nil
+	}
+	reqs := []reconcile.Request{}
+	for i := range backingStoreList.Items {
+		bs := &backingStoreList.Items[i]
+		if bs.Spec.Type == nbv1.StoreTypePVPool {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      bs.Name,
+					Namespace: bs.Namespace,
+				},
+			})
+		}
+	}
+	return reqs
+}
@@ -44,6 +44,12 @@ func init() {
 	bsModeInfoMap = modeInfoMap()
 }
 
+// GetModeInfo returns the phase and severity of a backing store mode reported by noobaa-core
+func GetModeInfo(mode string) (ModeInfo, bool) {
+	info, exist := bsModeInfoMap[mode]
+	return info, exist
+}
+
 func modeInfoMap() map[string]ModeInfo {
 	return map[string]ModeInfo{
 		"INITIALIZING":        {nbv1.BackingStorePhaseCreating, corev1.EventTypeNormal},
@@ -152,6 +158,7 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 
 	if r.BackingStore.UID == "" {
 		log.Infof("BackingStore %q not found or deleted. Skip reconcile.", r.BackingStore.Name)
+		util.GlobalBackoff.Reset(r.BackingStore)
 		return reconcile.Result{}, nil
 	}
 
@@ -159,17 +166,30 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		if !util.KubeUpdate(r.BackingStore) {
 			log.Errorf("❌ BackingStore %q failed to add mandatory meta fields", r.BackingStore.Name)
 
-			res.RequeueAfter = 3 * time.Second
+			res.RequeueAfter = util.GlobalBackoff.TemporaryError(r.BackingStore, r.DependenciesVersion(),
+				fmt.Errorf("failed to add mandatory meta fields"), nil, nil)
 			return res, nil
 		}
 	}
 
+	if paused, requeue := util.CheckReconcilePause(r.BackingStore, &r.BackingStore.Status.Conditions, r.Recorder); paused {
+		// the mode of a paused backingstore is still updated by the system reconcile
+		res.RequeueAfter = requeue
+		if err := r.UpdateStatus(); err != nil {
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter,
+				util.GlobalBackoff.TemporaryError(r.BackingStore, r.DependenciesVersion(), err, nil, nil))
+			log.Warnf("⏳ Temporary Error: %s", err)
+		}
+		return res, nil
+	}
+
 	system.CheckSystem(r.NooBaa)
 
 	oldStatefulSet := &appsv1.StatefulSet{}
 	oldStatefulSet.Name = fmt.Sprintf("%s-%s-noobaa", r.BackingStore.Name, options.SystemName)
 	oldStatefulSet.Namespace = r.Request.Namespace
 	var err error
+	backoffCounted := false
 	if util.KubeCheck(oldStatefulSet) {
 		err = r.upgradeBackingStore(oldStatefulSet)
 	}
@@ -190,16 +210,20 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		if perr, isPERR := err.(*util.PersistentError); isPERR {
 			r.SetPhase(nbv1.BackingStorePhaseRejected, perr.Reason, perr.Message)
 			log.Errorf("❌ Persistent Error: %s", err)
+			util.GlobalBackoff.Reset(r.BackingStore)
 			if r.Recorder != nil {
 				r.Recorder.Eventf(r.BackingStore, corev1.EventTypeWarning, perr.Reason, perr.Message)
 			}
 		} else {
-			res.RequeueAfter = 3 * time.Second
 			// leave current phase as is
 			r.SetPhase("", "TemporaryError", err.Error())
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter, util.GlobalBackoff.TemporaryError(
+				r.BackingStore, r.DependenciesVersion(), err, &r.BackingStore.Status.Conditions, r.Recorder))
+			backoffCounted = true
 			log.Warnf("⏳ Temporary Error: %s", err)
 		}
 	} else {
+		util.GlobalBackoff.Reset(r.BackingStore)
 		mode := r.BackingStore.Status.Mode.ModeCode
 		phaseInfo, exist := bsModeInfoMap[mode]
 
@@ -224,8 +248,12 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 	// if updateStatus will fail to update the CR for any reason we will continue to requeue the reconcile
 	// until the spec status will reflect the actual status of the backingstore
 	if err != nil {
-		res.RequeueAfter = 3 * time.Second
 		log.Warnf("⏳ Temporary Error: %s", err)
+		// a temporary error of the phases was already counted by the backoff of this reconcile
+		if !backoffCounted {
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter,
+				util.GlobalBackoff.TemporaryError(r.BackingStore, r.DependenciesVersion(), err, nil, nil))
+		}
 	}
 	return res, nil
 }
@@ -313,6 +341,11 @@ func (r *Reconciler) UpdateStatus() error {
 	return nil
 }
 
+// DependenciesVersion returns the version of the system and secret of the backing store for the reconcile backoff
+func (r *Reconciler) DependenciesVersion() string {
+	return util.DependenciesVersion(r.NooBaa, r.Secret)
+}
+
 // ReconcilePhaseVerifying checks that we have the system and secret needed to reconcile
 func (r *Reconciler) ReconcilePhaseVerifying() error {
 
@@ -484,7 +517,7 @@ func (r *Reconciler) FinalizeDeletion() error {
 // and prepares the structures to reconcile
 func (r *Reconciler) ReadSystemInfo() error {
 
-	sysClient, err := system.Connect(false)
+	sysClient, err := system.ConnectNamespace(r.Request.Namespace, false)
 	if err != nil {
 		return err
 	}
@@ -937,7 +970,7 @@ func (r *Reconciler) ReconcilePool() error {
 				cephCluster := &cephv1.CephCluster{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "ocs-storagecluster",
-						Namespace: options.Namespace,
+						Namespace: r.Request.Namespace,
 					},
 				}
 				if util.KubeCheck(cephCluster) {
@@ -979,14 +1012,15 @@ func (r *Reconciler) reconcilePvPool() error {
 	}
 	podsList := &corev1.PodList{}
 	pvcsList := &corev1.PersistentVolumeClaimList{}
-	util.KubeList(podsList, client.InNamespace(options.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
-	util.KubeList(pvcsList, client.InNamespace(options.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
+	util.KubeList(podsList, client.InNamespace(r.Request.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
+	// the pvcs are listed directly since their names are random and a stale list would create extra volumes
+	util.KubeListDirect(pvcsList, client.InNamespace(r.Request.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
 	if len(pvcsList.Items) < r.BackingStore.Spec.PVPool.NumVolumes {
 		err := r.reconcileMissingPvcs(pvcsList)
 		if err != nil {
 			return err
 		}
-		util.KubeList(pvcsList, client.InNamespace(options.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
+		util.KubeListDirect(pvcsList, client.InNamespace(r.Request.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
 	}
 	if len(podsList.Items) < len(pvcsList.Items) {
 		err := r.reconcileMissingPods(podsList, pvcsList)
@@ -1018,7 +1052,7 @@ func (r *Reconciler) reconcileMissingPods(podsList *corev1.PodList, pvcsList *co
 			postfix := pvc.Name[i+1:]
 			newPod := r.PodAgentTemplate.DeepCopy()
 			newPod.Name = fmt.Sprintf("%s-%s-pod-%s", r.BackingStore.Name, options.SystemName, postfix)
-			newPod.Namespace = options.Namespace
+			newPod.Namespace = r.Request.Namespace
 			newPod.Spec.Volumes[1].PersistentVolumeClaim.ClaimName = pvc.Name
 			r.Own(newPod)
 			util.KubeCreateSkipExisting(newPod)
@@ -1030,10 +1064,16 @@ func (r *Reconciler) reconcileMissingPods(podsList *corev1.PodList, pvcsList *co
 func (r *Reconciler) reconcileExistingPods(podsList *corev1.PodList) error {
 	noneAttachingAgents := 0
 	failedAttachingAgents := 0
+	configRollout := r.canRolloutConfig(podsList)
 	for _, pod := range podsList.Items {
 		// check if pod need to be updated and deleted
 		if r.needUpdate(&pod) {
 			util.KubeDelete(&pod)
+		} else if configRollout && r.needConfigUpdate(&pod) {
+			// config changes restart a single agent at a time to keep the pool available
+			r.Logger.Infof("Change in config detected, restarting agent pod %s", pod.Name)
+			util.KubeDelete(&pod)
+			configRollout = false
 		} else if !r.isPodinNoobaa(&pod) {
 			noneAttachingAgents++
 			if time.Since(pod.CreationTimestamp.Time) > 10*time.Minute {
@@ -1080,7 +1120,7 @@ func (r *Reconciler) needUpdate(pod *corev1.Pod) bool {
 	if noobaaSecret == nil {
 		sa := util.KubeObject(bundle.File_deploy_service_account_yaml).(*corev1.ServiceAccount)
 		sa.Name = pod.Spec.ServiceAccountName
-		sa.Namespace = options.Namespace
+		sa.Namespace = r.Request.Namespace
 		if util.KubeCheck(sa) && !reflect.DeepEqual(sa.ImagePullSecrets, podSecrets) {
 			r.Logger.Warnf("Change in Image Pull Secrets detected: SA(%v) Spec(%v)", sa.ImagePullSecrets, podSecrets)
 			return true
@@ -1092,6 +1132,28 @@ func (r *Reconciler) needUpdate(pod *corev1.Pod) bool {
 	return false
 }
 
+func (r *Reconciler) needConfigUpdate(pod *corev1.Pod) bool {
+	configEnv, err := system.ConfigEnv(r.NooBaa.Spec.Config)
+	if err != nil {
+		return false
+	}
+	return system.IsConfigEnvChanged(pod, &pod.Spec.Containers[0], configEnv)
+}
+
+// canRolloutConfig returns true when all the pool agents are running and attached to noobaa
+func (r *Reconciler) canRolloutConfig(podsList *corev1.PodList) bool {
+	if len(podsList.Items) < r.BackingStore.Spec.PVPool.NumVolumes {
+		return false
+	}
+	for i := range podsList.Items {
+		pod := &podsList.Items[i]
+		if pod.DeletionTimestamp != nil || !r.isPodinNoobaa(pod) {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *Reconciler) reconcileMissingPvcs(pvcsList *corev1.PersistentVolumeClaimList) error {
 	r.updatePvcTemplate()
 	for i := len(pvcsList.Items); i < r.BackingStore.Spec.PVPool.NumVolumes; i++ {
@@ -1099,7 +1161,7 @@ func (r *Reconciler) reconcileMissingPvcs(pvcsList *corev1.PersistentVolumeClaim
 		pvcName := fmt.Sprintf("%s-%s-pvc-%s", r.BackingStore.Name, options.SystemName, postfix)
 		newPvc := r.PvcAgentTemplate.DeepCopy()
 		newPvc.Name = pvcName
-		newPvc.Namespace = options.Namespace
+		newPvc.Namespace = r.Request.Namespace
 		r.Own(newPvc)
 		util.KubeCreateSkipExisting(newPvc)
 	}
@@ -1133,6 +1195,9 @@ func (r *Reconciler) updatePodTemplate() {
 	util.ReflectEnvVariable(&c.Env, "HTTP_PROXY")
 	util.ReflectEnvVariable(&c.Env, "HTTPS_PROXY")
 	util.ReflectEnvVariable(&c.Env, "NO_PROXY")
+	if configEnv, err := system.ConfigEnv(r.NooBaa.Spec.Config); err == nil {
+		system.SetConfigEnv(&r.PodAgentTemplate.ObjectMeta, c, configEnv, nil)
+	}
 
 	c.Image = r.NooBaa.Status.ActualImage
 	if r.NooBaa.Spec.ImagePullSecret == nil {
@@ -1169,9 +1234,9 @@ func (r *Reconciler) updatePvcTemplate() {
 
 func (r *Reconciler) deletePvPool() error {
 	podsList := &corev1.PodList{}
-	util.KubeList(podsList, client.InNamespace(options.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
-	util.KubeDeleteAllOf(&corev1.Pod{}, client.InNamespace(options.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
-	util.KubeDeleteAllOf(&corev1.PersistentVolumeClaim{}, client.InNamespace(options.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
+	util.KubeList(podsList, client.InNamespace(r.Request.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
+	util.KubeDeleteAllOf(&corev1.Pod{}, client.InNamespace(r.Request.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
+	util.KubeDeleteAllOf(&corev1.PersistentVolumeClaim{}, client.InNamespace(r.Request.Namespace), client.MatchingLabels{"pool": r.BackingStore.Name})
 	return nil
 }
 
@@ -1188,7 +1253,7 @@ func (r *Reconciler) upgradeBackingStore(sts *appsv1.StatefulSet) error {
 	o := util.KubeObject(bundle.File_deploy_internal_secret_empty_yaml)
 	secret := o.(*corev1.Secret)
 	secret.Name = fmt.Sprintf("backing-store-%s-%s", nbv1.StoreTypePVPool, r.BackingStore.Name)
-	secret.Namespace = options.Namespace
+	secret.Namespace = r.Request.Namespace
 	util.KubeCheck(secret)
 	secret.StringData["AGENT_CONFIG"] = agentConfig // update secret for future pods
 	util.KubeUpdate(secret)
@@ -53,6 +53,7 @@ func CmdStatus() *cobra.Command {
 		Short: "Show the status of a NooBaa bucket",
 		Run:   RunStatus,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -63,6 +64,7 @@ func CmdList() *cobra.Command {
 		Short: "List NooBaa buckets",
 		Run:   RunList,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -100,12 +102,16 @@ func RunStatus(cmd *cobra.Command, args []string) {
 	if len(args) != 1 || args[0] == "" {
 		log.Fatalf(`Missing expected arguments: <bucket-name> %s`, cmd.UsageString())
 	}
+	out := util.GetOutput(cmd)
 	bucketName := args[0]
 	nbClient := system.GetNBClient()
 	b, err := nbClient.ReadBucketAPI(nb.ReadBucketParams{Name: bucketName})
 	if err != nil {
 		log.Fatal(err)
 	}
+	if out.Print(b) {
+		return
+	}
 
 	fmt.Printf("\n")
 	fmt.Printf("Bucket status:\n")
@@ -126,17 +132,30 @@ func RunStatus(cmd *cobra.Command, args []string) {
 	if b.NumObjects != nil {
 		fmt.Printf("  %-22s : %d\n", "Num Objects", b.NumObjects.Value)
 	}
-	if b.DataCapacity != nil {
-		fmt.Printf("  %-22s : %s\n", "Data Size", nb.BigIntToHumanBytes(b.DataCapacity.Size))
-		fmt.Printf("  %-22s : %s\n", "Data Size Reduced", nb.BigIntToHumanBytes(b.DataCapacity.SizeReduced))
-		fmt.Printf("  %-22s : %s\n", "Data Space Avail", nb.BigIntToHumanBytes(b.DataCapacity.AvailableToUpload))
+	if b.Data != nil {
+		fmt.Printf("  %-22s : %s\n", "Data Size", nb.BigIntToHumanBytes(b.Data.Size))
+		fmt.Printf("  %-22s : %s\n", "Data Size Reduced", nb.BigIntToHumanBytes(b.Data.SizeReduced))
+		fmt.Printf("  %-22s : %s\n", "Data Space Avail", nb.BigIntToHumanBytes(b.Data.AvailableForUpload))
 	}
 	fmt.Printf("\n")
 }
 
 // RunList runs a CLI command
 func RunList(cmd *cobra.Command, args []string) {
+	out := util.GetOutput(cmd)
 	nbClient := system.GetNBClient()
+	if out.IsWide() || out.IsStructured() {
+		// read_system returns the full info of all the buckets in a single call
+		sysInfo, err := nbClient.ReadSystemAPI()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if out.Print(sysInfo.Buckets) {
+			return
+		}
+		RunListWide(sysInfo.Buckets)
+		return
+	}
 	list, err := nbClient.ListBucketsAPI()
 	if err != nil {
 		log.Fatal(err)
@@ -154,3 +173,44 @@ func RunList(cmd *cobra.Command, args []string) {
 	fmt.Print(table.String())
 	fmt.Printf("\n")
 }
+
+// RunListWide prints the buckets table with the bucket info columns
+func RunListWide(buckets []nb.BucketInfo) {
+	if len(buckets) == 0 {
+		fmt.Printf("No buckets found.\n")
+		return
+	}
+	table := (&util.PrintTable{}).AddRow(
+		"BUCKET-NAME",
+		"TYPE",
+		"MODE",
+		"OBC-NAMESPACE",
+		"BUCKET-CLASS",
+		"OBJECTS",
+		"DATA-SIZE",
+		"DATA-SIZE-REDUCED",
+	)
+	for i := range buckets {
+		b := &buckets[i]
+		obcNamespace := ""
+		bucketClass := ""
+		if b.BucketClaim != nil {
+			obcNamespace = b.BucketClaim.Namespace
+			bucketClass = b.BucketClaim.BucketClass
+		}
+		objects := ""
+		if b.NumObjects != nil {
+			objects = fmt.Sprintf("%d", b.NumObjects.Value)
+		}
+		size := ""
+		sizeReduced := ""
+		if b.Data != nil {
+			size = nb.BigIntToHumanBytes(b.Data.Size)
+			sizeReduced = nb.BigIntToHumanBytes(b.Data.SizeReduced)
+		}
+		table.AddRow(b.Name, b.BucketType, b.Mode, obcNamespace, bucketClass, objects, size, sizeReduced)
+	}
+	fmt.Printf("\n")
+	fmt.Print(table.String())
+	fmt.Printf("\n")
+}
@@ -156,6 +156,7 @@ func CmdStatus() *cobra.Command {
 		Short: "Status bucket class",
 		Run:   RunStatus,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -166,6 +167,7 @@ func CmdList() *cobra.Command {
 		Short: "List bucket classes",
 		Run:   RunList,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -372,6 +374,7 @@ func RunDelete(cmd *cobra.Command, args []string) {
 // RunStatus runs a CLI command
 func RunStatus(cmd *cobra.Command, args []string) {
 	log := util.Logger()
+	out := util.GetOutput(cmd)
 
 	if len(args) != 1 || args[0] == "" {
 		log.Fatalf(`❌ Missing expected arguments: <bucket-class-name> %s`, cmd.UsageString())
@@ -388,6 +391,10 @@ func RunStatus(cmd *cobra.Command, args []string) {
 			bucketClass.Name, bucketClass.Namespace)
 	}
 
+	if out.Print(bucketClass) {
+		return
+	}
+
 	CheckPhase(bucketClass)
 
 	fmt.Println()
@@ -453,34 +460,46 @@ func CheckPhase(bucketClass *nbv1.BucketClass) {
 
 // RunList runs a CLI command
 func RunList(cmd *cobra.Command, args []string) {
+	out := util.GetOutput(cmd)
 	list := &nbv1.BucketClassList{
 		TypeMeta: metav1.TypeMeta{Kind: "BucketClassList"},
 	}
 	if !util.KubeList(list, &client.ListOptions{Namespace: options.Namespace}) {
 		return
 	}
+	if out.Print(list) {
+		return
+	}
 	if len(list.Items) == 0 {
 		fmt.Printf("No bucket classes found.\n")
 		return
 	}
-	table := (&util.PrintTable{}).AddRow(
+	headers := []string{
 		"NAME",
 		"PLACEMENT",
 		"NAMESPACE-POLICY",
 		"PHASE",
 		"AGE",
-	)
+	}
+	if out.IsWide() {
+		headers = append(headers, "MODE")
+	}
+	table := (&util.PrintTable{}).AddRow(headers...)
 	for i := range list.Items {
 		bc := &list.Items[i]
 		pp, _ := json.Marshal(bc.Spec.PlacementPolicy)
 		np, _ := json.Marshal(bc.Spec.NamespacePolicy)
-		table.AddRow(
+		row := []string{
 			bc.Name,
 			fmt.Sprintf("%+v", string(pp)),
 			fmt.Sprintf("%+v", string(np)),
 			string(bc.Status.Phase),
 			time.Since(bc.CreationTimestamp.Time).Round(time.Second).String(),
-		)
+		}
+		if out.IsWide() {
+			row = append(row, bc.Status.Mode)
+		}
+		table.AddRow(row...)
 	}
 	fmt.Print(table.String())
 }
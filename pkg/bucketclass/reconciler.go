@@ -3,7 +3,6 @@ package bucketclass
 import (
 	"context"
 	"fmt"
-	"time"
 
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
 	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
@@ -37,6 +36,7 @@ type Reconciler struct {
 
 	BucketClass *nbv1.BucketClass
 	NooBaa      *nbv1.NooBaa
+	Stores      []metav1.Object
 }
 
 // NewReconciler initializes a reconciler to be used for loading or reconciling a bucket class
@@ -83,6 +83,7 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 
 	if r.BucketClass.UID == "" {
 		log.Infof("BucketClass %q not found or deleted. Skip reconcile.", r.BucketClass.Name)
+		util.GlobalBackoff.Reset(r.BucketClass)
 		return reconcile.Result{}, nil
 	}
 
@@ -90,14 +91,27 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		if !util.KubeUpdate(r.BucketClass) {
 			log.Errorf("❌ BucketClass %q failed to add mandatory meta fields", r.BucketClass.Name)
 
-			res.RequeueAfter = 3 * time.Second
+			res.RequeueAfter = util.GlobalBackoff.TemporaryError(r.BucketClass, r.DependenciesVersion(),
+				fmt.Errorf("failed to add mandatory meta fields"), nil, nil)
 			return res, nil
 		}
 	}
 
+	if paused, requeue := util.CheckReconcilePause(r.BucketClass, &r.BucketClass.Status.Conditions, r.Recorder); paused {
+		// the mode of a paused bucketclass is still updated by the system reconcile
+		res.RequeueAfter = requeue
+		if err := r.UpdateStatus(); err != nil {
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter,
+				util.GlobalBackoff.TemporaryError(r.BucketClass, r.DependenciesVersion(), err, nil, nil))
+			log.Warnf("⏳ Temporary Error: %s", err)
+		}
+		return res, nil
+	}
+
 	system.CheckSystem(r.NooBaa)
 
 	var err error
+	backoffCounted := false
 	if r.BucketClass.DeletionTimestamp != nil {
 		err = r.ReconcileDeletion()
 	} else {
@@ -107,16 +121,20 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		if perr, isPERR := err.(*util.PersistentError); isPERR {
 			r.SetPhase(nbv1.BucketClassPhaseRejected, perr.Reason, perr.Message)
 			log.Errorf("❌ Persistent Error: %s", err)
+			util.GlobalBackoff.Reset(r.BucketClass)
 			if r.Recorder != nil {
 				r.Recorder.Eventf(r.BucketClass, corev1.EventTypeWarning, perr.Reason, perr.Message)
 			}
 		} else {
-			res.RequeueAfter = 3 * time.Second
 			// leave current phase as is
 			r.SetPhase("", "TemporaryError", err.Error())
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter, util.GlobalBackoff.TemporaryError(
+				r.BucketClass, r.DependenciesVersion(), err, &r.BucketClass.Status.Conditions, r.Recorder))
+			backoffCounted = true
 			log.Warnf("⏳ Temporary Error: %s", err)
 		}
 	} else {
+		util.GlobalBackoff.Reset(r.BucketClass)
 		if r.BucketClass.Status.Mode != "OPTIMAL" && r.BucketClass.Status.Mode != "" {
 			if r.Recorder != nil {
 				r.Recorder.Eventf(r.BucketClass, corev1.EventTypeWarning, r.BucketClass.Status.Mode, r.BucketClass.Status.Mode)
@@ -134,8 +152,12 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 	// if updateStatus will fail to update the CR for any reason we will continue to requeue the reconcile
 	// until the spec status will reflect the actual status of the bucketclass
 	if err != nil {
-		res.RequeueAfter = 3 * time.Second
 		log.Warnf("⏳ Temporary Error: %s", err)
+		// a temporary error of the phases was already counted by the backoff of this reconcile
+		if !backoffCounted {
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter,
+				util.GlobalBackoff.TemporaryError(r.BucketClass, r.DependenciesVersion(), err, nil, nil))
+		}
 	}
 	return res, nil
 }
@@ -187,6 +209,11 @@ func (r *Reconciler) UpdateStatus() error {
 	return nil
 }
 
+// DependenciesVersion returns the version of the system and stores of the bucket class for the reconcile backoff
+func (r *Reconciler) DependenciesVersion() string {
+	return util.DependenciesVersion(append([]metav1.Object{r.NooBaa}, r.Stores...)...)
+}
+
 // ReconcilePhaseVerifying checks that we have the system and secret needed to reconcile
 func (r *Reconciler) ReconcilePhaseVerifying() error {
 
@@ -220,6 +247,7 @@ func (r *Reconciler) ReconcilePhaseVerifying() error {
 					return util.NewPersistentError("MissingBackingStore",
 						fmt.Sprintf("NooBaa BackingStore %q not found or deleted", backingStoreName))
 				}
+				r.Stores = append(r.Stores, backStore)
 				if backStore.Status.Phase == nbv1.BackingStorePhaseRejected {
 					return util.NewPersistentError("RejectedBackingStore",
 						fmt.Sprintf("NooBaa BackingStore %q is in rejected phase", backingStoreName))
@@ -247,13 +275,14 @@ func (r *Reconciler) ReconcilePhaseVerifying() error {
 				TypeMeta: metav1.TypeMeta{Kind: "NamespaceStore"},
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
-					Namespace: options.Namespace,
+					Namespace: r.Request.Namespace,
 				},
 			}
 			if !util.KubeCheck(nsStore) {
 				return util.NewPersistentError("MissingNamespaceStore",
 					fmt.Sprintf("NooBaa NamespaceStore %q not found or deleted", name))
 			}
+			r.Stores = append(r.Stores, nsStore)
 			if nsStore.Status.Phase == nbv1.NamespaceStorePhaseRejected {
 				return util.NewPersistentError("RejectedNamespaceStore",
 					fmt.Sprintf("NooBaa NamespaceStore %q is in rejected phase", name))
@@ -280,7 +309,7 @@ func (r *Reconciler) ReconcilePhaseConfiguring() error {
 	)
 
 	objectBuckets := &nbv1.ObjectBucketList{}
-	obcSelector, _ := labels.Parse("noobaa-domain=" + options.SubDomainNS())
+	obcSelector, _ := labels.Parse("noobaa-domain=" + options.SubDomainNSFor(r.Request.Namespace))
 	util.KubeList(objectBuckets, &client.ListOptions{LabelSelector: obcSelector})
 
 	var bucketNames []string
@@ -302,7 +331,7 @@ func (r *Reconciler) ReconcilePhaseConfiguring() error {
 		return nil
 	}
 
-	sysClient, err := system.Connect(false)
+	sysClient, err := system.ConnectNamespace(r.Request.Namespace, false)
 	if err != nil {
 		return err
 	}
@@ -997,7 +997,7 @@ spec:
       status: {}
 `
 
-const Sha256_deploy_crds_noobaa_io_noobaas_crd_yaml = "a7a87b507e0d7ca2ee52f440e86b1d7383123d14c5e9265ca5b837345175aca8"
+const Sha256_deploy_crds_noobaa_io_noobaas_crd_yaml = "1de3e32156bfd6b9db84bd5416c5c11a9fbff27113c46b499b78ef1af3eaef8a"
 
 const File_deploy_crds_noobaa_io_noobaas_crd_yaml = `apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
@@ -1654,6 +1654,141 @@ spec:
                       cleanup confirmation
                     type: string
                 type: object
+              config:
+                description: Config (optional) overrides the configuration of the
+                  noobaa-core processes in the core, endpoint and pv-pool agent pods.
+                  Changes roll out the affected pods.
+                properties:
+                  chunkSplitAvgChunk:
+                    description: ChunkSplitAvgChunk (optional) sets the average size
+                      in bytes of the chunks that objects are split to
+                    format: int64
+                    minimum: 1048576
+                    type: integer
+                  configJS:
+                    additionalProperties:
+                      type: string
+                    description: 'ConfigJS (optional) overrides noobaa-core config.js
+                      keys, for example MAX_OBJECT_PART_SIZE: "104857600". Keys are
+                      upper case config.js names, and values are parsed by noobaa-core
+                      by the type of the key.'
+                    type: object
+                  disableCompression:
+                    description: DisableCompression (optional) disables the compression
+                      of the data chunks
+                    type: boolean
+                  env:
+                    description: Env (optional) adds environment variables to the
+                      noobaa-core containers. Variables that are managed by the operator
+                      are not allowed.
+                    items:
+                      description: EnvVar represents an environment variable present
+                        in a Container.
+                      properties:
+                        name:
+                          description: Name of the environment variable. Must be a
+                            C_IDENTIFIER.
+                          type: string
+                        value:
+                          description: 'Variable references $(VAR_NAME) are expanded
+                            using the previous defined environment variables in the
+                            container and any service environment variables. If a
+                            variable cannot be resolved, the reference in the input
+                            string will be unchanged. The $(VAR_NAME) syntax can be
+                            escaped with a double $$, ie: $$(VAR_NAME). Escaped references
+                            will never be expanded, regardless of whether the variable
+                            exists or not. Defaults to "".'
+                          type: string
+                        valueFrom:
+                          description: Source for the environment variable's value.
+                            Cannot be used if value is not empty.
+                          properties:
+                            configMapKeyRef:
+                              description: Selects a key of a ConfigMap.
+                              properties:
+                                key:
+                                  description: The key to select.
+                                  type: string
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names
+                                    TODO: Add other useful fields. apiVersion, kind,
+                                    uid?'
+                                  type: string
+                                optional:
+                                  description: Specify whether the ConfigMap or its
+                                    key must be defined
+                                  type: boolean
+                              required:
+                              - key
+                              type: object
+                            fieldRef:
+                              description: 'Selects a field of the pod: supports metadata.name,
+                                metadata.namespace, ` + "`" + `metadata.labels[''<KEY>'']` + "`" + `,
+                                ` + "`" + `metadata.annotations[''<KEY>'']` + "`" + `, spec.nodeName,
+                                spec.serviceAccountName, status.hostIP, status.podIP,
+                                status.podIPs.'
+                              properties:
+                                apiVersion:
+                                  description: Version of the schema the FieldPath
+                                    is written in terms of, defaults to "v1".
+                                  type: string
+                                fieldPath:
+                                  description: Path of the field to select in the
+                                    specified API version.
+                                  type: string
+                              required:
+                              - fieldPath
+                              type: object
+                            resourceFieldRef:
+                              description: 'Selects a resource of the container: only
+                                resources limits and requests (limits.cpu, limits.memory,
+                                limits.ephemeral-storage, requests.cpu, requests.memory
+                                and requests.ephemeral-storage) are currently supported.'
+                              properties:
+                                containerName:
+                                  description: 'Container name: required for volumes,
+                                    optional for env vars'
+                                  type: string
+                                divisor:
+                                  anyOf:
+                                  - type: integer
+                                  - type: string
+                                  description: Specifies the output format of the
+                                    exposed resources, defaults to "1"
+                                  pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                  x-kubernetes-int-or-string: true
+                                resource:
+                                  description: 'Required: resource to select'
+                                  type: string
+                              required:
+                              - resource
+                              type: object
+                            secretKeyRef:
+                              description: Selects a key of a secret in the pod's
+                                namespace
+                              properties:
+                                key:
+                                  description: The key of the secret to select from.  Must
+                                    be a valid secret key.
+                                  type: string
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names
+                                    TODO: Add other useful fields. apiVersion, kind,
+                                    uid?'
+                                  type: string
+                                optional:
+                                  description: Specify whether the Secret or its key
+                                    must be defined
+                                  type: boolean
+                              required:
+                              - key
+                              type: object
+                          type: object
+                      required:
+                      - name
+                      type: object
+                    type: array
+                type: object
               coreResources:
                 description: CoreResources (optional) overrides the default resource
                   requirements for the server container
@@ -1757,6 +1892,707 @@ spec:
                       to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/'
                     type: object
                 type: object
+              endpointGroups:
+                description: EndpointGroups (optional) adds endpoint deployments
+                  on top of the main endpoint deployment, each with its own
+                  region, autoscaling bounds, scheduling and resources. This
+                  allows to run endpoints in several zones with different node
+                  pools.
+                items:
+                  description: EndpointGroupSpec defines the desired state of an
+                    additional endpoint deployment
+                  properties:
+                    affinity:
+                      description: Affinity (optional) overrides the system
+                        affinity for the group pods
+                      properties:
+                        nodeAffinity:
+                          description: Describes node affinity scheduling rules for the
+                            pod.
+                          properties:
+                            preferredDuringSchedulingIgnoredDuringExecution:
+                              description: The scheduler will prefer to schedule pods to
+                                nodes that satisfy the affinity expressions specified by
+                                this field, but it may choose a node that violates one or
+                                more of the expressions. The node that is most preferred
+                                is the one with the greatest sum of weights, i.e. for each
+                                node that meets all of the scheduling requirements (resource
+                                request, requiredDuringScheduling affinity expressions,
+                                etc.), compute a sum by iterating through the elements of
+                                this field and adding "weight" to the sum if the node matches
+                                the corresponding matchExpressions; the node(s) with the
+                                highest sum are the most preferred.
+                              items:
+                                description: An empty preferred scheduling term matches
+                                  all objects with implicit weight 0 (i.e. it's a no-op).
+                                  A null preferred scheduling term matches no objects (i.e.
+                                  is also a no-op).
+                                properties:
+                                  preference:
+                                    description: A node selector term, associated with the
+                                      corresponding weight.
+                                    properties:
+                                      matchExpressions:
+                                        description: A list of node selector requirements
+                                          by node's labels.
+                                        items:
+                                          description: A node selector requirement is a
+                                            selector that contains values, a key, and an
+                                            operator that relates the key and values.
+                                          properties:
+                                            key:
+                                              description: The label key that the selector
+                                                applies to.
+                                              type: string
+                                            operator:
+                                              description: Represents a key's relationship
+                                                to a set of values. Valid operators are
+                                                In, NotIn, Exists, DoesNotExist. Gt, and
+                                                Lt.
+                                              type: string
+                                            values:
+                                              description: An array of string values. If
+                                                the operator is In or NotIn, the values
+                                                array must be non-empty. If the operator
+                                                is Exists or DoesNotExist, the values array
+                                                must be empty. If the operator is Gt or
+                                                Lt, the values array must have a single
+                                                element, which will be interpreted as an
+                                                integer. This array is replaced during a
+                                                strategic merge patch.
+                                              items:
+                                                type: string
+                                              type: array
+                                          required:
+                                          - key
+                                          - operator
+                                          type: object
+                                        type: array
+                                      matchFields:
+                                        description: A list of node selector requirements
+                                          by node's fields.
+                                        items:
+                                          description: A node selector requirement is a
+                                            selector that contains values, a key, and an
+                                            operator that relates the key and values.
+                                          properties:
+                                            key:
+                                              description: The label key that the selector
+                                                applies to.
+                                              type: string
+                                            operator:
+                                              description: Represents a key's relationship
+                                                to a set of values. Valid operators are
+                                                In, NotIn, Exists, DoesNotExist. Gt, and
+                                                Lt.
+                                              type: string
+                                            values:
+                                              description: An array of string values. If
+                                                the operator is In or NotIn, the values
+                                                array must be non-empty. If the operator
+                                                is Exists or DoesNotExist, the values array
+                                                must be empty. If the operator is Gt or
+                                                Lt, the values array must have a single
+                                                element, which will be interpreted as an
+                                                integer. This array is replaced during a
+                                                strategic merge patch.
+                                              items:
+                                                type: string
+                                              type: array
+                                          required:
+                                          - key
+                                          - operator
+                                          type: object
+                                        type: array
+                                    type: object
+                                  weight:
+                                    description: Weight associated with matching the corresponding
+                                      nodeSelectorTerm, in the range 1-100.
+                                    format: int32
+                                    type: integer
+                                required:
+                                - preference
+                                - weight
+                                type: object
+                              type: array
+                            requiredDuringSchedulingIgnoredDuringExecution:
+                              description: If the affinity requirements specified by this
+                                field are not met at scheduling time, the pod will not be
+                                scheduled onto the node. If the affinity requirements specified
+                                by this field cease to be met at some point during pod execution
+                                (e.g. due to an update), the system may or may not try to
+                                eventually evict the pod from its node.
+                              properties:
+                                nodeSelectorTerms:
+                                  description: Required. A list of node selector terms.
+                                    The terms are ORed.
+                                  items:
+                                    description: A null or empty node selector term matches
+                                      no objects. The requirements of them are ANDed. The
+                                      TopologySelectorTerm type implements a subset of the
+                                      NodeSelectorTerm.
+                                    properties:
+                                      matchExpressions:
+                                        description: A list of node selector requirements
+                                          by node's labels.
+                                        items:
+                                          description: A node selector requirement is a
+                                            selector that contains values, a key, and an
+                                            operator that relates the key and values.
+                                          properties:
+                                            key:
+                                              description: The label key that the selector
+                                                applies to.
+                                              type: string
+                                            operator:
+                                              description: Represents a key's relationship
+                                                to a set of values. Valid operators are
+                                                In, NotIn, Exists, DoesNotExist. Gt, and
+                                                Lt.
+                                              type: string
+                                            values:
+                                              description: An array of string values. If
+                                                the operator is In or NotIn, the values
+                                                array must be non-empty. If the operator
+                                                is Exists or DoesNotExist, the values array
+                                                must be empty. If the operator is Gt or
+                                                Lt, the values array must have a single
+                                                element, which will be interpreted as an
+                                                integer. This array is replaced during a
+                                                strategic merge patch.
+                                              items:
+                                                type: string
+                                              type: array
+                                          required:
+                                          - key
+                                          - operator
+                                          type: object
+                                        type: array
+                                      matchFields:
+                                        description: A list of node selector requirements
+                                          by node's fields.
+                                        items:
+                                          description: A node selector requirement is a
+                                            selector that contains values, a key, and an
+                                            operator that relates the key and values.
+                                          properties:
+                                            key:
+                                              description: The label key that the selector
+                                                applies to.
+                                              type: string
+                                            operator:
+                                              description: Represents a key's relationship
+                                                to a set of values. Valid operators are
+                                                In, NotIn, Exists, DoesNotExist. Gt, and
+                                                Lt.
+                                              type: string
+                                            values:
+                                              description: An array of string values. If
+                                                the operator is In or NotIn, the values
+                                                array must be non-empty. If the operator
+                                                is Exists or DoesNotExist, the values array
+                                                must be empty. If the operator is Gt or
+                                                Lt, the values array must have a single
+                                                element, which will be interpreted as an
+                                                integer. This array is replaced during a
+                                                strategic merge patch.
+                                              items:
+                                                type: string
+                                              type: array
+                                          required:
+                                          - key
+                                          - operator
+                                          type: object
+                                        type: array
+                                    type: object
+                                  type: array
+                              required:
+                              - nodeSelectorTerms
+                              type: object
+                          type: object
+                        podAffinity:
+                          description: Describes pod affinity scheduling rules (e.g. co-locate
+                            this pod in the same node, zone, etc. as some other pod(s)).
+                          properties:
+                            preferredDuringSchedulingIgnoredDuringExecution:
+                              description: The scheduler will prefer to schedule pods to
+                                nodes that satisfy the affinity expressions specified by
+                                this field, but it may choose a node that violates one or
+                                more of the expressions. The node that is most preferred
+                                is the one with the greatest sum of weights, i.e. for each
+                                node that meets all of the scheduling requirements (resource
+                                request, requiredDuringScheduling affinity expressions,
+                                etc.), compute a sum by iterating through the elements of
+                                this field and adding "weight" to the sum if the node has
+                                pods which matches the corresponding podAffinityTerm; the
+                                node(s) with the highest sum are the most preferred.
+                              items:
+                                description: The weights of all of the matched WeightedPodAffinityTerm
+                                  fields are added per-node to find the most preferred node(s)
+                                properties:
+                                  podAffinityTerm:
+                                    description: Required. A pod affinity term, associated
+                                      with the corresponding weight.
+                                    properties:
+                                      labelSelector:
+                                        description: A label query over a set of resources,
+                                          in this case pods.
+                                        properties:
+                                          matchExpressions:
+                                            description: matchExpressions is a list of label
+                                              selector requirements. The requirements are
+                                              ANDed.
+                                            items:
+                                              description: A label selector requirement
+                                                is a selector that contains values, a key,
+                                                and an operator that relates the key and
+                                                values.
+                                              properties:
+                                                key:
+                                                  description: key is the label key that
+                                                    the selector applies to.
+                                                  type: string
+                                                operator:
+                                                  description: operator represents a key's
+                                                    relationship to a set of values. Valid
+                                                    operators are In, NotIn, Exists and
+                                                    DoesNotExist.
+                                                  type: string
+                                                values:
+                                                  description: values is an array of string
+                                                    values. If the operator is In or NotIn,
+                                                    the values array must be non-empty.
+                                                    If the operator is Exists or DoesNotExist,
+                                                    the values array must be empty. This
+                                                    array is replaced during a strategic
+                                                    merge patch.
+                                                  items:
+                                                    type: string
+                                                  type: array
+                                              required:
+                                              - key
+                                              - operator
+                                              type: object
+                                            type: array
+                                          matchLabels:
+                                            additionalProperties:
+                                              type: string
+                                            description: matchLabels is a map of {key,value}
+                                              pairs. A single {key,value} in the matchLabels
+                                              map is equivalent to an element of matchExpressions,
+                                              whose key field is "key", the operator is
+                                              "In", and the values array contains only "value".
+                                              The requirements are ANDed.
+                                            type: object
+                                        type: object
+                                      namespaces:
+                                        description: namespaces specifies which namespaces
+                                          the labelSelector applies to (matches against);
+                                          null or empty list means "this pod's namespace"
+                                        items:
+                                          type: string
+                                        type: array
+                                      topologyKey:
+                                        description: This pod should be co-located (affinity)
+                                          or not co-located (anti-affinity) with the pods
+                                          matching the labelSelector in the specified namespaces,
+                                          where co-located is defined as running on a node
+                                          whose value of the label with key topologyKey
+                                          matches that of any node on which any of the selected
+                                          pods is running. Empty topologyKey is not allowed.
+                                        type: string
+                                    required:
+                                    - topologyKey
+                                    type: object
+                                  weight:
+                                    description: weight associated with matching the corresponding
+                                      podAffinityTerm, in the range 1-100.
+                                    format: int32
+                                    type: integer
+                                required:
+                                - podAffinityTerm
+                                - weight
+                                type: object
+                              type: array
+                            requiredDuringSchedulingIgnoredDuringExecution:
+                              description: If the affinity requirements specified by this
+                                field are not met at scheduling time, the pod will not be
+                                scheduled onto the node. If the affinity requirements specified
+                                by this field cease to be met at some point during pod execution
+                                (e.g. due to a pod label update), the system may or may
+                                not try to eventually evict the pod from its node. When
+                                there are multiple elements, the lists of nodes corresponding
+                                to each podAffinityTerm are intersected, i.e. all terms
+                                must be satisfied.
+                              items:
+                                description: Defines a set of pods (namely those matching
+                                  the labelSelector relative to the given namespace(s))
+                                  that this pod should be co-located (affinity) or not co-located
+                                  (anti-affinity) with, where co-located is defined as running
+                                  on a node whose value of the label with key <topologyKey>
+                                  matches that of any node on which a pod of the set of
+                                  pods is running
+                                properties:
+                                  labelSelector:
+                                    description: A label query over a set of resources,
+                                      in this case pods.
+                                    properties:
+                                      matchExpressions:
+                                        description: matchExpressions is a list of label
+                                          selector requirements. The requirements are ANDed.
+                                        items:
+                                          description: A label selector requirement is a
+                                            selector that contains values, a key, and an
+                                            operator that relates the key and values.
+                                          properties:
+                                            key:
+                                              description: key is the label key that the
+                                                selector applies to.
+                                              type: string
+                                            operator:
+                                              description: operator represents a key's relationship
+                                                to a set of values. Valid operators are
+                                                In, NotIn, Exists and DoesNotExist.
+                                              type: string
+                                            values:
+                                              description: values is an array of string
+                                                values. If the operator is In or NotIn,
+                                                the values array must be non-empty. If the
+                                                operator is Exists or DoesNotExist, the
+                                                values array must be empty. This array is
+                                                replaced during a strategic merge patch.
+                                              items:
+                                                type: string
+                                              type: array
+                                          required:
+                                          - key
+                                          - operator
+                                          type: object
+                                        type: array
+                                      matchLabels:
+                                        additionalProperties:
+                                          type: string
+                                        description: matchLabels is a map of {key,value}
+                                          pairs. A single {key,value} in the matchLabels
+                                          map is equivalent to an element of matchExpressions,
+                                          whose key field is "key", the operator is "In",
+                                          and the values array contains only "value". The
+                                          requirements are ANDed.
+                                        type: object
+                                    type: object
+                                  namespaces:
+                                    description: namespaces specifies which namespaces the
+                                      labelSelector applies to (matches against); null or
+                                      empty list means "this pod's namespace"
+                                    items:
+                                      type: string
+                                    type: array
+                                  topologyKey:
+                                    description: This pod should be co-located (affinity)
+                                      or not co-located (anti-affinity) with the pods matching
+                                      the labelSelector in the specified namespaces, where
+                                      co-located is defined as running on a node whose value
+                                      of the label with key topologyKey matches that of
+                                      any node on which any of the selected pods is running.
+                                      Empty topologyKey is not allowed.
+                                    type: string
+                                required:
+                                - topologyKey
+                                type: object
+                              type: array
+                          type: object
+                        podAntiAffinity:
+                          description: Describes pod anti-affinity scheduling rules (e.g.
+                            avoid putting this pod in the same node, zone, etc. as some
+                            other pod(s)).
+                          properties:
+                            preferredDuringSchedulingIgnoredDuringExecution:
+                              description: The scheduler will prefer to schedule pods to
+                                nodes that satisfy the anti-affinity expressions specified
+                                by this field, but it may choose a node that violates one
+                                or more of the expressions. The node that is most preferred
+                                is the one with the greatest sum of weights, i.e. for each
+                                node that meets all of the scheduling requirements (resource
+                                request, requiredDuringScheduling anti-affinity expressions,
+                                etc.), compute a sum by iterating through the elements of
+                                this field and adding "weight" to the sum if the node has
+                                pods which matches the corresponding podAffinityTerm; the
+                                node(s) with the highest sum are the most preferred.
+                              items:
+                                description: The weights of all of the matched WeightedPodAffinityTerm
+                                  fields are added per-node to find the most preferred node(s)
+                                properties:
+                                  podAffinityTerm:
+                                    description: Required. A pod affinity term, associated
+                                      with the corresponding weight.
+                                    properties:
+                                      labelSelector:
+                                        description: A label query over a set of resources,
+                                          in this case pods.
+                                        properties:
+                                          matchExpressions:
+                                            description: matchExpressions is a list of label
+                                              selector requirements. The requirements are
+                                              ANDed.
+                                            items:
+                                              description: A label selector requirement
+                                                is a selector that contains values, a key,
+                                                and an operator that relates the key and
+                                                values.
+                                              properties:
+                                                key:
+                                                  description: key is the label key that
+                                                    the selector applies to.
+                                                  type: string
+                                                operator:
+                                                  description: operator represents a key's
+                                                    relationship to a set of values. Valid
+                                                    operators are In, NotIn, Exists and
+                                                    DoesNotExist.
+                                                  type: string
+                                                values:
+                                                  description: values is an array of string
+                                                    values. If the operator is In or NotIn,
+                                                    the values array must be non-empty.
+                                                    If the operator is Exists or DoesNotExist,
+                                                    the values array must be empty. This
+                                                    array is replaced during a strategic
+                                                    merge patch.
+                                                  items:
+                                                    type: string
+                                                  type: array
+                                              required:
+                                              - key
+                                              - operator
+                                              type: object
+                                            type: array
+                                          matchLabels:
+                                            additionalProperties:
+                                              type: string
+                                            description: matchLabels is a map of {key,value}
+                                              pairs. A single {key,value} in the matchLabels
+                                              map is equivalent to an element of matchExpressions,
+                                              whose key field is "key", the operator is
+                                              "In", and the values array contains only "value".
+                                              The requirements are ANDed.
+                                            type: object
+                                        type: object
+                                      namespaces:
+                                        description: namespaces specifies which namespaces
+                                          the labelSelector applies to (matches against);
+                                          null or empty list means "this pod's namespace"
+                                        items:
+                                          type: string
+                                        type: array
+                                      topologyKey:
+                                        description: This pod should be co-located (affinity)
+                                          or not co-located (anti-affinity) with the pods
+                                          matching the labelSelector in the specified namespaces,
+                                          where co-located is defined as running on a node
+                                          whose value of the label with key topologyKey
+                                          matches that of any node on which any of the selected
+                                          pods is running. Empty topologyKey is not allowed.
+                                        type: string
+                                    required:
+                                    - topologyKey
+                                    type: object
+                                  weight:
+                                    description: weight associated with matching the corresponding
+                                      podAffinityTerm, in the range 1-100.
+                                    format: int32
+                                    type: integer
+                                required:
+                                - podAffinityTerm
+                                - weight
+                                type: object
+                              type: array
+                            requiredDuringSchedulingIgnoredDuringExecution:
+                              description: If the anti-affinity requirements specified by
+                                this field are not met at scheduling time, the pod will
+                                not be scheduled onto the node. If the anti-affinity requirements
+                                specified by this field cease to be met at some point during
+                                pod execution (e.g. due to a pod label update), the system
+                                may or may not try to eventually evict the pod from its
+                                node. When there are multiple elements, the lists of nodes
+                                corresponding to each podAffinityTerm are intersected, i.e.
+                                all terms must be satisfied.
+                              items:
+                                description: Defines a set of pods (namely those matching
+                                  the labelSelector relative to the given namespace(s))
+                                  that this pod should be co-located (affinity) or not co-located
+                                  (anti-affinity) with, where co-located is defined as running
+                                  on a node whose value of the label with key <topologyKey>
+                                  matches that of any node on which a pod of the set of
+                                  pods is running
+                                properties:
+                                  labelSelector:
+                                    description: A label query over a set of resources,
+                                      in this case pods.
+                                    properties:
+                                      matchExpressions:
+                                        description: matchExpressions is a list of label
+                                          selector requirements. The requirements are ANDed.
+                                        items:
+                                          description: A label selector requirement is a
+                                            selector that contains values, a key, and an
+                                            operator that relates the key and values.
+                                          properties:
+                                            key:
+                                              description: key is the label key that the
+                                                selector applies to.
+                                              type: string
+                                            operator:
+                                              description: operator represents a key's relationship
+                                                to a set of values. Valid operators are
+                                                In, NotIn, Exists and DoesNotExist.
+                                              type: string
+                                            values:
+                                              description: values is an array of string
+                                                values. If the operator is In or NotIn,
+                                                the values array must be non-empty. If the
+                                                operator is Exists or DoesNotExist, the
+                                                values array must be empty. This array is
+                                                replaced during a strategic merge patch.
+                                              items:
+                                                type: string
+                                              type: array
+                                          required:
+                                          - key
+                                          - operator
+                                          type: object
+                                        type: array
+                                      matchLabels:
+                                        additionalProperties:
+                                          type: string
+                                        description: matchLabels is a map of {key,value}
+                                          pairs. A single {key,value} in the matchLabels
+                                          map is equivalent to an element of matchExpressions,
+                                          whose key field is "key", the operator is "In",
+                                          and the values array contains only "value". The
+                                          requirements are ANDed.
+                                        type: object
+                                    type: object
+                                  namespaces:
+                                    description: namespaces specifies which namespaces the
+                                      labelSelector applies to (matches against); null or
+                                      empty list means "this pod's namespace"
+                                    items:
+                                      type: string
+                                    type: array
+                                  topologyKey:
+                                    description: This pod should be co-located (affinity)
+                                      or not co-located (anti-affinity) with the pods matching
+                                      the labelSelector in the specified namespaces, where
+                                      co-located is defined as running on a node whose value
+                                      of the label with key topologyKey matches that of
+                                      any node on which any of the selected pods is running.
+                                      Empty topologyKey is not allowed.
+                                    type: string
+                                required:
+                                - topologyKey
+                                type: object
+                              type: array
+                          type: object
+                      type: object
+                    maxCount:
+                      description: MaxCount, the number of endpoint instances
+                        (pods) to be used as the upper bound when autoscaling
+                      format: int32
+                      type: integer
+                    minCount:
+                      description: MinCount, the number of endpoint instances
+                        (pods) to be used as the lower bound when autoscaling
+                      format: int32
+                      type: integer
+                    name:
+                      description: Name of the group, used as a suffix for the
+                        names of the group deployment and autoscaler
+                      maxLength: 32
+                      pattern: ^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+                      type: string
+                    nodeSelector:
+                      additionalProperties:
+                        type: string
+                      description: NodeSelector (optional) schedules the group
+                        pods on the matching nodes
+                      type: object
+                    region:
+                      description: Region (optional) provide a region for the
+                        location info of the endpoints in the group
+                      type: string
+                    resources:
+                      description: Resources (optional) overrides the default
+                        resource requirements for every endpoint pod in the
+                        group
+                      properties:
+                        limits:
+                          additionalProperties:
+                            anyOf:
+                            - type: integer
+                            - type: string
+                            pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                            x-kubernetes-int-or-string: true
+                          description: 'Limits describes the maximum amount of compute
+                            resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/'
+                          type: object
+                        requests:
+                          additionalProperties:
+                            anyOf:
+                            - type: integer
+                            - type: string
+                            pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                            x-kubernetes-int-or-string: true
+                          description: 'Requests describes the minimum amount of compute
+                            resources required. If Requests is omitted for a container,
+                            it defaults to Limits if that is explicitly specified, otherwise
+                            to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/'
+                          type: object
+                      type: object
+                    tolerations:
+                      description: Tolerations (optional) overrides the system
+                        tolerations for the group pods
+                      items:
+                        description: The pod this Toleration is attached to tolerates any
+                          taint that matches the triple <key,value,effect> using the matching
+                          operator <operator>.
+                        properties:
+                          effect:
+                            description: Effect indicates the taint effect to match. Empty
+                              means match all taint effects. When specified, allowed values
+                              are NoSchedule, PreferNoSchedule and NoExecute.
+                            type: string
+                          key:
+                            description: Key is the taint key that the toleration applies
+                              to. Empty means match all taint keys. If the key is empty,
+                              operator must be Exists; this combination means to match all
+                              values and all keys.
+                            type: string
+                          operator:
+                            description: Operator represents a key's relationship to the
+                              value. Valid operators are Exists and Equal. Defaults to Equal.
+                              Exists is equivalent to wildcard for value, so that a pod
+                              can tolerate all taints of a particular category.
+                            type: string
+                          tolerationSeconds:
+                            description: TolerationSeconds represents the period of time
+                              the toleration (which must be of effect NoExecute, otherwise
+                              this field is ignored) tolerates the taint. By default, it
+                              is not set, which means tolerate the taint forever (do not
+                              evict). Zero and negative values will be treated as 0 (evict
+                              immediately) by the system.
+                            format: int64
+                            type: integer
+                          value:
+                            description: Value is the taint value the toleration matches
+                              to. If the operator is Exists, the value should be empty,
+                              otherwise just a regular string.
+                            type: string
+                        type: object
+                      type: array
+                  required:
+                  - name
+                  type: object
+                type: array
               endpoints:
                 description: Endpoints (optional) sets configuration info for the
                   noobaa endpoint deployment.
@@ -1769,6 +2605,554 @@ spec:
                     items:
                       type: string
                     type: array
+                  autoscaling:
+                    description: Autoscaling (optional) sets the metrics and the scaling
+                      behavior of the endpoints autoscalers. By default the endpoints
+                      are scaled on 80% average cpu utilization.
+                    properties:
+                      behavior:
+                        description: Behavior (optional) sets the stabilization windows
+                          and the policies of scaling up and down
+                        properties:
+                          scaleDown:
+                            description: scaleDown is scaling policy for scaling Down.
+                              If not set, the default value is to allow to scale down
+                              to minReplicas pods, with a 300 second stabilization
+                              window (i.e., the highest recommendation for the last
+                              300sec is used).
+                            properties:
+                              policies:
+                                description: policies is a list of potential scaling
+                                  polices which can be used during scaling. At least
+                                  one policy must be specified, otherwise the HPAScalingRules
+                                  will be discarded as invalid
+                                items:
+                                  description: HPAScalingPolicy is a single policy
+                                    which must hold true for a specified past interval.
+                                  properties:
+                                    periodSeconds:
+                                      description: PeriodSeconds specifies the window
+                                        of time for which the policy should hold true.
+                                        PeriodSeconds must be greater than zero and
+                                        less than or equal to 1800 (30 min).
+                                      format: int32
+                                      type: integer
+                                    type:
+                                      description: Type is used to specify the scaling
+                                        policy.
+                                      type: string
+                                    value:
+                                      description: Value contains the amount of change
+                                        which is permitted by the policy. It must
+                                        be greater than zero
+                                      format: int32
+                                      type: integer
+                                  required:
+                                  - type
+                                  - value
+                                  - periodSeconds
+                                  type: object
+                                type: array
+                              selectPolicy:
+                                description: selectPolicy is used to specify which
+                                  policy should be used. If not set, the default value
+                                  MaxPolicySelect is used.
+                                type: string
+                              stabilizationWindowSeconds:
+                                description: 'StabilizationWindowSeconds is the number
+                                  of seconds for which past recommendations should
+                                  be considered while scaling up or scaling down.
+                                  StabilizationWindowSeconds must be greater than
+                                  or equal to zero and less than or equal to 3600
+                                  (one hour). If not set, use the default values:
+                                  - For scale up: 0 (i.e. no stabilization is done).
+                                  - For scale down: 300 (i.e. the stabilization window
+                                  is 300 seconds long).'
+                                format: int32
+                                type: integer
+                            type: object
+                          scaleUp:
+                            description: 'scaleUp is scaling policy for scaling Up.
+                              If not set, the default value is the higher of: * increase
+                              no more than 4 pods per 60 seconds * double the number
+                              of pods per 60 seconds No stabilization is used.'
+                            properties:
+                              policies:
+                                description: policies is a list of potential scaling
+                                  polices which can be used during scaling. At least
+                                  one policy must be specified, otherwise the HPAScalingRules
+                                  will be discarded as invalid
+                                items:
+                                  description: HPAScalingPolicy is a single policy
+                                    which must hold true for a specified past interval.
+                                  properties:
+                                    periodSeconds:
+                                      description: PeriodSeconds specifies the window
+                                        of time for which the policy should hold true.
+                                        PeriodSeconds must be greater than zero and
+                                        less than or equal to 1800 (30 min).
+                                      format: int32
+                                      type: integer
+                                    type:
+                                      description: Type is used to specify the scaling
+                                        policy.
+                                      type: string
+                                    value:
+                                      description: Value contains the amount of change
+                                        which is permitted by the policy. It must
+                                        be greater than zero
+                                      format: int32
+                                      type: integer
+                                  required:
+                                  - type
+                                  - value
+                                  - periodSeconds
+                                  type: object
+                                type: array
+                              selectPolicy:
+                                description: selectPolicy is used to specify which
+                                  policy should be used. If not set, the default value
+                                  MaxPolicySelect is used.
+                                type: string
+                              stabilizationWindowSeconds:
+                                description: 'StabilizationWindowSeconds is the number
+                                  of seconds for which past recommendations should
+                                  be considered while scaling up or scaling down.
+                                  StabilizationWindowSeconds must be greater than
+                                  or equal to zero and less than or equal to 3600
+                                  (one hour). If not set, use the default values:
+                                  - For scale up: 0 (i.e. no stabilization is done).
+                                  - For scale down: 300 (i.e. the stabilization window
+                                  is 300 seconds long).'
+                                format: int32
+                                type: integer
+                            type: object
+                        type: object
+                      metrics:
+                        description: Metrics (optional) are the metrics to scale on,
+                          such as cpu and memory utilization, or the S3 requests rate
+                          of the endpoints exposed by a metrics adapter as a pods
+                          or external metric. The autoscaler computes the desired
+                          number of endpoints for every metric and uses the highest.
+                        items:
+                          description: MetricSpec specifies how to scale based on
+                            a single metric (only ` + "`" + `type` + "`" + ` and one other matching field
+                            should be set at once).
+                          properties:
+                            external:
+                              description: external refers to a global metric that
+                                is not associated with any Kubernetes object. It allows
+                                autoscaling based on information coming from components
+                                running outside of cluster (for example length of
+                                queue in cloud messaging service, or QPS from loadbalancer
+                                running outside of cluster).
+                              properties:
+                                metric:
+                                  description: metric identifies the target metric
+                                    by name and selector
+                                  properties:
+                                    name:
+                                      description: name is the name of the given metric
+                                      type: string
+                                    selector:
+                                      description: selector is the string-encoded
+                                        form of a standard kubernetes label selector
+                                        for the given metric When set, it is passed
+                                        as an additional parameter to the metrics
+                                        server for more specific metrics scoping.
+                                        When unset, just the metricName will be used
+                                        to gather metrics.
+                                      properties:
+                                        matchExpressions:
+                                          description: matchExpressions is a list
+                                            of label selector requirements. The requirements
+                                            are ANDed.
+                                          items:
+                                            description: A label selector requirement
+                                              is a selector that contains values,
+                                              a key, and an operator that relates
+                                              the key and values.
+                                            properties:
+                                              key:
+                                                description: key is the label key
+                                                  that the selector applies to.
+                                                type: string
+                                              operator:
+                                                description: operator represents a
+                                                  key's relationship to a set of values.
+                                                  Valid operators are In, NotIn, Exists
+                                                  and DoesNotExist.
+                                                type: string
+                                              values:
+                                                description: values is an array of
+                                                  string values. If the operator is
+                                                  In or NotIn, the values array must
+                                                  be non-empty. If the operator is
+                                                  Exists or DoesNotExist, the values
+                                                  array must be empty. This array
+                                                  is replaced during a strategic merge
+                                                  patch.
+                                                items:
+                                                  type: string
+                                                type: array
+                                            required:
+                                            - key
+                                            - operator
+                                            type: object
+                                          type: array
+                                        matchLabels:
+                                          additionalProperties:
+                                            type: string
+                                          description: matchLabels is a map of {key,value}
+                                            pairs. A single {key,value} in the matchLabels
+                                            map is equivalent to an element of matchExpressions,
+                                            whose key field is "key", the operator
+                                            is "In", and the values array contains
+                                            only "value". The requirements are ANDed.
+                                          type: object
+                                      type: object
+                                  required:
+                                  - name
+                                  type: object
+                                target:
+                                  description: target specifies the target value for
+                                    the given metric
+                                  properties:
+                                    averageUtilization:
+                                      description: averageUtilization is the target
+                                        value of the average of the resource metric
+                                        across all relevant pods, represented as a
+                                        percentage of the requested value of the resource
+                                        for the pods. Currently only valid for Resource
+                                        metric source type
+                                      format: int32
+                                      type: integer
+                                    averageValue:
+                                      anyOf: &id001
+                                      - type: integer
+                                      - type: string
+                                      description: averageValue is the target value
+                                        of the average of the metric across all relevant
+                                        pods (as a quantity)
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                    type:
+                                      description: type represents whether the metric
+                                        type is Utilization, Value, or AverageValue
+                                      type: string
+                                    value:
+                                      anyOf: *id001
+                                      description: value is the target value of the
+                                        metric (as a quantity).
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                  required:
+                                  - type
+                                  type: object
+                              required:
+                              - metric
+                              - target
+                              type: object
+                            object:
+                              description: object refers to a metric describing a
+                                single kubernetes object (for example, hits-per-second
+                                on an Ingress object).
+                              properties:
+                                describedObject:
+                                  description: CrossVersionObjectReference contains
+                                    enough information to let you identify the referred
+                                    resource.
+                                  properties:
+                                    apiVersion:
+                                      description: API version of the referent
+                                      type: string
+                                    kind:
+                                      description: 'Kind of the referent; More info:
+                                        https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds"'
+                                      type: string
+                                    name:
+                                      description: 'Name of the referent; More info:
+                                        http://kubernetes.io/docs/user-guide/identifiers#names'
+                                      type: string
+                                  required:
+                                  - kind
+                                  - name
+                                  type: object
+                                metric:
+                                  description: metric identifies the target metric
+                                    by name and selector
+                                  properties:
+                                    name:
+                                      description: name is the name of the given metric
+                                      type: string
+                                    selector:
+                                      description: selector is the string-encoded
+                                        form of a standard kubernetes label selector
+                                        for the given metric When set, it is passed
+                                        as an additional parameter to the metrics
+                                        server for more specific metrics scoping.
+                                        When unset, just the metricName will be used
+                                        to gather metrics.
+                                      properties:
+                                        matchExpressions:
+                                          description: matchExpressions is a list
+                                            of label selector requirements. The requirements
+                                            are ANDed.
+                                          items:
+                                            description: A label selector requirement
+                                              is a selector that contains values,
+                                              a key, and an operator that relates
+                                              the key and values.
+                                            properties:
+                                              key:
+                                                description: key is the label key
+                                                  that the selector applies to.
+                                                type: string
+                                              operator:
+                                                description: operator represents a
+                                                  key's relationship to a set of values.
+                                                  Valid operators are In, NotIn, Exists
+                                                  and DoesNotExist.
+                                                type: string
+                                              values:
+                                                description: values is an array of
+                                                  string values. If the operator is
+                                                  In or NotIn, the values array must
+                                                  be non-empty. If the operator is
+                                                  Exists or DoesNotExist, the values
+                                                  array must be empty. This array
+                                                  is replaced during a strategic merge
+                                                  patch.
+                                                items:
+                                                  type: string
+                                                type: array
+                                            required:
+                                            - key
+                                            - operator
+                                            type: object
+                                          type: array
+                                        matchLabels:
+                                          additionalProperties:
+                                            type: string
+                                          description: matchLabels is a map of {key,value}
+                                            pairs. A single {key,value} in the matchLabels
+                                            map is equivalent to an element of matchExpressions,
+                                            whose key field is "key", the operator
+                                            is "In", and the values array contains
+                                            only "value". The requirements are ANDed.
+                                          type: object
+                                      type: object
+                                  required:
+                                  - name
+                                  type: object
+                                target:
+                                  description: target specifies the target value for
+                                    the given metric
+                                  properties:
+                                    averageUtilization:
+                                      description: averageUtilization is the target
+                                        value of the average of the resource metric
+                                        across all relevant pods, represented as a
+                                        percentage of the requested value of the resource
+                                        for the pods. Currently only valid for Resource
+                                        metric source type
+                                      format: int32
+                                      type: integer
+                                    averageValue:
+                                      anyOf: *id001
+                                      description: averageValue is the target value
+                                        of the average of the metric across all relevant
+                                        pods (as a quantity)
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                    type:
+                                      description: type represents whether the metric
+                                        type is Utilization, Value, or AverageValue
+                                      type: string
+                                    value:
+                                      anyOf: *id001
+                                      description: value is the target value of the
+                                        metric (as a quantity).
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                  required:
+                                  - type
+                                  type: object
+                              required:
+                              - describedObject
+                              - target
+                              - metric
+                              type: object
+                            pods:
+                              description: pods refers to a metric describing each
+                                pod in the current scale target (for example, transactions-processed-per-second).  The
+                                values will be averaged together before being compared
+                                to the target value.
+                              properties:
+                                metric:
+                                  description: metric identifies the target metric
+                                    by name and selector
+                                  properties:
+                                    name:
+                                      description: name is the name of the given metric
+                                      type: string
+                                    selector:
+                                      description: selector is the string-encoded
+                                        form of a standard kubernetes label selector
+                                        for the given metric When set, it is passed
+                                        as an additional parameter to the metrics
+                                        server for more specific metrics scoping.
+                                        When unset, just the metricName will be used
+                                        to gather metrics.
+                                      properties:
+                                        matchExpressions:
+                                          description: matchExpressions is a list
+                                            of label selector requirements. The requirements
+                                            are ANDed.
+                                          items:
+                                            description: A label selector requirement
+                                              is a selector that contains values,
+                                              a key, and an operator that relates
+                                              the key and values.
+                                            properties:
+                                              key:
+                                                description: key is the label key
+                                                  that the selector applies to.
+                                                type: string
+                                              operator:
+                                                description: operator represents a
+                                                  key's relationship to a set of values.
+                                                  Valid operators are In, NotIn, Exists
+                                                  and DoesNotExist.
+                                                type: string
+                                              values:
+                                                description: values is an array of
+                                                  string values. If the operator is
+                                                  In or NotIn, the values array must
+                                                  be non-empty. If the operator is
+                                                  Exists or DoesNotExist, the values
+                                                  array must be empty. This array
+                                                  is replaced during a strategic merge
+                                                  patch.
+                                                items:
+                                                  type: string
+                                                type: array
+                                            required:
+                                            - key
+                                            - operator
+                                            type: object
+                                          type: array
+                                        matchLabels:
+                                          additionalProperties:
+                                            type: string
+                                          description: matchLabels is a map of {key,value}
+                                            pairs. A single {key,value} in the matchLabels
+                                            map is equivalent to an element of matchExpressions,
+                                            whose key field is "key", the operator
+                                            is "In", and the values array contains
+                                            only "value". The requirements are ANDed.
+                                          type: object
+                                      type: object
+                                  required:
+                                  - name
+                                  type: object
+                                target:
+                                  description: target specifies the target value for
+                                    the given metric
+                                  properties:
+                                    averageUtilization:
+                                      description: averageUtilization is the target
+                                        value of the average of the resource metric
+                                        across all relevant pods, represented as a
+                                        percentage of the requested value of the resource
+                                        for the pods. Currently only valid for Resource
+                                        metric source type
+                                      format: int32
+                                      type: integer
+                                    averageValue:
+                                      anyOf: *id001
+                                      description: averageValue is the target value
+                                        of the average of the metric across all relevant
+                                        pods (as a quantity)
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                    type:
+                                      description: type represents whether the metric
+                                        type is Utilization, Value, or AverageValue
+                                      type: string
+                                    value:
+                                      anyOf: *id001
+                                      description: value is the target value of the
+                                        metric (as a quantity).
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                  required:
+                                  - type
+                                  type: object
+                              required:
+                              - metric
+                              - target
+                              type: object
+                            resource:
+                              description: resource refers to a resource metric (such
+                                as those specified in requests and limits) known to
+                                Kubernetes describing each pod in the current scale
+                                target (e.g. CPU or memory). Such metrics are built
+                                in to Kubernetes, and have special scaling options
+                                on top of those available to normal per-pod metrics
+                                using the "pods" source.
+                              properties:
+                                name:
+                                  description: name is the name of the resource in
+                                    question.
+                                  type: string
+                                target:
+                                  description: target specifies the target value for
+                                    the given metric
+                                  properties:
+                                    averageUtilization:
+                                      description: averageUtilization is the target
+                                        value of the average of the resource metric
+                                        across all relevant pods, represented as a
+                                        percentage of the requested value of the resource
+                                        for the pods. Currently only valid for Resource
+                                        metric source type
+                                      format: int32
+                                      type: integer
+                                    averageValue:
+                                      anyOf: *id001
+                                      description: averageValue is the target value
+                                        of the average of the metric across all relevant
+                                        pods (as a quantity)
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                    type:
+                                      description: type represents whether the metric
+                                        type is Utilization, Value, or AverageValue
+                                      type: string
+                                    value:
+                                      anyOf: *id001
+                                      description: value is the target value of the
+                                        metric (as a quantity).
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
+                                  required:
+                                  - type
+                                  type: object
+                              required:
+                              - name
+                              - target
+                              type: object
+                            type:
+                              description: type is the type of metric source.  It
+                                should be one of "Object", "Pods" or "Resource", each
+                                mapping to a matching field in the object.
+                              type: string
+                          required:
+                          - type
+                          type: object
+                        type: array
+                    type: object
                   maxCount:
                     description: MaxCount, the number of endpoint instances (pods)
                       to be used as the upper bound when autoscaling
@@ -1833,6 +3217,50 @@ spec:
                       name must be unique.
                     type: string
                 type: object
+              logging:
+                description: Logging (optional) sets the log levels of the system
+                  components and the log format of the operator. Core and endpoint
+                  levels are applied to the running processes without restarting the
+                  pods.
+                properties:
+                  coreLevel:
+                    description: CoreLevel (optional) sets the log level of the core
+                      server processes
+                    enum:
+                    - error
+                    - warn
+                    - info
+                    - debug
+                    - trace
+                    type: string
+                  endpointLevel:
+                    description: EndpointLevel (optional) sets the log level of the
+                      S3 endpoint processes
+                    enum:
+                    - error
+                    - warn
+                    - info
+                    - debug
+                    - trace
+                    type: string
+                  operatorFormat:
+                    description: OperatorFormat (optional) sets the log format of
+                      the operator logs of reconciling this system
+                    enum:
+                    - text
+                    - json
+                    type: string
+                  operatorLevel:
+                    description: OperatorLevel (optional) sets the log level of the
+                      operator logs of reconciling this system
+                    enum:
+                    - error
+                    - warn
+                    - info
+                    - debug
+                    - trace
+                    type: string
+                type: object
               mongoDbURL:
                 description: MongoDbURL (optional) overrides the default mongo db
                   remote url
@@ -1903,6 +3331,28 @@ spec:
                       type: string
                   type: object
                 type: array
+              upgrade:
+                description: Upgrade (optional) sets how changes of the core image
+                  are rolled out. The operator takes a snapshot of the DB volume,
+                  upgrades core, then the endpoints and then the pv-pool agents,
+                  and reverts to the previous image if a step does not become healthy
+                  in time.
+                properties:
+                  dbSnapshotClass:
+                    description: DBSnapshotClass (optional) is the VolumeSnapshotClass
+                      of the DB volume snapshot, the default snapshot class of the
+                      cluster is used when not set
+                    type: string
+                  disableDBSnapshot:
+                    description: DisableDBSnapshot (optional) skips the snapshot
+                      of the DB volume before the upgrade
+                    type: boolean
+                  stepTimeout:
+                    description: StepTimeout (optional) is the time that every upgrade
+                      step has to become healthy before the upgrade is rolled back,
+                      10 minutes by default
+                    type: string
+                type: object
             type: object
           status:
             description: Most recently observed status of the noobaa system.
@@ -1936,6 +3386,34 @@ spec:
                 description: ActualImage is set to report which image the operator
                   is using
                 type: string
+              compatibility:
+                description: Compatibility reports the decision of the operator about
+                  the requested core image according to the compatibility matrix of
+                  the operator version
+                properties:
+                  coreVersion:
+                    description: CoreVersion is the version of the requested core
+                      image, parsed from its tag
+                    type: string
+                  decision:
+                    description: Decision is the result of the compatibility check
+                      of the requested core image
+                    type: string
+                  message:
+                    description: Message explains the decision
+                    type: string
+                  operatorVersion:
+                    description: OperatorVersion is the version of the operator that
+                      checked the image
+                    type: string
+                  runningCoreVersion:
+                    description: RunningCoreVersion is the version of the core image
+                      that is currently running, parsed from its tag
+                    type: string
+                required:
+                - decision
+                - operatorVersion
+                type: object
               conditions:
                 description: Conditions is a list of conditions related to operator
                   reconciliation
@@ -1969,6 +3447,25 @@ spec:
                   endpoint deployment and the virtual hosts list used recognized by
                   the endpoints
                 properties:
+                  groups:
+                    description: Groups reports the actual number of endpoints
+                      of every endpoint group
+                    items:
+                      description: EndpointGroupStatus is the status of an endpoint
+                        group deployment
+                      properties:
+                        name:
+                          type: string
+                        readyCount:
+                          format: int32
+                          type: integer
+                        region:
+                          type: string
+                      required:
+                      - name
+                      - readyCount
+                      type: object
+                    type: array
                   readyCount:
                     format: int32
                     type: integer
@@ -1980,6 +3477,25 @@ spec:
                 - readyCount
                 - virtualHosts
                 type: object
+              logging:
+                description: Logging reports the core and endpoint log levels that
+                  were applied to the running processes
+                properties:
+                  appliedTime:
+                    description: AppliedTime is the last time the levels were applied,
+                      processes that became ready later run with the default level
+                      until the levels are applied again
+                    format: date-time
+                    type: string
+                  coreLevel:
+                    description: CoreLevel is the log level that was applied to the
+                      core server processes
+                    type: string
+                  endpointLevel:
+                    description: EndpointLevel is the log level that was applied to
+                      the S3 endpoint processes
+                    type: string
+                type: object
               observedGeneration:
                 description: ObservedGeneration is the most recent generation observed
                   for this noobaa system. It corresponds to the CR generation, which
@@ -2170,6 +3686,91 @@ spec:
                 - serviceMgmt
                 - serviceS3
                 type: object
+              upgrade:
+                description: Upgrade reports the progress of the ongoing upgrade
+                  of the core image, or the failed upgrade that holds the system
+                  on the previous image until the image is changed
+                properties:
+                  dbSnapshot:
+                    description: DBSnapshot is the name of the VolumeSnapshot of
+                      the DB volume taken before the upgrade
+                    type: string
+                  fromImage:
+                    description: FromImage is the image that the system ran before
+                      the upgrade
+                    type: string
+                  message:
+                    description: Message explains the result or the current step
+                    type: string
+                  previousUpgradePhase:
+                    description: PreviousUpgradePhase is the upgrade phase of the
+                      DB migration to restore when the upgrade completes
+                    type: string
+                  result:
+                    description: Result is set when the upgrade failed and the system
+                      is held on FromImage until the image is changed
+                    type: string
+                  startTime:
+                    description: StartTime is the time the upgrade started
+                    format: date-time
+                    type: string
+                  stepStartTime:
+                    description: StepStartTime is the time the current step of the
+                      upgrade started
+                    format: date-time
+                    type: string
+                  toImage:
+                    description: ToImage is the image that the system is upgraded
+                      to
+                    type: string
+                required:
+                - fromImage
+                - startTime
+                - stepStartTime
+                - toImage
+                type: object
+              upgradeHistory:
+                description: UpgradeHistory lists the recent upgrades of the core
+                  image, oldest first
+                items:
+                  description: UpgradeHistoryEntry is a completed upgrade of the
+                    core image
+                  properties:
+                    completionTime:
+                      description: CompletionTime is the time the upgrade completed,
+                        succeeded or not
+                      format: date-time
+                      type: string
+                    dbSnapshot:
+                      description: DBSnapshot is the name of the VolumeSnapshot
+                        of the DB volume taken before the upgrade
+                      type: string
+                    fromImage:
+                      description: FromImage is the image that the system ran before
+                        the upgrade
+                      type: string
+                    message:
+                      description: Message explains the result
+                      type: string
+                    result:
+                      description: Result of the upgrade
+                      type: string
+                    startTime:
+                      description: StartTime is the time the upgrade started
+                      format: date-time
+                      type: string
+                    toImage:
+                      description: ToImage is the image that the system was upgraded
+                        to
+                      type: string
+                  required:
+                  - completionTime
+                  - fromImage
+                  - result
+                  - startTime
+                  - toImage
+                  type: object
+                type: array
               upgradePhase:
                 description: Upgrade reports the status of the ongoing upgrade process
                 type: string
@@ -2439,6 +4040,30 @@ spec:
   targetCPUUtilizationPercentage: 80
 `
 
+const Sha256_deploy_internal_hpav2_endpoint_yaml = "09b4a1c446437fe5463e9a1cddd909c2d9b126fb22be7bf1d492ceba5ece921b"
+
+const File_deploy_internal_hpav2_endpoint_yaml = `apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  labels:
+    app: noobaa
+  name: noobaa-endpoint
+spec:
+  maxReplicas: 3
+  minReplicas: 1
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: noobaa-endpoint
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 80
+`
+
 const Sha256_deploy_internal_job_upgrade_db_yaml = "4ae1ae1f6009e578ea4cc937c305068dd8f21b93b0d7fd43350628e84725f337"
 
 const File_deploy_internal_job_upgrade_db_yaml = `apiVersion: batch/v1
@@ -3926,7 +5551,7 @@ spec:
   sourceNamespace: default
 `
 
-const Sha256_deploy_operator_yaml = "3030f31026433e2737957a3f153739ce3d1a69cf11813d27b4fd95e7452ee3df"
+const Sha256_deploy_operator_yaml = "423890df32048f5a21c8cb78eaf5f2f43fcd3fd203ad0b499bacf9563153e6cf"
 
 const File_deploy_operator_yaml = `apiVersion: apps/v1
 kind: Deployment
@@ -3947,6 +5572,23 @@ spec:
       containers:
         - name: noobaa-operator
           image: NOOBAA_OPERATOR_IMAGE
+          ports:
+            - containerPort: 8383
+              name: metrics
+            - containerPort: 8081
+              name: health
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: health
+            initialDelaySeconds: 15
+            periodSeconds: 20
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: health
+            initialDelaySeconds: 5
+            periodSeconds: 10
           resources:
             limits:
               cpu: "250m"
@@ -3964,7 +5606,7 @@ spec:
                   fieldPath: metadata.namespace
 `
 
-const Sha256_deploy_role_yaml = "eb0941a5e095fa7ac391e05782e6847e419e4d0dc17f6d8151df0032c977c743"
+const Sha256_deploy_role_yaml = "cf2a15f61a8359f500926729fffa0013a46b95986fef1e2b0f6300767760e740"
 
 const File_deploy_role_yaml = `apiVersion: rbac.authorization.k8s.io/v1
 kind: Role
@@ -4099,6 +5741,25 @@ rules:
   - list
   - watch
   - delete
+- apiGroups:
+  - snapshot.storage.k8s.io
+  resources:
+  - volumesnapshots
+  verbs:
+  - get
+  - create
+  - list
+  - watch
+- apiGroups:
+  - coordination.k8s.io
+  resources:
+  - leases
+  verbs:
+  - get
+  - create
+  - update
+  - list
+  - watch
 - apiGroups:
   - security.openshift.io 
   resourceNames:
@@ -4292,3 +5953,39 @@ metadata:
   name: noobaa-endpoint
 `
 
+const Sha256_deploy_service_operator_metrics_yaml = "046355654081fa1cb428b80a93a542cbd183acd5f2e9cfa838b4426e1e338f9c"
+
+const File_deploy_service_operator_metrics_yaml = `apiVersion: v1
+kind: Service
+metadata:
+  name: noobaa-operator-metrics
+  labels:
+    app: noobaa
+    noobaa-operator-svc: "true"
+spec:
+  selector:
+    noobaa-operator: deployment
+  ports:
+    - port: 8383
+      name: metrics
+      targetPort: 8383
+`
+
+const Sha256_deploy_servicemonitor_operator_yaml = "6a5250251e5291c37dd95b00d9fc5cf848b3513263d740f4d9a586c38a31bf6b"
+
+const File_deploy_servicemonitor_operator_yaml = `apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: noobaa-operator-metrics-service-monitor
+  labels:
+    app: noobaa
+spec:
+  endpoints:
+  - port: metrics
+    path: /metrics
+  namespaceSelector: {}
+  selector:
+    matchLabels:
+      noobaa-operator-svc: "true"
+`
+
@@ -12,6 +12,7 @@ import (
 	"github.com/noobaa/noobaa-operator/v2/pkg/bucketclass"
 	"github.com/noobaa/noobaa-operator/v2/pkg/crd"
 	"github.com/noobaa/noobaa-operator/v2/pkg/diagnose"
+	"github.com/noobaa/noobaa-operator/v2/pkg/doctor"
 	"github.com/noobaa/noobaa-operator/v2/pkg/install"
 	"github.com/noobaa/noobaa-operator/v2/pkg/namespacestore"
 	"github.com/noobaa/noobaa-operator/v2/pkg/obc"
@@ -20,6 +21,7 @@ import (
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
 	"github.com/noobaa/noobaa-operator/v2/pkg/pvstore"
 	"github.com/noobaa/noobaa-operator/v2/pkg/system"
+	"github.com/noobaa/noobaa-operator/v2/pkg/top"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 	"github.com/noobaa/noobaa-operator/v2/pkg/version"
 
@@ -119,6 +121,9 @@ Load noobaa completion to bash:
 			bucketclass.Cmd(),
 			obc.Cmd(),
 			diagnose.Cmd(),
+			diagnose.CmdLogs(),
+			doctor.Cmd(),
+			top.Cmd(),
 			system.CmdUI(),
 		},
 	}, {
@@ -3,8 +3,10 @@ package backingstore
 import (
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
 	"github.com/noobaa/noobaa-operator/v2/pkg/backingstore"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -25,12 +27,16 @@ func Add(mgr manager.Manager) error {
 		MaxConcurrentReconciles: 1,
 		Reconciler: reconcile.Func(
 			func(req reconcile.Request) (reconcile.Result, error) {
-				return backingstore.NewReconciler(
+				r := backingstore.NewReconciler(
 					req.NamespacedName,
 					mgr.GetClient(),
 					mgr.GetScheme(),
 					mgr.GetEventRecorderFor("noobaa-operator"),
-				).Reconcile()
+				)
+				return metrics.InstrumentReconcile("BackingStore", r.BackingStore,
+					func() string { return string(r.BackingStore.Status.Phase) },
+					r.Reconcile,
+				)
 			}),
 	})
 	if err != nil {
@@ -72,6 +78,20 @@ func Add(mgr manager.Manager) error {
 		return err
 	}
 
+	// Watch the system image to roll the pv-pool agents in the last step of a core image upgrade
+	systemHandler := handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return backingstore.MapSystemToPVPoolBackingStores(types.NamespacedName{
+				Name:      obj.Meta.GetName(),
+				Namespace: obj.Meta.GetNamespace(),
+			})
+		}),
+	}
+	err = c.Watch(&source.Kind{Type: &nbv1.NooBaa{}}, &systemHandler, systemImageChangedPredicate{}, &logEventsPredicate)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -93,3 +113,36 @@ func (p backingStoreModeChangedPredicate) Update(e event.UpdateEvent) bool {
 	}
 	return oldBackingStore.Status.Mode.ModeCode != newBackingStore.Status.Mode.ModeCode
 }
+
+// systemImageChangedPredicate will only allow updates of the system that changed Status.ActualImage
+type systemImageChangedPredicate struct {
+	predicate.Funcs
+}
+
+// Create implements the create event trap for systemImageChangedPredicate
+func (p systemImageChangedPredicate) Create(e event.CreateEvent) bool {
+	return false
+}
+
+// Delete implements the delete event trap for systemImageChangedPredicate
+func (p systemImageChangedPredicate) Delete(e event.DeleteEvent) bool {
+	return false
+}
+
+// Generic implements the generic event trap for systemImageChangedPredicate
+func (p systemImageChangedPredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
+
+// Update implements the update event trap for systemImageChangedPredicate
+func (p systemImageChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	oldSystem, oldCastOk := e.ObjectOld.(*nbv1.NooBaa)
+	newSystem, newCastOk := e.ObjectNew.(*nbv1.NooBaa)
+	if !oldCastOk || !newCastOk {
+		return false
+	}
+	return oldSystem.Status.ActualImage != newSystem.Status.ActualImage
+}
@@ -3,6 +3,7 @@ package bucketclass
 import (
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
 	"github.com/noobaa/noobaa-operator/v2/pkg/bucketclass"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -24,12 +25,16 @@ func Add(mgr manager.Manager) error {
 		MaxConcurrentReconciles: 1,
 		Reconciler: reconcile.Func(
 			func(req reconcile.Request) (reconcile.Result, error) {
-				return bucketclass.NewReconciler(
+				r := bucketclass.NewReconciler(
 					req.NamespacedName,
 					mgr.GetClient(),
 					mgr.GetScheme(),
 					mgr.GetEventRecorderFor("noobaa-operator"),
-				).Reconcile()
+				)
+				return metrics.InstrumentReconcile("BucketClass", r.BucketClass,
+					func() string { return string(r.BucketClass.Status.Phase) },
+					r.Reconcile,
+				)
 			}),
 	})
 	if err != nil {
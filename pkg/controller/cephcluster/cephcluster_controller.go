@@ -23,7 +23,14 @@ import (
 // The Manager will set fields on the Controller and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
 	// Create a controller to react to ceph cluster changes
-	if !util.KubeList(&cephv1.CephClusterList{}, client.InNamespace(options.Namespace)) {
+	found := false
+	for _, ns := range options.WatchedNamespaces() {
+		if util.KubeList(&cephv1.CephClusterList{}, client.InNamespace(ns)) {
+			found = true
+			break
+		}
+	}
+	if !found {
 		return nil
 	}
 
@@ -71,7 +78,7 @@ func doReconcile(req reconcile.Request) (reconcile.Result, error) {
 	cephCapacity := &cephCluster.Status.CephStatus.Capacity
 
 	// Get a noobaa client
-	sysClient, err := system.Connect(false)
+	sysClient, err := system.ConnectNamespace(req.Namespace, false)
 	if err != nil {
 		logrus.Errorf("Could not connect to system %+v", err)
 		return res, err
@@ -79,7 +86,7 @@ func doReconcile(req reconcile.Request) (reconcile.Result, error) {
 	nbClient := sysClient.NBClient
 
 	backingStoreList := nbv1.BackingStoreList{}
-	util.KubeList(&backingStoreList, client.InNamespace(options.Namespace))
+	util.KubeList(&backingStoreList, client.InNamespace(req.Namespace))
 	for _, bs := range backingStoreList.Items {
 		if bs.Spec.S3Compatible != nil && bs.ObjectMeta.Annotations != nil {
 			if _, ok := bs.ObjectMeta.Annotations["rgw"]; ok {
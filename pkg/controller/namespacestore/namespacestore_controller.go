@@ -2,6 +2,7 @@ package namespacestore
 
 import (
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	"github.com/noobaa/noobaa-operator/v2/pkg/namespacestore"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 
@@ -24,12 +25,16 @@ func Add(mgr manager.Manager) error {
 		MaxConcurrentReconciles: 1,
 		Reconciler: reconcile.Func(
 			func(req reconcile.Request) (reconcile.Result, error) {
-				return namespacestore.NewReconciler(
+				r := namespacestore.NewReconciler(
 					req.NamespacedName,
 					mgr.GetClient(),
 					mgr.GetScheme(),
 					mgr.GetEventRecorderFor("noobaa-operator"),
-				).Reconcile()
+				)
+				return metrics.InstrumentReconcile("NamespaceStore", r.NamespaceStore,
+					func() string { return string(r.NamespaceStore.Status.Phase) },
+					r.Reconcile,
+				)
 			}),
 	})
 	if err != nil {
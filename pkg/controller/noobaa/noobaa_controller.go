@@ -2,17 +2,17 @@ package noobaa
 
 import (
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
 	"github.com/noobaa/noobaa-operator/v2/pkg/system"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
-	"github.com/sirupsen/logrus"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -21,17 +21,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-// NotificationSource specifies a queue of notifications
-type NotificationSource struct {
-	Queue workqueue.RateLimitingInterface
-}
-
-// Start will setup s.Queue field
-func (s *NotificationSource) Start(handler handler.EventHandler, q workqueue.RateLimitingInterface, predicates ...predicate.Predicate) error {
-	s.Queue = q
-	return nil
-}
-
 // Add creates a Controller and adds it to the Manager.
 // The Manager will set fields on the Controller and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
@@ -42,12 +31,16 @@ func Add(mgr manager.Manager) error {
 		MaxConcurrentReconciles: 1,
 		Reconciler: reconcile.Func(
 			func(req reconcile.Request) (reconcile.Result, error) {
-				return system.NewReconciler(
+				r := system.NewReconciler(
 					req.NamespacedName,
 					mgr.GetClient(),
 					mgr.GetScheme(),
 					mgr.GetEventRecorderFor("noobaa-operator"),
-				).Reconcile()
+				)
+				return metrics.InstrumentReconcile("NooBaa", r.NooBaa,
+					func() string { return string(r.NooBaa.Status.Phase) },
+					r.Reconcile,
+				)
 			}),
 	})
 	if err != nil {
@@ -95,19 +88,28 @@ func Add(mgr manager.Manager) error {
 	if err != nil {
 		return err
 	}
+	err = c.Watch(&source.Kind{Type: &autoscalingv1.HorizontalPodAutoscaler{}}, ownerHandler, &filterForOwnerPredicate, &logEventsPredicate)
+	if err != nil {
+		return err
+	}
 
 	storageClassHandler := handler.EnqueueRequestsFromMapFunc{
 		ToRequests: handler.ToRequestsFunc(func(mo handler.MapObject) []reconcile.Request {
 			sc, ok := mo.Object.(*storagev1.StorageClass)
-			if !ok || sc.Provisioner != options.ObjectBucketProvisionerName() {
+			if !ok {
 				return nil
 			}
-			return []reconcile.Request{{
-				NamespacedName: types.NamespacedName{
-					Name:      options.SystemName,
-					Namespace: options.Namespace,
-				},
-			}}
+			for _, ns := range options.WatchedNamespaces() {
+				if sc.Provisioner == options.ObjectBucketProvisionerNameFor(ns) {
+					return []reconcile.Request{{
+						NamespacedName: types.NamespacedName{
+							Name:      options.SystemName,
+							Namespace: ns,
+						},
+					}}
+				}
+			}
+			return nil
 		}),
 	}
 	// Watch for StorageClass changes to trigger reconcile and recreate it when deleted
@@ -115,22 +117,38 @@ func Add(mgr manager.Manager) error {
 	if err != nil {
 		return err
 	}
-	// watch on notificationSource in order to keep the controller work queue
-	notificationSource := &NotificationSource{}
-	err = c.Watch(notificationSource, nil)
+	// notifications from noobaa-core refresh the status of the system that sent them
+	// instead of triggering a full reconcile of all the watched systems
+	notifier := system.NewStatusNotifier(func(ns string) *system.Reconciler {
+		return system.NewReconciler(
+			types.NamespacedName{Namespace: ns, Name: options.SystemName},
+			mgr.GetClient(),
+			mgr.GetScheme(),
+			mgr.GetEventRecorderFor("noobaa-operator"),
+		)
+	})
+	err = mgr.Add(notifier)
 	if err != nil {
 		return err
 	}
-
-	// handler for global RPC message and ,simply trigger a reconcile on every message
-	nb.GlobalRPC.Handler = func(req *nb.RPCMessage) (interface{}, error) {
-		logrus.Infof("RPC Handle: {Op: %s, API: %s, Method: %s, Error: %s, Params: %+v}", req.Op, req.API, req.Method, req.Error, req.Params)
-		notificationSource.Queue.AddRateLimited(reconcile.Request{NamespacedName: types.NamespacedName{
-			Name:      options.SystemName,
-			Namespace: options.Namespace,
-		}})
-		return nil, nil
+	// the notifier requests full reconciles through the controller queue
+	notifierHandler := handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(mo handler.MapObject) []reconcile.Request {
+			ns := mo.Meta.GetNamespace()
+			return []reconcile.Request{{
+				NamespacedName: types.NamespacedName{Namespace: ns, Name: options.SystemName},
+			}}
+		}),
+	}
+	err = c.Watch(&source.Channel{Source: notifier.Reconciles}, &notifierHandler)
+	if err != nil {
+		return err
 	}
+	system.GlobalStatusNotifier = notifier
+	nb.GlobalRPC.Handler = notifier.HandleRPC
+	nb.GlobalRPC.ConnectHandler = notifier.HandleConnect
+	nb.GlobalRPC.PingInterval = options.RPCPingInterval
+	nb.GlobalRPC.MaxMissedPings = options.RPCMaxMissedPings
 
 	return nil
 }
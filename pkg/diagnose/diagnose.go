@@ -1,28 +1,38 @@
 package diagnose
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"time"
 
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/system"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	sigyaml "sigs.k8s.io/yaml"
 
 	"github.com/spf13/cobra"
 )
 
 // Collector configuration for diagnostics
 type Collector struct {
-	folderName string
-	log        *logrus.Entry
+	folderName    string
+	log           *logrus.Entry
+	since         time.Duration
+	namespaceWide bool
 }
 
 // Cmd returns a CLI command
@@ -33,6 +43,8 @@ func Cmd() *cobra.Command {
 		Run:   RunCollect,
 	}
 	cmd.Flags().String("dir", "", "collect noobaa diagnose tar file into destination directory")
+	cmd.Flags().Duration("since", 0, "collect only logs and events newer than a relative duration like 5s, 2m, or 3h (default all)")
+	cmd.Flags().Bool("namespace-wide", false, "collect all the pods, logs and resources in the namespace and not only the noobaa ones")
 	return cmd
 }
 
@@ -40,9 +52,13 @@ func Cmd() *cobra.Command {
 func RunCollect(cmd *cobra.Command, args []string) {
 
 	destDir, _ := cmd.Flags().GetString("dir")
+	since, _ := cmd.Flags().GetDuration("since")
+	namespaceWide, _ := cmd.Flags().GetBool("namespace-wide")
 	c := Collector{
-		folderName: fmt.Sprintf("%s_%d", "noobaa_diagnostics", time.Now().Unix()),
-		log:        util.Logger(),
+		folderName:    fmt.Sprintf("%s_%d", "noobaa_diagnostics", time.Now().Unix()),
+		log:           util.Logger(),
+		since:         since,
+		namespaceWide: namespaceWide,
 	}
 
 	c.log.Println("Running collection of diagnostics")
@@ -60,35 +76,98 @@ func RunCollect(cmd *cobra.Command, args []string) {
 		TypeMeta: metav1.TypeMeta{Kind: "BucketClassList"},
 	})
 
+	c.CollectCR(&nbv1.NamespaceStoreList{
+		TypeMeta: metav1.TypeMeta{Kind: "NamespaceStoreList"},
+	})
+
 	c.CollectCR(&nbv1.NooBaaList{
 		TypeMeta: metav1.TypeMeta{Kind: "NooBaaList"},
 	})
 
-	corePodSelector, _ := labels.Parse("noobaa-core=" + options.SystemName)
-	c.CollectPodLogs(corePodSelector)
+	obSelector, _ := labels.Parse("noobaa-domain=" + options.SubDomainNS())
+	c.CollectCR(&nbv1.ObjectBucketList{
+		TypeMeta: metav1.TypeMeta{Kind: "ObjectBucketList"},
+	}, &client.ListOptions{LabelSelector: obSelector})
+
+	c.CollectOBCs(obSelector)
+
+	c.CollectEvents()
+
+	appSelector, _ := labels.Parse("app=noobaa")
+	if c.namespaceWide {
+		appSelector = labels.Everything()
+	}
+	appListOptions := &client.ListOptions{Namespace: options.Namespace, LabelSelector: appSelector}
+
+	c.CollectCR(&corev1.PersistentVolumeClaimList{
+		TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaimList"},
+	}, appListOptions)
+
+	c.CollectCR(&appsv1.DeploymentList{
+		TypeMeta: metav1.TypeMeta{Kind: "DeploymentList"},
+	}, appListOptions)
 
-	operatorPodSelector, _ := labels.Parse("noobaa-operator=deployment")
-	c.CollectPodLogs(operatorPodSelector)
+	c.CollectCR(&appsv1.StatefulSetList{
+		TypeMeta: metav1.TypeMeta{Kind: "StatefulSetList"},
+	}, appListOptions)
 
-	endpointPodSelector, _ := labels.Parse("noobaa-s3=" + options.SystemName)
-	c.CollectPodLogs(endpointPodSelector)
+	c.CollectCR(&corev1.PodList{
+		TypeMeta: metav1.TypeMeta{Kind: "PodList"},
+	}, appListOptions)
 
-	dbPodSelector, _ := labels.Parse("noobaa-db=" + options.SystemName)
-	if options.DBType == "postgres" {
-		dbPodSelector, _ = labels.Parse("noobaa-db=" + options.DBType)
+	if c.namespaceWide {
+		c.CollectPodLogs(labels.Everything())
+	} else {
+		for _, component := range Components {
+			selector, _ := ComponentSelector(component, "")
+			c.CollectPodLogs(selector)
+		}
 	}
-	c.CollectPodLogs(dbPodSelector)
+
+	c.CollectSystemInfo()
 
 	// collectSystemMetrics()
 
 	c.ExportDiagnostics(destDir)
 }
 
+// Components are the names of the noobaa components that have pods
+var Components = []string{"core", "operator", "endpoint", "db", "pv-pool"}
+
+// ComponentSelector returns the label selector of the pods of a noobaa component.
+// For pv-pool the pool name selects the agents of a single pool, otherwise the agents of all pools.
+func ComponentSelector(component string, pool string) (labels.Selector, error) {
+	switch component {
+	case "core":
+		return labels.Parse("noobaa-core=" + options.SystemName)
+	case "operator":
+		return labels.Parse("noobaa-operator=deployment")
+	case "endpoint":
+		return labels.Parse(system.EndpointPodLabel + "=" + options.SystemName)
+	case "db":
+		if options.DBType == "postgres" {
+			return labels.Parse("noobaa-db=" + options.DBType)
+		}
+		return labels.Parse("noobaa-db=" + options.SystemName)
+	case "pv-pool":
+		if pool != "" {
+			return labels.Parse("pool=" + pool)
+		}
+		return labels.Parse("pool")
+	default:
+		return nil, fmt.Errorf("Unknown component %q, expected one of: %s", component, strings.Join(Components, ", "))
+	}
+}
+
 // CollectCR info
-func (c *Collector) CollectCR(list runtime.Object) {
+// the list is collected from the target namespace unless other list options are passed.
+func (c *Collector) CollectCR(list runtime.Object, listOptions ...client.ListOption) {
 	gvk := list.GetObjectKind().GroupVersionKind()
 
-	if !util.KubeList(list, &client.ListOptions{Namespace: options.Namespace}) {
+	if len(listOptions) == 0 {
+		listOptions = []client.ListOption{&client.ListOptions{Namespace: options.Namespace}}
+	}
+	if !util.KubeList(list, listOptions...) {
 		c.log.Printf(`❌ Failed to list %s\n`, gvk.Kind)
 		return
 	}
@@ -96,13 +175,106 @@ func (c *Collector) CollectCR(list runtime.Object) {
 	list.GetObjectKind().SetGroupVersionKind(gvk)
 
 	targetFile := fmt.Sprintf("%s/%s_crs.yaml", c.folderName, gvk.Kind)
-	err := util.SaveCRsToFile(list, targetFile)
+	err := c.SaveRedactedToFile(list, targetFile)
+	if err != nil {
+		c.log.Printf("got error on SaveRedactedToFile for %v: %v", targetFile, err)
+	}
+}
+
+// CollectOBCs collects the OBCs of this noobaa system.
+// OBCs are created in the application namespaces so they are listed from all namespaces,
+// and only the claims of the noobaa storage classes or bound to the noobaa OBs are kept.
+func (c *Collector) CollectOBCs(obSelector labels.Selector) {
+	list := &nbv1.ObjectBucketClaimList{
+		TypeMeta: metav1.TypeMeta{Kind: "ObjectBucketClaimList"},
+	}
+	if !util.KubeList(list, &client.ListOptions{}) {
+		c.log.Printf(`❌ Failed to list %s\n`, list.Kind)
+		return
+	}
+
+	storageClasses := map[string]bool{}
+	scList := &storagev1.StorageClassList{}
+	if util.KubeList(scList, &client.ListOptions{}) {
+		for i := range scList.Items {
+			sc := &scList.Items[i]
+			if sc.Provisioner == options.ObjectBucketProvisionerName() {
+				storageClasses[sc.Name] = true
+			}
+		}
+	}
+	obs := map[string]bool{}
+	obList := &nbv1.ObjectBucketList{}
+	if util.KubeList(obList, &client.ListOptions{LabelSelector: obSelector}) {
+		for i := range obList.Items {
+			obs[obList.Items[i].Name] = true
+		}
+	}
+
+	items := []nbv1.ObjectBucketClaim{}
+	for i := range list.Items {
+		obc := &list.Items[i]
+		if storageClasses[obc.Spec.StorageClassName] || obs[obc.Spec.ObjectBucketName] {
+			items = append(items, *obc)
+		}
+	}
+	list.Items = items
+
+	targetFile := fmt.Sprintf("%s/%s_crs.yaml", c.folderName, list.Kind)
+	err := c.SaveRedactedToFile(list, targetFile)
+	if err != nil {
+		c.log.Printf("got error on SaveRedactedToFile for %v: %v", targetFile, err)
+	}
+}
+
+// CollectEvents collects the events in the namespace (filtered by --since)
+func (c *Collector) CollectEvents() {
+	list := &corev1.EventList{
+		TypeMeta: metav1.TypeMeta{Kind: "EventList"},
+	}
+	if !util.KubeList(list, &client.ListOptions{Namespace: options.Namespace}) {
+		c.log.Printf(`❌ Failed to list %s\n`, list.Kind)
+		return
+	}
+	if c.since > 0 {
+		minTime := time.Now().Add(-c.since)
+		items := []corev1.Event{}
+		for i := range list.Items {
+			e := &list.Items[i]
+			if e.LastTimestamp.Time.After(minTime) || e.EventTime.Time.After(minTime) {
+				items = append(items, *e)
+			}
+		}
+		list.Items = items
+	}
+	targetFile := fmt.Sprintf("%s/%s_crs.yaml", c.folderName, list.Kind)
+	err := c.SaveRedactedToFile(list, targetFile)
+	if err != nil {
+		c.log.Printf("got error on SaveRedactedToFile for %v: %v", targetFile, err)
+	}
+}
+
+// CollectSystemInfo collects the system_api.read_system() reply from the noobaa core
+func (c *Collector) CollectSystemInfo() {
+	sysClient, err := system.Connect(true)
+	if err != nil {
+		c.log.Printf(`❌ Could not connect to system for read_system, reason: %s`, err)
+		return
+	}
+	systemInfo, err := sysClient.NBClient.ReadSystemAPI()
+	if err != nil {
+		c.log.Printf(`❌ Could not read system info, reason: %s`, err)
+		return
+	}
+	targetFile := fmt.Sprintf("%s/system_info.yaml", c.folderName)
+	err = c.SaveRedactedToFile(systemInfo, targetFile)
 	if err != nil {
-		c.log.Printf("got error on util.SaveCRsToFile for %v: %v", targetFile, err)
+		c.log.Printf("got error on SaveRedactedToFile for %v: %v", targetFile, err)
 	}
 }
 
 // CollectPodLogs info
+// collects the current logs of all containers and the previous logs of restarted containers
 func (c *Collector) CollectPodLogs(corePodSelector labels.Selector) {
 	corePodList := &corev1.PodList{}
 	currentPod := strings.Split(corePodSelector.String(), "=")[0]
@@ -114,18 +286,88 @@ func (c *Collector) CollectPodLogs(corePodSelector labels.Selector) {
 		return
 	}
 
+	var sinceSeconds *int64
+	if c.since > 0 {
+		s := int64(c.since.Seconds())
+		sinceSeconds = &s
+	}
+
 	for i := range corePodList.Items {
 		corePod := &corePodList.Items[i]
-		podLogs, _ := util.GetPodLogs(*corePod)
+		podLogs, _ := util.GetPodLogsWithOptions(*corePod, corev1.PodLogOptions{SinceSeconds: sinceSeconds})
 		for containerName, containerLog := range podLogs {
 			targetFile := fmt.Sprintf("%s/%s-%s.log", c.folderName, corePod.Name, containerName)
-			err := util.SaveStreamToFile(containerLog, targetFile)
+			err := c.SaveRedactedStreamToFile(containerLog, targetFile)
 			if err != nil {
-				c.log.Printf("got error on util.SaveStreamToFile for %v: %v", targetFile, err)
+				c.log.Printf("got error on SaveRedactedStreamToFile for %v: %v", targetFile, err)
 			}
+		}
+		for _, cs := range append(corePod.Status.InitContainerStatuses, corePod.Status.ContainerStatuses...) {
+			if cs.RestartCount == 0 {
+				continue
+			}
+			prevLogs, _ := util.GetPodLogsWithOptions(*corePod, corev1.PodLogOptions{
+				Container:    cs.Name,
+				Previous:     true,
+				SinceSeconds: sinceSeconds,
+			})
+			for containerName, containerLog := range prevLogs {
+				targetFile := fmt.Sprintf("%s/%s-%s-previous.log", c.folderName, corePod.Name, containerName)
+				err := c.SaveRedactedStreamToFile(containerLog, targetFile)
+				if err != nil {
+					c.log.Printf("got error on SaveRedactedStreamToFile for %v: %v", targetFile, err)
+				}
+			}
+		}
+	}
+}
 
+// SaveRedactedToFile saves the object as yaml after redacting sensitive values
+func (c *Collector) SaveRedactedToFile(obj interface{}, path string) error {
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(jsonBytes, &generic); err != nil {
+		return err
+	}
+	yamlBytes, err := sigyaml.Marshal(RedactObject(generic))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, yamlBytes, 0600)
+}
+
+// SaveRedactedStreamToFile saves the stream line by line after redacting sensitive values
+func (c *Collector) SaveRedactedStreamToFile(body io.ReadCloser, path string) error {
+	if body == nil {
+		return nil
+	}
+	defer body.Close()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	reader := bufio.NewReader(body)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			if _, err := w.WriteString(RedactLine(line)); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
 		}
 	}
+	return w.Flush()
 }
 
 // TODO: Use port forwarding (usePortForwarding in system.go)
@@ -162,7 +404,7 @@ func (c *Collector) ExportDiagnostics(destDir string) {
 		}
 		targetFile = fmt.Sprintf("%s/%s", destDir, targetFile)
 	}
-	fileToWrite, err := os.Create(targetFile)
+	fileToWrite, err := os.OpenFile(targetFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		c.log.Fatalf(`❌ Could not create target file %s, reason: %s`, targetFile, err)
 	}
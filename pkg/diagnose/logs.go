@@ -0,0 +1,292 @@
+package diagnose
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LogLevel is the severity of a log line, where lower values are more severe
+type LogLevel int
+
+// Log levels in the order of severity
+const (
+	LogLevelError LogLevel = iota
+	LogLevelWarn
+	LogLevelInfo
+	LogLevelDebug
+)
+
+// LogLevelNames maps the --level flag values to log levels
+var LogLevelNames = map[string]LogLevel{
+	"error": LogLevelError,
+	"warn":  LogLevelWarn,
+	"info":  LogLevelInfo,
+	"debug": LogLevelDebug,
+}
+
+// The components log in different formats - noobaa-core uses [ERROR] and [L1] prefixes,
+// the operator uses logrus level=error fields, and postgres uses ERROR: prefixes.
+var (
+	logLevelErrorRegexp = regexp.MustCompile(`\[ERROR\]|level=(error|fatal|panic)|\b(ERROR|FATAL|PANIC):`)
+	logLevelWarnRegexp  = regexp.MustCompile(`\[WARN\]|level=warn(ing)?|\bWARNING:`)
+	logLevelDebugRegexp = regexp.MustCompile(`\[L[1-9]\]|level=(debug|trace)|\bDEBUG[1-5]?:`)
+)
+
+// logsRelistInterval is how often the pods are listed again when following,
+// to start streaming from pods that were created or restarted
+const logsRelistInterval = 10 * time.Second
+
+// logPrefixColors are the ansi colors used to tell apart the pods prefixes
+var logPrefixColors = []string{"36", "32", "33", "35", "34", "96", "92", "93", "95", "94"}
+
+// LogFilter selects the log lines to print
+type LogFilter struct {
+	Level LogLevel
+	Grep  *regexp.Regexp
+}
+
+// LogLine is a single line read from a container log stream
+type LogLine struct {
+	Prefix string
+	Color  string
+	Text   string
+}
+
+// CmdLogs returns a CLI command
+func CmdLogs() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Stream and filter the logs of the noobaa components",
+		Run:   RunLogs,
+		Args:  cobra.NoArgs,
+	}
+	cmd.Flags().StringSlice("component", []string{"core", "endpoint", "db", "operator"},
+		"Components to stream logs from. One or more of: "+strings.Join(Components, ", "))
+	cmd.Flags().String("pool", "", "Stream the logs of the pv-pool agents of a single pool (implies --component pv-pool)")
+	cmd.Flags().Duration("since", 0, "Only return logs newer than a relative duration like 5s, 2m, or 3h (default all)")
+	cmd.Flags().Int64("tail", -1, "Number of recent lines of each container to start from (default all)")
+	cmd.Flags().String("grep", "", "Only print lines matching this regular expression")
+	cmd.Flags().String("level", "debug", "Only print lines at this level or more severe. One of: error, warn, info, debug")
+	cmd.Flags().BoolP("follow", "f", true, "Keep streaming new lines and new pods")
+	return cmd
+}
+
+// RunLogs runs a CLI command
+func RunLogs(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+	components, _ := cmd.Flags().GetStringSlice("component")
+	pool, _ := cmd.Flags().GetString("pool")
+	since, _ := cmd.Flags().GetDuration("since")
+	tail, _ := cmd.Flags().GetInt64("tail")
+	grep, _ := cmd.Flags().GetString("grep")
+	level, _ := cmd.Flags().GetString("level")
+	follow, _ := cmd.Flags().GetBool("follow")
+
+	filter := &LogFilter{}
+	if l, ok := LogLevelNames[strings.ToLower(level)]; ok {
+		filter.Level = l
+	} else {
+		log.Fatalf(`❌ Invalid log level %q, expected one of: error, warn, info, debug`, level)
+	}
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			log.Fatalf(`❌ Invalid grep expression %q: %s`, grep, err)
+		}
+		filter.Grep = re
+	}
+	if pool != "" && !cmd.Flags().Changed("component") {
+		components = []string{"pv-pool"}
+	}
+
+	selectors := []labels.Selector{}
+	for _, component := range components {
+		selector, err := ComponentSelector(component, pool)
+		if err != nil {
+			log.Fatalf(`❌ %s`, err)
+		}
+		selectors = append(selectors, selector)
+	}
+
+	logOpts := corev1.PodLogOptions{Follow: follow}
+	if since > 0 {
+		sinceSeconds := int64(since.Seconds())
+		logOpts.SinceSeconds = &sinceSeconds
+	}
+	if tail >= 0 {
+		logOpts.TailLines = &tail
+	}
+
+	colored := terminal.IsTerminal(int(os.Stdout.Fd()))
+	lines := make(chan LogLine, 100)
+	ended := make(chan string)
+	started := map[string]bool{}
+	endedAt := map[string]metav1.Time{}
+
+	startStreams := func() {
+		for _, pod := range listComponentPods(selectors) {
+			if pod.Status.Phase == corev1.PodPending {
+				continue
+			}
+			for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+				prefix := pod.Name + "/" + container.Name
+				if started[prefix] {
+					continue
+				}
+				containerOpts := logOpts
+				containerOpts.Container = container.Name
+				if t, wasEnded := endedAt[prefix]; wasEnded {
+					// continue a restarted container from where its previous stream ended
+					if !isContainerRunning(pod, container.Name) {
+						continue
+					}
+					containerOpts.SinceSeconds = nil
+					containerOpts.TailLines = nil
+					containerOpts.SinceTime = &t
+				}
+				streams, err := util.GetPodLogsWithOptions(pod, containerOpts)
+				if err != nil || streams[container.Name] == nil {
+					continue
+				}
+				started[prefix] = true
+				color := ""
+				if colored {
+					color = logPrefixColors[len(started)%len(logPrefixColors)]
+				}
+				go func(stream io.ReadCloser, prefix string, color string) {
+					ReadLogLines(stream, prefix, color, lines)
+					ended <- prefix
+				}(streams[container.Name], prefix, color)
+			}
+		}
+	}
+
+	startStreams()
+	if len(started) == 0 && !follow {
+		log.Fatalf(`❌ Could not find running pods for components: %s`, strings.Join(components, ", "))
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	relist := time.NewTicker(logsRelistInterval)
+	defer relist.Stop()
+
+	for {
+		select {
+		case line := <-lines:
+			if filter.Match(line.Text) {
+				printLogLine(line)
+			}
+		case <-relist.C:
+			if follow {
+				startStreams()
+			}
+		case prefix := <-ended:
+			// a restarted container is streamed again on the next relist
+			delete(started, prefix)
+			endedAt[prefix] = metav1.Now()
+			if !follow && len(started) == 0 {
+				// the lines of a stream are all sent before it ends
+				for len(lines) > 0 {
+					if line := <-lines; filter.Match(line.Text) {
+						printLogLine(line)
+					}
+				}
+				return
+			}
+		case <-signals:
+			return
+		}
+	}
+}
+
+// ParseLogLevel detects the level of a log line by the formats of the noobaa components.
+// Lines without a recognized level are considered info.
+func ParseLogLevel(line string) LogLevel {
+	switch {
+	case logLevelErrorRegexp.MatchString(line):
+		return LogLevelError
+	case logLevelWarnRegexp.MatchString(line):
+		return LogLevelWarn
+	case logLevelDebugRegexp.MatchString(line):
+		return LogLevelDebug
+	default:
+		return LogLevelInfo
+	}
+}
+
+// Match returns true if the line passes the level and grep filters
+func (f *LogFilter) Match(line string) bool {
+	if ParseLogLevel(line) > f.Level {
+		return false
+	}
+	if f.Grep != nil && !f.Grep.MatchString(line) {
+		return false
+	}
+	return true
+}
+
+// ReadLogLines reads a log stream line by line into the channel and closes the stream at the end
+func ReadLogLines(stream io.ReadCloser, prefix string, color string, lines chan<- LogLine) {
+	defer stream.Close()
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines <- LogLine{Prefix: prefix, Color: color, Text: scanner.Text()}
+	}
+}
+
+func printLogLine(line LogLine) {
+	if line.Color != "" {
+		fmt.Printf("\x1b[%sm[%s]\x1b[0m %s\n", line.Color, line.Prefix, line.Text)
+	} else {
+		fmt.Printf("[%s] %s\n", line.Prefix, line.Text)
+	}
+}
+
+func isContainerRunning(pod corev1.Pod, name string) bool {
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.Name == name {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// listComponentPods lists the pods of all the selectors without duplicates
+func listComponentPods(selectors []labels.Selector) []corev1.Pod {
+	pods := []corev1.Pod{}
+	seen := map[string]bool{}
+	for _, selector := range selectors {
+		podList := &corev1.PodList{}
+		err := util.KubeClient().List(util.Context(), podList,
+			&client.ListOptions{Namespace: options.Namespace, LabelSelector: selector})
+		if err != nil {
+			util.Logger().Warnf("⏳ Could not list pods %q: %s", selector, err)
+			continue
+		}
+		for _, pod := range podList.Items {
+			if !seen[pod.Name] {
+				seen[pod.Name] = true
+				pods = append(pods, pod)
+			}
+		}
+	}
+	return pods
+}
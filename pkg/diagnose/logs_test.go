@@ -0,0 +1,43 @@
+package diagnose
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	cases := map[string]LogLevel{
+		`Oct-18 10:00:00.000 [BGWorkers/35] [ERROR] core.server.bg_services::: failed`:    LogLevelError,
+		`time="2026-10-18T10:00:00Z" level=error msg="❌ failed"`:                          LogLevelError,
+		`2026-10-18 10:00:00.000 UTC [1] FATAL:  password authentication failed`:          LogLevelError,
+		`Oct-18 10:00:00.000 [Endpoint/14] [WARN] core.endpoint.s3.s3_rest:: slow`:        LogLevelWarn,
+		`time="2026-10-18T10:00:00Z" level=warning msg="⏳ waiting"`:                       LogLevelWarn,
+		`2026-10-18 10:00:00.000 UTC [1] WARNING:  could not open statistics file`:        LogLevelWarn,
+		`Oct-18 10:00:00.000 [WebServer/40]    [L1] core.server.system_services:: reload`: LogLevelDebug,
+		`time="2026-10-18T10:00:00Z" level=debug msg="reconcile"`:                         LogLevelDebug,
+		`time="2026-10-18T10:00:00Z" level=info msg="✅ Exists"`:                           LogLevelInfo,
+		`Oct-18 10:00:00.000 [WebServer/40] [LOG] core.server.system_services:: started`:  LogLevelInfo,
+		`plain line without a level`:                                                      LogLevelInfo,
+	}
+	for line, expected := range cases {
+		if level := ParseLogLevel(line); level != expected {
+			t.Errorf("ParseLogLevel(%q) = %d, expected %d", line, level, expected)
+		}
+	}
+}
+
+func TestLogFilterMatch(t *testing.T) {
+	filter := &LogFilter{Level: LogLevelWarn, Grep: regexp.MustCompile(`bucket-\d+`)}
+	if !filter.Match(`[ERROR] failed to read bucket-1`) {
+		t.Errorf("expected an error line matching the grep to pass")
+	}
+	if filter.Match(`[ERROR] failed to read the system`) {
+		t.Errorf("expected a line not matching the grep to be filtered")
+	}
+	if filter.Match(`[L1] reading bucket-1`) {
+		t.Errorf("expected a debug line to be filtered by the warn level")
+	}
+	if !(&LogFilter{Level: LogLevelDebug}).Match(`[L1] reading bucket-1`) {
+		t.Errorf("expected all lines to pass the debug level without grep")
+	}
+}
@@ -0,0 +1,94 @@
+package diagnose
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces sensitive values in collected diagnostics
+const Redacted = "<redacted>"
+
+// sensitiveKeys are (lower cased) substrings of object keys whose values are always redacted
+var sensitiveKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"access_key",
+	"accesskey",
+	"private_key",
+	"privatekey",
+	"credentials",
+	"cipher_key",
+	"jwt",
+	"account_key",
+	"accountkey",
+}
+
+// sensitiveLineREs match sensitive values in free text such as logs.
+// The first group of each regexp is kept and the rest of the match is redacted.
+var sensitiveLineREs = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:password|secret|token|access_?key|secret_?key|private_?key|account_?key|cipher_key|jwt)["']?\s*[:=]\s*["']?)[^"'\s,;}]+`),
+	regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic|aws4-hmac-sha256)\s+)\S+`),
+	regexp.MustCompile(`()\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+}
+
+// IsSensitiveKey returns true if values of the key should be redacted
+func IsSensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactObject redacts the values of sensitive keys in a generic json object in place
+// and returns it for convenience. Keys referencing secrets by name (like secretRef) are kept
+// since they hold a reference and not the secret itself.
+// Name/value pairs such as container env entries are redacted by their name.
+func RedactObject(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		if name, ok := v["name"].(string); ok && IsSensitiveKey(name) {
+			if _, ok := v["value"]; ok {
+				v["value"] = Redacted
+			}
+		}
+		for key, val := range v {
+			if IsSensitiveKey(key) && !isReference(val) {
+				v[key] = Redacted
+			} else {
+				v[key] = RedactObject(val)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = RedactObject(v[i])
+		}
+	}
+	return obj
+}
+
+// RedactLine redacts sensitive values from a single line of text
+func RedactLine(line string) string {
+	for _, re := range sensitiveLineREs {
+		line = re.ReplaceAllString(line, "${1}"+Redacted)
+	}
+	return line
+}
+
+// isReference returns true for kubernetes references to secrets such as {name, namespace}
+// or a secretKeyRef {name, key, optional}
+func isReference(val interface{}) bool {
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for key := range m {
+		if key != "name" && key != "namespace" && key != "key" && key != "optional" {
+			return false
+		}
+	}
+	return len(m) > 0
+}
@@ -0,0 +1,362 @@
+package doctor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	obv1 "github.com/kube-object-storage/lib-bucket-provisioner/pkg/apis/objectbucket.io/v1alpha1"
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/backingstore"
+	"github.com/noobaa/noobaa-operator/v2/pkg/namespacestore"
+	"github.com/noobaa/noobaa-operator/v2/pkg/system"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// StuckTimeout is the time after which pods and claims that are not ready are reported as stuck
+var StuckTimeout = 10 * time.Minute
+
+// storeModeRemediations are hints for the modes reported by noobaa-core on backing and namespace stores
+var storeModeRemediations = map[string]string{
+	"AUTH_FAILED":         "Verify the credentials in the store secret and that they have access to the target bucket",
+	"IO_ERRORS":           "Check the connectivity to the target endpoint and the permissions on the target bucket",
+	"STORAGE_NOT_EXIST":   "Verify that the target bucket/container exists or recreate the store with an existing one",
+	"NO_CAPACITY":         "The store is full, add capacity (e.g. more pv-pool volumes) or add another store to the bucketclass",
+	"LOW_CAPACITY":        "The store is almost full, add capacity or add another store to the bucketclass",
+	"HAS_NO_NODES":        "The pv-pool has no agents, check the pv-pool pods and their volumes",
+	"ALL_NODES_OFFLINE":   "All the pv-pool agents are offline, check the pv-pool pods and their logs",
+	"MANY_NODES_OFFLINE":  "Many pv-pool agents are offline, check the pv-pool pods and their logs",
+	"MOST_NODES_ISSUES":   "Most pv-pool agents have issues, check the pv-pool pods and their logs",
+	"MANY_NODES_ISSUES":   "Many pv-pool agents have issues, check the pv-pool pods and their logs",
+	"MOST_STORAGE_ISSUES": "Most pv-pool volumes have issues, check the pv-pool volumes and their storage class",
+	"MANY_STORAGE_ISSUES": "Many pv-pool volumes have issues, check the pv-pool volumes and their storage class",
+}
+
+func init() {
+	RegisterCheck(Check{
+		Name:        "system-phase",
+		Description: "The NooBaa system exists and is Ready",
+		Run:         CheckSystemPhase,
+	})
+	RegisterCheck(Check{
+		Name:        "db-pvc",
+		Description: "The database volume claim is bound",
+		Run:         CheckDBPVC,
+	})
+	RegisterCheck(Check{
+		Name:        "pv-pool-pods",
+		Description: "The pv-pool agent pods are running",
+		Run:         CheckPVPoolPods,
+	})
+	RegisterCheck(Check{
+		Name:        "store-mode",
+		Description: "The backing stores and namespace stores are in a healthy mode",
+		Run:         CheckStoreModes,
+	})
+	RegisterCheck(Check{
+		Name:        "endpoint-hpa",
+		Description: "The endpoints autoscaler has room to scale up",
+		Run:         CheckEndpointHPA,
+	})
+	RegisterCheck(Check{
+		Name:        "kms",
+		Description: "The external KMS is reachable",
+		Run:         CheckKMS,
+	})
+	RegisterCheck(Check{
+		Name:        "obc-pending",
+		Description: "The object bucket claims are bound",
+		Run:         CheckOBCs,
+	})
+	RegisterCheck(Check{
+		Name:        "version-skew",
+		Description: "The operator and core versions match",
+		Run:         CheckVersionSkew,
+	})
+}
+
+// CheckSystemPhase reports a missing system or a system that is not ready
+func CheckSystemPhase(in *Inputs) []Finding {
+	sys := in.NooBaa
+	if sys == nil {
+		return []Finding{{
+			Severity:    SeverityCritical,
+			Resource:    "noobaa",
+			Message:     "NooBaa system not found",
+			Remediation: "Install the system with `noobaa install` or check the --namespace flag",
+		}}
+	}
+	if sys.Status.Phase == nbv1.SystemPhaseReady {
+		return nil
+	}
+	severity := SeverityWarning
+	if sys.Status.Phase == nbv1.SystemPhaseRejected {
+		severity = SeverityCritical
+	}
+	msg := fmt.Sprintf("System phase is %q", sys.Status.Phase)
+	if c := lastCondition(sys.Status.Conditions); c != "" {
+		msg += ": " + c
+	}
+	return []Finding{{
+		Severity:    severity,
+		Resource:    "noobaa/" + sys.Name,
+		Message:     msg,
+		Remediation: "Run `noobaa status` to see what the system is waiting for",
+	}}
+}
+
+// CheckDBPVC reports database volume claims that are not bound
+func CheckDBPVC(in *Inputs) []Finding {
+	findings := []Finding{}
+	for i := range in.PVCs {
+		pvc := &in.PVCs[i]
+		if !strings.HasPrefix(pvc.Name, "db-") || pvc.Status.Phase == corev1.ClaimBound {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityCritical,
+			Resource: "pvc/" + pvc.Name,
+			Message: fmt.Sprintf("Database volume claim is %q for %s",
+				pvc.Status.Phase, age(in, pvc.CreationTimestamp.Time)),
+			Remediation: "Check that the storage class exists and can provision volumes (kubectl describe pvc " +
+				pvc.Name + "), or set a valid storage class with --db-storage-class",
+		})
+	}
+	return findings
+}
+
+// CheckPVPoolPods reports pv-pool agent pods that did not start for a long time
+func CheckPVPoolPods(in *Inputs) []Finding {
+	findings := []Finding{}
+	for i := range in.Pods {
+		pod := &in.Pods[i]
+		pool := pod.Labels["pool"]
+		if pool == "" || isPodReady(pod) {
+			continue
+		}
+		if in.Now.Sub(pod.CreationTimestamp.Time) < StuckTimeout {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Resource: "pod/" + pod.Name,
+			Message: fmt.Sprintf("pv-pool %q agent pod is not ready for %s: %s",
+				pool, age(in, pod.CreationTimestamp.Time), util.GetPodStatusLine(pod)),
+			Remediation: "Check that the agent volume is bound and attached and that the node has enough resources (kubectl describe pod " +
+				pod.Name + ")",
+		})
+	}
+	return findings
+}
+
+// CheckStoreModes reports backing stores and namespace stores with a mode that is not healthy
+func CheckStoreModes(in *Inputs) []Finding {
+	findings := []Finding{}
+	for i := range in.BackingStores {
+		bs := &in.BackingStores[i]
+		mode := bs.Status.Mode.ModeCode
+		info, exist := backingstore.GetModeInfo(mode)
+		if !exist || info.Severity != corev1.EventTypeWarning {
+			continue
+		}
+		findings = append(findings, storeModeFinding("backingstore/"+bs.Name, mode,
+			info.Phase == nbv1.BackingStorePhaseRejected))
+	}
+	for i := range in.NamespaceStores {
+		ns := &in.NamespaceStores[i]
+		mode := ns.Status.Mode.ModeCode
+		info, exist := namespacestore.GetModeInfo(mode)
+		if !exist || info.Severity != corev1.EventTypeWarning {
+			continue
+		}
+		findings = append(findings, storeModeFinding("namespacestore/"+ns.Name, mode,
+			info.Phase == nbv1.NamespaceStorePhaseRejected))
+	}
+	return findings
+}
+
+func storeModeFinding(resource string, mode string, rejected bool) Finding {
+	severity := SeverityWarning
+	if rejected {
+		severity = SeverityCritical
+	}
+	return Finding{
+		Severity:    severity,
+		Resource:    resource,
+		Message:     fmt.Sprintf("Store mode is %q", mode),
+		Remediation: storeModeRemediations[mode],
+	}
+}
+
+// CheckEndpointHPA reports when the autoscaler of the endpoints or of an endpoint group reached its max replicas
+func CheckEndpointHPA(in *Inputs) []Finding {
+	findings := []Finding{}
+	for i := range in.HPAEndpoints {
+		hpa := &in.HPAEndpoints[i]
+		if hpa.Spec.MaxReplicas == 0 || hpa.Status.CurrentReplicas < hpa.Spec.MaxReplicas {
+			continue
+		}
+		remediation := "Increase spec.endpoints.maxCount in the NooBaa CR to allow more endpoints"
+		if group := hpa.Labels[system.EndpointGroupLabel]; group != "" {
+			remediation = fmt.Sprintf("Increase the maxCount of endpoint group %q in spec.endpointGroups of the NooBaa CR", group)
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Resource: "hpa/" + hpa.Name,
+			Message: fmt.Sprintf("Endpoints autoscaler is at its max replicas %d/%d",
+				hpa.Status.CurrentReplicas, hpa.Spec.MaxReplicas),
+			Remediation: remediation,
+		})
+	}
+	return findings
+}
+
+// CheckKMS reports when the system is configured with an external KMS and the reconcile fails with it
+func CheckKMS(in *Inputs) []Finding {
+	sys := in.NooBaa
+	if sys == nil || len(sys.Spec.Security.KeyManagementService.ConnectionDetails) == 0 {
+		return nil
+	}
+	for i := range sys.Status.Conditions {
+		c := &sys.Status.Conditions[i]
+		if c.Type != conditionsv1.ConditionProgressing || c.Reason != system.KMSErrorReason ||
+			c.Status != corev1.ConditionTrue {
+			continue
+		}
+		return []Finding{{
+			Severity: SeverityCritical,
+			Resource: "noobaa/" + sys.Name,
+			Message:  "External KMS failed: " + c.Message,
+			Remediation: "Verify the KMS address and that the token secret " +
+				sys.Spec.Security.KeyManagementService.TokenSecretName + " is valid",
+		}}
+	}
+	return nil
+}
+
+// CheckOBCs reports object bucket claims that failed or are pending for a long time
+func CheckOBCs(in *Inputs) []Finding {
+	findings := []Finding{}
+	for i := range in.OBCs {
+		obc := &in.OBCs[i]
+		switch obc.Status.Phase {
+		case obv1.ObjectBucketClaimStatusPhaseBound:
+			continue
+		case obv1.ObjectBucketClaimStatusPhaseFailed:
+			findings = append(findings, Finding{
+				Severity:    SeverityCritical,
+				Resource:    "obc/" + obc.Namespace + "/" + obc.Name,
+				Message:     "Object bucket claim failed",
+				Remediation: "Check the operator logs for the provisioner error and recreate the claim",
+			})
+		default:
+			if in.Now.Sub(obc.CreationTimestamp.Time) < StuckTimeout {
+				continue
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Resource: "obc/" + obc.Namespace + "/" + obc.Name,
+				Message: fmt.Sprintf("Object bucket claim is %q for %s",
+					obc.Status.Phase, age(in, obc.CreationTimestamp.Time)),
+				Remediation: "Check that the system is Ready and that the bucketclass of the claim exists and is Ready",
+			})
+		}
+	}
+	return findings
+}
+
+// CheckVersionSkew reports the compatibility decision of the operator about the core image,
+// or when the operator and core images have different major.minor versions for operators that do not report it
+func CheckVersionSkew(in *Inputs) []Finding {
+	if in.NooBaa != nil && in.NooBaa.Status.Compatibility != nil {
+		c := in.NooBaa.Status.Compatibility
+		severity := SeverityWarning
+		switch c.Decision {
+		case nbv1.CompatibilityDecisionSupported:
+			return nil
+		case nbv1.CompatibilityDecisionRejected:
+			severity = SeverityCritical
+		}
+		return []Finding{{
+			Severity:    severity,
+			Resource:    "noobaa/" + in.NooBaa.Name,
+			Message:     fmt.Sprintf("Core image compatibility is %s: %s", c.Decision, c.Message),
+			Remediation: "Use a core image that is supported by the operator version, or remove spec.image from the NooBaa CR to use the operator default",
+		}}
+	}
+	if in.NooBaa == nil || in.OperatorDeployment == nil || len(in.OperatorDeployment.Spec.Template.Spec.Containers) == 0 {
+		return nil
+	}
+	operatorImage := in.OperatorDeployment.Spec.Template.Spec.Containers[0].Image
+	coreImage := in.NooBaa.Status.ActualImage
+	operatorVersion := imageMinorVersion(operatorImage)
+	coreVersion := imageMinorVersion(coreImage)
+	if operatorVersion == "" || coreVersion == "" || operatorVersion == coreVersion {
+		return nil
+	}
+	return []Finding{{
+		Severity:    SeverityWarning,
+		Resource:    "noobaa/" + in.NooBaa.Name,
+		Message:     fmt.Sprintf("Operator image %q and core image %q have different versions", operatorImage, coreImage),
+		Remediation: "Upgrade the operator and core together, or remove spec.image from the NooBaa CR to use the operator default",
+	}}
+}
+
+// imageMinorVersion returns the major.minor version from an image tag such as 5.8.0-20210519
+func imageMinorVersion(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	tag := strings.TrimPrefix(image[i+1:], "v")
+	parts := strings.SplitN(tag, ".", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	for _, r := range parts[0] + parts[1] {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for i := range pod.Status.Conditions {
+		c := &pod.Status.Conditions[i]
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// lastCondition returns the message of the true condition with the latest transition
+func lastCondition(conditions []conditionsv1.Condition) string {
+	var last *conditionsv1.Condition
+	for i := range conditions {
+		c := &conditions[i]
+		if c.Status != corev1.ConditionTrue || c.Message == "" {
+			continue
+		}
+		if last == nil || last.LastTransitionTime.Before(&c.LastTransitionTime) {
+			last = c
+		}
+	}
+	if last == nil {
+		return ""
+	}
+	return last.Message
+}
+
+func age(in *Inputs, t time.Time) string {
+	return in.Now.Sub(t).Round(time.Second).String()
+}
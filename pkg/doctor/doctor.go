@@ -0,0 +1,264 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/system"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Severity is the severity of a finding
+type Severity string
+
+const (
+	// SeverityInfo is used for findings that do not require any action
+	SeverityInfo Severity = "Info"
+	// SeverityWarning is used for findings that degrade the system or might block it soon
+	SeverityWarning Severity = "Warning"
+	// SeverityCritical is used for findings that block the system or make it lose service
+	SeverityCritical Severity = "Critical"
+)
+
+var severityOrder = map[Severity]int{
+	SeverityCritical: 0,
+	SeverityWarning:  1,
+	SeverityInfo:     2,
+}
+
+// Finding is a single issue found by a check
+type Finding struct {
+	Check       string   `json:"check"`
+	Severity    Severity `json:"severity"`
+	Resource    string   `json:"resource,omitempty"`
+	Message     string   `json:"message"`
+	Remediation string   `json:"remediation,omitempty"`
+}
+
+// Check is a rule that analyzes the loaded resources and returns its findings.
+// Checks should only read the resources from the inputs so that they can run without a cluster.
+type Check struct {
+	Name        string
+	Description string
+	Run         func(in *Inputs) []Finding
+}
+
+// Checks is the list of registered checks in the order they run
+var Checks []Check
+
+// RegisterCheck adds a check to the list of checks that the doctor runs
+func RegisterCheck(check Check) {
+	Checks = append(Checks, check)
+}
+
+// Inputs are the resources that the checks analyze, loaded once from the cluster
+type Inputs struct {
+	Now                time.Time
+	NooBaa             *nbv1.NooBaa
+	BackingStores      []nbv1.BackingStore
+	NamespaceStores    []nbv1.NamespaceStore
+	OBCs               []nbv1.ObjectBucketClaim
+	PVCs               []corev1.PersistentVolumeClaim
+	Pods               []corev1.Pod
+	HPAEndpoints       []autoscalingv1.HorizontalPodAutoscaler
+	OperatorDeployment *appsv1.Deployment
+}
+
+// Report is the result of running the checks
+type Report struct {
+	Namespace string    `json:"namespace"`
+	Checks    []string  `json:"checks"`
+	Findings  []Finding `json:"findings"`
+}
+
+// Cmd returns a CLI command
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Analyze the system health and suggest remediations",
+		Run:   RunDoctor,
+		Args:  cobra.NoArgs,
+	}
+	cmd.Flags().StringP("output", "o", "", "Output format. One of: json")
+	cmd.Flags().StringSlice("checks", nil, "Run only the named checks (default all)")
+	return cmd
+}
+
+// RunDoctor runs a CLI command
+func RunDoctor(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+	output, _ := cmd.Flags().GetString("output")
+	checkNames, _ := cmd.Flags().GetStringSlice("checks")
+
+	if output != "" && output != "json" {
+		log.Fatalf(`❌ Unsupported output format %q, expected one of: json`, output)
+	}
+
+	checks, err := SelectChecks(checkNames)
+	if err != nil {
+		log.Fatalf(`❌ %s`, err)
+	}
+
+	report := RunChecks(LoadInputs(), checks)
+
+	if output == "json" {
+		bytes, err := json.MarshalIndent(report, "", "  ")
+		util.Panic(err)
+		fmt.Println(string(bytes))
+	} else {
+		PrintReport(report)
+	}
+
+	for _, f := range report.Findings {
+		if f.Severity == SeverityCritical {
+			os.Exit(1)
+		}
+	}
+}
+
+// SelectChecks returns the registered checks by name, or all the checks when no names are given
+func SelectChecks(names []string) ([]Check, error) {
+	if len(names) == 0 {
+		return Checks, nil
+	}
+	selected := []Check{}
+	for _, name := range names {
+		found := false
+		for _, check := range Checks {
+			if check.Name == name {
+				selected = append(selected, check)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown check %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// RunChecks runs the checks on the inputs and returns the findings sorted by severity
+func RunChecks(in *Inputs, checks []Check) *Report {
+	report := &Report{
+		Namespace: options.Namespace,
+		Checks:    []string{},
+		Findings:  []Finding{},
+	}
+	for _, check := range checks {
+		report.Checks = append(report.Checks, check.Name)
+		for _, f := range check.Run(in) {
+			f.Check = check.Name
+			report.Findings = append(report.Findings, f)
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return severityOrder[report.Findings[i].Severity] < severityOrder[report.Findings[j].Severity]
+	})
+	return report
+}
+
+// PrintReport prints the findings of the report for humans
+func PrintReport(report *Report) {
+	if len(report.Findings) == 0 {
+		fmt.Printf("✅ No issues found by %d checks in namespace %q\n", len(report.Checks), report.Namespace)
+		return
+	}
+	for _, f := range report.Findings {
+		icon := "ℹ️ "
+		switch f.Severity {
+		case SeverityCritical:
+			icon = "❌"
+		case SeverityWarning:
+			icon = "⚠️ "
+		}
+		fmt.Printf("%s %-8s [%s] %s: %s\n", icon, f.Severity, f.Check, f.Resource, f.Message)
+		if f.Remediation != "" {
+			fmt.Printf("   ➡️  %s\n", f.Remediation)
+		}
+	}
+	fmt.Printf("\nFound %d issues by %d checks in namespace %q\n", len(report.Findings), len(report.Checks), report.Namespace)
+}
+
+// LoadInputs loads the resources that the checks analyze from the cluster
+func LoadInputs() *Inputs {
+	in := &Inputs{Now: time.Now()}
+	nsOptions := &client.ListOptions{Namespace: options.Namespace}
+
+	sys := &nbv1.NooBaa{
+		TypeMeta:   metav1.TypeMeta{Kind: "NooBaa"},
+		ObjectMeta: metav1.ObjectMeta{Name: options.SystemName, Namespace: options.Namespace},
+	}
+	if util.KubeCheckQuiet(sys) {
+		in.NooBaa = sys
+	}
+
+	bsList := &nbv1.BackingStoreList{TypeMeta: metav1.TypeMeta{Kind: "BackingStoreList"}}
+	if util.KubeList(bsList, nsOptions) {
+		in.BackingStores = bsList.Items
+	}
+
+	nsList := &nbv1.NamespaceStoreList{TypeMeta: metav1.TypeMeta{Kind: "NamespaceStoreList"}}
+	if util.KubeList(nsList, nsOptions) {
+		in.NamespaceStores = nsList.Items
+	}
+
+	// OBCs are created in the application namespaces so we list them from all namespaces
+	// and keep only the ones provisioned by this system storage class
+	obcList := &nbv1.ObjectBucketClaimList{TypeMeta: metav1.TypeMeta{Kind: "ObjectBucketClaimList"}}
+	if util.KubeList(obcList, &client.ListOptions{}) {
+		storageClassName := options.SubDomainNS()
+		for i := range obcList.Items {
+			if obcList.Items[i].Spec.StorageClassName == storageClassName {
+				in.OBCs = append(in.OBCs, obcList.Items[i])
+			}
+		}
+	}
+
+	appSelector, _ := labels.Parse("app=noobaa")
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if util.KubeList(pvcList, &client.ListOptions{Namespace: options.Namespace, LabelSelector: appSelector}) {
+		in.PVCs = pvcList.Items
+	}
+
+	podList := &corev1.PodList{}
+	if util.KubeList(podList, &client.ListOptions{Namespace: options.Namespace, LabelSelector: appSelector}) {
+		in.Pods = podList.Items
+	}
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{Kind: "HorizontalPodAutoscaler"},
+		ObjectMeta: metav1.ObjectMeta{Name: options.SystemName + "-endpoint", Namespace: options.Namespace},
+	}
+	if util.KubeCheckQuiet(hpa) {
+		in.HPAEndpoints = append(in.HPAEndpoints, *hpa)
+	}
+
+	groupHPAList := &autoscalingv1.HorizontalPodAutoscalerList{}
+	if util.KubeList(groupHPAList, client.InNamespace(options.Namespace), client.HasLabels{system.EndpointGroupLabel}) {
+		in.HPAEndpoints = append(in.HPAEndpoints, groupHPAList.Items...)
+	}
+
+	operatorDeployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "noobaa-operator", Namespace: options.Namespace},
+	}
+	if util.KubeCheckQuiet(operatorDeployment) {
+		in.OperatorDeployment = operatorDeployment
+	}
+
+	return in
+}
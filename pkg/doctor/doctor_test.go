@@ -0,0 +1,165 @@
+package doctor
+
+import (
+	"testing"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/system"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImageMinorVersion(t *testing.T) {
+	tests := map[string]string{
+		"noobaa/noobaa-core:5.8.0-20210519":         "5.8",
+		"registry:5000/noobaa/noobaa-operator:v5.7": "5.7",
+		"noobaa/noobaa-core:master-20210519":        "",
+		"noobaa/noobaa-core@sha256:abcdef":          "",
+		"registry:5000/noobaa/noobaa-core":          "",
+	}
+	for image, expected := range tests {
+		if got := imageMinorVersion(image); got != expected {
+			t.Errorf("imageMinorVersion(%q) = %q, expected %q", image, got, expected)
+		}
+	}
+}
+
+func TestRunChecks(t *testing.T) {
+	now := time.Now()
+	in := &Inputs{
+		Now: now,
+		NooBaa: &nbv1.NooBaa{
+			ObjectMeta: metav1.ObjectMeta{Name: "noobaa"},
+			Status: nbv1.NooBaaStatus{
+				Phase:       nbv1.SystemPhaseReady,
+				ActualImage: "noobaa/noobaa-core:5.8.0",
+			},
+		},
+		BackingStores: []nbv1.BackingStore{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "ok"},
+				Status:     nbv1.BackingStoreStatus{Mode: nbv1.BackingStoreMode{ModeCode: "OPTIMAL"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "low"},
+				Status:     nbv1.BackingStoreStatus{Mode: nbv1.BackingStoreMode{ModeCode: "LOW_CAPACITY"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "auth"},
+				Status:     nbv1.BackingStoreStatus{Mode: nbv1.BackingStoreMode{ModeCode: "AUTH_FAILED"}},
+			},
+		},
+		PVCs: []corev1.PersistentVolumeClaim{{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-noobaa-db-0", CreationTimestamp: metav1.NewTime(now)},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		}},
+		Pods: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "new-pool-pod", Labels: map[string]string{"pool": "pv"},
+					CreationTimestamp: metav1.NewTime(now.Add(-time.Minute))},
+				Status: corev1.PodStatus{Phase: corev1.PodPending},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "stuck-pool-pod", Labels: map[string]string{"pool": "pv"},
+					CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+				Status: corev1.PodStatus{Phase: corev1.PodPending},
+			},
+		},
+		HPAEndpoints: []autoscalingv1.HorizontalPodAutoscaler{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "noobaa-endpoint"},
+				Spec:       autoscalingv1.HorizontalPodAutoscalerSpec{MaxReplicas: 2},
+				Status:     autoscalingv1.HorizontalPodAutoscalerStatus{CurrentReplicas: 1},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "noobaa-endpoint-edge",
+					Labels: map[string]string{system.EndpointGroupLabel: "edge"}},
+				Spec:   autoscalingv1.HorizontalPodAutoscalerSpec{MaxReplicas: 2},
+				Status: autoscalingv1.HorizontalPodAutoscalerStatus{CurrentReplicas: 2},
+			},
+		},
+		OperatorDeployment: &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Image: "noobaa/noobaa-operator:5.7.0"}},
+			}}},
+		},
+	}
+
+	report := RunChecks(in, Checks)
+
+	expected := []struct {
+		check    string
+		resource string
+		severity Severity
+	}{
+		{"db-pvc", "pvc/db-noobaa-db-0", SeverityCritical},
+		{"store-mode", "backingstore/auth", SeverityCritical},
+		{"pv-pool-pods", "pod/stuck-pool-pod", SeverityWarning},
+		{"store-mode", "backingstore/low", SeverityWarning},
+		{"endpoint-hpa", "hpa/noobaa-endpoint-edge", SeverityWarning},
+		{"version-skew", "noobaa/noobaa", SeverityWarning},
+	}
+	if len(report.Findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %+v", len(expected), report.Findings)
+	}
+	for i, e := range expected {
+		f := report.Findings[i]
+		if f.Check != e.check || f.Resource != e.resource || f.Severity != e.severity {
+			t.Errorf("finding %d: expected %s %s %s, got %+v", i, e.check, e.resource, e.severity, f)
+		}
+	}
+}
+
+func TestCheckVersionSkewCompatibility(t *testing.T) {
+	in := &Inputs{NooBaa: &nbv1.NooBaa{ObjectMeta: metav1.ObjectMeta{Name: "noobaa"}}}
+	in.NooBaa.Status.Compatibility = &nbv1.CompatibilityStatus{Decision: nbv1.CompatibilityDecisionSupported}
+	if findings := CheckVersionSkew(in); len(findings) != 0 {
+		t.Errorf("expected no findings for a supported image, got %+v", findings)
+	}
+	in.NooBaa.Status.Compatibility.Decision = nbv1.CompatibilityDecisionRejected
+	if findings := CheckVersionSkew(in); len(findings) != 1 || findings[0].Severity != SeverityCritical {
+		t.Errorf("expected a critical finding for a rejected image, got %+v", findings)
+	}
+}
+
+func TestCheckKMS(t *testing.T) {
+	in := &Inputs{NooBaa: &nbv1.NooBaa{ObjectMeta: metav1.ObjectMeta{Name: "noobaa"}}}
+	in.NooBaa.Spec.Security.KeyManagementService.ConnectionDetails = map[string]string{"KMS_PROVIDER": "vault"}
+	in.NooBaa.Status.Conditions = []conditionsv1.Condition{{
+		Type:    conditionsv1.ConditionAvailable,
+		Status:  corev1.ConditionTrue,
+		Reason:  "SystemPhaseReady",
+		Message: "checked the KMS connection details",
+	}}
+	if findings := CheckKMS(in); len(findings) != 0 {
+		t.Errorf("expected no findings for a message that mentions the KMS, got %+v", findings)
+	}
+	in.NooBaa.Status.Conditions = append(in.NooBaa.Status.Conditions, conditionsv1.Condition{
+		Type:    conditionsv1.ConditionProgressing,
+		Status:  corev1.ConditionTrue,
+		Reason:  system.KMSErrorReason,
+		Message: "could not initialize external KMS client",
+	})
+	if findings := CheckKMS(in); len(findings) != 1 || findings[0].Severity != SeverityCritical {
+		t.Errorf("expected a critical finding for a kms error, got %+v", findings)
+	}
+}
+
+func TestLastCondition(t *testing.T) {
+	now := time.Now()
+	conditions := []conditionsv1.Condition{
+		{Type: conditionsv1.ConditionAvailable, Status: corev1.ConditionTrue, Message: "old",
+			LastTransitionTime: metav1.NewTime(now.Add(-time.Hour))},
+		{Type: conditionsv1.ConditionProgressing, Status: corev1.ConditionTrue, Message: "new",
+			LastTransitionTime: metav1.NewTime(now)},
+		{Type: conditionsv1.ConditionDegraded, Status: corev1.ConditionFalse, Message: "newest but false",
+			LastTransitionTime: metav1.NewTime(now.Add(time.Hour))},
+	}
+	if c := lastCondition(conditions); c != "new" {
+		t.Errorf("expected the latest true condition, got %q", c)
+	}
+}
@@ -17,11 +17,15 @@ import (
 // CmdInstall returns a CLI command
 func CmdInstall() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "install",
+		Use:   "install [<dir>]",
 		Short: "Install the operator and create the noobaa system",
 		Run:   RunInstall,
+		Args:  cobra.MaximumNArgs(1),
 	}
 	cmd.Flags().Bool("use-obc-cleanup-policy", false, "Create NooBaa system with obc cleanup policy")
+	cmd.Flags().String("output", "", "Render the install to <dir> without applying it to the cluster. One of: helm, kustomize")
+	cmd.Flags().Bool("preflight-only", false, "Only run the preflight checks against the cluster without installing")
+	cmd.Flags().Bool("skip-preflight", false, "Install even if the preflight checks fail")
 	return cmd
 }
 
@@ -50,9 +54,33 @@ func CmdStatus() *cobra.Command {
 // RunInstall runs a CLI command
 func RunInstall(cmd *cobra.Command, args []string) {
 	log := util.Logger()
+	output, _ := cmd.Flags().GetString("output")
+	if output != "" {
+		if len(args) != 1 {
+			log.Fatalf(`❌ Missing expected arguments: <dir> %s`, cmd.UsageString())
+		}
+		RunRender(output, args[0])
+		return
+	}
+	if len(args) != 0 {
+		log.Fatalf(`❌ Unexpected argument %q, a directory is expected only with --output`, args[0])
+	}
+	preflightOnly, _ := cmd.Flags().GetBool("preflight-only")
+	skipPreflight, _ := cmd.Flags().GetBool("skip-preflight")
 	system.RunSystemVersionsStatus(cmd, args)
 	log.Printf("Namespace: %s", options.Namespace)
 	log.Printf("")
+	log.Printf("Preflight:")
+	if !system.RunPreflight(cmd, system.PreflightChecks) {
+		if preflightOnly || !skipPreflight {
+			log.Fatalf(`❌ Preflight checks failed, fix the failures above or install with --skip-preflight`)
+		}
+		log.Printf("⚠️  Preflight checks failed, continuing with --skip-preflight")
+	}
+	if preflightOnly {
+		return
+	}
+	log.Printf("")
 	log.Printf("CRD Create:")
 	crd.RunCreate(cmd, args)
 	log.Printf("")
@@ -60,7 +88,7 @@ func RunInstall(cmd *cobra.Command, args []string) {
 	operator.RunInstall(cmd, args)
 	log.Printf("")
 	log.Printf("System Create:")
-	system.RunCreate(cmd, args)
+	system.CreateSystem(cmd)
 	log.Printf("")
 	util.PrintThisNoteWhenFinishedApplyingAndStartWaitLoop()
 	log.Printf("")
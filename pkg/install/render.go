@@ -0,0 +1,405 @@
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
+	"github.com/noobaa/noobaa-operator/v2/pkg/crd"
+	"github.com/noobaa/noobaa-operator/v2/pkg/operator"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/system"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"github.com/noobaa/noobaa-operator/v2/version"
+	"k8s.io/apimachinery/pkg/runtime"
+	sigyaml "sigs.k8s.io/yaml"
+)
+
+// Output formats of the install command
+const (
+	OutputHelm      = "helm"
+	OutputKustomize = "kustomize"
+)
+
+// Placeholders are set on the rendered objects and replaced by helm template expressions,
+// since some of the values are not strings and cannot be set on the typed objects directly
+const (
+	helmNamespace        = "HELM_NAMESPACE"
+	helmOperatorImage    = "HELM_OPERATOR_IMAGE"
+	helmOperatorRes      = "HELM_OPERATOR_RESOURCES"
+	helmImagePullSecrets = "HELM_IMAGE_PULL_SECRETS"
+	helmNooBaaSpec       = "HELM_NOOBAA_SPEC"
+)
+
+// RenderFile is a rendered file path relative to the output directory and its content
+type RenderFile struct {
+	Path    string
+	Content string
+}
+
+// namedObject is a bundled object and the file name to render it to
+type namedObject struct {
+	Name string
+	Obj  runtime.Object
+}
+
+// RunRender renders the install to a directory in the requested format
+func RunRender(format string, dir string) {
+	log := util.Logger()
+	var files []RenderFile
+	var err error
+	switch format {
+	case OutputHelm:
+		files, err = RenderHelm()
+	case OutputKustomize:
+		files, err = RenderKustomize()
+	default:
+		log.Fatalf(`❌ Invalid output %q, expected one of: %s, %s`, format, OutputHelm, OutputKustomize)
+	}
+	if err != nil {
+		log.Fatalf(`❌ Could not render the %s output: %s`, format, err)
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.Path)
+		util.Panic(os.MkdirAll(filepath.Dir(path), 0755))
+		util.Panic(ioutil.WriteFile(path, []byte(f.Content), 0644))
+	}
+	log.Printf("✅ Rendered the %s install to %s (%d files)", format, dir, len(files))
+}
+
+// RenderHelm renders the install as a helm chart.
+// The CRDs are rendered to the crds directory which helm installs before the templates,
+// and the namespace, images, resources and NooBaa spec are parameterized by the chart values.
+func RenderHelm() ([]RenderFile, error) {
+	c := operator.LoadOperatorConfForNamespace(helmNamespace)
+	c.Deployment.Spec.Template.Spec.Containers[0].Image = helmOperatorImage
+	operatorResources := c.Deployment.Spec.Template.Spec.Containers[0].Resources
+
+	files := []RenderFile{{
+		Path: "Chart.yaml",
+		Content: fmt.Sprintf(`apiVersion: v2
+name: noobaa
+description: NooBaa operator and system, rendered by noobaa install --output helm
+type: application
+version: %s
+appVersion: %s
+`, version.Version, version.Version),
+	}, {
+		Path: "templates/_helpers.tpl",
+		Content: `{{/* noobaa.namespace is the namespace of the operator and the system */}}
+{{- define "noobaa.namespace" -}}
+{{- .Values.namespace | default .Release.Namespace -}}
+{{- end -}}
+{{/* noobaa.spec is the NooBaa spec of the values with the db type and image of the top level values */}}
+{{- define "noobaa.spec" -}}
+{{- $spec := deepCopy .Values.noobaa.spec -}}
+{{- with .Values.dbType }}{{ $_ := set $spec "dbType" . }}{{ end -}}
+{{- with .Values.dbImage }}{{ $_ := set $spec "dbImage" . }}{{ end -}}
+{{- toYaml $spec -}}
+{{- end -}}
+`,
+	}}
+
+	for _, o := range renderCRDs() {
+		content, err := renderObject(o.Obj, nil)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, RenderFile{Path: "crds/" + o.Name, Content: content})
+	}
+
+	for _, o := range renderOperatorObjects(c) {
+		content, err := renderObject(o.Obj, func(u map[string]interface{}) {
+			if o.Obj != c.Deployment {
+				return
+			}
+			podSpec := u["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+			podSpec["containers"].([]interface{})[0].(map[string]interface{})["resources"] = helmOperatorRes
+			podSpec["imagePullSecrets"] = helmImagePullSecrets
+		})
+		if err != nil {
+			return nil, err
+		}
+		switch o.Obj {
+		case c.NS:
+			content = "{{- if .Values.createNamespace }}\n" + content + "{{- end }}\n"
+		case c.SecurityContextConstraints, c.SCCEndpoint:
+			content = "{{- if .Values.openshift }}\n" + content + "{{- end }}\n"
+		}
+		files = append(files, RenderFile{Path: "templates/" + o.Name, Content: helmTemplate(content)})
+	}
+
+	sys := renderSystem(helmNamespace)
+	content, err := renderObject(sys, func(u map[string]interface{}) {
+		u["spec"] = helmNooBaaSpec
+	})
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, RenderFile{
+		Path:    "templates/noobaa.yaml",
+		Content: "{{- if .Values.noobaa.create }}\n" + helmTemplate(content) + "{{- end }}\n",
+	})
+
+	values, err := helmValues(operatorResources)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, RenderFile{Path: "values.yaml", Content: values})
+	return files, nil
+}
+
+// RenderKustomize renders the install as a kustomize base with the bundled defaults,
+// and an overlay that sets the namespace, the operator image and the NooBaa spec of the CLI options
+func RenderKustomize() ([]RenderFile, error) {
+	c := operator.LoadOperatorConf(nil)
+	files := []RenderFile{}
+	resources := []string{}
+	add := func(o namedObject) error {
+		content, err := renderObject(o.Obj, nil)
+		if err != nil {
+			return err
+		}
+		files = append(files, RenderFile{Path: "base/" + o.Name, Content: content})
+		return nil
+	}
+
+	for _, o := range append(renderCRDs(), renderOperatorObjects(c)...) {
+		if err := add(o); err != nil {
+			return nil, err
+		}
+		if o.Obj != c.SecurityContextConstraints && o.Obj != c.SCCEndpoint {
+			resources = append(resources, o.Name)
+		}
+	}
+	base := util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_noobaa_cr_yaml).(*nbv1.NooBaa)
+	base.Namespace = options.Namespace
+	base.Name = options.SystemName
+	base.Finalizers = []string{nbv1.GracefulFinalizer}
+	if err := add(namedObject{"noobaa.yaml", base}); err != nil {
+		return nil, err
+	}
+	resources = append(resources, "noobaa.yaml")
+
+	var sb strings.Builder
+	sb.WriteString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n")
+	for _, r := range resources {
+		sb.WriteString("- " + r + "\n")
+	}
+	sb.WriteString("# security context constraints are needed only on openshift\n")
+	sb.WriteString("# - scc.yaml\n# - scc_endpoint.yaml\n")
+	files = append(files, RenderFile{Path: "base/kustomization.yaml", Content: sb.String()})
+
+	patch, err := renderObject(renderSystem(options.Namespace), nil)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, RenderFile{Path: "noobaa.yaml", Content: patch})
+
+	name, tag := SplitImage(options.OperatorImage)
+	image := fmt.Sprintf("- name: %s\n  newName: %s\n", name, name)
+	if tag != "" {
+		image += fmt.Sprintf("  newTag: %q\n", tag)
+	}
+	files = append(files, RenderFile{
+		Path: "kustomization.yaml",
+		Content: fmt.Sprintf(`# Rendered by noobaa install --output kustomize
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: %s
+resources:
+- base
+images:
+%spatchesStrategicMerge:
+- noobaa.yaml
+`, options.Namespace, image),
+	})
+	return files, nil
+}
+
+// SplitImage splits an image to its name and tag, where a digest is kept as part of the name
+func SplitImage(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		return image, ""
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, ""
+	}
+	return image[:i], image[i+1:]
+}
+
+// ReplaceHelmBlock replaces every "key: placeholder" line with the block returned for its indentation and key
+func ReplaceHelmBlock(text string, placeholder string, block func(indent string, key string) string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if !strings.HasSuffix(trimmed, ": "+placeholder) {
+			continue
+		}
+		indent := line[:len(line)-len(trimmed)]
+		lines[i] = block(indent, strings.TrimSuffix(trimmed, ": "+placeholder))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// helmTemplate replaces the placeholders of a rendered object with helm template expressions
+func helmTemplate(content string) string {
+	content = strings.ReplaceAll(content, helmNamespace, `{{ include "noobaa.namespace" . }}`)
+	content = strings.ReplaceAll(content, helmOperatorImage, `{{ .Values.operator.image }}`)
+	content = ReplaceHelmBlock(content, helmOperatorRes, func(indent string, key string) string {
+		return fmt.Sprintf("%s%s:\n%s  {{- toYaml .Values.operator.resources | nindent %d }}",
+			indent, key, indent, len(indent)+2)
+	})
+	content = ReplaceHelmBlock(content, helmImagePullSecrets, func(indent string, key string) string {
+		return fmt.Sprintf("%s{{- with .Values.imagePullSecret }}\n%s%s:\n%s- name: {{ . }}\n%s{{- end }}",
+			indent, indent, key, indent, indent)
+	})
+	content = ReplaceHelmBlock(content, helmNooBaaSpec, func(indent string, key string) string {
+		return fmt.Sprintf("%s%s:\n%s  {{- include \"noobaa.spec\" . | nindent %d }}",
+			indent, key, indent, len(indent)+2)
+	})
+	return content
+}
+
+// helmValues renders the default values of the chart from the CLI options.
+// The namespace is empty to use the release namespace, and the db type and image
+// are top level values that override the NooBaa spec.
+func helmValues(operatorResources interface{}) (string, error) {
+	resources, err := indentYAML(operatorResources, "    ")
+	if err != nil {
+		return "", err
+	}
+	sysSpec := system.LoadSystemDefaults().Spec
+	dbType := sysSpec.DBType
+	dbImage := ""
+	if sysSpec.DBImage != nil {
+		dbImage = *sysSpec.DBImage
+	}
+	sysSpec.DBType = ""
+	sysSpec.DBImage = nil
+	spec, err := indentYAML(sysSpec, "    ")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`# Values of the noobaa chart, rendered by noobaa install --output helm
+
+# namespace of the operator and the system, defaults to the release namespace
+namespace: ""
+
+# createNamespace creates the namespace, when not using helm install --create-namespace
+createNamespace: false
+
+# openshift installs the security context constraints of the operator and the endpoints
+openshift: false
+
+# imagePullSecret (optional) is the name of a secret to pull the operator image
+imagePullSecret: %q
+
+# dbType is the type of the NooBaa DB, mongodb or postgres
+dbType: %q
+
+# dbImage (optional) is the image of the NooBaa DB, defaults to the operator default of the db type
+dbImage: %q
+
+operator:
+  image: %s
+  resources:
+%s
+noobaa:
+  # create the NooBaa system, or install only the operator when false
+  create: true
+  # spec of the NooBaa system, see doc/noobaa-crd.md
+  spec:
+%s`, options.ImagePullSecret, dbType, dbImage, options.OperatorImage, resources, spec), nil
+}
+
+// renderCRDs returns the bundled CRDs
+func renderCRDs() []namedObject {
+	objects := []namedObject{}
+	for _, c := range crd.LoadCrds().All {
+		objects = append(objects, namedObject{c.Name + ".yaml", c})
+	}
+	return objects
+}
+
+// renderOperatorObjects returns the operator objects by the names of their bundled files
+func renderOperatorObjects(c *operator.Conf) []namedObject {
+	return []namedObject{
+		{"namespace.yaml", c.NS},
+		{"service_account.yaml", c.SA},
+		{"service_account_endpoint.yaml", c.SAEndpoint},
+		{"role.yaml", c.Role},
+		{"role_endpoint.yaml", c.RoleEndpoint},
+		{"role_binding.yaml", c.RoleBinding},
+		{"role_binding_endpoint.yaml", c.RoleBindingEndpoint},
+		{"cluster_role.yaml", c.ClusterRole},
+		{"cluster_role_binding.yaml", c.ClusterRoleBinding},
+		{"scc.yaml", c.SecurityContextConstraints},
+		{"scc_endpoint.yaml", c.SCCEndpoint},
+		{"operator.yaml", c.Deployment},
+		{"service_operator_metrics.yaml", c.ServiceMetrics},
+	}
+}
+
+// renderSystem returns the NooBaa system of the CLI options in the given namespace
+func renderSystem(ns string) *nbv1.NooBaa {
+	sys := system.LoadSystemDefaults()
+	sys.Namespace = ns
+	return sys
+}
+
+// renderObject converts an object to yaml without the status and the empty creation timestamps,
+// and lets the caller modify the unstructured object before it is rendered
+func renderObject(obj runtime.Object, modify func(u map[string]interface{})) (string, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+	delete(u, "status")
+	removeNullTimestamps(u)
+	if modify != nil {
+		modify(u)
+	}
+	out, err := sigyaml.Marshal(u)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func removeNullTimestamps(u map[string]interface{}) {
+	for key, value := range u {
+		if key == "creationTimestamp" && value == nil {
+			delete(u, key)
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			removeNullTimestamps(v)
+		case []interface{}:
+			for _, item := range v {
+				if m, isMap := item.(map[string]interface{}); isMap {
+					removeNullTimestamps(m)
+				}
+			}
+		}
+	}
+}
+
+// indentYAML renders a value as yaml with every line indented, or {} when it is empty
+func indentYAML(value interface{}, indent string) (string, error) {
+	out, err := sigyaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		sb.WriteString(indent + line + "\n")
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,129 @@
+package install
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+
+	sigyaml "sigs.k8s.io/yaml"
+)
+
+func TestSplitImage(t *testing.T) {
+	cases := map[string][2]string{
+		"noobaa/noobaa-operator:5.9.0":        {"noobaa/noobaa-operator", "5.9.0"},
+		"noobaa/noobaa-operator":              {"noobaa/noobaa-operator", ""},
+		"registry:5000/noobaa/noobaa-core":    {"registry:5000/noobaa/noobaa-core", ""},
+		"registry:5000/noobaa/noobaa-core:v1": {"registry:5000/noobaa/noobaa-core", "v1"},
+		"noobaa/noobaa-core@sha256:abcd":      {"noobaa/noobaa-core@sha256:abcd", ""},
+	}
+	for image, expected := range cases {
+		name, tag := SplitImage(image)
+		if name != expected[0] || tag != expected[1] {
+			t.Errorf("SplitImage(%q) = %q %q, expected %q %q", image, name, tag, expected[0], expected[1])
+		}
+	}
+}
+
+// TestRenderHelm executes the chart templates with the few helm functions they use,
+// and checks that every template renders to valid yaml without leftover placeholders
+func TestRenderHelm(t *testing.T) {
+	files, err := RenderHelm()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values := map[string]interface{}{}
+	helpers := ""
+	templates := map[string]string{}
+	for _, f := range files {
+		switch {
+		case f.Path == "values.yaml":
+			if err := sigyaml.Unmarshal([]byte(f.Content), &values); err != nil {
+				t.Fatalf("invalid values.yaml: %v", err)
+			}
+		case f.Path == "templates/_helpers.tpl":
+			helpers = f.Content
+		case strings.HasPrefix(f.Path, "templates/"):
+			templates[f.Path] = f.Content
+		}
+	}
+	if values["namespace"] != "" {
+		t.Fatalf("expected the namespace to default to the release namespace, got %q", values["namespace"])
+	}
+	spec := values["noobaa"].(map[string]interface{})["spec"].(map[string]interface{})
+	if _, exists := spec["dbType"]; exists {
+		t.Fatalf("expected the db type to be a top level value, got spec %v", spec)
+	}
+	values["imagePullSecret"] = "my-secret"
+	values["openshift"] = true
+	values["dbType"] = "postgres"
+	values["dbImage"] = "centos/postgresql-12-centos7"
+	data := map[string]interface{}{
+		"Values":  values,
+		"Release": map[string]interface{}{"Namespace": "release-ns"},
+	}
+
+	for path, content := range templates {
+		tmpl := template.New(path)
+		tmpl.Funcs(template.FuncMap{
+			"include": func(name string, data interface{}) (string, error) {
+				var buf bytes.Buffer
+				err := tmpl.ExecuteTemplate(&buf, name, data)
+				return buf.String(), err
+			},
+			"default": func(d interface{}, v interface{}) interface{} {
+				if v == nil || v == "" {
+					return d
+				}
+				return v
+			},
+			"toYaml": func(v interface{}) (string, error) {
+				out, err := sigyaml.Marshal(v)
+				return strings.TrimSuffix(string(out), "\n"), err
+			},
+			"deepCopy": func(v interface{}) interface{} {
+				out := map[string]interface{}{}
+				for key, value := range v.(map[string]interface{}) {
+					out[key] = value
+				}
+				return out
+			},
+			"set": func(d map[string]interface{}, key string, value interface{}) map[string]interface{} {
+				d[key] = value
+				return d
+			},
+			"nindent": func(n int, s string) string {
+				pad := strings.Repeat(" ", n)
+				return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+			},
+		})
+		if _, err := tmpl.Parse(helpers + content); err != nil {
+			t.Fatalf("%s: invalid template: %v", path, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			t.Fatalf("%s: failed to execute: %v", path, err)
+		}
+		out := buf.String()
+		if strings.Contains(out, "HELM_") {
+			t.Errorf("%s: leftover placeholder in:\n%s", path, out)
+		}
+		obj := map[string]interface{}{}
+		if err := sigyaml.Unmarshal(buf.Bytes(), &obj); err != nil {
+			t.Fatalf("%s: invalid yaml: %v\n%s", path, err, out)
+		}
+		if path == "templates/operator.yaml" {
+			if !strings.Contains(out, "- name: my-secret") || !strings.Contains(out, "memory: 512Mi") {
+				t.Errorf("%s: expected the pull secret and resources values in:\n%s", path, out)
+			}
+		}
+		if path == "templates/noobaa.yaml" {
+			if !strings.Contains(out, "dbType: postgres") || !strings.Contains(out, "dbImage: centos/postgresql-12-centos7") {
+				t.Errorf("%s: expected the db values in:\n%s", path, out)
+			}
+		}
+		if path == "templates/scc.yaml" && !strings.Contains(out, "system:serviceaccount:release-ns:noobaa") {
+			t.Errorf("%s: expected the namespace value in:\n%s", path, out)
+		}
+	}
+}
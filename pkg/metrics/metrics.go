@@ -0,0 +1,178 @@
+// Package metrics defines the prometheus metrics exported by the noobaa operator.
+// The metrics are registered on the controller-runtime registry which is served by the manager
+// on the metrics bind address, along with the builtin controller-runtime metrics.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// Namespace is the prefix of all the operator metrics
+	Namespace = "noobaa_operator"
+
+	// ReconcileResultSuccess is the result label of a reconcile that completed
+	ReconcileResultSuccess = "success"
+	// ReconcileResultRequeue is the result label of a reconcile that asked to be requeued
+	ReconcileResultRequeue = "requeue"
+	// ReconcileResultError is the result label of a reconcile that returned an error
+	ReconcileResultError = "error"
+
+	// RPCCodeConnError is the code label for rpc calls that failed without an rpc error code
+	RPCCodeConnError = "CONN_ERROR"
+
+	// KubeSourceAPI is the source label of kubernetes requests sent to the api server
+	KubeSourceAPI = "api"
+	// KubeSourceCache is the source label of kubernetes reads served by the informers cache
+	KubeSourceCache = "cache"
+)
+
+var (
+	// ReconcileDuration is the time it takes to reconcile a resource by controller, result and resulting phase
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of reconciles by controller, result and resulting phase",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"controller", "result", "phase"})
+
+	// ReconcileTotal counts the reconciles by controller, result and resulting phase
+	ReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "reconcile_total",
+		Help:      "Number of reconciles by controller, result and resulting phase",
+	}, []string{"controller", "result", "phase"})
+
+	// ResourcePhase is set to 1 for the current phase of every reconciled resource
+	ResourcePhase = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "resource_phase",
+		Help:      "Current phase of the noobaa resources (value is always 1)",
+	}, []string{"kind", "namespace", "name", "phase"})
+
+	// RPCDuration is the latency of rpc calls to noobaa-core by api and method
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Duration of rpc calls to noobaa-core by api and method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"api", "method"})
+
+	// RPCErrors counts the failed rpc calls to noobaa-core by api, method and error code
+	RPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "rpc_errors_total",
+		Help:      "Number of failed rpc calls to noobaa-core by api, method and error code",
+	}, []string{"api", "method", "code"})
+
+	// OBCOperations counts the object bucket claim provisioner operations by operation and result
+	OBCOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "obc_operations_total",
+		Help:      "Number of object bucket claim provisioner operations by operation and result",
+	}, []string{"operation", "result"})
+
+	// KubeRequests counts the kubernetes reads and writes of the operator by verb, kind and source,
+	// where the source tells if the request was sent to the api server or served by the informers cache
+	KubeRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "kube_requests_total",
+		Help:      "Number of kubernetes requests by verb, kind and source (api or cache)",
+	}, []string{"verb", "kind", "source"})
+
+	// resourcePhases keeps the last phase reported per resource
+	// in order to remove the previous phase series when the phase changes
+	resourcePhases     = map[string]string{}
+	resourcePhasesLock sync.Mutex
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ReconcileDuration,
+		ReconcileTotal,
+		ResourcePhase,
+		RPCDuration,
+		RPCErrors,
+		OBCOperations,
+		KubeRequests,
+	)
+}
+
+// InstrumentReconcile runs the reconcile function and records its duration, result and the resulting phase.
+// obj should point to the object loaded by the reconciler so that after the reconcile
+// it can be checked for existence (by UID) and its phase can be read with the phase function.
+func InstrumentReconcile(
+	controller string,
+	obj metav1.Object,
+	phase func() string,
+	reconcileFunc func() (reconcile.Result, error),
+) (reconcile.Result, error) {
+
+	start := time.Now()
+	res, err := reconcileFunc()
+	took := time.Since(start)
+
+	result := ReconcileResultSuccess
+	if err != nil {
+		result = ReconcileResultError
+	} else if res.Requeue || res.RequeueAfter > 0 {
+		result = ReconcileResultRequeue
+	}
+
+	currentPhase := ""
+	if obj.GetUID() != "" && obj.GetDeletionTimestamp() == nil {
+		currentPhase = phase()
+	}
+
+	ReconcileDuration.WithLabelValues(controller, result, currentPhase).Observe(took.Seconds())
+	ReconcileTotal.WithLabelValues(controller, result, currentPhase).Inc()
+	SetResourcePhase(controller, obj.GetNamespace(), obj.GetName(), currentPhase)
+
+	return res, err
+}
+
+// SetResourcePhase sets the current phase of a resource and removes the previous phase series.
+// An empty phase removes the resource series which is used when the resource is deleted.
+func SetResourcePhase(kind string, namespace string, name string, phase string) {
+	key := kind + "/" + namespace + "/" + name
+	resourcePhasesLock.Lock()
+	defer resourcePhasesLock.Unlock()
+	prev, hasPrev := resourcePhases[key]
+	if hasPrev && prev != phase {
+		ResourcePhase.DeleteLabelValues(kind, namespace, name, prev)
+	}
+	if phase == "" {
+		delete(resourcePhases, key)
+		return
+	}
+	resourcePhases[key] = phase
+	ResourcePhase.WithLabelValues(kind, namespace, name, phase).Set(1)
+}
+
+// ObserveRPC records the duration of an rpc call and counts it as an error by code if failed
+func ObserveRPC(api string, method string, took time.Duration, errCode string) {
+	RPCDuration.WithLabelValues(api, method).Observe(took.Seconds())
+	if errCode != "" {
+		RPCErrors.WithLabelValues(api, method, errCode).Inc()
+	}
+}
+
+// ObserveOBCOperation counts a provisioner operation by its outcome
+func ObserveOBCOperation(operation string, err error) {
+	result := ReconcileResultSuccess
+	if err != nil {
+		result = ReconcileResultError
+	}
+	OBCOperations.WithLabelValues(operation, result).Inc()
+}
+
+// ObserveKubeRequest counts a kubernetes request by verb, kind and source
+func ObserveKubeRequest(verb string, kind string, source string) {
+	KubeRequests.WithLabelValues(verb, kind, source).Inc()
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSetResourcePhaseReplacesPrevious(t *testing.T) {
+	SetResourcePhase("BackingStore", "test-ns", "bs1", "Creating")
+	SetResourcePhase("BackingStore", "test-ns", "bs1", "Ready")
+	if n := testutil.CollectAndCount(ResourcePhase); n != 1 {
+		t.Fatalf("expected 1 phase series, got %d", n)
+	}
+	if v := testutil.ToFloat64(ResourcePhase.WithLabelValues("BackingStore", "test-ns", "bs1", "Ready")); v != 1 {
+		t.Fatalf("expected Ready phase to be 1, got %v", v)
+	}
+	SetResourcePhase("BackingStore", "test-ns", "bs1", "")
+	if n := testutil.CollectAndCount(ResourcePhase); n != 0 {
+		t.Fatalf("expected no phase series after delete, got %d", n)
+	}
+}
+
+func TestSystemSnapshotReplacesPrevious(t *testing.T) {
+	s := NewSystemSnapshot("test-ns")
+	s.AddBucket("b1", "bc", "app-ns", "OPTIMAL", 100, 50, 3, 0)
+	s.AddBucket("b2", "", "", "OPTIMAL", 200, 100, 5, 1024)
+	s.AddBucketBackingStore("b2", "tier1", "bs1")
+	s.AddBucketBackingStore("b2", "tier1", "bs2")
+	SetSystemSnapshot(s)
+	// 3 samples + mode for b1, 4 samples + mode for b2 and a placement series per backing store of b2
+	if n := testutil.CollectAndCount(systemCollector); n != 11 {
+		t.Fatalf("expected 11 samples, got %d", n)
+	}
+
+	s = NewSystemSnapshot("test-ns")
+	s.AddBackingStore("bs1", "CLOUD", "OPTIMAL", 1000, 900, 100, 0)
+	SetSystemSnapshot(s)
+	if n := testutil.CollectAndCount(systemCollector); n != 5 {
+		t.Fatalf("expected 5 samples after replacing the snapshot, got %d", n)
+	}
+
+	DeleteSystemSnapshot("test-ns")
+	if n := testutil.CollectAndCount(systemCollector); n != 0 {
+		t.Fatalf("expected no samples after delete, got %d", n)
+	}
+}
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	bucketLabels       = []string{"namespace", "bucket", "bucketclass", "obc_namespace"}
+	backingStoreLabels = []string{"namespace", "backingstore", "type"}
+
+	bucketDataSizeDesc = prometheus.NewDesc(Namespace+"_bucket_data_size_bytes",
+		"Logical size of the data stored in the bucket", bucketLabels, nil)
+	bucketDataSizeReducedDesc = prometheus.NewDesc(Namespace+"_bucket_data_size_reduced_bytes",
+		"Size of the data stored in the bucket after dedup and compression", bucketLabels, nil)
+	bucketObjectsDesc = prometheus.NewDesc(Namespace+"_bucket_objects",
+		"Number of objects in the bucket", bucketLabels, nil)
+	bucketQuotaDesc = prometheus.NewDesc(Namespace+"_bucket_quota_bytes",
+		"Quota configured on the bucket (only for buckets with quota)", bucketLabels, nil)
+	bucketModeDesc = prometheus.NewDesc(Namespace+"_bucket_mode",
+		"Current mode of the bucket (value is always 1)", append(bucketLabels, "mode"), nil)
+	// the placement is a separate series per backing store instead of a label of the bucket gauges,
+	// since core reports the bucket size as a whole and not per backing store
+	bucketBackingStoreDesc = prometheus.NewDesc(Namespace+"_bucket_backingstore",
+		"Backing store of a tier of the bucket (value is always 1)",
+		[]string{"namespace", "bucket", "tier", "backingstore"}, nil)
+
+	backingStoreTotalDesc = prometheus.NewDesc(Namespace+"_backingstore_total_bytes",
+		"Total capacity of the backing store", backingStoreLabels, nil)
+	backingStoreFreeDesc = prometheus.NewDesc(Namespace+"_backingstore_free_bytes",
+		"Free capacity of the backing store", backingStoreLabels, nil)
+	backingStoreUsedDesc = prometheus.NewDesc(Namespace+"_backingstore_used_bytes",
+		"Capacity of the backing store used by noobaa data", backingStoreLabels, nil)
+	backingStoreUsedOtherDesc = prometheus.NewDesc(Namespace+"_backingstore_used_other_bytes",
+		"Capacity of the backing store used by data not managed by noobaa", backingStoreLabels, nil)
+	backingStoreModeDesc = prometheus.NewDesc(Namespace+"_backingstore_mode",
+		"Current mode of the backing store (value is always 1)", append(backingStoreLabels, "mode"), nil)
+
+	systemDescs = []*prometheus.Desc{
+		bucketDataSizeDesc,
+		bucketDataSizeReducedDesc,
+		bucketObjectsDesc,
+		bucketQuotaDesc,
+		bucketModeDesc,
+		bucketBackingStoreDesc,
+		backingStoreTotalDesc,
+		backingStoreFreeDesc,
+		backingStoreUsedDesc,
+		backingStoreUsedOtherDesc,
+		backingStoreModeDesc,
+	}
+
+	// systemCollector holds the last snapshot reported by every system
+	systemCollector = &SystemCollector{Snapshots: map[string]*SystemSnapshot{}}
+)
+
+func init() {
+	metrics.Registry.MustRegister(systemCollector)
+}
+
+// SystemCollector is a prometheus collector that exports the object level state
+// of noobaa systems (buckets and backing stores) from the last read of the system info.
+// Keeping snapshots instead of gauges makes deleted buckets disappear from the metrics
+// as soon as a new snapshot of their system is set.
+type SystemCollector struct {
+	Snapshots map[string]*SystemSnapshot
+	Lock      sync.Mutex
+}
+
+// SystemSnapshot is the set of object level samples of a single system
+type SystemSnapshot struct {
+	Namespace string
+	Samples   []prometheus.Metric
+}
+
+// NewSystemSnapshot returns an empty snapshot for the system in the namespace
+func NewSystemSnapshot(namespace string) *SystemSnapshot {
+	return &SystemSnapshot{Namespace: namespace}
+}
+
+// AddBucket adds the samples of a single bucket
+// quota is only reported when positive since most buckets have no quota.
+func (s *SystemSnapshot) AddBucket(
+	bucket string, bucketClass string, obcNamespace string, mode string,
+	size float64, sizeReduced float64, objects float64, quota float64,
+) {
+	labels := []string{s.Namespace, bucket, bucketClass, obcNamespace}
+	s.add(bucketDataSizeDesc, size, labels...)
+	s.add(bucketDataSizeReducedDesc, sizeReduced, labels...)
+	s.add(bucketObjectsDesc, objects, labels...)
+	if quota > 0 {
+		s.add(bucketQuotaDesc, quota, labels...)
+	}
+	if mode != "" {
+		s.add(bucketModeDesc, 1, append(labels, mode)...)
+	}
+}
+
+// AddBucketBackingStore adds the placement of the bucket on a backing store of one of its tiers
+func (s *SystemSnapshot) AddBucketBackingStore(bucket string, tier string, backingStore string) {
+	s.add(bucketBackingStoreDesc, 1, s.Namespace, bucket, tier, backingStore)
+}
+
+// AddBackingStore adds the samples of a single backing store
+func (s *SystemSnapshot) AddBackingStore(
+	backingStore string, storeType string, mode string,
+	total float64, free float64, used float64, usedOther float64,
+) {
+	labels := []string{s.Namespace, backingStore, storeType}
+	s.add(backingStoreTotalDesc, total, labels...)
+	s.add(backingStoreFreeDesc, free, labels...)
+	s.add(backingStoreUsedDesc, used, labels...)
+	s.add(backingStoreUsedOtherDesc, usedOther, labels...)
+	if mode != "" {
+		s.add(backingStoreModeDesc, 1, append(labels, mode)...)
+	}
+}
+
+func (s *SystemSnapshot) add(desc *prometheus.Desc, value float64, labels ...string) {
+	s.Samples = append(s.Samples, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...))
+}
+
+// SetSystemSnapshot replaces the exported snapshot of the system in the snapshot namespace
+func SetSystemSnapshot(s *SystemSnapshot) {
+	systemCollector.Lock.Lock()
+	defer systemCollector.Lock.Unlock()
+	systemCollector.Snapshots[s.Namespace] = s
+}
+
+// DeleteSystemSnapshot stops exporting the samples of the system in the namespace
+func DeleteSystemSnapshot(namespace string) {
+	systemCollector.Lock.Lock()
+	defer systemCollector.Lock.Unlock()
+	delete(systemCollector.Snapshots, namespace)
+}
+
+// Describe implements prometheus.Collector
+func (c *SystemCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range systemDescs {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *SystemCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	for _, s := range c.Snapshots {
+		for _, m := range s.Samples {
+			ch <- m
+		}
+	}
+}
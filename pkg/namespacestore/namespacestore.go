@@ -82,6 +82,10 @@ func CmdCreateAWSS3() *cobra.Command {
 		"region", "",
 		"The AWS bucket region",
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -116,6 +120,10 @@ func CmdCreateS3Compatible() *cobra.Command {
 		"signature-version", "v4",
 		"The S3 signature version v4|v2",
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -146,6 +154,10 @@ func CmdCreateIBMCos() *cobra.Command {
 		"endpoint", "",
 		"The target IBM Cos endpoint",
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -172,6 +184,10 @@ func CmdCreateAzureBlob() *cobra.Command {
 		"secret-name", "",
 		`The name of a secret for authentication - should have AccountName and AccountKey properties`,
 	)
+	cmd.Flags().Bool("dry-run", false,
+		"Only check the connection and the target bucket with the provided credentials, without creating anything")
+	cmd.Flags().Bool("allow-unreachable", false,
+		"Let the dry run pass when the target bucket cannot be checked from this host")
 	return cmd
 }
 
@@ -210,6 +226,7 @@ func CmdStatus() *cobra.Command {
 		Short: "Status namespace store",
 		Run:   RunStatus,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -220,6 +237,7 @@ func CmdList() *cobra.Command {
 		Short: "List namespace stores",
 		Run:   RunList,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -272,6 +290,13 @@ func createCommon(cmd *cobra.Command, args []string, storeType nbv1.NSType, popu
 
 	populate(namespaceStore, secret)
 
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		allowUnreachable, _ := cmd.Flags().GetBool("allow-unreachable")
+		dryRunCreate(namespaceStore, secret, secretName, allowUnreachable)
+		return
+	}
+
 	// Create namespace store CR
 	util.Panic(controllerutil.SetControllerReference(sys, namespaceStore, scheme.Scheme))
 	if !util.KubeCreateSkipExisting(namespaceStore) {
@@ -297,6 +322,34 @@ func createCommon(cmd *cobra.Command, args []string, storeType nbv1.NSType, popu
 	}
 }
 
+// dryRunCreate checks the connection and target bucket of a namespace store without creating it
+func dryRunCreate(namespaceStore *nbv1.NamespaceStore, secret *corev1.Secret, secretName string, allowUnreachable bool) {
+	log := util.Logger()
+	if GetNamespaceStoreSecret(namespaceStore) == nil {
+		log.Fatalf(`❌ Dry run is not supported for NamespaceStore type %q`, namespaceStore.Spec.Type)
+	}
+	if secretName != "" && !util.KubeCheck(secret) {
+		log.Fatalf(`❌ Could not get Secret %q in namespace %q`, secret.Name, secret.Namespace)
+	}
+	r := &Reconciler{NamespaceStore: namespaceStore, Secret: secret}
+	conn, err := r.MakeExternalConnectionParams()
+	if err != nil {
+		log.Fatalf(`❌ Invalid NamespaceStore %q: %s`, namespaceStore.Name, err)
+	}
+	// namespace store targets may be read-only, so the dry run does not write to them
+	switch system.RunConnectionDryRun(conn, GetNamespaceStoreTargetBucket(namespaceStore), false) {
+	case system.DryRunFailed:
+		log.Fatalf(`❌ Dry run failed, NamespaceStore %q was not created`, namespaceStore.Name)
+	case system.DryRunNotVerified:
+		if !allowUnreachable {
+			log.Fatalf(`❌ Dry run could not verify the target bucket from this host, NamespaceStore %q was not created (run again with --allow-unreachable to accept it)`, namespaceStore.Name)
+		}
+		log.Printf(`⚠️  Dry run passed without verifying the target bucket, NamespaceStore %q was not created (run again without --dry-run to create it)`, namespaceStore.Name)
+		return
+	}
+	log.Printf(`✅ Dry run passed, NamespaceStore %q was not created (run again without --dry-run to create it)`, namespaceStore.Name)
+}
+
 // RunCreateAWSS3 runs a CLI command
 func RunCreateAWSS3(cmd *cobra.Command, args []string) {
 	createCommon(cmd, args, nbv1.NSStoreTypeAWSS3, func(namespaceStore *nbv1.NamespaceStore, secret *corev1.Secret) {
@@ -474,6 +527,7 @@ func RunDelete(cmd *cobra.Command, args []string) {
 // RunStatus runs a CLI command
 func RunStatus(cmd *cobra.Command, args []string) {
 	log := util.Logger()
+	out := util.GetOutput(cmd)
 
 	if len(args) != 1 || args[0] == "" {
 		log.Fatalf(`❌ Missing expected arguments: <namespace-store-name> %s`, cmd.UsageString())
@@ -492,6 +546,10 @@ func RunStatus(cmd *cobra.Command, args []string) {
 			namespaceStore.Name, namespaceStore.Namespace)
 	}
 
+	if out.Print(namespaceStore) {
+		return
+	}
+
 	secretRef := GetNamespaceStoreSecret(namespaceStore)
 	if secretRef != nil {
 		secret.Name = secretRef.Name
@@ -583,32 +641,44 @@ func CheckPhase(namespaceStore *nbv1.NamespaceStore) {
 
 // RunList runs a CLI command
 func RunList(cmd *cobra.Command, args []string) {
+	out := util.GetOutput(cmd)
 	list := &nbv1.NamespaceStoreList{
 		TypeMeta: metav1.TypeMeta{Kind: "NamespaceStoreList"},
 	}
 	if !util.KubeList(list, &client.ListOptions{Namespace: options.Namespace}) {
 		return
 	}
+	if out.Print(list) {
+		return
+	}
 	if len(list.Items) == 0 {
 		fmt.Printf("No namespace stores found.\n")
 		return
 	}
-	table := (&util.PrintTable{}).AddRow(
+	headers := []string{
 		"NAME",
 		"TYPE",
 		"TARGET-BUCKET",
 		"PHASE",
 		"AGE",
-	)
+	}
+	if out.IsWide() {
+		headers = append(headers, "MODE", "MODE-TIME")
+	}
+	table := (&util.PrintTable{}).AddRow(headers...)
 	for i := range list.Items {
 		bs := &list.Items[i]
-		table.AddRow(
+		row := []string{
 			bs.Name,
 			string(bs.Spec.Type),
 			GetNamespaceStoreTargetBucket(bs),
 			string(bs.Status.Phase),
 			time.Since(bs.CreationTimestamp.Time).Round(time.Second).String(),
-		)
+		}
+		if out.IsWide() {
+			row = append(row, bs.Status.Mode.ModeCode, bs.Status.Mode.TimeStamp)
+		}
+		table.AddRow(row...)
 	}
 	fmt.Print(table.String())
 }
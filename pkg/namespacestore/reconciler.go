@@ -37,6 +37,12 @@ func init() {
 	nsrModeInfoMap = modeInfoMap()
 }
 
+// GetModeInfo returns the phase and severity of a namespace store mode reported by noobaa-core
+func GetModeInfo(mode string) (ModeInfo, bool) {
+	info, exist := nsrModeInfoMap[mode]
+	return info, exist
+}
+
 func modeInfoMap() map[string]ModeInfo {
 	return map[string]ModeInfo{
 		"OPTIMAL":           {nbv1.NamespaceStorePhaseReady, corev1.EventTypeNormal},
@@ -127,6 +133,7 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 
 	if r.NamespaceStore.UID == "" {
 		log.Infof("NamespaceStore %q not found or deleted. Skip reconcile.", r.NamespaceStore.Name)
+		util.GlobalBackoff.Reset(r.NamespaceStore)
 		return reconcile.Result{}, nil
 	}
 
@@ -134,12 +141,14 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		if !util.KubeUpdate(r.NamespaceStore) {
 			log.Errorf("❌ NamespaceStore %q failed to add mandatory meta fields", r.NamespaceStore.Name)
 
-			res.RequeueAfter = 3 * time.Second
+			res.RequeueAfter = util.GlobalBackoff.TemporaryError(r.NamespaceStore, r.DependenciesVersion(),
+				fmt.Errorf("failed to add mandatory meta fields"), nil, nil)
 			return res, nil
 		}
 	}
 	system.CheckSystem(r.NooBaa)
 	var err error
+	backoffCounted := false
 
 	if err == nil {
 		err = r.LoadNamespaceStoreSecret()
@@ -157,16 +166,20 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		if perr, isPERR := err.(*util.PersistentError); isPERR {
 			r.SetPhase(nbv1.NamespaceStorePhaseRejected, perr.Reason, perr.Message)
 			log.Errorf("❌ Persistent Error: %s", err)
+			util.GlobalBackoff.Reset(r.NamespaceStore)
 			if r.Recorder != nil {
 				r.Recorder.Eventf(r.NamespaceStore, corev1.EventTypeWarning, perr.Reason, perr.Message)
 			}
 		} else {
-			res.RequeueAfter = 3 * time.Second
 			// leave current phase as is
 			r.SetPhase("", "TemporaryError", err.Error())
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter, util.GlobalBackoff.TemporaryError(
+				r.NamespaceStore, r.DependenciesVersion(), err, &r.NamespaceStore.Status.Conditions, r.Recorder))
+			backoffCounted = true
 			log.Warnf("⏳ Temporary Error: %s", err)
 		}
 	} else {
+		util.GlobalBackoff.Reset(r.NamespaceStore)
 		mode := r.NamespaceStore.Status.Mode.ModeCode
 		phaseInfo, exist := nsrModeInfoMap[mode]
 
@@ -192,8 +205,12 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 	// if updateStatus will fail to update the CR for any reason we will continue to requeue the reconcile
 	// until the spec status will reflect the actual status of the namespacestore
 	if err != nil {
-		res.RequeueAfter = 3 * time.Second
 		log.Warnf("⏳ Temporary Error: %s", err)
+		// a temporary error of the phases was already counted by the backoff of this reconcile
+		if !backoffCounted {
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter,
+				util.GlobalBackoff.TemporaryError(r.NamespaceStore, r.DependenciesVersion(), err, nil, nil))
+		}
 	}
 	return res, nil
 }
@@ -251,6 +268,11 @@ func (r *Reconciler) UpdateStatus() error {
 	return nil
 }
 
+// DependenciesVersion returns the version of the system and secret of the namespace store for the reconcile backoff
+func (r *Reconciler) DependenciesVersion() string {
+	return util.DependenciesVersion(r.NooBaa, r.Secret)
+}
+
 // ReconcilePhaseVerifying checks that we have the system and secret needed to reconcile
 func (r *Reconciler) ReconcilePhaseVerifying() error {
 
@@ -411,7 +433,7 @@ func (r *Reconciler) FinalizeDeletion() error {
 // and prepares the structures to reconcile
 func (r *Reconciler) ReadSystemInfo() error {
 
-	sysClient, err := system.Connect(false)
+	sysClient, err := system.ConnectNamespace(r.Request.Namespace, false)
 	if err != nil {
 		logrus.Infof("ReadSystemInfo1 err1 %+v", err)
 		return err
@@ -446,7 +468,7 @@ func (r *Reconciler) ReadSystemInfo() error {
 			},
 			NamespaceStore: &nb.NamespaceStoreInfo{
 				Name:      r.NamespaceStore.Name,
-				Namespace: options.Namespace,
+				Namespace: r.Request.Namespace,
 			},
 		}
 		return nil
@@ -490,7 +512,7 @@ func (r *Reconciler) ReadSystemInfo() error {
 		TargetBucket: GetNamespaceStoreTargetBucket(r.NamespaceStore),
 		NamespaceStore: &nb.NamespaceStoreInfo{
 			Name:      r.NamespaceStore.Name,
-			Namespace: options.Namespace,
+			Namespace: r.Request.Namespace,
 		},
 	}
 
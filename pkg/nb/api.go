@@ -2,7 +2,11 @@
 package nb
 
 // Client is the interface providing typed noobaa API calls
+// GeneratedClient is embedded with the api calls generated from the noobaa-core api schemas
+// see pkg/apigen and pkg/nb/schema
 type Client interface {
+	GeneratedClient
+
 	Call(req *RPCMessage, res RPCResponse) error
 
 	SetAuthToken(token string)
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	util "github.com/noobaa/noobaa-operator/v2/pkg/util"
 	"github.com/sirupsen/logrus"
 )
@@ -20,6 +21,17 @@ const (
 
 	// RPCSendTimeout is a limit the time we wait for getting reply from the server
 	RPCSendTimeout = 120 * time.Second;
+
+	// RPCCodeDisconnected is the error code returned to pending requests when the connection is closed.
+	// such errors are retryable since the request can be sent again on a new connection.
+	RPCCodeDisconnected = "DISCONNECTED"
+
+	// DefaultRPCPingInterval is the default interval between keepalive pings sent on websocket connections
+	DefaultRPCPingInterval = 10 * time.Second
+
+	// DefaultRPCMaxMissedPings is the default number of consecutive pings without a pong
+	// after which a websocket connection is considered dead and closed
+	DefaultRPCMaxMissedPings = 3
 )
 
 // GlobalRPC is the global rpc
@@ -32,10 +44,17 @@ func init() {
 
 // RPC is a struct that describes the relevant fields upon handeling rpc protocol
 type RPC struct {
-	HTTPClient  http.Client
-	ConnMap     map[string]RPCConn
-	ConnMapLock sync.Mutex
-	Handler     RPCHandler
+	HTTPClient     http.Client
+	ConnMap        map[string]RPCConn
+	ConnMapLock    sync.Mutex
+	Handler        RPCHandler
+	ConnectHandler RPCConnectHandler
+	Reconnects     map[string]int
+
+	// PingInterval is the interval between keepalive pings on new websocket connections, 0 disables the pings
+	PingInterval time.Duration
+	// MaxMissedPings is the number of consecutive unanswered pings after which a websocket connection is closed
+	MaxMissedPings int
 }
 
 // RPCClient makes API calls to noobaa.
@@ -68,6 +87,7 @@ type RPCMessage struct {
 	Params    interface{} `json:"params,omitempty"`
 	Buffers   []RPCBuffer `json:"buffers,omitempty"`
 	RawBytes  []byte      `json:"-"`
+	Address   string      `json:"-"`
 }
 
 // RPCMessageReply structure encoded in every RPC message that contains reply
@@ -85,13 +105,18 @@ type RPCBuffer struct {
 
 // RPCError is a struct sent by noobaa servers to denote an error response.
 type RPCError struct {
-	RPCCode string `json:"rpc_code,omitempty"`
-	Message string `json:"message"`
+	RPCCode   string `json:"rpc_code,omitempty"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable,omitempty"`
 }
 
 // RPCHandler is the interface for RPCHandler struct
 type RPCHandler func(req *RPCMessage) (interface{}, error)
 
+// RPCConnectHandler is called with the address of a websocket connection every time it connects,
+// including reconnects, where server side state of the connection (like registrations) was lost
+type RPCConnectHandler func(address string)
+
 // RPCResponse is the interface for response structs.
 // RPCMessage is the only real implementor of it.
 type RPCResponse interface {
@@ -107,6 +132,12 @@ func (c *RPCClient) GetAuthToken() string { return c.AuthToken }
 // Error is implementing the standard error type interface
 func (e *RPCError) Error() string { return e.Message }
 
+// IsRetryableError returns true if the error indicates that the call can be retried
+func IsRetryableError(err error) bool {
+	rpcErr, isRPCErr := err.(*RPCError)
+	return isRPCErr && rpcErr.Retryable
+}
+
 // Response is implementing the RPCResponse interface
 func (msg *RPCMessage) Response() *RPCMessage { return msg }
 
@@ -131,8 +162,11 @@ func NewRPC() *RPC {
 		HTTPClient: http.Client{
 			Transport: util.InsecureHTTPTransport,
 		},
-		ConnMap:     make(map[string]RPCConn),
-		ConnMapLock: sync.Mutex{},
+		ConnMap:        make(map[string]RPCConn),
+		ConnMapLock:    sync.Mutex{},
+		Reconnects:     make(map[string]int),
+		PingInterval:   DefaultRPCPingInterval,
+		MaxMissedPings: DefaultRPCMaxMissedPings,
 	}
 }
 
@@ -163,19 +197,35 @@ func (c *RPCClient) Call(req *RPCMessage, res RPCResponse) error {
 	u := strings.TrimSuffix(api, "_api") + "." + method + "()"
 	logrus.Infof("✈️  RPC: %s Request: %+v", u, req.Params)
 
+	start := time.Now()
 	conn := c.RPC.GetConnection(address)
 	err := conn.Call(req, res)
+	if IsRetryableError(err) {
+		// the connection was closed while the request was pending,
+		// so send it once more on the new connection to the address
+		logrus.Warnf("⚠️  RPC: %s Call retrying on a new connection: %s", u, err)
+		conn = c.RPC.GetConnection(address)
+		err = conn.Call(req, res)
+	}
 	if err != nil {
+		errCode := metrics.RPCCodeConnError
+		if rpcErr, isRPCErr := err.(*RPCError); isRPCErr {
+			errCode = rpcErr.RPCCode
+		}
+		metrics.ObserveRPC(api, method, time.Since(start), errCode)
 		logrus.Errorf("⚠️  RPC: %s Call failed: %s", u, err)
 		return err
 	}
 
 	r := res.Response()
 	if r.Error != nil {
+		metrics.ObserveRPC(api, method, time.Since(start), r.Error.RPCCode)
 		logrus.Errorf("⚠️  RPC: %s Response Error: Code=%s Message=%s", u, r.Error.RPCCode, r.Error.Message)
 		return r.Error
 	}
 
+	metrics.ObserveRPC(api, method, time.Since(start), "")
+
 	logrus.Infof("✅ RPC: %s Response OK: took %.1fms", u, r.Took)
 	return nil
 }
@@ -209,9 +259,35 @@ func (r *RPC) RemoveConnection(conn RPCConn) {
 		delete(r.ConnMap, address)
 	}
 	if current == conn || current == nil {
+		r.Reconnects[address]++
 		go func() {
 			r.GetConnection(address).Reconnect()
 		}()
 	}
 	r.ConnMapLock.Unlock()
 }
+
+// GetConnStats returns the stats of the current websocket connections
+// along with the number of reconnects made to each address
+func (r *RPC) GetConnStats() []RPCConnStats {
+	r.ConnMapLock.Lock()
+	conns := []*RPCConnWS{}
+	for _, conn := range r.ConnMap {
+		if ws, ok := conn.(*RPCConnWS); ok {
+			conns = append(conns, ws)
+		}
+	}
+	reconnects := map[string]int{}
+	for address, count := range r.Reconnects {
+		reconnects[address] = count
+	}
+	r.ConnMapLock.Unlock()
+
+	stats := []RPCConnStats{}
+	for _, ws := range conns {
+		s := ws.GetStats()
+		s.Reconnects = reconnects[s.Address]
+		stats = append(stats, s)
+	}
+	return stats
+}
@@ -25,6 +25,23 @@ type RPCConnWS struct {
 	NextRequestID   uint64
 	Lock            sync.Mutex
 	ReconnectDelay  time.Duration
+	PingInterval    time.Duration
+	MaxMissedPings  int
+	PendingPings    map[string]time.Time
+	MissedPings     int
+	LastRTT         time.Duration
+	LastPong        time.Time
+}
+
+// RPCConnStats is a snapshot of the connection state used for diagnostics
+type RPCConnStats struct {
+	Address         string        `json:"address"`
+	State           string        `json:"state"`
+	PendingRequests int           `json:"pendingRequests"`
+	MissedPings     int           `json:"missedPings"`
+	LastRTT         time.Duration `json:"lastRTT"`
+	LastPong        time.Time     `json:"lastPong,omitempty"`
+	Reconnects      int           `json:"reconnects"`
 }
 
 // RPCPendingRequest is a struct that describes the fields related to an rpc pending requests
@@ -43,6 +60,9 @@ func NewRPCConnWS(r *RPC, address string) *RPCConnWS {
 		State:           "init",
 		PendingRequests: map[string]*RPCPendingRequest{},
 		Lock:            sync.Mutex{},
+		PingInterval:    r.PingInterval,
+		MaxMissedPings:  r.MaxMissedPings,
+		PendingPings:    map[string]time.Time{},
 	}
 }
 
@@ -115,6 +135,10 @@ func (c *RPCConnWS) ConnectUnderLock() error {
 	c.WS = ws
 	c.State = "connected"
 	go c.ReadMessages()
+	go c.Heartbeat()
+	if c.RPC.ConnectHandler != nil {
+		go c.RPC.ConnectHandler(c.Address)
+	}
 
 	return nil
 }
@@ -145,10 +169,16 @@ func (c *RPCConnWS) CloseUnderLock() {
 		}
 	}
 
-	// wakeup pending waiters with error
+	// wakeup pending waiters with a retryable error
+	// the waiters are removed from the map so that a late response will not try to wake them again
 	for reqid := range c.PendingRequests {
 		pending := c.PendingRequests[reqid]
-		pending.ReplyChan <- fmt.Errorf("RPC: connection closed while request is pending %s %s", c.Address, reqid)
+		delete(c.PendingRequests, reqid)
+		pending.ReplyChan <- &RPCError{
+			RPCCode:   RPCCodeDisconnected,
+			Message:   fmt.Sprintf("RPC: connection closed while request is pending %s %s", c.Address, reqid),
+			Retryable: true,
+		}
 	}
 
 	// tell the RPC to remove this connection which will reconnect if desired
@@ -224,7 +254,7 @@ func (c *RPCConnWS) ReadMessages() {
 		case "ping":
 			c.HandlePing(msg)
 		case "pong":
-			logrus.Infof("RPC pong %#v", msg)
+			c.HandlePong(msg)
 		case "routing_req":
 			fallthrough
 		case "routing_res":
@@ -288,8 +318,10 @@ func (c *RPCConnWS) ReadMessage() (*RPCMessage, error) {
 	return msg, nil
 }
 
-// HandleRequest handles an incoming message of type request
+// HandleRequest handles an incoming message of type request.
+// The request address is set to the connection address so the handler knows which server sent it.
 func (c *RPCConnWS) HandleRequest(req *RPCMessage) {
+	req.Address = c.Address
 
 	if c.RPC.Handler == nil {
 		logrus.Errorf("RPC request but not handler %#v", req)
@@ -345,3 +377,75 @@ func (c *RPCConnWS) HandlePing(msg *RPCMessage) {
 	}
 
 }
+
+// HandlePong handles an incoming message of type pong
+// and measures the round trip time of the ping it answers
+func (c *RPCConnWS) HandlePong(msg *RPCMessage) {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	sent, ok := c.PendingPings[msg.RequestID]
+	if !ok {
+		logrus.Warnf("RPC: got pong for unknown ping %s %s", c.Address, msg.RequestID)
+		return
+	}
+	c.PendingPings = map[string]time.Time{}
+	c.MissedPings = 0
+	c.LastPong = time.Now()
+	c.LastRTT = c.LastPong.Sub(sent)
+}
+
+// Heartbeat sends periodic pings on the connection while it is connected
+// and closes it after MaxMissedPings consecutive pings went unanswered.
+// Closing the connection fails the pending requests and triggers a reconnect
+// which is the only way to detect a half-open tcp connection in a timely manner.
+func (c *RPCConnWS) Heartbeat() {
+	if c.PingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.PingInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.Lock.Lock()
+		if c.State != "connected" {
+			c.Lock.Unlock()
+			return
+		}
+		if len(c.PendingPings) > 0 {
+			c.MissedPings++
+		}
+		if c.MaxMissedPings > 0 && c.MissedPings >= c.MaxMissedPings {
+			logrus.Errorf("RPC: connection (%p) %s missed %d pings, closing", c, c.Address, c.MissedPings)
+			c.CloseUnderLock()
+			c.Lock.Unlock()
+			return
+		}
+		ping := &RPCMessage{
+			Op:        "ping",
+			RequestID: fmt.Sprintf("%s-ping-%d", c.Address, c.NextRequestID),
+		}
+		c.NextRequestID++
+		c.PendingPings[ping.RequestID] = time.Now()
+		c.Lock.Unlock()
+
+		err := c.SendMessage(ping)
+		if err != nil {
+			logrus.Errorf("RPC: got error sending ping (%p) %s: %v", c, c.Address, err)
+			c.Close()
+			return
+		}
+	}
+}
+
+// GetStats returns a snapshot of the connection stats
+func (c *RPCConnWS) GetStats() RPCConnStats {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	return RPCConnStats{
+		Address:         c.Address,
+		State:           c.State,
+		PendingRequests: len(c.PendingRequests),
+		MissedPings:     c.MissedPings,
+		LastRTT:         c.LastRTT,
+		LastPong:        c.LastPong,
+	}
+}
@@ -0,0 +1,169 @@
+package nb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// newTestWSServer starts a websocket rpc server that passes every incoming message to onMessage
+func newTestWSServer(t *testing.T, onMessage func(conn *RPCConnWS, msg *RPCMessage)) (*httptest.Server, string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := websocket.Accept(w, req, nil)
+		if err != nil {
+			t.Errorf("websocket accept: %s", err)
+			return
+		}
+		// the server side reuses the message framing of the client
+		conn := &RPCConnWS{WS: ws}
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			onMessage(conn, msg)
+		}
+	}))
+	return srv, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func newTestRPC(pingInterval time.Duration, maxMissedPings int) *RPC {
+	r := NewRPC()
+	r.PingInterval = pingInterval
+	r.MaxMissedPings = maxMissedPings
+	return r
+}
+
+func waitFor(t *testing.T, timeout time.Duration, desc string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", desc)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRPCHeartbeatPong(t *testing.T) {
+	srv, address := newTestWSServer(t, func(conn *RPCConnWS, msg *RPCMessage) {
+		if msg.Op == "ping" {
+			if err := conn.SendMessage(&RPCMessage{Op: "pong", RequestID: msg.RequestID}); err != nil {
+				t.Errorf("send pong: %s", err)
+			}
+		}
+	})
+	defer srv.Close()
+
+	r := newTestRPC(20*time.Millisecond, 3)
+	conn := r.GetConnection(address).(*RPCConnWS)
+	defer conn.Close()
+	conn.Lock.Lock()
+	err := conn.ConnectUnderLock()
+	conn.Lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, 2*time.Second, "a pong", func() bool { return !conn.GetStats().LastPong.IsZero() })
+	// answered pings keep the connection alive past the missed pings limit
+	time.Sleep(100 * time.Millisecond)
+	stats := conn.GetStats()
+	if stats.State != "connected" || stats.MissedPings != 0 || stats.LastRTT <= 0 {
+		t.Fatalf("expected a live connection with a measured rtt, got %+v", stats)
+	}
+}
+
+func TestRPCHeartbeatDeadConnection(t *testing.T) {
+	// the server reads the messages but never answers, like a half-open connection
+	srv, address := newTestWSServer(t, func(conn *RPCConnWS, msg *RPCMessage) {})
+	defer srv.Close()
+
+	r := newTestRPC(20*time.Millisecond, 3)
+	conn := r.GetConnection(address).(*RPCConnWS)
+	callErr := make(chan error, 1)
+	go func() {
+		callErr <- conn.Call(&RPCMessage{Op: "req", API: "system_api", Method: "read_system"}, &RPCMessage{})
+	}()
+
+	select {
+	case err := <-callErr:
+		rpcErr, ok := err.(*RPCError)
+		if !ok || rpcErr.RPCCode != RPCCodeDisconnected || !IsRetryableError(err) {
+			t.Fatalf("expected a retryable disconnected error, got %#v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the pending call to fail after %d missed pings", r.MaxMissedPings)
+	}
+
+	stats := conn.GetStats()
+	if stats.State != "closed" || stats.MissedPings < r.MaxMissedPings {
+		t.Fatalf("expected the connection to be closed after %d missed pings, got %+v", r.MaxMissedPings, stats)
+	}
+	r.ConnMapLock.Lock()
+	reconnects := r.Reconnects[address]
+	r.ConnMapLock.Unlock()
+	if reconnects != 1 {
+		t.Fatalf("expected a reconnect to %s, got %d", address, reconnects)
+	}
+}
+
+func TestRPCCloseUnderLockFailsPendingRequests(t *testing.T) {
+	r := newTestRPC(0, 0)
+	conn := NewRPCConnWS(r, "ws://127.0.0.1:1")
+	replies := []chan error{}
+	conn.Lock.Lock()
+	for i := 0; i < 3; i++ {
+		replies = append(replies, conn.NewRequest(&RPCMessage{Op: "req"}, &RPCMessage{}))
+	}
+	conn.CloseUnderLock()
+	conn.Lock.Unlock()
+
+	for i, reply := range replies {
+		select {
+		case err := <-reply:
+			if !IsRetryableError(err) {
+				t.Errorf("request %d: expected a retryable error, got %#v", i, err)
+			}
+		default:
+			t.Errorf("request %d: expected to be failed by the close", i)
+		}
+	}
+	if len(conn.PendingRequests) != 0 {
+		t.Errorf("expected no pending requests after close, got %d", len(conn.PendingRequests))
+	}
+	// a late response does not wake the failed waiters again
+	conn.HandleResponse(&RPCMessage{Op: "res", RequestID: "ws://127.0.0.1:1-0"})
+}
+
+func TestRPCClientCallRetriesDisconnected(t *testing.T) {
+	requests := make(chan string, 10)
+	srv, address := newTestWSServer(t, func(conn *RPCConnWS, msg *RPCMessage) {
+		if msg.Op != "req" {
+			return
+		}
+		requests <- msg.RequestID
+		if len(requests) == 1 {
+			// drop the connection with the first request pending
+			conn.WS.Close(websocket.StatusGoingAway, "test")
+			return
+		}
+		if err := conn.SendMessage(&RPCMessage{Op: "res", RequestID: msg.RequestID}); err != nil {
+			t.Errorf("send response: %s", err)
+		}
+	})
+	defer srv.Close()
+
+	c := &RPCClient{RPC: newTestRPC(0, 0), Router: &SimpleRouter{Address: address}}
+	if err := c.Call(&RPCMessage{Op: "req", API: "system_api", Method: "read_system"}, nil); err != nil {
+		t.Fatalf("expected the call to succeed on the new connection, got %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected the request to be sent twice, got %d", len(requests))
+	}
+}
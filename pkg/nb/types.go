@@ -9,16 +9,8 @@ const (
 	petaInBytes = 1024 * 1024 * 1024 * 1024 * 1024
 )
 
-// SystemInfo is a struct of system info returned by the API
-type SystemInfo struct {
-	Accounts           []AccountInfo           `json:"accounts"`
-	Buckets            []BucketInfo            `json:"buckets"`
-	Pools              []PoolInfo              `json:"pools"`
-	Tiers              []TierInfo              `json:"tiers"`
-	Version            string                  `json:"version"`
-	NamespaceResources []NamespaceResourceInfo `json:"namespace_resources"`
-	// TODO SystemInfo struct is partial ...
-}
+// SystemInfo is the reply of system_api.read_system(), generated from its schema
+type SystemInfo = SystemAPISystemFullInfo
 
 // AccountInfo is a struct of account info returned by the API
 type AccountInfo struct {
@@ -49,41 +41,8 @@ type AccountInfo struct {
 	} `json:"preferences"`
 }
 
-// BucketInfo is a struct of bucket info returned by the API
-type BucketInfo struct {
-	Name        string `json:"name"`
-	BucketType  string `json:"bucket_type"`
-	Mode        string `json:"mode"`
-	Undeletable string `json:"undeletable"`
-
-	BucketClaim  *BucketClaimInfo   `json:"bucket_claim,omitempty"`
-	Tiering      *TieringPolicyInfo `json:"tiering,omitempty"`
-	DataCapacity *struct {
-		Size              *BigInt `json:"size,omitempty"`
-		SizeReduced       *BigInt `json:"size_reduced,omitempty"`
-		Free              *BigInt `json:"free,omitempty"`
-		AvailableToUpload *BigInt `json:"available_for_upload,omitempty"`
-		LastUpdate        int64   `json:"last_update"`
-	} `json:"data,omitempty"`
-	StorageCapacity *struct {
-		Values     *StorageInfo `json:"values,omitempty"`
-		LastUpdate int64        `json:"last_update"`
-	} `json:"storage,omitempty"`
-	NumObjects *struct {
-		Value      int64 `json:"value"`
-		LastUpdate int64 `json:"last_update"`
-	} `json:"num_objects,omitempty"`
-	Quota *struct {
-		Size int64  `json:"size"`
-		Unit string `json:"unit"`
-	} `json:"quota,omitempty"`
-	PolicyModes *struct {
-		ResiliencyStatus string `json:"resiliency_status"`
-		QuotaStatus      string `json:"quota_status"`
-	} `json:"policy_modes,omitempty"`
-	Namespace *NamespaceBucketInfo `json:"namespace,omitempty"`
-	// TODO BucketInfo struct is partial ...
-}
+// BucketInfo is the reply of bucket_api.read_bucket() and the buckets of SystemInfo, generated from its schema
+type BucketInfo = BucketAPIBucketInfo
 
 // TieringPolicyInfo is the information of a tiering policy
 type TieringPolicyInfo struct {
@@ -148,32 +107,8 @@ func (n *BigInt) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, (*bigint)(n))
 }
 
-// PoolInfo is a struct of pool info returned by the API
-type PoolInfo struct {
-	Name         string `json:"name"`
-	ResourceType string `json:"resource_type"`
-	Mode         string `json:"mode,omitempty"`
-	Region       string `json:"region,omitempty"`
-	PoolNodeType string `json:"pool_node_type,omitempty"`
-	Undeletable  string `json:"undeletable,omitempty"`
-	CloudInfo    *struct {
-		EndpointType EndpointType    `json:"endpoint_type,omitempty"`
-		Endpoint     string          `json:"endpoint,omitempty"`
-		TargetBucket string          `json:"target_bucket,omitempty"`
-		Identity     string          `json:"identity,omitempty"`
-		NodeName     string          `json:"node_name,omitempty"`
-		CreatedBy    string          `json:"created_by,omitempty"`
-		Host         string          `json:"host,omitempty"`
-		AuthMethod   CloudAuthMethod `json:"auth_method,omitempty"`
-	} `json:"cloud_info,omitempty"`
-	MongoInfo *map[string]interface{} `json:"mongo_info,omitempty"`
-	HostInfo  *PoolHostsInfo          `json:"host_info,omitempty"`
-	Hosts     *struct {
-		ConfiguredCount int64 `json:"configured_count"`
-		Count           int64 `json:"count"`
-	} `json:"hosts,omitempty"`
-	// TODO PoolInfo struct is partial ...
-}
+// PoolInfo is the reply of pool_api.read_pool() and the pools of SystemInfo, generated from its schema
+type PoolInfo = PoolAPIPoolExtendedInfo
 
 // NamespaceResourceInfo is a struct of namespace resource info returned by the API
 type NamespaceResourceInfo struct {
@@ -626,9 +561,20 @@ type UpdateEndpointGroupParams struct {
 
 // BigIntToHumanBytes returns a human readable bytes string
 func BigIntToHumanBytes(bi *BigInt) string {
+	if bi == nil {
+		return IntToHumanBytes(0)
+	}
 	return IntToHumanBytes(bi.N + (bi.Peta * petaInBytes))
 }
 
+// BigIntToFloat64 returns the BigInt value as float64 (losing precision above 2^53) and 0 for nil
+func BigIntToFloat64(bi *BigInt) float64 {
+	if bi == nil {
+		return 0
+	}
+	return float64(bi.N) + (float64(bi.Peta) * petaInBytes)
+}
+
 // IntToHumanBytes returns a human readable bytes string
 func IntToHumanBytes(bi int64) string {
 	units := []string{"", "K", "M", "G", "T", "P", "E", "Z", "Y"}
@@ -73,6 +73,7 @@ func CmdStatus() *cobra.Command {
 	}
 	cmd.Flags().String("app-namespace", "",
 		"Set the namespace of the application where the OBC should be created")
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -83,6 +84,7 @@ func CmdList() *cobra.Command {
 		Short: "List OBC's",
 		Run:   RunList,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -186,9 +188,22 @@ func RunDelete(cmd *cobra.Command, args []string) {
 	}
 }
 
+// StatusInfo is the structured output of the obc status command.
+// The credentials are not included and should be read from the OBC secret.
+type StatusInfo struct {
+	ObjectBucketClaim *nbv1.ObjectBucketClaim `json:"objectBucketClaim"`
+	ObjectBucket      *nbv1.ObjectBucket      `json:"objectBucket,omitempty"`
+	StorageClass      string                  `json:"storageClass,omitempty"`
+	BucketClass       string                  `json:"bucketClass,omitempty"`
+	Connection        map[string]string       `json:"connection,omitempty"`
+	S3Endpoint        string                  `json:"s3Endpoint,omitempty"`
+	Bucket            *nb.BucketInfo          `json:"bucket,omitempty"`
+}
+
 // RunStatus runs a CLI command
 func RunStatus(cmd *cobra.Command, args []string) {
 	log := util.Logger()
+	out := util.GetOutput(cmd)
 
 	if len(args) != 1 || args[0] == "" {
 		log.Fatalf(`Missing expected arguments: <bucket-claim-name> %s`, cmd.UsageString())
@@ -261,6 +276,19 @@ func RunStatus(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if out.IsStructured() {
+		out.Print(&StatusInfo{
+			ObjectBucketClaim: obc,
+			ObjectBucket:      ob,
+			StorageClass:      sc.Name,
+			BucketClass:       bucketClass.Name,
+			Connection:        cm.Data,
+			S3Endpoint:        sysClient.S3URL.String(),
+			Bucket:            b,
+		})
+		return
+	}
+
 	fmt.Printf("\n")
 	fmt.Printf("ObjectBucketClaim info:\n")
 	fmt.Printf("  %-22s : %s\n", "Phase", obc.Status.Phase)
@@ -303,10 +331,10 @@ func RunStatus(cmd *cobra.Command, args []string) {
 		if b.NumObjects != nil {
 			fmt.Printf("  %-22s : %d\n", "Num Objects", b.NumObjects.Value)
 		}
-		if b.DataCapacity != nil {
-			fmt.Printf("  %-22s : %s\n", "Data Size", nb.BigIntToHumanBytes(b.DataCapacity.Size))
-			fmt.Printf("  %-22s : %s\n", "Data Size Reduced", nb.BigIntToHumanBytes(b.DataCapacity.SizeReduced))
-			fmt.Printf("  %-22s : %s\n", "Data Space Avail", nb.BigIntToHumanBytes(b.DataCapacity.AvailableToUpload))
+		if b.Data != nil {
+			fmt.Printf("  %-22s : %s\n", "Data Size", nb.BigIntToHumanBytes(b.Data.Size))
+			fmt.Printf("  %-22s : %s\n", "Data Size Reduced", nb.BigIntToHumanBytes(b.Data.SizeReduced))
+			fmt.Printf("  %-22s : %s\n", "Data Space Avail", nb.BigIntToHumanBytes(b.Data.AvailableForUpload))
 		}
 		fmt.Printf("\n")
 	}
@@ -314,24 +342,32 @@ func RunStatus(cmd *cobra.Command, args []string) {
 
 // RunList runs a CLI command
 func RunList(cmd *cobra.Command, args []string) {
+	out := util.GetOutput(cmd)
 	list := &nbv1.ObjectBucketClaimList{
 		TypeMeta: metav1.TypeMeta{Kind: "ObjectBucketClaim"},
 	}
 	if !util.KubeList(list) {
 		return
 	}
+	if out.Print(list) {
+		return
+	}
 	if len(list.Items) == 0 {
 		fmt.Printf("No OBCs found.\n")
 		return
 	}
-	table := (&util.PrintTable{}).AddRow(
+	headers := []string{
 		"NAMESPACE",
 		"NAME",
 		"BUCKET-NAME",
 		"STORAGE-CLASS",
 		"BUCKET-CLASS",
 		"PHASE",
-	)
+	}
+	if out.IsWide() {
+		headers = append(headers, "OBJECT-BUCKET", "AGE")
+	}
+	table := (&util.PrintTable{}).AddRow(headers...)
 	scMap := map[string]*storagev1.StorageClass{}
 	for i := range list.Items {
 		obc := &list.Items[i]
@@ -347,14 +383,21 @@ func RunList(cmd *cobra.Command, args []string) {
 			}
 			bucketClass = sc.Parameters["bucketclass"]
 		}
-		table.AddRow(
+		row := []string{
 			obc.Namespace,
 			obc.Name,
 			obc.Spec.BucketName,
 			obc.Spec.StorageClassName,
 			bucketClass,
 			string(obc.Status.Phase),
-		)
+		}
+		if out.IsWide() {
+			row = append(row,
+				obc.Spec.ObjectBucketName,
+				time.Since(obc.CreationTimestamp.Time).Round(time.Second).String(),
+			)
+		}
+		table.AddRow(row...)
 	}
 	fmt.Print(table.String())
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
 	"github.com/noobaa/noobaa-operator/v2/pkg/system"
@@ -40,10 +41,20 @@ type Provisioner struct {
 	Namespace string
 }
 
-// RunProvisioner will run OBC provisioner
+// RunProvisioner will run OBC provisioner for every watched namespace
 func RunProvisioner(client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder) error {
+	for _, ns := range options.WatchedNamespaces() {
+		if err := RunProvisionerForNamespace(client, scheme, recorder, ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunProvisionerForNamespace will run the OBC provisioner of the system in the given namespace
+func RunProvisionerForNamespace(client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, ns string) error {
 
-	provisionerName := options.ObjectBucketProvisionerName()
+	provisionerName := options.ObjectBucketProvisionerNameFor(ns)
 	log := logrus.WithField("provisioner", provisionerName)
 	log.Info("OBC Provisioner - start..")
 
@@ -54,7 +65,7 @@ func RunProvisioner(client client.Client, scheme *runtime.Scheme, recorder recor
 		scheme:    scheme,
 		recorder:  recorder,
 		Logger:    log,
-		Namespace: options.Namespace,
+		Namespace: ns,
 	}
 
 	// Create and run the s3 provisioner controller.
@@ -68,7 +79,7 @@ func RunProvisioner(client client.Client, scheme *runtime.Scheme, recorder recor
 
 	errStrings := libProv.SetLabels(map[string]string{
 		"app":           "noobaa",
-		"noobaa-domain": options.SubDomainNS(),
+		"noobaa-domain": options.SubDomainNSFor(ns),
 	})
 	if errStrings != nil {
 		util.Panic(fmt.Errorf("SetLabels errors: %+v", errStrings))
@@ -84,7 +95,13 @@ func RunProvisioner(client client.Client, scheme *runtime.Scheme, recorder recor
 }
 
 // Provision implements lib-bucket-provisioner callback to create a new bucket
-func (p *Provisioner) Provision(bucketOptions *obAPI.BucketOptions) (*nbv1.ObjectBucket, error) {
+func (p *Provisioner) Provision(bucketOptions *obAPI.BucketOptions) (ob *nbv1.ObjectBucket, err error) {
+	defer func() {
+		metrics.ObserveOBCOperation("provision", err)
+		if err == nil {
+			util.GlobalBackoff.Reset(bucketOptions.ObjectBucketClaim)
+		}
+	}()
 
 	log := p.Logger
 	log.Infof("Provision: got request to provision bucket %q", bucketOptions.BucketName)
@@ -122,8 +139,26 @@ func (p *Provisioner) Provision(bucketOptions *obAPI.BucketOptions) (*nbv1.Objec
 	return r.OB, nil
 }
 
+// recordFailure records a warning event of a failed obc provisioning.
+// The provisioner library retries failed claims with its own backoff, so to avoid flooding the events
+// the warning is recorded on the first failure and again once the same failure repeats for the failure budget.
+// Requests without a claim, like a grant of an existing bucket, have no object to record on so the failure is only logged.
+func (p *Provisioner) recordFailure(obc *nbv1.ObjectBucketClaim, reason string, msg string) {
+	if obc == nil {
+		p.Logger.Warnf("%s: %s", reason, msg)
+		return
+	}
+	_, repeats := util.GlobalBackoff.Failure(obc, obc.Spec.StorageClassName, msg)
+	if repeats == 1 {
+		p.recorder.Event(obc, "Warning", reason, msg)
+	} else if repeats == util.GlobalBackoff.Budget {
+		p.recorder.Eventf(obc, "Warning", reason, "Failed %d times in a row: %s", repeats, msg)
+	}
+}
+
 // Grant implements lib-bucket-provisioner callback to use an existing bucket
-func (p *Provisioner) Grant(bucketOptions *obAPI.BucketOptions) (*nbv1.ObjectBucket, error) {
+func (p *Provisioner) Grant(bucketOptions *obAPI.BucketOptions) (ob *nbv1.ObjectBucket, err error) {
+	defer func() { metrics.ObserveOBCOperation("grant", err) }()
 
 	log := p.Logger
 	log.Infof("Grant: got request to grant access to bucket %q", bucketOptions.BucketName)
@@ -152,7 +187,8 @@ func (p *Provisioner) Grant(bucketOptions *obAPI.BucketOptions) (*nbv1.ObjectBuc
 }
 
 // Delete implements lib-bucket-provisioner callback to delete a bucket
-func (p *Provisioner) Delete(ob *nbv1.ObjectBucket) error {
+func (p *Provisioner) Delete(ob *nbv1.ObjectBucket) (err error) {
+	defer func() { metrics.ObserveOBCOperation("delete", err) }()
 
 	log := p.Logger
 
@@ -183,7 +219,8 @@ func (p *Provisioner) Delete(ob *nbv1.ObjectBucket) error {
 }
 
 // Revoke implements lib-bucket-provisioner callback to stop using an existing bucket
-func (p *Provisioner) Revoke(ob *nbv1.ObjectBucket) error {
+func (p *Provisioner) Revoke(ob *nbv1.ObjectBucket) (err error) {
+	defer func() { metrics.ObserveOBCOperation("revoke", err) }()
 
 	log := p.Logger
 
@@ -220,7 +257,7 @@ func NewBucketRequest(
 	bucketOptions *obAPI.BucketOptions,
 ) (*BucketRequest, error) {
 
-	sysClient, err := system.Connect(false)
+	sysClient, err := system.ConnectNamespace(p.Namespace, false)
 	if err != nil {
 		return nil, err
 	}
@@ -262,12 +299,12 @@ func NewBucketRequest(
 		}
 		if !util.KubeCheck(r.BucketClass) {
 			msg := fmt.Sprintf("BucketClass %q not found in provisioner namespace %q", bucketClassName, p.Namespace)
-			p.recorder.Event(r.OBC, "Warning", "MissingBucketClass", msg)
+			p.recordFailure(r.OBC, "MissingBucketClass", msg)
 			return nil, fmt.Errorf(msg)
 		}
 		if r.BucketClass.Status.Phase != nbv1.BucketClassPhaseReady {
 			msg := fmt.Sprintf("BucketClass %q is not ready", bucketClassName)
-			p.recorder.Event(r.OBC, "Warning", "BucketClassNotReady", msg)
+			p.recordFailure(r.OBC, "BucketClassNotReady", msg)
 			return nil, fmt.Errorf(msg)
 		}
 		r.OB = &nbv1.ObjectBucket{
@@ -303,7 +340,7 @@ func NewBucketRequest(
 		}
 		if !util.KubeCheck(r.BucketClass) {
 			msg := fmt.Sprintf("BucketClass %q not found in provisioner namespace %q", bucketClassName, p.Namespace)
-			p.recorder.Event(r.OBC, "Warning", "MissingBucketClass", msg)
+			p.recordFailure(r.OBC, "MissingBucketClass", msg)
 			return nil, fmt.Errorf(msg)
 		}
 	}
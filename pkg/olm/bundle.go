@@ -0,0 +1,182 @@
+package olm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/crd"
+	"github.com/noobaa/noobaa-operator/v2/pkg/operator"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+
+	"github.com/blang/semver"
+	operv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/spf13/cobra"
+	sigyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// PackageName is the OLM package of the operator
+	PackageName = "noobaa-operator"
+
+	bundleAnnotationsPrefix = "operators.operatorframework.io.bundle."
+	bundleManifestsDir      = "manifests/"
+	bundleMetadataDir       = "metadata/"
+)
+
+// CmdBundle returns a CLI command
+func CmdBundle() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle <dir>",
+		Short: "Create OLM bundle dir (manifests and metadata)",
+		Run:   RunBundle,
+	}
+	cmd.Flags().String("channels", "alpha", "Comma separated list of channels for the bundle")
+	cmd.Flags().String("default-channel", "", "The default channel of the package (defaults to the first of --channels)")
+	cmd.Flags().String("replaces", "", "The CSV name that this version replaces in the upgrade graph, e.g noobaa-operator.v2.3.0")
+	cmd.Flags().String("skip-range", "", "Semver range of versions that can upgrade directly to this version, e.g '>=2.0.0 <2.3.0'")
+	return cmd
+}
+
+// RunBundle runs a CLI command
+func RunBundle(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+
+	if len(args) != 1 || args[0] == "" {
+		log.Fatalf(`❌ Missing expected arguments: <dir> %s`, cmd.UsageString())
+	}
+	dir := args[0]
+	channelsFlag, _ := cmd.Flags().GetString("channels")
+	defaultChannel, _ := cmd.Flags().GetString("default-channel")
+	replaces, _ := cmd.Flags().GetString("replaces")
+	skipRange, _ := cmd.Flags().GetString("skip-range")
+
+	channels := []string{}
+	for _, c := range strings.Split(channelsFlag, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			channels = append(channels, c)
+		}
+	}
+	if len(channels) == 0 {
+		log.Fatalf(`❌ Missing required flag: --channels: %s`, cmd.UsageString())
+	}
+	if defaultChannel == "" {
+		defaultChannel = channels[0]
+	}
+	if !util.Contains(defaultChannel, channels) {
+		log.Fatalf(`❌ Default channel %q is not one of the bundle channels %q`, defaultChannel, channels)
+	}
+
+	opConf := operator.LoadOperatorConf(cmd)
+	csv := GenerateCSV(opConf)
+	if err := SetUpgradeGraph(csv, replaces, skipRange); err != nil {
+		log.Fatalf(`❌ %s`, err)
+	}
+	if err := WriteBundle(dir, csv, channels, defaultChannel); err != nil {
+		log.Fatalf(`❌ Could not write the bundle to %q: %s`, dir, err)
+	}
+	log.Printf("✅ Created OLM bundle %s in %s", csv.Name, dir)
+}
+
+// SetUpgradeGraph sets the previous version that the CSV replaces, and the range of versions
+// that OLM can upgrade from directly, skipping the versions between them and this version.
+// Both must refer to versions older than the CSV version.
+func SetUpgradeGraph(csv *operv1.ClusterServiceVersion, replaces string, skipRange string) error {
+	current := csv.Spec.Version.Version
+	if replaces != "" {
+		if replaces == csv.Name {
+			return fmt.Errorf("Replaces %q is the current CSV", replaces)
+		}
+		if !strings.HasPrefix(replaces, PackageName+".v") {
+			return fmt.Errorf("Replaces %q is not a %s CSV name, e.g %s.v2.3.0", replaces, PackageName, PackageName)
+		}
+		replacesVersion, err := semver.Parse(strings.TrimPrefix(replaces, PackageName+".v"))
+		if err != nil {
+			return fmt.Errorf("Replaces %q has an invalid version: %s", replaces, err)
+		}
+		if !replacesVersion.LT(current) {
+			return fmt.Errorf("Replaces %q is not older than the current version %s", replaces, current)
+		}
+		csv.Spec.Replaces = replaces
+	}
+	if skipRange != "" {
+		inRange, err := semver.ParseRange(skipRange)
+		if err != nil {
+			return fmt.Errorf("Skip range %q is invalid: %s", skipRange, err)
+		}
+		if inRange(current) {
+			return fmt.Errorf("Skip range %q includes the current version %s", skipRange, current)
+		}
+		if csv.Annotations == nil {
+			csv.Annotations = map[string]string{}
+		}
+		csv.Annotations["olm.skipRange"] = skipRange
+	}
+	return nil
+}
+
+// BundleAnnotations returns the annotations of the bundle metadata and image labels
+func BundleAnnotations(channels []string, defaultChannel string) map[string]string {
+	return map[string]string{
+		bundleAnnotationsPrefix + "mediatype.v1":       "registry+v1",
+		bundleAnnotationsPrefix + "manifests.v1":       bundleManifestsDir,
+		bundleAnnotationsPrefix + "metadata.v1":        bundleMetadataDir,
+		bundleAnnotationsPrefix + "package.v1":         PackageName,
+		bundleAnnotationsPrefix + "channels.v1":        strings.Join(channels, ","),
+		bundleAnnotationsPrefix + "channel.default.v1": defaultChannel,
+	}
+}
+
+// WriteBundle writes the CSV and the owned CRDs in the operator-framework bundle format:
+// the manifests dir, the metadata annotations and a Dockerfile to build the bundle image.
+func WriteBundle(dir string, csv *operv1.ClusterServiceVersion, channels []string, defaultChannel string) error {
+	manifestsDir := filepath.Join(dir, bundleManifestsDir)
+	metadataDir := filepath.Join(dir, bundleMetadataDir)
+	if err := os.MkdirAll(manifestsDir, 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return err
+	}
+
+	// OLM installs the CSV in the namespace of the subscription
+	bundleCSV := csv.DeepCopy()
+	bundleCSV.Namespace = ""
+	if err := util.WriteYamlFile(filepath.Join(manifestsDir, PackageName+".clusterserviceversion.yaml"), bundleCSV); err != nil {
+		return err
+	}
+	var err error
+	crd.ForEachCRD(func(c *crd.CRD) {
+		if err == nil && c.Spec.Group == nbv1.SchemeGroupVersion.Group {
+			err = util.WriteYamlFile(filepath.Join(manifestsDir, c.Name+".crd.yaml"), c)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	annotations := BundleAnnotations(channels, defaultChannel)
+	annotationsBytes, err := sigyaml.Marshal(unObj{"annotations": annotations})
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(metadataDir, "annotations.yaml"), annotationsBytes, 0644); err != nil {
+		return err
+	}
+
+	keys := []string{}
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	dockerfile := "FROM scratch\n\n"
+	for _, key := range keys {
+		dockerfile += fmt.Sprintf("LABEL %s=%s\n", key, annotations[key])
+	}
+	dockerfile += fmt.Sprintf("\nCOPY %s /%s\nCOPY %s /%s\n", bundleManifestsDir, bundleManifestsDir, bundleMetadataDir, bundleMetadataDir)
+	return ioutil.WriteFile(filepath.Join(dir, "bundle.Dockerfile"), []byte(dockerfile), 0644)
+}
@@ -0,0 +1,179 @@
+package olm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/noobaa/noobaa-operator/v2/pkg/operator"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+
+	"github.com/blang/semver"
+	operv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	sigyaml "sigs.k8s.io/yaml"
+)
+
+func testCSV(t *testing.T) *operv1.ClusterServiceVersion {
+	t.Helper()
+	return GenerateCSV(operator.LoadOperatorConfForNamespace(options.Namespace))
+}
+
+func TestCRDDescriptors(t *testing.T) {
+	csv := testCSV(t)
+	specs := map[string]operv1.SpecDescriptor{}
+	statuses := map[string]operv1.StatusDescriptor{}
+	for _, desc := range csv.Spec.CustomResourceDefinitions.Owned {
+		for _, d := range desc.SpecDescriptors {
+			specs[desc.Kind+"/"+d.Path] = d
+		}
+		for _, d := range desc.StatusDescriptors {
+			statuses[desc.Kind+"/"+d.Path] = d
+		}
+	}
+
+	expectedSpecs := map[string][]string{
+		"NooBaa/dbImage":                   {uiText},
+		"NooBaa/dbVolumeResources":         {uiResources},
+		"NooBaa/dbStorageClass":            {uiK8sStorage},
+		"NooBaa/imagePullSecret.name":      {uiK8sSecret},
+		"NooBaa/endpoints.minCount":        {uiFieldGroup + "endpoints", uiNumber},
+		"BackingStore/awsS3.secret.name":   {uiFieldGroup + "awsS3", uiK8sSecret},
+		"BackingStore/awsS3.sslDisabled":   {uiFieldGroup + "awsS3", uiBooleanSwitch},
+		"BackingStore/pvPool.resources":    {uiFieldGroup + "pvPool", uiResources},
+		"BackingStore/ibmCos.targetBucket": {uiFieldGroup + "ibmCos", uiText},
+		"NamespaceStore/type": {uiSelect + "aws-s3", uiSelect + "s3-compatible",
+			uiSelect + "ibm-cos", uiSelect + "azure-blob", uiSelect + "nsfs"},
+		"BucketClass/namespacePolicy.multi.writeResource":       {uiFieldGroup + "namespacePolicy", uiText},
+		"BucketClass/placementPolicy.tiers[0].backingStores[0]": {uiFieldGroup + "placementPolicy", uiText},
+	}
+	for path, xDescriptors := range expectedSpecs {
+		d, ok := specs[path]
+		if !ok {
+			t.Errorf("missing spec descriptor %s", path)
+			continue
+		}
+		if len(d.XDescriptors) != len(xDescriptors) {
+			t.Errorf("spec descriptor %s: expected %v got %v", path, xDescriptors, d.XDescriptors)
+			continue
+		}
+		for i := range xDescriptors {
+			if d.XDescriptors[i] != xDescriptors[i] {
+				t.Errorf("spec descriptor %s: expected %v got %v", path, xDescriptors, d.XDescriptors)
+				break
+			}
+		}
+		if d.DisplayName == "" || d.Description == "" {
+			t.Errorf("spec descriptor %s: expected display name and description, got %+v", path, d)
+		}
+	}
+	if _, ok := specs["NooBaa/tolerations"]; ok {
+		t.Errorf("expected tolerations to be left out of the descriptors")
+	}
+	if d := specs["BackingStore/awsS3.sslDisabled"]; d.DisplayName != "SSL Disabled" {
+		t.Errorf("expected acronyms in display name, got %q", d.DisplayName)
+	}
+
+	for _, kind := range []string{"NooBaa", "BackingStore", "NamespaceStore", "BucketClass"} {
+		if d := statuses[kind+"/phase"]; len(d.XDescriptors) != 1 || d.XDescriptors[0] != uiStatusPhase {
+			t.Errorf("%s: expected phase status descriptor, got %+v", kind, d)
+		}
+		if d := statuses[kind+"/conditions"]; len(d.XDescriptors) != 1 || d.XDescriptors[0] != uiStatusConds {
+			t.Errorf("%s: expected conditions status descriptor, got %+v", kind, d)
+		}
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"sslDisabled":    "SSL Disabled",
+		"awsS3":          "AWS S3",
+		"s3Compatible":   "S3 Compatible",
+		"mongoDbURL":     "Mongo DB URL",
+		"numVolumes":     "Num Volumes",
+		"ibmCos":         "IBM COS",
+		"pvPoolDefault":  "PV Pool Default",
+		"additionalHost": "Additional Host",
+	} {
+		if got := displayName(name); got != expected {
+			t.Errorf("displayName(%q): expected %q got %q", name, expected, got)
+		}
+	}
+}
+
+func TestSetUpgradeGraph(t *testing.T) {
+	csv := &operv1.ClusterServiceVersion{}
+	csv.Name = PackageName + ".v2.3.0"
+	csv.Spec.Version.Version = semver.MustParse("2.3.0")
+
+	for _, replaces := range []string{csv.Name, "other-operator.v2.2.0", PackageName + ".vX", PackageName + ".v2.4.0"} {
+		if err := SetUpgradeGraph(csv, replaces, ""); err == nil {
+			t.Errorf("expected replaces %q to be rejected", replaces)
+		}
+	}
+	for _, skipRange := range []string{"not a range", ">=2.0.0 <=2.3.0"} {
+		if err := SetUpgradeGraph(csv, "", skipRange); err == nil {
+			t.Errorf("expected skip range %q to be rejected", skipRange)
+		}
+	}
+
+	if err := SetUpgradeGraph(csv, PackageName+".v2.2.0", ">=2.0.0 <2.3.0"); err != nil {
+		t.Fatal(err)
+	}
+	if csv.Spec.Replaces != PackageName+".v2.2.0" || csv.Annotations["olm.skipRange"] != ">=2.0.0 <2.3.0" {
+		t.Errorf("expected upgrade graph to be set, got replaces %q annotations %v", csv.Spec.Replaces, csv.Annotations)
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "noobaa-olm-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	csv := testCSV(t)
+	if err := WriteBundle(dir, csv, []string{"alpha", "stable"}, "stable"); err != nil {
+		t.Fatal(err)
+	}
+
+	csvBytes, err := ioutil.ReadFile(filepath.Join(dir, "manifests", PackageName+".clusterserviceversion.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := util.KubeObject(string(csvBytes)).(*operv1.ClusterServiceVersion)
+	if written.Name != csv.Name || written.Namespace != "" {
+		t.Errorf("expected the bundle CSV %s without namespace, got %s/%s", csv.Name, written.Namespace, written.Name)
+	}
+	for _, name := range []string{"noobaas", "backingstores", "namespacestores", "bucketclasses"} {
+		if _, err := ioutil.ReadFile(filepath.Join(dir, "manifests", name+".noobaa.io.crd.yaml")); err != nil {
+			t.Errorf("expected the %s CRD in the bundle manifests: %s", name, err)
+		}
+	}
+
+	metadata := struct {
+		Annotations map[string]string `json:"annotations"`
+	}{}
+	metadataBytes, err := ioutil.ReadFile(filepath.Join(dir, "metadata", "annotations.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sigyaml.Unmarshal(metadataBytes, &metadata); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]string{
+		"mediatype.v1":       "registry+v1",
+		"manifests.v1":       "manifests/",
+		"metadata.v1":        "metadata/",
+		"package.v1":         PackageName,
+		"channels.v1":        "alpha,stable",
+		"channel.default.v1": "stable",
+	} {
+		if got := metadata.Annotations[bundleAnnotationsPrefix+key]; got != value {
+			t.Errorf("annotation %s: expected %q got %q", key, value, got)
+		}
+	}
+	if _, err := ioutil.ReadFile(filepath.Join(dir, "bundle.Dockerfile")); err != nil {
+		t.Errorf("expected a bundle Dockerfile: %s", err)
+	}
+}
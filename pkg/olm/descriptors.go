@@ -0,0 +1,318 @@
+package olm
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/crd"
+
+	operv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const (
+	uiTectonic       = "urn:alm:descriptor:com.tectonic.ui:"
+	uiText           = uiTectonic + "text"
+	uiNumber         = uiTectonic + "number"
+	uiBooleanSwitch  = uiTectonic + "booleanSwitch"
+	uiResources      = uiTectonic + "resourceRequirements"
+	uiAdvanced       = uiTectonic + "advanced"
+	uiFieldGroup     = uiTectonic + "fieldGroup:"
+	uiSelect         = uiTectonic + "select:"
+	uiKubernetes     = "urn:alm:descriptor:io.kubernetes:"
+	uiK8sSecret      = uiKubernetes + "Secret"
+	uiK8sStorage     = uiKubernetes + "StorageClass"
+	uiStatusText     = "urn:alm:descriptor:text"
+	uiStatusPhase    = "urn:alm:descriptor:io.kubernetes.phase"
+	uiStatusConds    = "urn:alm:descriptor:io.kubernetes.conditions"
+	descriptorsDepth = 3
+)
+
+// descriptorSelects are the values of string fields that the CRD schemas do not declare as enums
+var descriptorSelects = map[string][]string{
+	"NooBaa/dbType": {
+		string(nbv1.DBTypeMongo),
+		string(nbv1.DBTypePostgres),
+	},
+	"BackingStore/type": {
+		string(nbv1.StoreTypeAWSS3),
+		string(nbv1.StoreTypeS3Compatible),
+		string(nbv1.StoreTypeIBMCos),
+		string(nbv1.StoreTypeGoogleCloudStorage),
+		string(nbv1.StoreTypeAzureBlob),
+		string(nbv1.StoreTypePVPool),
+	},
+	"NamespaceStore/type": {
+		string(nbv1.NSStoreTypeAWSS3),
+		string(nbv1.NSStoreTypeS3Compatible),
+		string(nbv1.NSStoreTypeIBMCos),
+		string(nbv1.NSStoreTypeAzureBlob),
+		string(nbv1.NSStoreTypeNSFS),
+	},
+	"BucketClass/placementPolicy.tiers[0].placement": {
+		string(nbv1.TierPlacementMirror),
+		string(nbv1.TierPlacementSpread),
+	},
+	"BucketClass/namespacePolicy.type": {
+		string(nbv1.NSBucketClassTypeSingle),
+		string(nbv1.NSBucketClassTypeMulti),
+		string(nbv1.NSBucketClassTypeCache),
+	},
+}
+
+// descriptorSkips are spec field names left out of the descriptors.
+// tolerations caused the OCP console to crash on noobaa CRD page, when trying to display them.
+var descriptorSkips = map[string]bool{
+	"tolerations": true,
+}
+
+// descriptorAffinities are affinity fields that the console edits with dedicated widgets
+var descriptorAffinities = map[string]string{
+	"nodeAffinity":    uiTectonic + "nodeAffinity",
+	"podAffinity":     uiTectonic + "podAffinity",
+	"podAntiAffinity": uiTectonic + "podAntiAffinity",
+}
+
+// CRDDescriptors generates the spec and status descriptors of a CRD from the openAPI schema
+// of its first version, so that every field of the CRD is described in the CSV with the
+// description that the schema took from the api types doc comments.
+func CRDDescriptors(c *crd.CRD) ([]operv1.SpecDescriptor, []operv1.StatusDescriptor) {
+	specDescriptors := []operv1.SpecDescriptor{}
+	statusDescriptors := []operv1.StatusDescriptor{}
+	if len(c.Spec.Versions) == 0 || c.Spec.Versions[0].Schema == nil || c.Spec.Versions[0].Schema.OpenAPIV3Schema == nil {
+		return specDescriptors, statusDescriptors
+	}
+	kind := c.Spec.Names.Kind
+	props := c.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties
+
+	spec := props["spec"]
+	walkSchema(spec.Properties, "", "", 1, []string{uiAdvanced}, func(path string, group string, name string, schema *apiextv1.JSONSchemaProps, xDescriptor []string) {
+		if group != "" {
+			xDescriptor = append([]string{uiFieldGroup + group}, xDescriptor...)
+		}
+		specDescriptors = append(specDescriptors, operv1.SpecDescriptor{
+			Path:         path,
+			DisplayName:  displayName(name),
+			Description:  description(schema),
+			XDescriptors: xDescriptor,
+		})
+	}, func(path string, name string, schema *apiextv1.JSONSchemaProps) []string {
+		if descriptorSkips[name] {
+			return nil
+		}
+		if values := descriptorSelects[kind+"/"+path]; len(values) > 0 {
+			xDescriptor := []string{}
+			for _, v := range values {
+				xDescriptor = append(xDescriptor, uiSelect+v)
+			}
+			return xDescriptor
+		}
+		return specXDescriptor(name, schema)
+	})
+
+	status := props["status"]
+	walkSchema(status.Properties, "", "", 1, nil, func(path string, group string, name string, schema *apiextv1.JSONSchemaProps, xDescriptor []string) {
+		statusDescriptors = append(statusDescriptors, operv1.StatusDescriptor{
+			Path:         path,
+			DisplayName:  displayName(name),
+			Description:  description(schema),
+			XDescriptors: xDescriptor,
+		})
+	}, func(path string, name string, schema *apiextv1.JSONSchemaProps) []string {
+		return statusXDescriptor(path, name, schema)
+	})
+
+	return specDescriptors, statusDescriptors
+}
+
+// walkSchema calls add for every leaf field of the schema properties up to descriptorsDepth,
+// with the x-descriptors that xDescriptorOf returns for it, where nil means to skip the field
+// and an empty list means the field is an object to descend into.
+// Objects that cannot be descended into get the fallback x-descriptors, or are skipped when it is nil.
+// Secret references are described by the path of their name with the parent name and description.
+func walkSchema(
+	props map[string]apiextv1.JSONSchemaProps,
+	prefix string,
+	group string,
+	depth int,
+	fallback []string,
+	add func(path string, group string, name string, schema *apiextv1.JSONSchemaProps, xDescriptor []string),
+	xDescriptorOf func(path string, name string, schema *apiextv1.JSONSchemaProps) []string,
+) {
+	names := []string{}
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		schema := props[name]
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		fieldGroup := group
+		if depth > 1 && fieldGroup == "" {
+			fieldGroup = strings.SplitN(strings.SplitN(prefix, ".", 2)[0], "[", 2)[0]
+		}
+
+		xDescriptor := xDescriptorOf(path, name, &schema)
+		if xDescriptor == nil {
+			continue
+		}
+		if len(xDescriptor) > 0 {
+			if isSecretRef(name, &schema) {
+				path += ".name"
+			}
+			add(path, fieldGroup, name, &schema, xDescriptor)
+			continue
+		}
+
+		// descend into objects and into the first item of arrays
+		switch {
+		case schema.Type == "array" && schema.Items != nil && schema.Items.Schema != nil:
+			items := schema.Items.Schema
+			if items.Type == "object" {
+				if depth < descriptorsDepth {
+					walkSchema(items.Properties, path+"[0]", fieldGroup, depth+1, fallback, add, xDescriptorOf)
+				} else if fallback != nil {
+					add(path, fieldGroup, name, &schema, fallback)
+				}
+			} else {
+				itemXDescriptor := xDescriptorOf(path+"[0]", name, items)
+				if len(itemXDescriptor) > 0 {
+					// the items are described by the array field
+					items = &apiextv1.JSONSchemaProps{Description: schema.Description}
+					add(path+"[0]", fieldGroup, name, items, itemXDescriptor)
+				}
+			}
+		case schema.Type == "object" && len(schema.Properties) > 0 && depth < descriptorsDepth:
+			walkSchema(schema.Properties, path, fieldGroup, depth+1, fallback, add, xDescriptorOf)
+		case fallback != nil:
+			add(path, fieldGroup, name, &schema, fallback)
+		}
+	}
+}
+
+// specXDescriptor returns the x-descriptors of a spec field by its schema
+func specXDescriptor(name string, schema *apiextv1.JSONSchemaProps) []string {
+	if xDescriptor, ok := descriptorAffinities[name]; ok {
+		return []string{xDescriptor}
+	}
+	if isSecretRef(name, schema) || (schema.Type == "string" && strings.HasSuffix(name, "SecretName")) {
+		return []string{uiK8sSecret}
+	}
+	if isResourceRequirements(schema) {
+		return []string{uiResources}
+	}
+	if len(schema.Enum) > 0 {
+		return enumXDescriptor(schema)
+	}
+	switch schema.Type {
+	case "boolean":
+		return []string{uiBooleanSwitch}
+	case "integer", "number":
+		return []string{uiNumber}
+	case "string":
+		if strings.HasSuffix(strings.ToLower(name), "storageclass") {
+			return []string{uiK8sStorage}
+		}
+		return []string{uiText}
+	}
+	if schema.XIntOrString {
+		return []string{uiText}
+	}
+	return []string{}
+}
+
+// statusXDescriptor returns the x-descriptors of a status field by its schema
+func statusXDescriptor(path string, name string, schema *apiextv1.JSONSchemaProps) []string {
+	switch path {
+	case "phase":
+		return []string{uiStatusPhase}
+	case "conditions":
+		return []string{uiStatusConds}
+	case "relatedObjects":
+		return nil
+	}
+	if isSecretRef(name, schema) {
+		return []string{uiK8sSecret}
+	}
+	switch schema.Type {
+	case "boolean", "integer", "number", "string":
+		return []string{uiStatusText}
+	case "array":
+		if schema.Items != nil && schema.Items.Schema != nil && schema.Items.Schema.Type != "object" {
+			return []string{uiStatusText}
+		}
+		return nil
+	}
+	if schema.XIntOrString {
+		return []string{uiStatusText}
+	}
+	return []string{}
+}
+
+func enumXDescriptor(schema *apiextv1.JSONSchemaProps) []string {
+	xDescriptor := []string{}
+	for _, v := range schema.Enum {
+		xDescriptor = append(xDescriptor, uiSelect+strings.Trim(string(v.Raw), `"`))
+	}
+	return xDescriptor
+}
+
+// isSecretRef returns true for a reference to a secret by name, such as a SecretReference
+func isSecretRef(name string, schema *apiextv1.JSONSchemaProps) bool {
+	lower := strings.ToLower(name)
+	if !strings.HasSuffix(lower, "secret") && !strings.HasSuffix(lower, "secretref") {
+		return false
+	}
+	_, hasName := schema.Properties["name"]
+	return schema.Type == "object" && hasName
+}
+
+// isResourceRequirements returns true for a ResourceRequirements or a VolumeResourceRequirements
+func isResourceRequirements(schema *apiextv1.JSONSchemaProps) bool {
+	_, hasLimits := schema.Properties["limits"]
+	_, hasRequests := schema.Properties["requests"]
+	return schema.Type == "object" && hasLimits && hasRequests
+}
+
+var (
+	camelCaseWords = regexp.MustCompile(`[A-Z]+[a-z]*|[a-z]+|[0-9]+`)
+	displayAcronym = map[string]string{
+		"Api": "API", "Aws": "AWS", "Cos": "COS", "Db": "DB", "Dns": "DNS", "Fs": "FS",
+		"Ibm": "IBM", "Ip": "IP", "Js": "JS", "Kms": "KMS", "Nsfs": "NSFS", "Pv": "PV",
+		"Ssl": "SSL", "Url": "URL",
+	}
+)
+
+// displayName returns a display name for a camelCase field name,
+// for example "sslDisabled" is "SSL Disabled" and "awsS3" is "AWS S3"
+func displayName(name string) string {
+	words := []string{}
+	for _, w := range camelCaseWords.FindAllString(name, -1) {
+		if len(w) > 1 && strings.ToUpper(w) == w {
+			words = append(words, w)
+			continue
+		}
+		w = strings.ToUpper(w[:1]) + w[1:]
+		if acronym, ok := displayAcronym[w]; ok {
+			w = acronym
+		}
+		words = append(words, w)
+	}
+	// digits stick to the previous word, like in S3
+	display := strings.Join(words, " ")
+	for d := '0'; d <= '9'; d++ {
+		display = strings.ReplaceAll(display, " "+string(d), string(d))
+	}
+	return display
+}
+
+// description returns the schema description in a single line
+func description(schema *apiextv1.JSONSchemaProps) string {
+	return strings.Join(strings.Fields(schema.Description), " ")
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/blang/semver"
 	operv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -38,6 +40,7 @@ func Cmd() *cobra.Command {
 	cmd.AddCommand(
 		CmdCatalog(),
 		CmdCSV(),
+		CmdBundle(),
 		CmdHubInstall(),
 		CmdHubUninstall(),
 		CmdHubStatus(),
@@ -163,12 +166,7 @@ func RunCSV(cmd *cobra.Command, args []string) {
 
 // GenerateCSV creates the CSV
 func GenerateCSV(opConf *operator.Conf) *operv1.ClusterServiceVersion {
-	almExamples, err := json.Marshal([]runtime.Object{
-		util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_noobaa_cr_yaml),
-		util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_backingstore_cr_yaml),
-		util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_namespacestore_cr_yaml),
-		util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_bucketclass_cr_yaml),
-	})
+	almExamples, err := json.Marshal(ALMExamples())
 	util.Panic(err)
 
 	o := util.KubeObject(bundle.File_deploy_olm_noobaa_operator_clusterserviceversion_yaml)
@@ -204,6 +202,7 @@ func GenerateCSV(opConf *operator.Conf) *operv1.ClusterServiceVersion {
 			Name: opConf.Deployment.Name,
 			Spec: opConf.Deployment.Spec,
 		})
+	csv.Spec.WebhookDefinitions = WebhookDefinitions(opConf.Deployment.Name, ownedCRDs()...)
 	csv.Spec.CustomResourceDefinitions.Owned = []operv1.CRDDescription{}
 	csv.Spec.CustomResourceDefinitions.Required = []operv1.CRDDescription{}
 	crdDescriptions := map[string]string{
@@ -227,338 +226,6 @@ func GenerateCSV(opConf *operator.Conf) *operv1.ClusterServiceVersion {
 		"ObjectBucketClaim": "Object Bucket Claim",
 		"ObjectBucket":      "Object Bucket",
 	}
-	const (
-		uiTectonic                     = "urn:alm:descriptor:com.tectonic.ui:"
-		uiText                         = uiTectonic + "text"
-		uiResources                    = uiTectonic + "resourceRequirements"
-		uiFieldGroup                   = uiTectonic + "fieldGroup:"
-		uiKubernetes                   = "urn:alm:descriptor:io.kubernetes:"
-		uiK8sSecret                    = uiKubernetes + "Secret"
-		uiK8sTolerations               = uiKubernetes + "Tolerations"
-		uiBooleanSwitch                = uiTectonic + "booleanSwitch"
-		uiNumber                       = uiTectonic + "number"
-		uiFieldGroupAwsS3              = uiFieldGroup + "awsS3"
-		uiFieldGroupAzureBlob          = uiFieldGroup + "azureBlob"
-		uiFieldGroupGoogleCloudStorage = uiFieldGroup + "googleCloudStorage"
-		uiFieldGroupPvPool             = uiFieldGroup + "pvPool"
-		uiFieldGroupS3Compatible       = uiFieldGroup + "s3Compatible"
-		uiFieldGroupIBMCos             = uiFieldGroup + "ibmCos"
-		uiFieldGroupPlacementPolicy    = uiFieldGroup + "placementPolicy"
-		uiFieldGroupNamespacePolicy    = uiFieldGroup + "namespacePolicy"
-	)
-
-	crdSpecDescriptors := map[string][]operv1.SpecDescriptor{
-		"NooBaa": []operv1.SpecDescriptor{
-			operv1.SpecDescriptor{
-				Path:         "image",
-				XDescriptors: []string{uiText},
-				Description:  "DBImage (optional) overrides the default image for the db container.",
-				DisplayName:  "DB Image",
-			},
-			operv1.SpecDescriptor{
-				Path:         "dbImage",
-				XDescriptors: []string{uiText},
-				Description:  "Image (optional) overrides the default image for the server container.",
-				DisplayName:  "Image",
-			},
-			operv1.SpecDescriptor{
-				Path:         "coreResources",
-				XDescriptors: []string{uiResources},
-				Description:  "CoreResources (optional) overrides the default resource requirements for the server container.",
-				DisplayName:  "Core Resources",
-			},
-			operv1.SpecDescriptor{
-				Path:         "dbResources",
-				XDescriptors: []string{uiResources},
-				Description:  "DBResources (optional) overrides the default resource requirements for the db container.",
-				DisplayName:  "DB Resources",
-			},
-			operv1.SpecDescriptor{
-				Path:         "dbVolumeResources",
-				XDescriptors: []string{uiResources},
-				Description:  "DBVolumeResources (optional) overrides the default PVC resource requirements for the database volume. For the time being this field is immutable and can only be set on system creation. This is because volume size updates are only supported for increasing the size, and only if the storage class specifies `allowVolumeExpansion: true`, +immutable.",
-				DisplayName:  "Image",
-			},
-			operv1.SpecDescriptor{
-				Path:         "dbStorageClass",
-				XDescriptors: []string{uiText},
-				Description:  "DBStorageClass (optional) overrides the default cluster StorageClass for the database volume. For the time being this field is immutable and can only be set on system creation. This affects where the system stores its database which contains system config, buckets, objects meta-data and mapping file parts to storage locations. +immutable.",
-				DisplayName:  "DB StorageClass",
-			},
-			operv1.SpecDescriptor{
-				Path:         "pvPoolDefaultStorageClass",
-				XDescriptors: []string{uiText},
-				Description:  "PVPoolDefaultStorageClass (optional) overrides the default cluster StorageClass for the pv-pool volumes. This affects where the system stores data chunks (encrypted). Updates to this field will only affect new pv-pools, but updates to existing pools are not supported by the operator.",
-				DisplayName:  "PV Pool DefaultStorageClass",
-			},
-			// this descriptor caused the OCP console to crash on noobaa CRD page, when trying to display tolerations.
-			// removing for now
-			// operv1.SpecDescriptor{
-			// 	Path:         "tolerations",
-			// 	XDescriptors: []string{uiK8sTolerations},
-			// 	Description:  "Tolerations.",
-			// 	DisplayName:  "Tolerations",
-			// },
-			operv1.SpecDescriptor{
-				Path:         "imagePullSecret",
-				XDescriptors: []string{uiK8sSecret},
-				Description:  "ImagePullSecret (optional) sets a pull secret for the system image.",
-				DisplayName:  "Image Pull Secret",
-			},
-		},
-		"BackingStore": []operv1.SpecDescriptor{
-			operv1.SpecDescriptor{
-				Description:  "Region is the AWS region.",
-				Path:         "awsS3.region",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiText},
-				DisplayName:  "Region",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Secret refers to a secret that provides the credentials. The secret should define AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.",
-				Path:         "awsS3.secret.name",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "SSLDisabled allows to disable SSL and use plain http.",
-				Path:         "awsS3.sslDisabled",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiBooleanSwitch},
-				DisplayName:  "SSL Disabled",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBucket is the name of the target S3 bucket.",
-				Path:         "awsS3.targetBucket",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiText},
-				DisplayName:  "Target Bucket",
-			},
-			operv1.SpecDescriptor{
-				Description:  " Secret refers to a secret that provides the credentials. The secret should define AccountName and AccountKey as provided\nby Azure Blob.",
-				Path:         "azureBlob.secret.name",
-				XDescriptors: []string{uiFieldGroupAzureBlob, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBlobContainer is the name of the target Azure Blob container.",
-				Path:         "azureBlob.targetBlobContainer",
-				XDescriptors: []string{uiFieldGroupAzureBlob, uiText},
-				DisplayName:  "Target Blob Container",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Secret refers to a secret that provides the credentials. The secret should define GoogleServiceAccountPrivateKeyJson containing\nthe entire json string as provided by Google.",
-				Path:         "googleCloudStorage.secret.name",
-				XDescriptors: []string{uiFieldGroupGoogleCloudStorage, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBucket is the name of the target S3 bucket.",
-				Path:         "googleCloudStorage.targetBucket",
-				XDescriptors: []string{uiFieldGroupGoogleCloudStorage, uiText},
-				DisplayName:  "Target Bucket",
-			},
-			operv1.SpecDescriptor{
-				Description:  "NumVolumes is the number of volumes to allocate.",
-				Path:         "pvPool.numVolumes",
-				XDescriptors: []string{uiFieldGroupPvPool, uiNumber},
-				DisplayName:  "Num Volumes",
-			},
-			operv1.SpecDescriptor{
-				Description:  "VolumeResources represents the minimum resources each volume should have.",
-				Path:         "pvPool.resources",
-				XDescriptors: []string{uiFieldGroupPvPool, uiResources},
-				DisplayName:  "Resources",
-			},
-			operv1.SpecDescriptor{
-				Description:  "StorageClass is the name of the storage class to use for the PV's.",
-				Path:         "pvPool.storageClass",
-				XDescriptors: []string{uiFieldGroupPvPool, uiText},
-				DisplayName:  "Storage Class",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Endpoint is the S3 compatible endpoint: http(s)://host:port.",
-				Path:         "s3Compatible.endpoint",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiText},
-				DisplayName:  "End Point",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Secret refers to a secret that provides the credentials. The secret should define AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.",
-				Path:         "s3Compatible.secret.name",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "SignatureVersion specifies the client signature version to use when signing requests.",
-				Path:         "s3Compatible.signatureVersion",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiNumber},
-				DisplayName:  "Signature Version",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBucket is the name of the target S3 bucket.",
-				Path:         "s3Compatible.targetBucket",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiText},
-				DisplayName:  "Target Bucket",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Endpoint is the IBM COS endpoint: http(s)://host:port.",
-				Path:         "IBMCos.endpoint",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiText},
-				DisplayName:  "End Point",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Secret refers to a secret that provides the credentials. The secret should define IBM_COS_ACCESS_KEY_ID and IBM_COS_SECRET_ACCESS_KEY.",
-				Path:         "IBMCos.secret.name",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "SignatureVersion specifies the client signature version to use when signing requests.",
-				Path:         "IBMCos.signatureVersion",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiNumber},
-				DisplayName:  "Signature Version",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBucket is the name of the target IBM COS bucket.",
-				Path:         "IBMCos.targetBucket",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiText},
-				DisplayName:  "Target Bucket",
-			},
-		},
-
-		"NamespaceStore": []operv1.SpecDescriptor{
-			operv1.SpecDescriptor{
-				Description:  "Region is the AWS region.",
-				Path:         "awsS3.region",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiText},
-				DisplayName:  "Region",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Secret refers to a secret that provides the credentials. The secret should define AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.",
-				Path:         "awsS3.secret.name",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "SSLDisabled allows to disable SSL and use plain http.",
-				Path:         "awsS3.sslDisabled",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiBooleanSwitch},
-				DisplayName:  "SSL Disabled",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBucket is the name of the target S3 bucket.",
-				Path:         "awsS3.targetBucket",
-				XDescriptors: []string{uiFieldGroupAwsS3, uiText},
-				DisplayName:  "Target Bucket",
-			},
-			operv1.SpecDescriptor{
-				Description:  " Secret refers to a secret that provides the credentials. The secret should define AccountName and AccountKey as provided\nby Azure Blob.",
-				Path:         "azureBlob.secret.name",
-				XDescriptors: []string{uiFieldGroupAzureBlob, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBlobContainer is the name of the target Azure Blob container.",
-				Path:         "azureBlob.targetBlobContainer",
-				XDescriptors: []string{uiFieldGroupAzureBlob, uiText},
-				DisplayName:  "Target Blob Container",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Endpoint is the S3 compatible endpoint: http(s)://host:port.",
-				Path:         "s3Compatible.endpoint",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiText},
-				DisplayName:  "End Point",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Secret refers to a secret that provides the credentials. The secret should define AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.",
-				Path:         "s3Compatible.secret.name",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "SignatureVersion specifies the client signature version to use when signing requests.",
-				Path:         "s3Compatible.signatureVersion",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiNumber},
-				DisplayName:  "Signature Version",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBucket is the name of the target S3 bucket.",
-				Path:         "s3Compatible.targetBucket",
-				XDescriptors: []string{uiFieldGroupS3Compatible, uiText},
-				DisplayName:  "Target Bucket",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Endpoint is the IBM COS endpoint: http(s)://host:port.",
-				Path:         "IBMCos.endpoint",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiText},
-				DisplayName:  "End Point",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Secret refers to a secret that provides the credentials. The secret should define IBM_COS_ACCESS_KEY_ID and IBM_COS_SECRET_ACCESS_KEY.",
-				Path:         "IBMCos.secret.name",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiK8sSecret},
-				DisplayName:  "Secret",
-			},
-			operv1.SpecDescriptor{
-				Description:  "SignatureVersion specifies the client signature version to use when signing requests.",
-				Path:         "IBMCos.signatureVersion",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiNumber},
-				DisplayName:  "Signature Version",
-			},
-			operv1.SpecDescriptor{
-				Description:  "TargetBucket is the name of the target IBM COS bucket.",
-				Path:         "IBMCos.targetBucket",
-				XDescriptors: []string{uiFieldGroupIBMCos, uiText},
-				DisplayName:  "Target Bucket",
-			},
-		},
-
-		"BucketClass": []operv1.SpecDescriptor{
-			operv1.SpecDescriptor{
-				Description:  "BackingStores is an unordered list of backing store names. The meaning of the list depends on the placement.",
-				Path:         "placementPolicy.tiers[0].backingStores[0]",
-				XDescriptors: []string{uiFieldGroupPlacementPolicy, uiText},
-				DisplayName:  "Backing Stores",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Placement specifies the type of placement for the tier If empty it should have a single backing store.",
-				Path:         "placementPolicy.tiers[0].placement",
-				XDescriptors: []string{uiFieldGroupPlacementPolicy, uiText},
-				DisplayName:  "Placement",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Namespace Policy type specifies the type of the namespace policy configuration.",
-				Path:         "namespacePolicy.type",
-				XDescriptors: []string{uiFieldGroupNamespacePolicy, uiText},
-				DisplayName:  "Namespace Policy",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Resource specifies the namespace store configured by the bucket class to be read and write targets.",
-				Path:         "namespacePolicy.single.resource",
-				XDescriptors: []string{uiFieldGroupNamespacePolicy, uiText},
-				DisplayName:  "Resource",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Read Resources specifies the namespace stores configured by the bucket class to be read targets.",
-				Path:         "namespacePolicy.Multi.readResources",
-				XDescriptors: []string{uiFieldGroupNamespacePolicy, uiText},
-				DisplayName:  "Read Resources",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Write Resource specifies the namespace store configured by the bucket class to be write target.",
-				Path:         "namespacePolicy.Multi.writeResource",
-				XDescriptors: []string{uiFieldGroupNamespacePolicy, uiText},
-				DisplayName:  "Write Resource",
-			},
-			operv1.SpecDescriptor{
-				Description:  "Hub Resource specifies the target namespace store configured by the bucket class to be read and write targets.",
-				Path:         "namespacePolicy.Cache.hubResource",
-				XDescriptors: []string{uiFieldGroupNamespacePolicy, uiText},
-				DisplayName:  "Hub Resource",
-			},
-		},
-
-		"ObjectBucketClaim": []operv1.SpecDescriptor{},
-		"ObjectBucket":      []operv1.SpecDescriptor{},
-	}
-
 	crd.ForEachCRD(func(c *crd.CRD) {
 		crdDesc := operv1.CRDDescription{
 			Name:            c.Name,
@@ -566,7 +233,7 @@ func GenerateCSV(opConf *operator.Conf) *operv1.ClusterServiceVersion {
 			Version:         c.Spec.Versions[0].Name,
 			DisplayName:     crdDisplayNames[c.Spec.Names.Kind],
 			Description:     crdDescriptions[c.Spec.Names.Kind],
-			SpecDescriptors: crdSpecDescriptors[c.Spec.Names.Kind],
+			SpecDescriptors: []operv1.SpecDescriptor{},
 			Resources: []operv1.APIResourceReference{
 				operv1.APIResourceReference{Name: "services", Kind: "Service", Version: "v1"},
 				operv1.APIResourceReference{Name: "secrets", Kind: "Secret", Version: "v1"},
@@ -575,6 +242,7 @@ func GenerateCSV(opConf *operator.Conf) *operv1.ClusterServiceVersion {
 			},
 		}
 		if c.Spec.Group == nbv1.SchemeGroupVersion.Group {
+			crdDesc.SpecDescriptors, crdDesc.StatusDescriptors = CRDDescriptors(c)
 			csv.Spec.CustomResourceDefinitions.Owned = append(csv.Spec.CustomResourceDefinitions.Owned, crdDesc)
 		} else {
 			csv.Spec.CustomResourceDefinitions.Required = append(csv.Spec.CustomResourceDefinitions.Required, crdDesc)
@@ -584,6 +252,54 @@ func GenerateCSV(opConf *operator.Conf) *operv1.ClusterServiceVersion {
 	return csv
 }
 
+// ownedCRDs returns the noobaa.io CRDs that the CSV owns
+func ownedCRDs() []runtime.Object {
+	crds := []runtime.Object{}
+	crd.ForEachCRD(func(c *crd.CRD) {
+		if c.Spec.Group == nbv1.SchemeGroupVersion.Group {
+			crds = append(crds, c)
+		}
+	})
+	return crds
+}
+
+// ALMExamples returns the example objects that the console offers when creating the owned CRDs
+func ALMExamples() []runtime.Object {
+	sys := util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_noobaa_cr_yaml).(*nbv1.NooBaa)
+
+	backingStore := util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_backingstore_cr_yaml).(*nbv1.BackingStore)
+	backingStore.Name = "noobaa-pv-backing-store"
+	backingStore.Spec.Type = nbv1.StoreTypePVPool
+	backingStore.Spec.PVPool = &nbv1.PVPoolSpec{
+		NumVolumes: 1,
+		VolumeResources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse("50Gi"),
+			},
+		},
+	}
+
+	namespaceStore := util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_namespacestore_cr_yaml).(*nbv1.NamespaceStore)
+	namespaceStore.Name = "noobaa-aws-namespace-store"
+	namespaceStore.Spec.Type = nbv1.NSStoreTypeAWSS3
+	namespaceStore.Spec.AWSS3 = &nbv1.AWSS3Spec{
+		TargetBucket: "my-bucket",
+		Region:       "us-east-1",
+		Secret:       corev1.SecretReference{Name: "aws-credentials"},
+	}
+
+	bucketClass := util.KubeObject(bundle.File_deploy_crds_noobaa_io_v1alpha1_bucketclass_cr_yaml).(*nbv1.BucketClass)
+	bucketClass.Name = "noobaa-pv-bucket-class"
+	bucketClass.Spec.PlacementPolicy = &nbv1.PlacementPolicy{
+		Tiers: []nbv1.Tier{{
+			Placement:     nbv1.TierPlacementSpread,
+			BackingStores: []nbv1.BackingStoreName{backingStore.Name},
+		}},
+	}
+
+	return []runtime.Object{sys, backingStore, namespaceStore, bucketClass}
+}
+
 // RunHubInstall runs a CLI command
 func RunHubInstall(cmd *cobra.Command, args []string) {
 	hub := LoadHubConf()
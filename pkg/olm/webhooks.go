@@ -0,0 +1,96 @@
+package olm
+
+import (
+	operv1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// WebhookDefinitions converts the admission webhook configurations and the conversion webhooks
+// of CRDs in the given objects to CSV webhook definitions, served by the operator deployment.
+// OLM creates the webhook configurations itself, with a CA bundle for the service that it creates
+// for the deployment, so only the webhooks are taken from the objects and not their client configs.
+func WebhookDefinitions(deploymentName string, objs ...runtime.Object) []operv1.WebhookDescription {
+	webhooks := []operv1.WebhookDescription{}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *admissionv1.ValidatingWebhookConfiguration:
+			for i := range o.Webhooks {
+				w := &o.Webhooks[i]
+				webhooks = append(webhooks, operv1.WebhookDescription{
+					GenerateName:            w.Name,
+					Type:                    operv1.ValidatingAdmissionWebhook,
+					DeploymentName:          deploymentName,
+					ContainerPort:           webhookServicePort(w.ClientConfig.Service),
+					WebhookPath:             webhookServicePath(w.ClientConfig.Service),
+					Rules:                   w.Rules,
+					FailurePolicy:           w.FailurePolicy,
+					MatchPolicy:             w.MatchPolicy,
+					ObjectSelector:          w.ObjectSelector,
+					SideEffects:             w.SideEffects,
+					TimeoutSeconds:          w.TimeoutSeconds,
+					AdmissionReviewVersions: w.AdmissionReviewVersions,
+				})
+			}
+		case *admissionv1.MutatingWebhookConfiguration:
+			for i := range o.Webhooks {
+				w := &o.Webhooks[i]
+				webhooks = append(webhooks, operv1.WebhookDescription{
+					GenerateName:            w.Name,
+					Type:                    operv1.MutatingAdmissionWebhook,
+					DeploymentName:          deploymentName,
+					ContainerPort:           webhookServicePort(w.ClientConfig.Service),
+					WebhookPath:             webhookServicePath(w.ClientConfig.Service),
+					Rules:                   w.Rules,
+					FailurePolicy:           w.FailurePolicy,
+					MatchPolicy:             w.MatchPolicy,
+					ObjectSelector:          w.ObjectSelector,
+					SideEffects:             w.SideEffects,
+					TimeoutSeconds:          w.TimeoutSeconds,
+					AdmissionReviewVersions: w.AdmissionReviewVersions,
+					ReinvocationPolicy:      w.ReinvocationPolicy,
+				})
+			}
+		case *apiextv1.CustomResourceDefinition:
+			conv := o.Spec.Conversion
+			if conv == nil || conv.Strategy != apiextv1.WebhookConverter || conv.Webhook == nil || conv.Webhook.ClientConfig == nil {
+				continue
+			}
+			sideEffects := admissionv1.SideEffectClassNone
+			port := int32(443)
+			var path *string
+			if svc := conv.Webhook.ClientConfig.Service; svc != nil {
+				if svc.Port != nil {
+					port = *svc.Port
+				}
+				path = svc.Path
+			}
+			webhooks = append(webhooks, operv1.WebhookDescription{
+				GenerateName:            o.Name,
+				Type:                    operv1.ConversionWebhook,
+				DeploymentName:          deploymentName,
+				ContainerPort:           port,
+				WebhookPath:             path,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: conv.Webhook.ConversionReviewVersions,
+				ConversionCRDs:          []string{o.Name},
+			})
+		}
+	}
+	return webhooks
+}
+
+func webhookServicePort(svc *admissionv1.ServiceReference) int32 {
+	if svc == nil || svc.Port == nil {
+		return 443
+	}
+	return *svc.Port
+}
+
+func webhookServicePath(svc *admissionv1.ServiceReference) *string {
+	if svc == nil {
+		return nil
+	}
+	return svc.Path
+}
@@ -0,0 +1,154 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Probes serves the liveness and readiness probes of the operator.
+// The probes are served by every replica, also before it becomes the leader.
+// A replica is ready once its cache is synced, and the leader also needs to reach
+// the rpc port of the system in its namespace when that system is ready.
+type Probes struct {
+	CheckTimeout time.Duration
+	Dial         func(network string, addr string, timeout time.Duration) (net.Conn, error)
+
+	mutex   sync.Mutex
+	mgr     manager.Manager
+	leading bool
+}
+
+// NewProbes returns probes of a replica that is not the leader yet
+func NewProbes() *Probes {
+	return &Probes{
+		CheckTimeout: 2 * time.Second,
+		Dial:         net.DialTimeout,
+	}
+}
+
+// SetManager is called when the replica creates the manager, before the leader election
+func (p *Probes) SetManager(mgr manager.Manager) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.mgr = mgr
+}
+
+// SetLeading is called when the replica becomes the leader and starts the controllers
+func (p *Probes) SetLeading() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.leading = true
+}
+
+func (p *Probes) getManager() manager.Manager {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.mgr
+}
+
+func (p *Probes) getLeaderManager() manager.Manager {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.leading {
+		return nil
+	}
+	return p.mgr
+}
+
+// Handler returns the http handler of /healthz and /readyz
+func (p *Probes) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", http.StripPrefix("/healthz", &healthz.Handler{Checks: map[string]healthz.Checker{
+		"ping": healthz.Ping,
+	}}))
+	mux.Handle("/readyz", http.StripPrefix("/readyz", &healthz.Handler{Checks: map[string]healthz.Checker{
+		"cache-sync":      p.CheckCacheSync,
+		"noobaa-rpc-port": p.CheckNooBaaRPCPort,
+	}}))
+	return mux
+}
+
+// Serve listens on the address and serves the probes until the process exits
+func (p *Probes) Serve(addr string) {
+	log.Infof("Serving health probes on %s", addr)
+	if err := http.ListenAndServe(addr, p.Handler()); err != nil {
+		log.Fatalf("Failed to serve health probes: %s", err)
+	}
+}
+
+// CheckCacheSync fails while the informers cache of the replica is not synced
+func (p *Probes) CheckCacheSync(req *http.Request) error {
+	mgr := p.getManager()
+	if mgr == nil {
+		return nil
+	}
+	stop := make(chan struct{})
+	timer := time.AfterFunc(p.CheckTimeout, func() { close(stop) })
+	defer timer.Stop()
+	if !mgr.GetCache().WaitForCacheSync(stop) {
+		return fmt.Errorf("informers cache is not synced")
+	}
+	return nil
+}
+
+// CheckNooBaaRPCPort fails when the leader cannot open a tcp connection to the rpc port of the system
+// in the operator namespace, when that system is ready.
+// It only checks that the port accepts connections and not that the rpc server answers requests.
+// In cluster-wide mode the systems of the watched namespaces are not checked, so that one unreachable
+// tenant system does not make the operator unready - their state is reported by their own status.
+func (p *Probes) CheckNooBaaRPCPort(req *http.Request) error {
+	mgr := p.getLeaderManager()
+	if mgr == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.CheckTimeout)
+	defer cancel()
+	sys := &nbv1.NooBaa{}
+	key := client.ObjectKey{Namespace: options.Namespace, Name: options.SystemName}
+	if err := mgr.GetClient().Get(ctx, key, sys); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Warnf("Readiness: failed to get system %s: %s", key, err)
+		}
+		return nil
+	}
+	return p.CheckSystemRPCPort(sys)
+}
+
+// CheckSystemRPCPort opens and closes a tcp connection to the rpc port of a ready system
+func (p *Probes) CheckSystemRPCPort(sys *nbv1.NooBaa) error {
+	addr := SystemRPCAddress(sys)
+	if sys.Status.Phase != nbv1.SystemPhaseReady || addr == "" {
+		return nil
+	}
+	conn, err := p.Dial("tcp", addr, p.CheckTimeout)
+	if err != nil {
+		return fmt.Errorf("system %s/%s rpc port %s is unreachable: %v", sys.Namespace, sys.Name, addr, err)
+	}
+	return conn.Close()
+}
+
+// SystemRPCAddress returns the host:port of the mgmt service from the system status,
+// or an empty string when the status does not have it yet
+func SystemRPCAddress(sys *nbv1.NooBaa) string {
+	if sys.Status.Services == nil || len(sys.Status.Services.ServiceMgmt.InternalDNS) == 0 {
+		return ""
+	}
+	u, err := url.Parse(sys.Status.Services.ServiceMgmt.InternalDNS[0])
+	if err != nil || u.Port() == "" {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,61 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+)
+
+func TestSystemRPCAddress(t *testing.T) {
+	sys := &nbv1.NooBaa{}
+	if addr := SystemRPCAddress(sys); addr != "" {
+		t.Errorf("expected no address without services status, got %q", addr)
+	}
+	sys.Status.Services = &nbv1.ServicesStatus{}
+	sys.Status.Services.ServiceMgmt.InternalDNS = []string{"https://noobaa-mgmt.tenant-a.svc:443"}
+	if addr := SystemRPCAddress(sys); addr != "noobaa-mgmt.tenant-a.svc:443" {
+		t.Errorf("expected the mgmt service address, got %q", addr)
+	}
+}
+
+func TestCheckSystemRPCPort(t *testing.T) {
+	dialed := ""
+	p := NewProbes()
+	p.Dial = func(network string, addr string, timeout time.Duration) (net.Conn, error) {
+		dialed = addr
+		return nil, fmt.Errorf("connection refused")
+	}
+	sys := &nbv1.NooBaa{}
+	sys.Status.Services = &nbv1.ServicesStatus{}
+	sys.Status.Services.ServiceMgmt.InternalDNS = []string{"https://noobaa-mgmt.noobaa.svc:443"}
+
+	sys.Status.Phase = nbv1.SystemPhaseConfiguring
+	if err := p.CheckSystemRPCPort(sys); err != nil || dialed != "" {
+		t.Errorf("expected a system that is not ready to be skipped, got %v %q", err, dialed)
+	}
+
+	sys.Status.Phase = nbv1.SystemPhaseReady
+	if err := p.CheckSystemRPCPort(sys); err == nil || dialed != "noobaa-mgmt.noobaa.svc:443" {
+		t.Errorf("expected an unreachable ready system to fail, got %v %q", err, dialed)
+	}
+}
+
+func TestProbesStandby(t *testing.T) {
+	server := httptest.NewServer(NewProbes().Handler())
+	defer server.Close()
+	for _, path := range []string{"/healthz", "/readyz"} {
+		res, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected a standby replica to be ok, got %s", path, res.Status)
+		}
+	}
+}
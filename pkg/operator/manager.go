@@ -1,8 +1,13 @@
 package operator
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"sync"
 
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
 	"github.com/noobaa/noobaa-operator/v2/pkg/system"
 	"github.com/noobaa/noobaa-operator/v2/pkg/version"
@@ -16,7 +21,16 @@ import (
 	"github.com/noobaa/noobaa-operator/v2/pkg/controller"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 
-	"github.com/operator-framework/operator-lib/leader"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 )
@@ -28,25 +42,188 @@ var (
 	log               = util.Logger()
 )
 
+// leaderElectionID is the name of the lease that the operator replicas compete on
+const leaderElectionID = "noobaa-operator-lock"
+
 // RunOperator is the main function of the operator but it is called from a cobra.Command
 func RunOperator(cmd *cobra.Command, args []string) {
 
 	version.RunVersion(cmd, args)
 
+	if _, err := util.SetLogLevel(options.OperatorLogLevel); err != nil {
+		log.Fatalf("Invalid operator log level: %s", err)
+	}
+	if _, err := util.SetLogFormat(options.OperatorLogFormat); err != nil {
+		log.Fatalf("Invalid operator log format: %s", err)
+	}
+
 	config := util.KubeConfig()
+	leaderElect, _ := cmd.Flags().GetBool("leader-elect")
+	probeAddr, _ := cmd.Flags().GetString("health-probe-bind-address")
+
+	// Serve the probes before the election so that standby replicas are live and ready
+	probes := NewProbes()
+	if probeAddr != "" && probeAddr != "0" {
+		go probes.Serve(probeAddr)
+	}
+
+	stopChan := signals.SetupSignalHandler()
+	mgr := newManager(cmd, args, config)
+	probes.SetManager(mgr)
+	if !leaderElect {
+		probes.SetLeading()
+		startManager(mgr, stopChan)
+		return
+	}
+
+	// Standby replicas keep the cache synced so that a new leader starts its controllers without reloading it
+	startStandbyCache(mgr, stopChan)
+
+	ctx, cancel := context.WithCancel(util.Context())
+	go func() {
+		<-stopChan
+		cancel()
+	}()
+	RunLeaderElection(ctx, cmd, config, func(ctx context.Context) {
+		probes.SetLeading()
+		startManager(mgr, ctx.Done())
+	})
+}
 
-	// Become the leader before proceeding
-	err := leader.Become(util.Context(), "noobaa-operator-lock")
+// RunLeaderElection blocks until this replica holds the operator lease and then calls run.
+// The lease is renewed while run is running, and the process exits if it is lost,
+// so that the replica restarts as a standby and does not reconcile concurrently with the new leader.
+func RunLeaderElection(ctx context.Context, cmd *cobra.Command, config *rest.Config, run func(ctx context.Context)) {
+	leaseDuration, _ := cmd.Flags().GetDuration("leader-elect-lease-duration")
+	renewDeadline, _ := cmd.Flags().GetDuration("leader-elect-renew-deadline")
+	retryPeriod, _ := cmd.Flags().GetDuration("leader-elect-retry-period")
+
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		hostname, err := os.Hostname()
+		util.Panic(err)
+		identity = hostname
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Failed to become leader: %s", err)
+		log.Fatalf("Failed to create leader election client: %s", err)
+	}
+	lock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		options.Namespace,
+		leaderElectionID,
+		clientset.CoreV1(),
+		clientset.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity},
+	)
+	if err != nil {
+		log.Fatalf("Failed to create leader election lock: %s", err)
 	}
 
-	// Create a new Cmd to provide shared dependencies and start components
-	mgr, err := manager.New(config, manager.Options{
+	log.Infof("Waiting to become the leader with lease %s/%s as %s", options.Namespace, leaderElectionID, identity)
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            leaderElectionID,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Infof("Became the leader as %s", identity)
+				run(ctx)
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					log.Infof("Released the leader lease on shutdown")
+					return
+				}
+				log.Fatalf("Lost the leader lease, exiting to restart as a standby")
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					log.Infof("Current leader is %s", leader)
+				}
+			},
+		},
+	})
+}
+
+// CachedKinds are the kinds that the controllers watch in the system namespaces,
+// so their informers are always running and reads of these kinds are served from the cache.
+// Secrets are not cached to keep them out of the operator memory and to always read fresh credentials.
+var CachedKinds = []runtime.Object{
+	&nbv1.NooBaa{},
+	&nbv1.BackingStore{},
+	&nbv1.NamespaceStore{},
+	&nbv1.BucketClass{},
+	&appsv1.StatefulSet{},
+	&appsv1.Deployment{},
+	&corev1.Service{},
+	&corev1.Pod{},
+	&corev1.PersistentVolumeClaim{},
+	&autoscalingv1.HorizontalPodAutoscaler{},
+}
+
+// newCountingClient is the default manager client that reads from the cache and writes to the api server,
+// wrapped to count its requests in the kube requests metric
+func newCountingClient(cache cache.Cache, config *rest.Config, clientOptions client.Options) (client.Client, error) {
+	c, err := manager.DefaultNewClient(cache, config, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewCountingClient(c, metrics.KubeSourceCache), nil
+}
+
+// standbyCache is the manager cache that is started before the replica becomes the leader.
+// The manager starts its cache again when it is started, so only the first start runs the informers
+// and the later start only waits to be stopped.
+type standbyCache struct {
+	cache.Cache
+	once sync.Once
+}
+
+// Start runs the informers on the first call and waits to be stopped on the next calls
+func (c *standbyCache) Start(stopChan <-chan struct{}) error {
+	first := false
+	c.once.Do(func() { first = true })
+	if !first {
+		<-stopChan
+		return nil
+	}
+	return c.Cache.Start(stopChan)
+}
+
+// newManager creates the manager with all the controllers.
+// The controllers and runnables are started by startManager once the replica is the leader.
+func newManager(cmd *cobra.Command, args []string, config *rest.Config) manager.Manager {
+
+	newCache := cache.New
+
+	mgrOptions := manager.Options{
 		Namespace:          options.Namespace,
 		MapperProvider:     util.MapperProvider, // restmapper.NewDynamicRESTMapper,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
-	})
+		NewClient:          newCountingClient,
+	}
+
+	// A cluster-wide operator caches and reconciles the systems of all the watched namespaces
+	if options.ClusterWide() {
+		log.Infof("Watching namespaces %v", options.WatchedNamespaces())
+		mgrOptions.Namespace = ""
+		newCache = cache.MultiNamespacedCacheBuilder(options.WatchedNamespaces())
+	}
+	mgrOptions.NewCache = func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		c, err := newCache(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &standbyCache{Cache: c}, nil
+	}
+
+	// Create a new Cmd to provide shared dependencies and start components
+	mgr, err := manager.New(config, mgrOptions)
 	if err != nil {
 		log.Fatalf("Failed to create manager: %s", err)
 	}
@@ -63,6 +240,9 @@ func RunOperator(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed AddToManager: %s", err)
 	}
 
+	// Serve the reads of the watched kinds from the manager cache instead of the api server
+	util.SetKubeCache(mgr.GetCache(), options.WatchedNamespaces(), CachedKinds...)
+
 	util.Panic(mgr.Add(manager.RunnableFunc(func(stopChan <-chan struct{}) error {
 		system.RunOperatorCreate(cmd, args)
 		<-stopChan
@@ -75,9 +255,28 @@ func RunOperator(cmd *cobra.Command, args []string) {
 	// 	log.Warnf("Failed ExposeMetricsPort: %s", err)
 	// }
 
-	// Start the manager
+	return mgr
+}
+
+// startStandbyCache creates the informers of the cached kinds and starts the manager cache
+// while the replica waits for the lease
+func startStandbyCache(mgr manager.Manager, stopChan <-chan struct{}) {
+	for _, obj := range CachedKinds {
+		if _, err := mgr.GetCache().GetInformer(util.Context(), obj); err != nil {
+			log.Fatalf("Failed to create the informer of %T: %s", obj, err)
+		}
+	}
+	go func() {
+		if err := mgr.GetCache().Start(stopChan); err != nil {
+			log.Fatalf("Cache exited non-zero: %s", err)
+		}
+	}()
+}
+
+// startManager starts the controllers and runnables of the manager and runs it until stopped
+func startManager(mgr manager.Manager, stopChan <-chan struct{}) {
 	log.Info("Starting the Operator ...")
-	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(stopChan); err != nil {
 		log.Fatalf("Manager exited non-zero: %s", err)
 	}
 }
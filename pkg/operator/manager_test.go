@@ -0,0 +1,43 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+type countingCache struct {
+	cache.Cache
+	started chan struct{}
+}
+
+func (c *countingCache) Start(stopChan <-chan struct{}) error {
+	close(c.started)
+	<-stopChan
+	return nil
+}
+
+func TestStandbyCacheStartsOnce(t *testing.T) {
+	inner := &countingCache{started: make(chan struct{})}
+	c := &standbyCache{Cache: inner}
+	standbyStop := make(chan struct{})
+	defer close(standbyStop)
+	go func() { _ = c.Start(standbyStop) }()
+	<-inner.started
+
+	// the manager start of a cache that already runs waits to be stopped without starting it again,
+	// which would close the started channel twice
+	managerStop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- c.Start(managerStop) }()
+	close(managerStop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected the second start to return nil, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the second start to return when stopped")
+	}
+}
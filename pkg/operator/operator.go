@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	secv1 "github.com/openshift/api/security/v1"
 	"github.com/spf13/cobra"
 	appsv1 "k8s.io/api/apps/v1"
@@ -84,6 +86,11 @@ func CmdRun() *cobra.Command {
 		Short: "Runs the noobaa-operator",
 		Run:   RunOperator,
 	}
+	cmd.Flags().Bool("leader-elect", true, "Elect a leader with a lease so that standby replicas take over when it is lost")
+	cmd.Flags().Duration("leader-elect-lease-duration", 15*time.Second, "Duration that standby replicas wait before taking over an expired lease")
+	cmd.Flags().Duration("leader-elect-renew-deadline", 10*time.Second, "Duration that the leader retries renewing the lease before giving up")
+	cmd.Flags().Duration("leader-elect-retry-period", 2*time.Second, "Interval between attempts to acquire or renew the lease")
+	cmd.Flags().String("health-probe-bind-address", ":8081", "Address to serve the /healthz and /readyz probes (0 to disable)")
 	return cmd
 }
 
@@ -101,6 +108,17 @@ func RunInstall(cmd *cobra.Command, args []string) {
 	util.KubeCreateSkipExisting(c.ClusterRoleBinding)
 	util.KubeCreateOptional(c.SecurityContextConstraints)
 	util.KubeCreateOptional(c.SCCEndpoint)
+	util.KubeCreateSkipExisting(c.ServiceMetrics)
+	util.KubeCreateOptional(c.ServiceMonitor)
+	for _, t := range c.Tenants {
+		util.KubeCreateSkipExisting(t.NS)
+		util.KubeCreateSkipExisting(t.SA)
+		util.KubeCreateSkipExisting(t.SAEndpoint)
+		util.KubeCreateSkipExisting(t.Role)
+		util.KubeCreateSkipExisting(t.RoleEndpoint)
+		util.KubeCreateSkipExisting(t.RoleBinding)
+		util.KubeCreateSkipExisting(t.RoleBindingEndpoint)
+	}
 	noDeploy, _ := cmd.Flags().GetBool("no-deploy")
 	if !noDeploy {
 		util.KubeCreateSkipExisting(c.Deployment)
@@ -123,8 +141,16 @@ func RunUninstall(cmd *cobra.Command, args []string) {
 		util.KubeDelete(c.SCCEndpoint)
 		util.KubeDelete(c.SecurityContextConstraints)
 	}
+	util.KubeDelete(c.ServiceMonitor)
+	util.KubeDelete(c.ServiceMetrics)
 	util.KubeDelete(c.ClusterRoleBinding)
 	util.KubeDelete(c.ClusterRole)
+	for _, t := range c.Tenants {
+		util.KubeDelete(t.RoleBindingEndpoint)
+		util.KubeDelete(t.RoleBinding)
+		util.KubeDelete(t.RoleEndpoint)
+		util.KubeDelete(t.Role)
+	}
 	util.KubeDelete(c.RoleBindingEndpoint)
 	util.KubeDelete(c.RoleBinding)
 	util.KubeDelete(c.RoleEndpoint)
@@ -166,6 +192,14 @@ func RunStatus(cmd *cobra.Command, args []string) {
 	util.KubeCheck(c.RoleBindingEndpoint)
 	util.KubeCheck(c.ClusterRole)
 	util.KubeCheck(c.ClusterRoleBinding)
+	util.KubeCheck(c.ServiceMetrics)
+	util.KubeCheckOptional(c.ServiceMonitor)
+	for _, t := range c.Tenants {
+		util.KubeCheck(t.Role)
+		util.KubeCheck(t.RoleEndpoint)
+		util.KubeCheck(t.RoleBinding)
+		util.KubeCheck(t.RoleBindingEndpoint)
+	}
 	noDeploy, _ := cmd.Flags().GetBool("no-deploy")
 	if !noDeploy {
 		util.KubeCheck(c.Deployment)
@@ -185,6 +219,15 @@ func RunYaml(cmd *cobra.Command, args []string) {
 	util.Panic(p.PrintObj(c.RoleBindingEndpoint, os.Stdout))
 	util.Panic(p.PrintObj(c.ClusterRole, os.Stdout))
 	util.Panic(p.PrintObj(c.ClusterRoleBinding, os.Stdout))
+	util.Panic(p.PrintObj(c.ServiceMetrics, os.Stdout))
+	for _, t := range c.Tenants {
+		util.Panic(p.PrintObj(t.SA, os.Stdout))
+		util.Panic(p.PrintObj(t.SAEndpoint, os.Stdout))
+		util.Panic(p.PrintObj(t.Role, os.Stdout))
+		util.Panic(p.PrintObj(t.RoleEndpoint, os.Stdout))
+		util.Panic(p.PrintObj(t.RoleBinding, os.Stdout))
+		util.Panic(p.PrintObj(t.RoleBindingEndpoint, os.Stdout))
+	}
 	noDeploy, _ := cmd.Flags().GetBool("no-deploy")
 	if !noDeploy {
 		util.Panic(p.PrintObj(c.Deployment, os.Stdout))
@@ -205,10 +248,78 @@ type Conf struct {
 	SecurityContextConstraints *secv1.SecurityContextConstraints
 	SCCEndpoint                *secv1.SecurityContextConstraints
 	Deployment                 *appsv1.Deployment
+	ServiceMetrics             *corev1.Service
+	ServiceMonitor             *monitoringv1.ServiceMonitor
+	Tenants                    []*TenantConf
+}
+
+// TenantConf holds the objects that a cluster-wide operator needs in every watched namespace
+// to reconcile the system in it and run its pods
+type TenantConf struct {
+	NS                  *corev1.Namespace
+	SA                  *corev1.ServiceAccount
+	SAEndpoint          *corev1.ServiceAccount
+	Role                *rbacv1.Role
+	RoleEndpoint        *rbacv1.Role
+	RoleBinding         *rbacv1.RoleBinding
+	RoleBindingEndpoint *rbacv1.RoleBinding
 }
 
 // LoadOperatorConf loads and initializes all the objects needed to install the operator
 func LoadOperatorConf(cmd *cobra.Command) *Conf {
+	c := LoadOperatorConfForNamespace(options.Namespace)
+	if options.ClusterWide() {
+		c.SetWatchNamespaces(options.WatchNamespaces)
+	}
+	return c
+}
+
+// SetWatchNamespaces configures the operator to run cluster-wide and reconcile the systems in the
+// given namespaces. The operator namespace is always watched and is first in WATCH_NAMESPACE.
+// Every other namespace gets the service accounts of the system pods and the operator roles,
+// and the role binding also binds the operator service account from the operator namespace.
+func (c *Conf) SetWatchNamespaces(namespaces []string) {
+	ns := c.Deployment.Namespace
+	watched := []string{ns}
+	c.Tenants = nil
+	for _, tns := range namespaces {
+		if util.Contains(tns, watched) {
+			continue
+		}
+		watched = append(watched, tns)
+		t := LoadOperatorConfForNamespace(tns)
+		t.RoleBinding.Subjects = append(t.RoleBinding.Subjects, rbacv1.Subject{
+			Kind:      "ServiceAccount",
+			Name:      c.SA.Name,
+			Namespace: ns,
+		})
+		c.Tenants = append(c.Tenants, &TenantConf{
+			NS:                  t.NS,
+			SA:                  t.SA,
+			SAEndpoint:          t.SAEndpoint,
+			Role:                t.Role,
+			RoleEndpoint:        t.RoleEndpoint,
+			RoleBinding:         t.RoleBinding,
+			RoleBindingEndpoint: t.RoleBindingEndpoint,
+		})
+		c.SecurityContextConstraints.Users = append(c.SecurityContextConstraints.Users,
+			fmt.Sprintf("system:serviceaccount:%s:%s", tns, t.SA.Name))
+		c.SCCEndpoint.Users = append(c.SCCEndpoint.Users,
+			fmt.Sprintf("system:serviceaccount:%s:%s", tns, t.SAEndpoint.Name))
+	}
+
+	env := c.Deployment.Spec.Template.Spec.Containers[0].Env
+	for i := range env {
+		if env[i].Name == "WATCH_NAMESPACE" {
+			env[i].Value = strings.Join(watched, ",")
+			env[i].ValueFrom = nil
+		}
+	}
+}
+
+// LoadOperatorConfForNamespace loads and initializes all the objects needed to install the operator
+// in the given namespace, which can also be a placeholder when rendering the objects to templates
+func LoadOperatorConfForNamespace(ns string) *Conf {
 	c := &Conf{}
 
 	c.NS = util.KubeObject(bundle.File_deploy_namespace_yaml).(*corev1.Namespace)
@@ -223,32 +334,40 @@ func LoadOperatorConf(cmd *cobra.Command) *Conf {
 	c.SecurityContextConstraints = util.KubeObject(bundle.File_deploy_scc_yaml).(*secv1.SecurityContextConstraints)
 	c.SCCEndpoint = util.KubeObject(bundle.File_deploy_scc_endpoint_yaml).(*secv1.SecurityContextConstraints)
 	c.Deployment = util.KubeObject(bundle.File_deploy_operator_yaml).(*appsv1.Deployment)
+	c.ServiceMetrics = util.KubeObject(bundle.File_deploy_service_operator_metrics_yaml).(*corev1.Service)
+	c.ServiceMonitor = util.KubeObject(bundle.File_deploy_servicemonitor_operator_yaml).(*monitoringv1.ServiceMonitor)
 
-	c.NS.Name = options.Namespace
-	c.SA.Namespace = options.Namespace
-	c.SAEndpoint.Namespace = options.Namespace
-	c.Role.Namespace = options.Namespace
-	c.RoleEndpoint.Namespace = options.Namespace
-	c.RoleBinding.Namespace = options.Namespace
-	c.RoleBindingEndpoint.Namespace = options.Namespace
-	c.ClusterRole.Namespace = options.Namespace
-	c.Deployment.Namespace = options.Namespace
+	c.NS.Name = ns
+	c.SA.Namespace = ns
+	c.SAEndpoint.Namespace = ns
+	c.Role.Namespace = ns
+	c.RoleEndpoint.Namespace = ns
+	c.RoleBinding.Namespace = ns
+	c.RoleBindingEndpoint.Namespace = ns
+	c.ClusterRole.Namespace = ns
+	c.Deployment.Namespace = ns
+	c.ServiceMetrics.Namespace = ns
+	c.ServiceMonitor.Namespace = ns
 
-	c.ClusterRole.Name = options.SubDomainNS()
+	c.ClusterRole.Name = ns + ".noobaa.io"
 	c.ClusterRoleBinding.Name = c.ClusterRole.Name
 	c.ClusterRoleBinding.RoleRef.Name = c.ClusterRole.Name
 	for i := range c.ClusterRoleBinding.Subjects {
-		c.ClusterRoleBinding.Subjects[i].Namespace = options.Namespace
+		c.ClusterRoleBinding.Subjects[i].Namespace = ns
 	}
 
 	c.Deployment.Spec.Template.Spec.Containers[0].Image = options.OperatorImage
+	if options.OperatorReplicas > 1 {
+		replicas := int32(options.OperatorReplicas)
+		c.Deployment.Spec.Replicas = &replicas
+	}
 	if options.ImagePullSecret != "" {
 		c.Deployment.Spec.Template.Spec.ImagePullSecrets =
 			[]corev1.LocalObjectReference{{Name: options.ImagePullSecret}}
 	}
 
-	c.SecurityContextConstraints.Users[0] = fmt.Sprintf("system:serviceaccount:%s:%s", options.Namespace, c.SA.Name)
-	c.SCCEndpoint.Users[0] = fmt.Sprintf("system:serviceaccount:%s:%s", options.Namespace, c.SAEndpoint.Name)
+	c.SecurityContextConstraints.Users[0] = fmt.Sprintf("system:serviceaccount:%s:%s", ns, c.SA.Name)
+	c.SCCEndpoint.Users[0] = fmt.Sprintf("system:serviceaccount:%s:%s", ns, c.SAEndpoint.Name)
 	return c
 }
 
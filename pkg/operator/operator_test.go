@@ -0,0 +1,85 @@
+package operator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+)
+
+func TestSetWatchNamespaces(t *testing.T) {
+	c := LoadOperatorConfForNamespace("noobaa-operator")
+	c.SetWatchNamespaces([]string{"tenant-a", "noobaa-operator", "tenant-b", "tenant-a"})
+
+	if len(c.Tenants) != 2 || c.Tenants[0].NS.Name != "tenant-a" || c.Tenants[1].NS.Name != "tenant-b" {
+		t.Fatalf("expected tenants for tenant-a and tenant-b, got %+v", c.Tenants)
+	}
+
+	tenant := c.Tenants[0]
+	if tenant.SA.Namespace != "tenant-a" || tenant.Role.Namespace != "tenant-a" || tenant.RoleBinding.Namespace != "tenant-a" {
+		t.Errorf("expected the tenant objects in the tenant namespace")
+	}
+	found := false
+	for _, s := range tenant.RoleBinding.Subjects {
+		if s.Name == c.SA.Name && s.Namespace == "noobaa-operator" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the tenant role binding to bind the operator service account, got %+v", tenant.RoleBinding.Subjects)
+	}
+	if !util.Contains("system:serviceaccount:tenant-b:noobaa", c.SecurityContextConstraints.Users) {
+		t.Errorf("expected the tenant service account in the scc users, got %v", c.SecurityContextConstraints.Users)
+	}
+
+	value := ""
+	for _, env := range c.Deployment.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "WATCH_NAMESPACE" {
+			if env.ValueFrom != nil {
+				t.Errorf("expected WATCH_NAMESPACE to have a value instead of a field ref")
+			}
+			value = env.Value
+		}
+	}
+	expected := []string{"noobaa-operator", "tenant-a", "tenant-b"}
+	if watched := util.ParseWatchNamespaces(value); !reflect.DeepEqual(watched, expected) {
+		t.Errorf("expected WATCH_NAMESPACE %v, got %q", expected, value)
+	}
+}
+
+func TestOperatorMetricsService(t *testing.T) {
+	c := LoadOperatorConfForNamespace("noobaa-operator")
+	c.SetWatchNamespaces([]string{"tenant-a"})
+
+	if c.ServiceMetrics.Namespace != "noobaa-operator" || c.ServiceMonitor.Namespace != "noobaa-operator" {
+		t.Errorf("expected the metrics service and monitor in the operator namespace")
+	}
+	podLabels := c.Deployment.Spec.Template.Labels
+	for k, v := range c.ServiceMetrics.Spec.Selector {
+		if podLabels[k] != v {
+			t.Errorf("expected the metrics service selector %s=%s to select the operator pods %v", k, v, podLabels)
+		}
+	}
+	if c.ServiceMetrics.Spec.Ports[0].Port != metricsPort {
+		t.Errorf("expected the metrics service on port %d, got %d", metricsPort, c.ServiceMetrics.Spec.Ports[0].Port)
+	}
+	for _, l := range c.ServiceMonitor.Spec.Selector.MatchLabels {
+		if c.ServiceMetrics.Labels["noobaa-operator-svc"] != l {
+			t.Errorf("expected the service monitor to select the metrics service")
+		}
+	}
+}
+
+func TestParseWatchNamespaces(t *testing.T) {
+	cases := map[string][]string{
+		"":              {},
+		"noobaa":        {"noobaa"},
+		"a, b,,a,c ,":   {"a", "b", "c"},
+		" noobaa , b ,": {"noobaa", "b"},
+	}
+	for value, expected := range cases {
+		if watched := util.ParseWatchNamespaces(value); !reflect.DeepEqual(watched, expected) {
+			t.Errorf("ParseWatchNamespaces(%q) = %v, expected %v", value, watched, expected)
+		}
+	}
+}
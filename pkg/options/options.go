@@ -1,6 +1,9 @@
 package options
 
 import (
+	"strings"
+	"time"
+
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 	"github.com/noobaa/noobaa-operator/v2/version"
 
@@ -30,10 +33,6 @@ const (
 	ContainerImageRepo = "noobaa-core"
 	// ContainerImageTag is the tag of the default image url
 	ContainerImageTag = "5.8.0-20210519"
-	// ContainerImageSemverLowerBound is the lower bound for supported image versions
-	ContainerImageSemverLowerBound = "5.0.0"
-	// ContainerImageSemverUpperBound is the upper bound for supported image versions
-	ContainerImageSemverUpperBound = "6.0.0"
 	// ContainerImageName is the default image name without the tag/version
 	ContainerImageName = ContainerImageOrg + "/" + ContainerImageRepo
 	// ContainerImage is the full default image url
@@ -55,10 +54,18 @@ const (
 // so we may consider to use current namespace.
 var Namespace = "noobaa"
 
+// WatchNamespaces are the namespaces that a cluster-wide operator watches for noobaa systems.
+// It is empty when the operator watches only its own namespace.
+// The operator reads it from a comma separated WATCH_NAMESPACE that starts with its own namespace.
+var WatchNamespaces = []string{}
+
 // OperatorImage is the container image url built from https://github.com/noobaa/noobaa-operator
 // it can be overridden for testing or different registry locations.
 var OperatorImage = "noobaa/noobaa-operator:" + version.Version
 
+// OperatorReplicas is the number of operator pods, where one is the elected leader and the rest are warm standby
+var OperatorReplicas = 1
+
 // NooBaaImage is the container image url built from https://github.com/noobaa/noobaa-core
 // it can be overridden for testing or different registry locations.
 var NooBaaImage = ContainerImage
@@ -103,14 +110,57 @@ var ImagePullSecret = ""
 // pod)
 var MiniEnv = false
 
+// RPCPingInterval is the interval between keepalive pings sent on websocket rpc connections
+// a value of 0 disables the client-initiated pings.
+var RPCPingInterval = 10 * time.Second
+
+// RPCMaxMissedPings is the number of consecutive pings without a pong
+// after which a websocket rpc connection is considered dead and closed.
+var RPCMaxMissedPings = 3
+
+// SystemStatusResyncPeriod is the interval between reads of the system status by the operator,
+// which keep the stores and bucket classes status up to date even if change notifications from noobaa-core are missed.
+var SystemStatusResyncPeriod = 5 * time.Minute
+
+// OperatorLogLevel is the log level of the operator process.
+// The logging spec of a NooBaa can override it only for the logs of reconciling that system.
+var OperatorLogLevel = "debug"
+
+// OperatorLogFormat is the log format of the operator process, text or json.
+// The logging spec of a NooBaa can override it only for the logs of reconciling that system.
+var OperatorLogFormat = "text"
+
 // SubDomainNS returns a unique subdomain for the namespace
 func SubDomainNS() string {
-	return Namespace + ".noobaa.io"
+	return SubDomainNSFor(Namespace)
+}
+
+// SubDomainNSFor returns a unique subdomain for the given system namespace
+func SubDomainNSFor(ns string) string {
+	return ns + ".noobaa.io"
 }
 
 // ObjectBucketProvisionerName returns the provisioner name to be used in storage classes for OB/OBC
 func ObjectBucketProvisionerName() string {
-	return SubDomainNS() + "/obc"
+	return ObjectBucketProvisionerNameFor(Namespace)
+}
+
+// ObjectBucketProvisionerNameFor returns the OB/OBC provisioner name of the given system namespace
+func ObjectBucketProvisionerNameFor(ns string) string {
+	return SubDomainNSFor(ns) + "/obc"
+}
+
+// ClusterWide returns true when the operator watches other namespaces than its own
+func ClusterWide() bool {
+	return len(WatchNamespaces) > 0
+}
+
+// WatchedNamespaces returns the namespaces where the operator reconciles noobaa systems
+func WatchedNamespaces() []string {
+	if ClusterWide() {
+		return WatchNamespaces
+	}
+	return []string{Namespace}
 }
 
 // FlagSet defines the
@@ -118,6 +168,14 @@ var FlagSet = pflag.NewFlagSet("noobaa", pflag.ContinueOnError)
 
 func init() {
 	ns, _ := util.GetWatchNamespace()
+	if strings.Contains(ns, ",") {
+		// a cluster-wide operator runs in its own namespace, which is the first in the list
+		WatchNamespaces = util.ParseWatchNamespaces(ns)
+		ns = util.CurrentNamespace()
+		if ns == "" && len(WatchNamespaces) > 0 {
+			ns = WatchNamespaces[0]
+		}
+	}
 	if ns == "" {
 		ns = util.CurrentNamespace()
 	}
@@ -128,10 +186,18 @@ func init() {
 		&Namespace, "namespace", "n",
 		Namespace, "Target namespace",
 	)
+	FlagSet.StringSliceVar(
+		&WatchNamespaces, "watch-namespaces",
+		WatchNamespaces, "Namespaces that a cluster-wide operator watches for noobaa systems (default only the operator namespace)",
+	)
 	FlagSet.StringVar(
 		&OperatorImage, "operator-image",
 		OperatorImage, "Operator image",
 	)
+	FlagSet.IntVar(
+		&OperatorReplicas, "operator-replicas",
+		OperatorReplicas, "Number of operator replicas, where the replicas other than the elected leader are warm standby",
+	)
 	FlagSet.StringVar(
 		&NooBaaImage, "noobaa-image",
 		NooBaaImage, "NooBaa image",
@@ -164,6 +230,26 @@ func init() {
 		&ImagePullSecret, "image-pull-secret",
 		ImagePullSecret, "Image pull secret (must be in same namespace)",
 	)
+	FlagSet.DurationVar(
+		&RPCPingInterval, "rpc-ping-interval",
+		RPCPingInterval, "Interval between keepalive pings on rpc websocket connections (0 to disable)",
+	)
+	FlagSet.IntVar(
+		&RPCMaxMissedPings, "rpc-max-missed-pings",
+		RPCMaxMissedPings, "Number of missed keepalive pings before an rpc websocket connection is closed",
+	)
+	FlagSet.DurationVar(
+		&SystemStatusResyncPeriod, "system-status-resync-period",
+		SystemStatusResyncPeriod, "Interval between system status reads when relying on change notifications from noobaa-core",
+	)
+	FlagSet.StringVar(
+		&OperatorLogLevel, "operator-log-level",
+		OperatorLogLevel, "The log level of the operator - error, warn, info, debug or trace",
+	)
+	FlagSet.StringVar(
+		&OperatorLogFormat, "operator-log-format",
+		OperatorLogFormat, "The log format of the operator - text or json",
+	)
 	FlagSet.BoolVar(
 		&MiniEnv, "mini",
 		false, "Signal the operator that it is running in a low resource environment",
@@ -61,6 +61,7 @@ func CmdList() *cobra.Command {
 		Short: "List NooBaa PV stores",
 		Run:   RunList,
 	}
+	util.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -142,22 +143,45 @@ func RunDelete(cmd *cobra.Command, args []string) {
 
 // RunList runs a CLI command
 func RunList(cmd *cobra.Command, args []string) {
+	out := util.GetOutput(cmd)
 	nbClient := system.GetNBClient()
 	res, err := nbClient.ReadSystemAPI()
 	util.Panic(err)
 
-	table := (&util.PrintTable{}).AddRow("POOL-NAME", "NUM-VOLUMES", "PV-SIZE-GB")
-	empty := true
+	pools := []nb.PoolInfo{}
 	for i := range res.Pools {
-		p := &res.Pools[i]
-		if p.ResourceType == "HOSTS" {
-			empty = false
-			table.AddRow(
-				p.Name,
-				fmt.Sprintf("%d", p.Hosts.ConfiguredCount),
-				fmt.Sprintf("%d", p.HostInfo.VolumeSize/1024/1024/1024),
-			)
+		if res.Pools[i].ResourceType == "HOSTS" {
+			pools = append(pools, res.Pools[i])
+		}
+	}
+	if out.Print(pools) {
+		return
+	}
+
+	headers := []string{"POOL-NAME", "NUM-VOLUMES", "PV-SIZE-GB"}
+	if out.IsWide() {
+		headers = append(headers, "MODE", "TOTAL", "FREE", "USED")
+	}
+	table := (&util.PrintTable{}).AddRow(headers...)
+	empty := true
+	for i := range pools {
+		p := &pools[i]
+		empty = false
+		row := []string{
+			p.Name,
+			fmt.Sprintf("%d", p.Hosts.ConfiguredCount),
+			fmt.Sprintf("%d", p.HostInfo.VolumeSize/1024/1024/1024),
+		}
+		if out.IsWide() {
+			total, free, used := "", "", ""
+			if p.Storage != nil {
+				total = nb.BigIntToHumanBytes(p.Storage.Total)
+				free = nb.BigIntToHumanBytes(p.Storage.Free)
+				used = nb.BigIntToHumanBytes(p.Storage.Used)
+			}
+			row = append(row, p.Mode, total, free, used)
 		}
+		table.AddRow(row...)
 	}
 
 	if empty {
@@ -0,0 +1,158 @@
+package system
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+	dockerref "github.com/docker/distribution/reference"
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"github.com/noobaa/noobaa-operator/v2/version"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AllowUnsupportedCoreImageAnnotation allows the system to use a core image that is not supported
+// by the operator version. Its value must be the exact image to allow, so that a later change
+// of the image is checked again.
+const AllowUnsupportedCoreImageAnnotation = "noobaa.io/allow-unsupported-core-image"
+
+// CompatibilityEntry is a row of the compatibility matrix, using semver ranges of versions
+type CompatibilityEntry struct {
+	// Operator is the range of operator versions of the row
+	Operator string
+	// Core is the range of core versions that these operator versions were tested with
+	Core string
+	// UpgradeFrom is the range of running core versions that the upgrade path of these operator versions
+	// can upgrade the system from (see UpgradeSplitDB and UpgradeMigrateDB)
+	UpgradeFrom string
+}
+
+// CompatibilityMatrix lists the core versions that every operator version supports.
+// Core versions are compared without the build suffix of the image tag, like 5.8.0-20210519.
+var CompatibilityMatrix = []CompatibilityEntry{
+	{Operator: ">=5.9.0 <5.10.0", Core: ">=5.8.0 <5.10.0", UpgradeFrom: ">=5.0.0 <5.10.0"},
+	{Operator: ">=5.8.0 <5.9.0", Core: ">=5.7.0 <5.9.0", UpgradeFrom: ">=5.0.0 <5.9.0"},
+	{Operator: ">=5.0.0 <5.8.0", Core: ">=5.0.0 <5.8.0", UpgradeFrom: ">=2.0.0 <5.8.0"},
+}
+
+// FindCompatibilityEntry returns the matrix row of the operator version or nil if there is none
+func FindCompatibilityEntry(operatorVersion semver.Version) *CompatibilityEntry {
+	for i := range CompatibilityMatrix {
+		entry := &CompatibilityMatrix[i]
+		if semver.MustParseRange(entry.Operator)(operatorVersion) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// CoreImageVersion returns the version of a core image from its tag,
+// or nil for custom images and tags that are not versions
+func CoreImageVersion(image string) *semver.Version {
+	imageRef, err := dockerref.Parse(image)
+	if err != nil {
+		return nil
+	}
+	named, isNamed := imageRef.(dockerref.NamedTagged)
+	if !isNamed || named.Name() != options.ContainerImageName {
+		return nil
+	}
+	v, err := semver.ParseTolerant(named.Tag())
+	if err != nil {
+		return nil
+	}
+	// the build suffix of the tag would make it a pre-release that is lower than the version
+	v.Pre = nil
+	v.Build = nil
+	return &v
+}
+
+// DecideCompatibility decides if the operator version can use the core image,
+// and upgrade to it from the running core image when it is not empty.
+// allowedImage is the value of the AllowUnsupportedCoreImageAnnotation of the system.
+func DecideCompatibility(operatorVersion string, image string, runningImage string, allowedImage string) *nbv1.CompatibilityStatus {
+	c := &nbv1.CompatibilityStatus{OperatorVersion: operatorVersion}
+
+	opVersion, err := semver.ParseTolerant(operatorVersion)
+	if err != nil {
+		c.Decision = nbv1.CompatibilityDecisionUnknown
+		c.Message = fmt.Sprintf("Operator version %q is not a semver: %s", operatorVersion, err)
+		return c
+	}
+	entry := FindCompatibilityEntry(opVersion)
+	if entry == nil {
+		c.Decision = nbv1.CompatibilityDecisionUnknown
+		c.Message = fmt.Sprintf("Operator version %s has no compatibility matrix entry", operatorVersion)
+		return c
+	}
+	coreVersion := CoreImageVersion(image)
+	if coreVersion == nil {
+		c.Decision = nbv1.CompatibilityDecisionUnknown
+		c.Message = fmt.Sprintf("Using custom image %q, make sure it is compatible with operator version %s", image, operatorVersion)
+		return c
+	}
+	c.CoreVersion = coreVersion.String()
+
+	reason := ""
+	runningVersion := CoreImageVersion(runningImage)
+	if runningVersion != nil {
+		c.RunningCoreVersion = runningVersion.String()
+	}
+	if !semver.MustParseRange(entry.Core)(*coreVersion) {
+		reason = fmt.Sprintf("Core version %s is not supported by operator version %s (supported %s)",
+			coreVersion, operatorVersion, entry.Core)
+	} else if runningVersion != nil && !runningVersion.Equals(*coreVersion) {
+		if coreVersion.LT(*runningVersion) {
+			reason = fmt.Sprintf("Downgrade of the running core version %s to %s is not supported",
+				runningVersion, coreVersion)
+		} else if !semver.MustParseRange(entry.UpgradeFrom)(*runningVersion) {
+			reason = fmt.Sprintf("Upgrade of the running core version %s to %s is not supported by operator version %s (supported from %s)",
+				runningVersion, coreVersion, operatorVersion, entry.UpgradeFrom)
+		}
+	}
+
+	switch {
+	case reason == "":
+		c.Decision = nbv1.CompatibilityDecisionSupported
+		c.Message = fmt.Sprintf("Core version %s is supported by operator version %s", coreVersion, operatorVersion)
+	case allowedImage == image:
+		c.Decision = nbv1.CompatibilityDecisionOverridden
+		c.Message = fmt.Sprintf("%s, allowed by the %s annotation", reason, AllowUnsupportedCoreImageAnnotation)
+	default:
+		c.Decision = nbv1.CompatibilityDecisionRejected
+		c.Message = fmt.Sprintf("%s, set the annotation %s=%s to allow it", reason, AllowUnsupportedCoreImageAnnotation, image)
+	}
+	return c
+}
+
+// CheckCompatibility checks the requested core image against the compatibility matrix
+// and the running core image, reports the decision in the system status,
+// and rejects an unsupported image unless it was allowed by annotation
+func (r *Reconciler) CheckCompatibility(image string) error {
+	runningImage := ""
+	coreApp := r.CoreApp.DeepCopy()
+	if r.NooBaa.Status.Upgrade != nil {
+		// the system runs the previous image until the upgrade succeeds
+		runningImage = r.NooBaa.Status.Upgrade.FromImage
+	} else if util.KubeCheckQuiet(coreApp) && len(coreApp.Spec.Template.Spec.Containers) > 0 {
+		runningImage = coreApp.Spec.Template.Spec.Containers[0].Image
+	}
+
+	prev := r.NooBaa.Status.Compatibility
+	c := DecideCompatibility(version.Version, image, runningImage, r.NooBaa.Annotations[AllowUnsupportedCoreImageAnnotation])
+	r.NooBaa.Status.Compatibility = c
+
+	switch c.Decision {
+	case nbv1.CompatibilityDecisionRejected:
+		return util.NewPersistentError("UnsupportedCoreImage", c.Message)
+	case nbv1.CompatibilityDecisionOverridden:
+		r.Logger.Warnf("⚠️  %s", c.Message)
+		if r.Recorder != nil && (prev == nil || prev.Decision != c.Decision || prev.CoreVersion != c.CoreVersion) {
+			r.Recorder.Event(r.NooBaa, corev1.EventTypeWarning, "UnsupportedCoreImageAllowed", c.Message)
+		}
+	default:
+		r.Logger.Infof("%s", c.Message)
+	}
+	return nil
+}
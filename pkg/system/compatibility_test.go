@@ -0,0 +1,85 @@
+package system
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/version"
+)
+
+func TestCompatibilityMatrix(t *testing.T) {
+	for _, entry := range CompatibilityMatrix {
+		for _, r := range []string{entry.Operator, entry.Core, entry.UpgradeFrom} {
+			if _, err := semver.ParseRange(r); err != nil {
+				t.Errorf("invalid range %q in entry %+v: %s", r, entry, err)
+			}
+		}
+	}
+	// the operator must support its own default core image
+	c := DecideCompatibility(version.Version, options.ContainerImage, "", "")
+	if c.Decision != nbv1.CompatibilityDecisionSupported {
+		t.Errorf("expected the default image %s to be supported by %s, got %+v", options.ContainerImage, version.Version, c)
+	}
+}
+
+func TestCoreImageVersion(t *testing.T) {
+	tests := map[string]string{
+		"noobaa/noobaa-core:5.8.0-20210519":                    "5.8.0",
+		"noobaa/noobaa-core:v5.9":                              "5.9.0",
+		"noobaa/noobaa-core:master-20210519":                   "",
+		"noobaa/noobaa-core@sha256:" + strings.Repeat("a", 64): "",
+		"quay.io/custom/core:5.8.0":                            "",
+	}
+	for image, expected := range tests {
+		got := ""
+		if v := CoreImageVersion(image); v != nil {
+			got = v.String()
+		}
+		if got != expected {
+			t.Errorf("CoreImageVersion(%q) = %q, expected %q", image, got, expected)
+		}
+	}
+}
+
+func TestDecideCompatibility(t *testing.T) {
+	const (
+		supported   = "noobaa/noobaa-core:5.9.0"
+		unsupported = "noobaa/noobaa-core:5.6.0"
+	)
+	tests := []struct {
+		name     string
+		image    string
+		running  string
+		allowed  string
+		decision nbv1.CompatibilityDecision
+	}{
+		{"new system", supported, "", "", nbv1.CompatibilityDecisionSupported},
+		{"same version", supported, supported, "", nbv1.CompatibilityDecisionSupported},
+		{"supported upgrade", supported, "noobaa/noobaa-core:5.8.0-20210519", "", nbv1.CompatibilityDecisionSupported},
+		{"unsupported core", unsupported, "", "", nbv1.CompatibilityDecisionRejected},
+		{"downgrade", "noobaa/noobaa-core:5.8.0", supported, "", nbv1.CompatibilityDecisionRejected},
+		{"upgrade from unsupported", supported, "noobaa/noobaa-core:4.0.0", "", nbv1.CompatibilityDecisionRejected},
+		{"override", unsupported, "", unsupported, nbv1.CompatibilityDecisionOverridden},
+		{"override of another image", unsupported, "", supported, nbv1.CompatibilityDecisionRejected},
+		{"custom image", "quay.io/custom/core:1.0.0", "", "", nbv1.CompatibilityDecisionUnknown},
+	}
+	for _, test := range tests {
+		c := DecideCompatibility("5.9.0", test.image, test.running, test.allowed)
+		if c.Decision != test.decision {
+			t.Errorf("%s: expected %s, got %+v", test.name, test.decision, c)
+		}
+		if c.OperatorVersion != "5.9.0" || c.Message == "" {
+			t.Errorf("%s: expected operator version and message, got %+v", test.name, c)
+		}
+	}
+
+	if c := DecideCompatibility("1.0.0", supported, "", ""); c.Decision != nbv1.CompatibilityDecisionUnknown {
+		t.Errorf("expected an operator without a matrix entry to be unknown, got %+v", c)
+	}
+	if c := DecideCompatibility("5.9.0", unsupported, "", ""); !strings.Contains(c.Message, AllowUnsupportedCoreImageAnnotation) {
+		t.Errorf("expected the rejection to explain the override annotation, got %q", c.Message)
+	}
+}
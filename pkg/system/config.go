@@ -0,0 +1,205 @@
+package system
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConfigJSEnvPrefix prefixes the env variables that noobaa-core loads as config.js overrides
+	ConfigJSEnvPrefix = "CONFIG_JS_"
+
+	// ConfigEnvAnnotation lists the env variables that the config overrides set on a pod,
+	// so that variables which are removed from the config are also removed from the containers
+	ConfigEnvAnnotation = "noobaa.io/config-env"
+)
+
+var (
+	configJSKeyRegexp  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	configEnvVarRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ConfigEnv validates the config overrides of the system spec and returns
+// the env variables to set on the core, endpoint and agent containers
+func ConfigEnv(config *nbv1.ConfigSpec) ([]corev1.EnvVar, error) {
+	env := []corev1.EnvVar{}
+	if config == nil {
+		return env, nil
+	}
+	names := map[string]bool{}
+	add := func(e corev1.EnvVar, source string) error {
+		if names[e.Name] {
+			return fmt.Errorf("Invalid config %s: %s is set more than once", source, e.Name)
+		}
+		names[e.Name] = true
+		env = append(env, e)
+		return nil
+	}
+
+	if config.DisableCompression != nil {
+		names["NOOBAA_DISABLE_COMPRESSION"] = true
+		env = append(env, corev1.EnvVar{
+			Name:  "NOOBAA_DISABLE_COMPRESSION",
+			Value: strconv.FormatBool(*config.DisableCompression),
+		})
+	}
+	if config.ChunkSplitAvgChunk != nil {
+		names[ConfigJSEnvPrefix+"CHUNK_SPLIT_AVG_CHUNK"] = true
+		env = append(env, corev1.EnvVar{
+			Name:  ConfigJSEnvPrefix + "CHUNK_SPLIT_AVG_CHUNK",
+			Value: strconv.FormatInt(*config.ChunkSplitAvgChunk, 10),
+		})
+	}
+
+	keys := []string{}
+	for key := range config.ConfigJS {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !configJSKeyRegexp.MatchString(key) {
+			return nil, fmt.Errorf("Invalid config configJS key %q, expected an upper case config.js name", key)
+		}
+		if err := add(corev1.EnvVar{Name: ConfigJSEnvPrefix + key, Value: config.ConfigJS[key]}, "configJS"); err != nil {
+			return nil, err
+		}
+	}
+
+	reserved := configReservedEnvNames()
+	for _, e := range config.Env {
+		if !configEnvVarRegexp.MatchString(e.Name) {
+			return nil, fmt.Errorf("Invalid config env name %q", e.Name)
+		}
+		if reserved[e.Name] {
+			return nil, fmt.Errorf("Invalid config env %s: the variable is managed by the operator", e.Name)
+		}
+		if strings.HasPrefix(e.Name, ConfigJSEnvPrefix) {
+			return nil, fmt.Errorf("Invalid config env %s: use configJS to override config.js keys", e.Name)
+		}
+		if err := add(e, "env"); err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+// SetConfigEnv sets the config env variables on a container of a pod template,
+// and restores the defaults of the variables that were removed from the config.
+// Returns true if the container env was changed, which rolls out the pods.
+func SetConfigEnv(meta *metav1.ObjectMeta, c *corev1.Container, env []corev1.EnvVar, defaults []corev1.EnvVar) bool {
+	changed := false
+	desired := map[string]bool{}
+	for _, e := range env {
+		desired[e.Name] = true
+		if existing := util.GetEnvVariable(&c.Env, e.Name); existing != nil {
+			if existing.Value != e.Value || !equalEnvSource(existing.ValueFrom, e.ValueFrom) {
+				existing.Value = e.Value
+				existing.ValueFrom = e.ValueFrom
+				changed = true
+			}
+		} else {
+			c.Env = append(c.Env, e)
+			changed = true
+		}
+	}
+
+	for _, name := range strings.Split(meta.Annotations[ConfigEnvAnnotation], ",") {
+		if name == "" || desired[name] {
+			continue
+		}
+		if def := util.GetEnvVariable(&defaults, name); def != nil {
+			existing := util.GetEnvVariable(&c.Env, name)
+			if existing != nil && (existing.Value != def.Value || !equalEnvSource(existing.ValueFrom, def.ValueFrom)) {
+				*existing = *def.DeepCopy()
+				changed = true
+			}
+			continue
+		}
+		for i := range c.Env {
+			if c.Env[i].Name == name {
+				c.Env = append(c.Env[:i], c.Env[i+1:]...)
+				changed = true
+				break
+			}
+		}
+	}
+
+	names := []string{}
+	for _, e := range env {
+		names = append(names, e.Name)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	if len(names) > 0 {
+		meta.Annotations[ConfigEnvAnnotation] = strings.Join(names, ",")
+	} else {
+		delete(meta.Annotations, ConfigEnvAnnotation)
+	}
+	return changed
+}
+
+// IsConfigEnvChanged returns true if a running pod container does not match the config env variables
+func IsConfigEnvChanged(pod *corev1.Pod, c *corev1.Container, env []corev1.EnvVar) bool {
+	desired := map[string]bool{}
+	for _, e := range env {
+		desired[e.Name] = true
+		existing := util.GetEnvVariable(&c.Env, e.Name)
+		if existing == nil || existing.Value != e.Value || !equalEnvSource(existing.ValueFrom, e.ValueFrom) {
+			return true
+		}
+	}
+	for _, name := range strings.Split(pod.Annotations[ConfigEnvAnnotation], ",") {
+		if name != "" && !desired[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// configReservedEnvNames returns the env variables of the core, endpoint and agent containers
+// which the operator manages and cannot be overridden by the config env passthrough
+func configReservedEnvNames() map[string]bool {
+	reserved := map[string]bool{
+		"HTTP_PROXY":  true,
+		"HTTPS_PROXY": true,
+		"NO_PROXY":    true,
+	}
+	containers := []corev1.Container{}
+	coreApp := util.KubeObject(bundle.File_deploy_internal_statefulset_core_yaml).(*appsv1.StatefulSet)
+	containers = append(containers, coreApp.Spec.Template.Spec.Containers...)
+	endpoint := util.KubeObject(bundle.File_deploy_internal_deployment_endpoint_yaml).(*appsv1.Deployment)
+	containers = append(containers, endpoint.Spec.Template.Spec.Containers...)
+	agent := util.KubeObject(bundle.File_deploy_internal_pod_agent_yaml).(*corev1.Pod)
+	containers = append(containers, agent.Spec.Containers...)
+	for _, c := range containers {
+		for _, e := range c.Env {
+			reserved[e.Name] = true
+		}
+	}
+	return reserved
+}
+
+// recordConfigRollout reports that a config change rolls out the pods of a component
+func (r *Reconciler) recordConfigRollout(name string) {
+	r.Logger.Infof("Config changed, rolling out %q", name)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(r.NooBaa, corev1.EventTypeNormal,
+			"ConfigRollout", "Rolling out the config change to %q", name)
+	}
+}
+
+func equalEnvSource(a *corev1.EnvVarSource, b *corev1.EnvVarSource) bool {
+	return reflect.DeepEqual(a, b)
+}
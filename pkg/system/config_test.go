@@ -0,0 +1,91 @@
+package system
+
+import (
+	"testing"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigEnv(t *testing.T) {
+	disable := true
+	chunk := int64(8 * 1024 * 1024)
+	env, err := ConfigEnv(&nbv1.ConfigSpec{
+		DisableCompression: &disable,
+		ChunkSplitAvgChunk: &chunk,
+		ConfigJS:           map[string]string{"MAX_OBJECT_PART_SIZE": "104857600", "AGENT_RPC_PORT": "9999"},
+		Env:                []corev1.EnvVar{{Name: "UV_THREADPOOL_SIZE", Value: "64"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []corev1.EnvVar{
+		{Name: "NOOBAA_DISABLE_COMPRESSION", Value: "true"},
+		{Name: "CONFIG_JS_CHUNK_SPLIT_AVG_CHUNK", Value: "8388608"},
+		{Name: "CONFIG_JS_AGENT_RPC_PORT", Value: "9999"},
+		{Name: "CONFIG_JS_MAX_OBJECT_PART_SIZE", Value: "104857600"},
+		{Name: "UV_THREADPOOL_SIZE", Value: "64"},
+	}
+	if len(env) != len(expected) {
+		t.Fatalf("expected %d env variables, got %v", len(expected), env)
+	}
+	for i := range expected {
+		if env[i].Name != expected[i].Name || env[i].Value != expected[i].Value {
+			t.Errorf("env[%d] = %s=%s, expected %s=%s", i, env[i].Name, env[i].Value, expected[i].Name, expected[i].Value)
+		}
+	}
+}
+
+func TestConfigEnvInvalid(t *testing.T) {
+	chunk := int64(8 * 1024 * 1024)
+	invalid := map[string]*nbv1.ConfigSpec{
+		"lower case config key": {ConfigJS: map[string]string{"max_object_part_size": "1"}},
+		"typed and config key":  {ChunkSplitAvgChunk: &chunk, ConfigJS: map[string]string{"CHUNK_SPLIT_AVG_CHUNK": "1"}},
+		"managed env":           {Env: []corev1.EnvVar{{Name: "MONGODB_URL", Value: "mongodb://other"}}},
+		"proxy env":             {Env: []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://proxy"}}},
+		"config prefix env":     {Env: []corev1.EnvVar{{Name: "CONFIG_JS_DEDUP_ENABLED", Value: "false"}}},
+		"invalid env name":      {Env: []corev1.EnvVar{{Name: "MY-VAR", Value: "1"}}},
+		"duplicate env":         {Env: []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "A", Value: "2"}}},
+	}
+	for name, config := range invalid {
+		if _, err := ConfigEnv(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSetConfigEnv(t *testing.T) {
+	meta := &metav1.ObjectMeta{}
+	defaults := []corev1.EnvVar{{Name: "NOOBAA_DISABLE_COMPRESSION", Value: "false"}}
+	c := &corev1.Container{Env: []corev1.EnvVar{{Name: "NOOBAA_DISABLE_COMPRESSION", Value: "false"}}}
+	env := []corev1.EnvVar{
+		{Name: "NOOBAA_DISABLE_COMPRESSION", Value: "true"},
+		{Name: "CONFIG_JS_MAX_OBJECT_PART_SIZE", Value: "104857600"},
+	}
+
+	if !SetConfigEnv(meta, c, env, defaults) {
+		t.Fatalf("expected the first set to change the container")
+	}
+	if SetConfigEnv(meta, c, env, defaults) {
+		t.Fatalf("expected setting the same config again to keep the container")
+	}
+	pod := &corev1.Pod{ObjectMeta: *meta, Spec: corev1.PodSpec{Containers: []corev1.Container{*c}}}
+	if IsConfigEnvChanged(pod, &pod.Spec.Containers[0], env) {
+		t.Errorf("expected a pod with the config to be up to date")
+	}
+	if !IsConfigEnvChanged(pod, &pod.Spec.Containers[0], env[:1]) {
+		t.Errorf("expected a pod with a removed config variable to be changed")
+	}
+
+	// removing the config restores the defaults and removes the rest
+	if !SetConfigEnv(meta, c, nil, defaults) {
+		t.Fatalf("expected removing the config to change the container")
+	}
+	if len(c.Env) != 1 || c.Env[0].Name != "NOOBAA_DISABLE_COMPRESSION" || c.Env[0].Value != "false" {
+		t.Errorf("expected only the default env to remain, got %v", c.Env)
+	}
+	if _, exists := meta.Annotations[ConfigEnvAnnotation]; exists {
+		t.Errorf("expected the config env annotation to be removed")
+	}
+}
@@ -0,0 +1,277 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"google.golang.org/api/option"
+)
+
+// dryRunCheckData is the content of the object written to the target bucket to check it is writable
+var dryRunCheckData = []byte("noobaa dry-run check\n")
+
+// dryRunDialTimeout is the time to connect to the endpoint of the target before checking it
+const dryRunDialTimeout = 10 * time.Second
+
+// TargetUnreachableError is returned when the endpoint of a target bucket cannot be reached from the CLI host.
+// noobaa-core checks the connection from the cluster, so an endpoint that is reachable only
+// from the cluster, like an in-cluster s3 service, is not a failure of the dry run.
+type TargetUnreachableError struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *TargetUnreachableError) Error() string {
+	return fmt.Sprintf("endpoint %q is not reachable from this host: %s", e.Endpoint, e.Err)
+}
+
+// DryRunResult is the outcome of a dry run of creating a store
+type DryRunResult string
+
+const (
+	// DryRunPassed means the connection and the target bucket were verified
+	DryRunPassed DryRunResult = "Passed"
+	// DryRunFailed means the connection or the target bucket check failed
+	DryRunFailed DryRunResult = "Failed"
+	// DryRunNotVerified means the connection check passed but the target bucket could not be checked from this host
+	DryRunNotVerified DryRunResult = "NotVerified"
+)
+
+// RunConnectionDryRun checks an external connection of a store before creating it.
+// It runs check_external_connection in noobaa-core with the connection credentials,
+// and then verifies that the target bucket exists, and when checkWrite is set that it is writable
+// by writing and deleting a small object.
+func RunConnectionDryRun(conn *nb.AddExternalConnectionParams, targetBucket string, checkWrite bool) DryRunResult {
+	log := util.Logger()
+
+	sysClient, err := Connect(true)
+	if err != nil {
+		log.Fatalf("❌ %s", err)
+	}
+
+	res, err := sysClient.NBClient.CheckExternalConnectionAPI(*conn)
+	if err != nil {
+		log.Errorf("❌ Connection check to %q failed: %s", conn.Endpoint, err)
+		return DryRunFailed
+	}
+	if res.Status != nb.ExternalConnectionSuccess {
+		log.Errorf("❌ Connection check to %q failed: Status=%s Error=%s Message=%s",
+			conn.Endpoint, res.Status, res.Error.Code, res.Error.Message)
+		return DryRunFailed
+	}
+	log.Printf("✅ Connection check to %q passed", conn.Endpoint)
+
+	err = CheckTargetBucket(conn, targetBucket, checkWrite)
+	if unreachable, ok := err.(*TargetUnreachableError); ok {
+		log.Warnf("⚠️  Could not check the target bucket %q: %s", targetBucket, unreachable)
+		return DryRunNotVerified
+	}
+	if err != nil {
+		log.Errorf("❌ Target bucket %q check failed: %s", targetBucket, err)
+		return DryRunFailed
+	}
+	if checkWrite {
+		log.Printf("✅ Target bucket %q exists and is writable", targetBucket)
+	} else {
+		log.Printf("✅ Target bucket %q exists", targetBucket)
+	}
+	return DryRunPassed
+}
+
+// CheckTargetBucket verifies that the target bucket (or container) of an external connection exists,
+// and when checkWrite is set that it is writable by writing and deleting a small object.
+// Returns a TargetUnreachableError when the endpoint cannot be reached or its certificate is not trusted by this host.
+func CheckTargetBucket(conn *nb.AddExternalConnectionParams, targetBucket string, checkWrite bool) error {
+	if targetBucket == "" {
+		return fmt.Errorf("missing target bucket")
+	}
+	key := fmt.Sprintf("noobaa-dry-run-check-%d", time.Now().UnixNano())
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch conn.EndpointType {
+	case nb.EndpointTypeAws, nb.EndpointTypeS3Compat, nb.EndpointTypeIBMCos:
+		if err := probeEndpoint(conn.Endpoint); err != nil {
+			return err
+		}
+		s3Client, err := newS3Client(conn)
+		if err != nil {
+			return err
+		}
+		return checkS3Bucket(ctx, s3Client, targetBucket, key, checkWrite)
+	case nb.EndpointTypeAzure:
+		serviceURL, err := azureServiceURL(conn)
+		if err != nil {
+			return err
+		}
+		if err := probeEndpoint(serviceURL.String()); err != nil {
+			return err
+		}
+		c, err := azblob.NewSharedKeyCredential(conn.Identity, conn.Secret)
+		if err != nil {
+			return err
+		}
+		p := azblob.NewPipeline(c, azblob.PipelineOptions{
+			Telemetry: azblob.TelemetryOptions{Value: "Go-http-client/1.1"},
+		})
+		containerURL := azblob.NewServiceURL(*serviceURL, p).NewContainerURL(targetBucket)
+		return checkAzureContainer(ctx, containerURL, key, checkWrite)
+	case nb.EndpointTypeGoogle:
+		if err := probeEndpoint(conn.Endpoint); err != nil {
+			return err
+		}
+		client, err := storage.NewClient(ctx, option.WithCredentialsJSON([]byte(conn.Secret)))
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return checkGoogleBucket(ctx, client.Bucket(targetBucket), key, checkWrite)
+	default:
+		return fmt.Errorf("unsupported endpoint type %q", conn.EndpointType)
+	}
+}
+
+// probeEndpoint connects to the host of the endpoint, and for https verifies its certificate with the trusted roots of this host
+func probeEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+	dialer := &net.Dialer{Timeout: dryRunDialTimeout}
+	if u.Scheme == "http" {
+		conn, err := dialer.Dial("tcp", addr)
+		if err != nil {
+			return &TargetUnreachableError{Endpoint: endpoint, Err: err}
+		}
+		return conn.Close()
+	}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: u.Hostname()})
+	if err != nil {
+		return &TargetUnreachableError{Endpoint: endpoint, Err: err}
+	}
+	return conn.Close()
+}
+
+// newS3Client returns an s3 client of the connection endpoint.
+// Endpoints other than aws are addressed with path style, since their buckets are usually not dns names.
+func newS3Client(conn *nb.AddExternalConnectionParams) (*s3.S3, error) {
+	u, err := url.Parse(conn.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	s3Config := &aws.Config{
+		Credentials: credentials.NewStaticCredentials(conn.Identity, conn.Secret, ""),
+		Endpoint:    aws.String(conn.Endpoint),
+		Region:      aws.String("us-east-1"),
+	}
+	if conn.EndpointType == nb.EndpointTypeAws {
+		// the aws endpoint is s3.<region>.amazonaws.com and the region is needed for signing
+		parts := strings.Split(u.Hostname(), ".")
+		if len(parts) == 4 && parts[0] == "s3" {
+			s3Config.Region = aws.String(parts[1])
+		}
+	} else {
+		s3Config.S3ForcePathStyle = aws.Bool(true)
+	}
+	s3Session, err := session.NewSession(s3Config)
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(s3Session), nil
+}
+
+func checkS3Bucket(ctx context.Context, s3Client *s3.S3, bucket string, key string, checkWrite bool) error {
+	if _, err := s3Client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: &bucket}); err != nil {
+		return fmt.Errorf("bucket does not exist or is not accessible: %s", err)
+	}
+	if !checkWrite {
+		return nil
+	}
+	if _, err := s3Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		Body:   bytes.NewReader(dryRunCheckData),
+	}); err != nil {
+		return fmt.Errorf("bucket is not writable: %s", err)
+	}
+	if _, err := s3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{Bucket: &bucket, Key: &key}); err != nil {
+		return fmt.Errorf("could not delete the check object %q: %s", key, err)
+	}
+	return nil
+}
+
+// azureServiceURL returns the blob service url of the connection.
+// The account is added as a sub domain of the default endpoints, like https://blob.core.windows.net,
+// and other endpoints, like an emulator that has the account in the path, are used as is.
+func azureServiceURL(conn *nb.AddExternalConnectionParams) (*url.URL, error) {
+	u, err := url.Parse(conn.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", conn.Endpoint)
+	}
+	if strings.HasPrefix(u.Host, "blob.") {
+		u.Host = conn.Identity + "." + u.Host
+	}
+	return u, nil
+}
+
+func checkAzureContainer(ctx context.Context, containerURL azblob.ContainerURL, key string, checkWrite bool) error {
+	if _, err := containerURL.GetProperties(ctx, azblob.LeaseAccessConditions{}); err != nil {
+		return fmt.Errorf("container does not exist or is not accessible: %s", err)
+	}
+	if !checkWrite {
+		return nil
+	}
+	blobURL := containerURL.NewBlockBlobURL(key)
+	if _, err := azblob.UploadBufferToBlockBlob(ctx, dryRunCheckData, blobURL, azblob.UploadToBlockBlobOptions{}); err != nil {
+		return fmt.Errorf("container is not writable: %s", err)
+	}
+	if _, err := blobURL.Delete(ctx, azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{}); err != nil {
+		return fmt.Errorf("could not delete the check blob %q: %s", key, err)
+	}
+	return nil
+}
+
+func checkGoogleBucket(ctx context.Context, b *storage.BucketHandle, key string, checkWrite bool) error {
+	if _, err := b.Attrs(ctx); err != nil {
+		return fmt.Errorf("bucket does not exist or is not accessible: %s", err)
+	}
+	if !checkWrite {
+		return nil
+	}
+	w := b.Object(key).NewWriter(ctx)
+	if _, err := w.Write(dryRunCheckData); err != nil {
+		return fmt.Errorf("bucket is not writable: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("bucket is not writable: %s", err)
+	}
+	if err := b.Object(key).Delete(ctx); err != nil {
+		return fmt.Errorf("could not delete the check object %q: %s", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	"google.golang.org/api/option"
+)
+
+// targetServer is a fake storage endpoint that records the requests it got
+type targetServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	requests []string
+}
+
+func newTargetServer(t *testing.T, handle func(w http.ResponseWriter, req *http.Request)) *targetServer {
+	s := &targetServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.mutex.Lock()
+		s.requests = append(s.requests, req.Method+" "+req.URL.Path)
+		s.mutex.Unlock()
+		handle(w, req)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *targetServer) methods() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	methods := []string{}
+	for _, r := range s.requests {
+		methods = append(methods, strings.Fields(r)[0])
+	}
+	s.requests = nil
+	return strings.Join(methods, ",")
+}
+
+func TestCheckS3TargetBucket(t *testing.T) {
+	s := newTargetServer(t, func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	conn := &nb.AddExternalConnectionParams{
+		EndpointType: nb.EndpointTypeS3Compat,
+		Endpoint:     s.URL,
+		Identity:     "access",
+		Secret:       "secret",
+	}
+
+	if err := CheckTargetBucket(conn, "bucket", true); err != nil {
+		t.Fatalf("expected a writable bucket, got %v", err)
+	}
+	if methods := s.methods(); methods != "HEAD,PUT,DELETE" {
+		t.Errorf("expected the bucket to be checked, written and cleaned, got %s", methods)
+	}
+
+	if err := CheckTargetBucket(conn, "bucket", false); err != nil {
+		t.Fatalf("expected an existing bucket, got %v", err)
+	}
+	if methods := s.methods(); methods != "HEAD" {
+		t.Errorf("expected a read only check not to write, got %s", methods)
+	}
+
+	err := CheckTargetBucket(conn, "missing", false)
+	if _, unreachable := err.(*TargetUnreachableError); err == nil || unreachable {
+		t.Errorf("expected a missing bucket to fail the check, got %v", err)
+	}
+}
+
+func TestCheckAzureTargetContainer(t *testing.T) {
+	s := newTargetServer(t, func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	})
+	// an endpoint with the account in the path, like an emulator, is used as is
+	conn := &nb.AddExternalConnectionParams{
+		EndpointType: nb.EndpointTypeAzure,
+		Endpoint:     s.URL + "/account",
+		Identity:     "account",
+		Secret:       "a2V5",
+	}
+
+	if err := CheckTargetBucket(conn, "container", true); err != nil {
+		t.Fatalf("expected a writable container, got %v", err)
+	}
+	s.mutex.Lock()
+	requests := strings.Join(s.requests, ",")
+	s.mutex.Unlock()
+	if !strings.HasPrefix(requests, "GET /account/container,PUT /account/container/noobaa-dry-run-check-") {
+		t.Errorf("expected the requests to go to the connection endpoint, got %s", requests)
+	}
+	if methods := s.methods(); methods != "GET,PUT,DELETE" {
+		t.Errorf("expected the container to be checked, written and cleaned, got %s", methods)
+	}
+
+	if err := CheckTargetBucket(conn, "container", false); err != nil {
+		t.Fatalf("expected an existing container, got %v", err)
+	}
+	if methods := s.methods(); methods != "GET" {
+		t.Errorf("expected a read only check not to write, got %s", methods)
+	}
+}
+
+func TestAzureServiceURL(t *testing.T) {
+	for endpoint, expected := range map[string]string{
+		"https://blob.core.windows.net":       "https://account.blob.core.windows.net",
+		"https://blob.core.usgovcloudapi.net": "https://account.blob.core.usgovcloudapi.net",
+		"http://azurite:10000/account":        "http://azurite:10000/account",
+	} {
+		u, err := azureServiceURL(&nb.AddExternalConnectionParams{Endpoint: endpoint, Identity: "account"})
+		if err != nil || u.String() != expected {
+			t.Errorf("%s: expected %s, got %v %v", endpoint, expected, u, err)
+		}
+	}
+}
+
+func TestCheckGoogleTargetBucket(t *testing.T) {
+	s := newTargetServer(t, func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"name":"bucket"}`)
+		case http.MethodPost:
+			fmt.Fprint(w, `{"name":"object","bucket":"bucket"}`)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx, option.WithEndpoint(s.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("storage client: %v", err)
+	}
+	defer client.Close()
+
+	if err := checkGoogleBucket(ctx, client.Bucket("bucket"), "object", true); err != nil {
+		t.Fatalf("expected a writable bucket, got %v", err)
+	}
+	if methods := s.methods(); methods != "GET,POST,DELETE" {
+		t.Errorf("expected the bucket to be checked, written and cleaned, got %s", methods)
+	}
+
+	if err := checkGoogleBucket(ctx, client.Bucket("bucket"), "object", false); err != nil {
+		t.Fatalf("expected an existing bucket, got %v", err)
+	}
+	if methods := s.methods(); methods != "GET" {
+		t.Errorf("expected a read only check not to write, got %s", methods)
+	}
+}
+
+func TestCheckTargetBucketUnreachable(t *testing.T) {
+	untrusted := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer untrusted.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	closed.Close()
+
+	for _, endpoint := range []string{closed.URL, untrusted.URL} {
+		conn := &nb.AddExternalConnectionParams{EndpointType: nb.EndpointTypeS3Compat, Endpoint: endpoint}
+		err := CheckTargetBucket(conn, "bucket", true)
+		if _, unreachable := err.(*TargetUnreachableError); !unreachable {
+			t.Errorf("%s: expected the endpoint to be reported unreachable from this host, got %v", endpoint, err)
+		}
+	}
+}
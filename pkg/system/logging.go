@@ -0,0 +1,361 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LogLevelRevertAnnotation holds the log levels that were raised for a limited time by
+// `noobaa system set-debug`, with the level to revert to and the revert time of each component
+const LogLevelRevertAnnotation = "noobaa.io/log-level-revert"
+
+// LoggingComponents are the components that the logging spec sets the log level of
+var LoggingComponents = []string{"core", "endpoint", "operator"}
+
+// LogLevels are the valid log levels from the least to the most verbose
+var LogLevels = []nbv1.LogLevel{
+	nbv1.LogLevelError,
+	nbv1.LogLevelWarn,
+	nbv1.LogLevelInfo,
+	nbv1.LogLevelDebug,
+	nbv1.LogLevelTrace,
+}
+
+// coreLogModules are the noobaa-core debug modules of the core and endpoint components.
+// noobaa-core names the modules by the source tree, and setting the level of a module sets all its sub modules.
+var coreLogModules = map[string][]string{
+	"core":     {"core.server"},
+	"endpoint": {"core.endpoint", "core.sdk"},
+}
+
+// LogLevelRevert is the level to revert a component to and the time to revert it
+type LogLevelRevert struct {
+	Level nbv1.LogLevel `json:"level"`
+	At    metav1.Time   `json:"at"`
+}
+
+// CmdSetDebug returns a CLI command
+func CmdSetDebug() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-debug <component> <level>",
+		Short: "Set the log level of a system component",
+		Long: "Set the log level of a system component - one of: " + strings.Join(LoggingComponents, ", ") + ".\n" +
+			"Core and endpoint levels are applied to the running processes without restarting the pods.",
+		Run:  RunSetDebug,
+		Args: cobra.ExactArgs(2),
+	}
+	cmd.Flags().Duration("for", 0, "Revert to the previous level after this duration, like 30m (default no revert)")
+	return cmd
+}
+
+// RunSetDebug runs a CLI command
+func RunSetDebug(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+	component := args[0]
+	level := nbv1.LogLevel(args[1])
+	duration, _ := cmd.Flags().GetDuration("for")
+
+	if duration < 0 {
+		log.Fatalf(`❌ Invalid duration %s`, duration)
+	}
+	sys := loadSystemForUpdate()
+	reverts, err := GetLogLevelReverts(sys)
+	if err != nil {
+		log.Warnf("⏳ Ignoring invalid annotation %s: %s", LogLevelRevertAnnotation, err)
+		reverts = map[string]LogLevelRevert{}
+	}
+	if sys.Spec.Logging == nil {
+		sys.Spec.Logging = &nbv1.LoggingSpec{}
+	}
+	previous := GetComponentLogLevel(sys.Spec.Logging, component)
+	if err := SetComponentLogLevel(sys.Spec.Logging, component, level); err != nil {
+		log.Fatalf(`❌ %s`, err)
+	}
+
+	if duration > 0 {
+		// keep the original level when raising again before the revert
+		if revert, exists := reverts[component]; exists {
+			previous = revert.Level
+		}
+		reverts[component] = LogLevelRevert{Level: previous, At: metav1.NewTime(time.Now().Add(duration))}
+	} else {
+		delete(reverts, component)
+	}
+	SetLogLevelReverts(sys, reverts)
+
+	if !util.KubeUpdate(sys) {
+		log.Fatalf(`❌ Could not update the log level of system %q`, sys.Name)
+	}
+	if duration > 0 {
+		log.Printf("✅ Set the %s log level to %s for %s", component, level, duration)
+	} else {
+		log.Printf("✅ Set the %s log level to %s", component, level)
+	}
+}
+
+// ValidateLogging returns an error if the logging spec has an invalid level or format
+func ValidateLogging(logging *nbv1.LoggingSpec) error {
+	if logging == nil {
+		return nil
+	}
+	for _, component := range LoggingComponents {
+		if err := validateLogLevel(GetComponentLogLevel(logging, component)); err != nil {
+			return fmt.Errorf("Invalid logging %sLevel: %s", component, err)
+		}
+	}
+	switch logging.OperatorFormat {
+	case "", nbv1.LogFormatText, nbv1.LogFormatJSON:
+	default:
+		return fmt.Errorf("Invalid logging operatorFormat %q, expected text or json", logging.OperatorFormat)
+	}
+	return nil
+}
+
+// GetComponentLogLevel returns the log level of a component, which is empty for the default level
+func GetComponentLogLevel(logging *nbv1.LoggingSpec, component string) nbv1.LogLevel {
+	if logging == nil {
+		return ""
+	}
+	switch component {
+	case "core":
+		return logging.CoreLevel
+	case "endpoint":
+		return logging.EndpointLevel
+	case "operator":
+		return logging.OperatorLevel
+	}
+	return ""
+}
+
+// SetComponentLogLevel sets the log level of a component, where an empty level sets the default level
+func SetComponentLogLevel(logging *nbv1.LoggingSpec, component string, level nbv1.LogLevel) error {
+	if err := validateLogLevel(level); err != nil {
+		return err
+	}
+	switch component {
+	case "core":
+		logging.CoreLevel = level
+	case "endpoint":
+		logging.EndpointLevel = level
+	case "operator":
+		logging.OperatorLevel = level
+	default:
+		return fmt.Errorf("Invalid component %q, expected one of: %s", component, strings.Join(LoggingComponents, ", "))
+	}
+	return nil
+}
+
+// CoreDebugLevel translates a log level to the noobaa-core debug level, which is 0 by default and 5 for the most verbose.
+// noobaa-core always logs errors and warnings, so error, warn and info are all level 0.
+func CoreDebugLevel(level nbv1.LogLevel) int64 {
+	switch level {
+	case nbv1.LogLevelDebug:
+		return 1
+	case nbv1.LogLevelTrace:
+		return 5
+	}
+	return 0
+}
+
+// GetLogLevelReverts parses the log level revert annotation of the system
+func GetLogLevelReverts(sys *nbv1.NooBaa) (map[string]LogLevelRevert, error) {
+	reverts := map[string]LogLevelRevert{}
+	value := sys.Annotations[LogLevelRevertAnnotation]
+	if value == "" {
+		return reverts, nil
+	}
+	if err := json.Unmarshal([]byte(value), &reverts); err != nil {
+		return nil, err
+	}
+	return reverts, nil
+}
+
+// SetLogLevelReverts sets the log level revert annotation of the system, and removes it when empty
+func SetLogLevelReverts(sys *nbv1.NooBaa, reverts map[string]LogLevelRevert) {
+	if len(reverts) == 0 {
+		delete(sys.Annotations, LogLevelRevertAnnotation)
+		return
+	}
+	value, err := json.Marshal(reverts)
+	util.Panic(err)
+	if sys.Annotations == nil {
+		sys.Annotations = map[string]string{}
+	}
+	sys.Annotations[LogLevelRevertAnnotation] = string(value)
+}
+
+// ApplyLogLevelReverts reverts the components whose revert time has passed,
+// and returns the reverted components and the time until the next revert, or 0 if none are left
+func ApplyLogLevelReverts(sys *nbv1.NooBaa, now time.Time) ([]string, time.Duration, error) {
+	reverts, err := GetLogLevelReverts(sys)
+	if err != nil {
+		return nil, 0, err
+	}
+	reverted := []string{}
+	next := time.Duration(0)
+	for component, revert := range reverts {
+		if left := revert.At.Time.Sub(now); left > 0 {
+			if next == 0 || left < next {
+				next = left
+			}
+			continue
+		}
+		if sys.Spec.Logging == nil {
+			sys.Spec.Logging = &nbv1.LoggingSpec{}
+		}
+		if err := SetComponentLogLevel(sys.Spec.Logging, component, revert.Level); err != nil {
+			return nil, 0, err
+		}
+		delete(reverts, component)
+		reverted = append(reverted, component)
+	}
+	sort.Strings(reverted)
+	if len(reverted) > 0 {
+		SetLogLevelReverts(sys, reverts)
+	}
+	return reverted, next, nil
+}
+
+// ReconcileLogLevelReverts reverts the log levels that were raised for a limited time,
+// and returns the time to requeue the next revert, or 0 if none are left
+func (r *Reconciler) ReconcileLogLevelReverts() time.Duration {
+	reverted, next, err := ApplyLogLevelReverts(r.NooBaa, time.Now())
+	if err != nil {
+		r.Logger.Warnf("Ignoring invalid annotation %s: %s", LogLevelRevertAnnotation, err)
+		return 0
+	}
+	if len(reverted) == 0 {
+		return next
+	}
+	if !util.KubeUpdate(r.NooBaa) {
+		r.Logger.Errorf("❌ NooBaa %q failed to revert the log level of %s", r.NooBaa.Name, strings.Join(reverted, ", "))
+		return 3 * time.Second
+	}
+	for _, component := range reverted {
+		level := GetComponentLogLevel(r.NooBaa.Spec.Logging, component)
+		if level == "" {
+			level = "default"
+		}
+		r.Logger.Infof("Reverted the %s log level to %s", component, level)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(r.NooBaa, corev1.EventTypeNormal,
+				"LogLevelReverted", "Reverted the %s log level to %s", component, level)
+		}
+	}
+	return next
+}
+
+// ReconcileOperatorLogging sets the level and format of the logs of reconciling this system.
+// The operator process logger is set by the operator flags and is shared by all the systems,
+// so the logging spec of a system only overrides them for the reconciler logger of that system.
+func (r *Reconciler) ReconcileOperatorLogging() {
+	logging := r.NooBaa.Spec.Logging
+	if logging == nil || (logging.OperatorLevel == "" && logging.OperatorFormat == "") {
+		return
+	}
+	logger, err := util.NewLogger(string(logging.OperatorLevel), string(logging.OperatorFormat))
+	if err != nil {
+		r.Logger.Warnf("Ignoring invalid operator logging spec: %s", err)
+		return
+	}
+	r.Logger = logger.WithFields(r.Logger.Data)
+}
+
+// ReconcileCoreLogging applies the core and endpoint log levels to the running noobaa-core processes.
+// The applied levels are recorded in the status, and a level is sent again only when it changes,
+// when the logging spec is removed to reset the processes to the default level, or when a process
+// that runs with the default level became ready since the last time a non default level was applied.
+func (r *Reconciler) ReconcileCoreLogging() {
+	applied := r.NooBaa.Status.Logging
+	if applied == nil {
+		applied = &nbv1.LoggingStatus{}
+	}
+	since := time.Time{}
+	if applied.AppliedTime != nil {
+		since = applied.AppliedTime.Time
+	}
+	now := metav1.Now()
+	changed := false
+	for _, component := range []string{"core", "endpoint"} {
+		desired := GetComponentLogLevel(r.NooBaa.Spec.Logging, component)
+		current := applied.CoreLevel
+		if component == "endpoint" {
+			current = applied.EndpointLevel
+		}
+		level := CoreDebugLevel(desired)
+		if level == CoreDebugLevel(current) && (level == 0 || !r.isComponentReadySince(component, since)) {
+			continue
+		}
+		if err := r.setCoreDebugLevel(component, level); err != nil {
+			// logging should not fail the reconcile, the level is sent again on the next reconcile
+			r.Logger.Warnf("Could not set the %s log level: %s", component, err)
+			continue
+		}
+		r.Logger.Infof("Applied the %s log level %q (debug level %d)", component, desired, level)
+		if component == "core" {
+			applied.CoreLevel = desired
+		} else {
+			applied.EndpointLevel = desired
+		}
+		changed = true
+	}
+	if changed {
+		applied.AppliedTime = &now
+		r.NooBaa.Status.Logging = applied
+	}
+}
+
+// setCoreDebugLevel sets the noobaa-core debug level of all the modules of a component
+func (r *Reconciler) setCoreDebugLevel(component string, level int64) error {
+	for _, module := range coreLogModules[component] {
+		err := r.NBClient.DebugAPISetDebugLevel(nb.DebugAPISetDebugLevelParams{Module: module, Level: level})
+		if err != nil {
+			return fmt.Errorf("module %s: %v", module, err)
+		}
+	}
+	return nil
+}
+
+// isComponentReadySince returns true if a pod of the core or the endpoints became ready after the time
+func (r *Reconciler) isComponentReadySince(component string, since time.Time) bool {
+	selector := client.MatchingLabels{"noobaa-core": r.Request.Name}
+	if component == "endpoint" {
+		selector = client.MatchingLabels{EndpointPodLabel: r.Request.Name}
+	}
+	pods := &corev1.PodList{}
+	if err := r.Client.List(r.Ctx, pods, client.InNamespace(r.Request.Namespace), selector); err != nil {
+		// apply the level when the pods are unknown
+		return true
+	}
+	for i := range pods.Items {
+		for _, c := range pods.Items[i].Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue && c.LastTransitionTime.Time.After(since) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validateLogLevel(level nbv1.LogLevel) error {
+	if level == "" {
+		return nil
+	}
+	for _, l := range LogLevels {
+		if level == l {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid log level %q, expected one of: error, warn, info, debug, trace", level)
+}
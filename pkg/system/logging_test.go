@@ -0,0 +1,169 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateLogging(t *testing.T) {
+	valid := []*nbv1.LoggingSpec{
+		nil,
+		{},
+		{CoreLevel: nbv1.LogLevelTrace, EndpointLevel: nbv1.LogLevelDebug, OperatorLevel: nbv1.LogLevelWarn, OperatorFormat: nbv1.LogFormatJSON},
+	}
+	for _, logging := range valid {
+		if err := ValidateLogging(logging); err != nil {
+			t.Errorf("unexpected error for %+v: %v", logging, err)
+		}
+	}
+	invalid := []*nbv1.LoggingSpec{
+		{CoreLevel: "verbose"},
+		{OperatorLevel: "DEBUG"},
+		{OperatorFormat: "yaml"},
+	}
+	for _, logging := range invalid {
+		if err := ValidateLogging(logging); err == nil {
+			t.Errorf("expected an error for %+v", logging)
+		}
+	}
+}
+
+func TestCoreDebugLevel(t *testing.T) {
+	expected := map[nbv1.LogLevel]int64{
+		"":                 0,
+		nbv1.LogLevelError: 0,
+		nbv1.LogLevelInfo:  0,
+		nbv1.LogLevelDebug: 1,
+		nbv1.LogLevelTrace: 5,
+	}
+	for level, debugLevel := range expected {
+		if l := CoreDebugLevel(level); l != debugLevel {
+			t.Errorf("CoreDebugLevel(%q) = %d, expected %d", level, l, debugLevel)
+		}
+	}
+}
+
+func TestApplyLogLevelReverts(t *testing.T) {
+	now := time.Now()
+	sys := &nbv1.NooBaa{}
+	sys.Spec.Logging = &nbv1.LoggingSpec{CoreLevel: nbv1.LogLevelTrace, EndpointLevel: nbv1.LogLevelDebug}
+	SetLogLevelReverts(sys, map[string]LogLevelRevert{
+		"core":     {Level: "", At: metav1.NewTime(now.Add(-time.Minute))},
+		"endpoint": {Level: nbv1.LogLevelInfo, At: metav1.NewTime(now.Add(10 * time.Minute))},
+	})
+
+	reverted, next, err := ApplyLogLevelReverts(sys, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0] != "core" {
+		t.Errorf("expected only core to be reverted, got %v", reverted)
+	}
+	if next <= 9*time.Minute || next > 10*time.Minute {
+		t.Errorf("expected the next revert in 10 minutes, got %s", next)
+	}
+	if sys.Spec.Logging.CoreLevel != "" || sys.Spec.Logging.EndpointLevel != nbv1.LogLevelDebug {
+		t.Errorf("unexpected levels after revert %+v", sys.Spec.Logging)
+	}
+
+	reverted, next, err = ApplyLogLevelReverts(sys, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0] != "endpoint" || next != 0 {
+		t.Errorf("expected endpoint to be reverted last, got %v %s", reverted, next)
+	}
+	if sys.Spec.Logging.EndpointLevel != nbv1.LogLevelInfo {
+		t.Errorf("expected endpoint level info, got %q", sys.Spec.Logging.EndpointLevel)
+	}
+	if _, exists := sys.Annotations[LogLevelRevertAnnotation]; exists {
+		t.Errorf("expected the revert annotation to be removed")
+	}
+}
+
+func TestReconcileOperatorLogging(t *testing.T) {
+	r, _ := newFakeReconciler(t)
+	level := logrus.GetLevel()
+	formatter := logrus.StandardLogger().Formatter
+
+	r.NooBaa.Spec.Logging = &nbv1.LoggingSpec{OperatorLevel: nbv1.LogLevelTrace, OperatorFormat: nbv1.LogFormatJSON}
+	r.ReconcileOperatorLogging()
+	if r.Logger.Logger.GetLevel() != logrus.TraceLevel {
+		t.Fatalf("expected the system logger level to be trace, got %s", r.Logger.Logger.GetLevel())
+	}
+	if _, ok := r.Logger.Logger.Formatter.(*logrus.JSONFormatter); !ok {
+		t.Fatalf("expected the system logger format to be json, got %T", r.Logger.Logger.Formatter)
+	}
+	if r.Logger.Data["test"] == nil {
+		t.Fatalf("expected the system logger to keep its fields, got %v", r.Logger.Data)
+	}
+	if logrus.GetLevel() != level || logrus.StandardLogger().Formatter != formatter {
+		t.Fatalf("expected the operator logger not to change")
+	}
+}
+
+func TestReconcileCoreLogging(t *testing.T) {
+	readyEndpoint := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "noobaa-endpoint-1", Namespace: testNamespace,
+			Labels: map[string]string{EndpointPodLabel: "noobaa"}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+		}}},
+	}
+	r, nbClient := newFakeReconciler(t, readyEndpoint)
+	sent := func() []int64 {
+		levels := []int64{}
+		for _, p := range nbClient.debugLevels {
+			levels = append(levels, p.Level)
+		}
+		nbClient.debugLevels = nil
+		return levels
+	}
+
+	// the default levels are not sent to processes that already run with them
+	r.ReconcileCoreLogging()
+	if levels := sent(); len(levels) != 0 {
+		t.Fatalf("expected no levels to be sent, got %v", levels)
+	}
+
+	// a changed level is sent once and recorded in the status
+	r.NooBaa.Spec.Logging = &nbv1.LoggingSpec{CoreLevel: nbv1.LogLevelInfo, EndpointLevel: nbv1.LogLevelTrace}
+	r.ReconcileCoreLogging()
+	if levels := sent(); len(levels) != len(coreLogModules["endpoint"]) || levels[0] != 5 {
+		t.Fatalf("expected the endpoint level to be sent, got %v", levels)
+	}
+	if s := r.NooBaa.Status.Logging; s == nil || s.EndpointLevel != nbv1.LogLevelTrace || s.AppliedTime == nil {
+		t.Fatalf("expected the applied levels in the status, got %+v", s)
+	}
+	r.ReconcileCoreLogging()
+	if levels := sent(); len(levels) != 0 {
+		t.Fatalf("expected unchanged levels not to be sent again, got %v", levels)
+	}
+
+	// an endpoint that became ready since the last apply gets the level again
+	readyEndpoint.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(time.Minute))
+	if err := r.Client.Update(r.Ctx, readyEndpoint); err != nil {
+		t.Fatal(err)
+	}
+	r.ReconcileCoreLogging()
+	if levels := sent(); len(levels) != len(coreLogModules["endpoint"]) {
+		t.Fatalf("expected the endpoint level to be sent to the new endpoint, got %v", levels)
+	}
+
+	// removing the logging spec resets the processes to the default level
+	r.NooBaa.Spec.Logging = nil
+	r.ReconcileCoreLogging()
+	if levels := sent(); len(levels) != len(coreLogModules["endpoint"]) || levels[0] != 0 {
+		t.Fatalf("expected the endpoint level to be reset, got %v", levels)
+	}
+	if s := r.NooBaa.Status.Logging; s.EndpointLevel != "" {
+		t.Fatalf("expected the default level in the status, got %+v", s)
+	}
+}
@@ -0,0 +1,92 @@
+package system
+
+import (
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CmdPause returns a CLI command
+func CmdPause() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Pause the reconcile of a noobaa system",
+		Long: "Pause the reconcile of a noobaa system, so that manual changes to its resources are not reverted.\n" +
+			"The system status is still updated while paused.",
+		Run:  RunPause,
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().Duration("for", 0, "Resume automatically after this duration, like 1h (default until resumed)")
+	return cmd
+}
+
+// CmdResume returns a CLI command
+func CmdResume() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume the reconcile of a paused noobaa system",
+		Run:   RunResume,
+		Args:  cobra.NoArgs,
+	}
+	return cmd
+}
+
+// RunPause runs a CLI command
+func RunPause(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+	duration, _ := cmd.Flags().GetDuration("for")
+	if duration < 0 {
+		log.Fatalf(`❌ Invalid duration %s`, duration)
+	}
+
+	sys := loadSystemForUpdate()
+	if sys.Annotations == nil {
+		sys.Annotations = map[string]string{}
+	}
+	value := "true"
+	if duration > 0 {
+		value = time.Now().Add(duration).UTC().Format(time.RFC3339)
+	}
+	sys.Annotations[util.ReconcilePausedAnnotation] = value
+	if !util.KubeUpdate(sys) {
+		log.Fatalf(`❌ Could not pause system %q`, sys.Name)
+	}
+	if duration > 0 {
+		log.Printf("✅ Paused the reconcile of system %q until %s", sys.Name, value)
+	} else {
+		log.Printf("✅ Paused the reconcile of system %q, run \"noobaa system resume\" to resume", sys.Name)
+	}
+}
+
+// RunResume runs a CLI command
+func RunResume(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+	sys := loadSystemForUpdate()
+	if _, paused := sys.Annotations[util.ReconcilePausedAnnotation]; !paused {
+		log.Printf("✅ System %q is not paused", sys.Name)
+		return
+	}
+	delete(sys.Annotations, util.ReconcilePausedAnnotation)
+	if !util.KubeUpdate(sys) {
+		log.Fatalf(`❌ Could not resume system %q`, sys.Name)
+	}
+	log.Printf("✅ Resumed the reconcile of system %q", sys.Name)
+}
+
+// loadSystemForUpdate reads the system CR of the CLI options or exits if it does not exist
+func loadSystemForUpdate() *nbv1.NooBaa {
+	sys := &nbv1.NooBaa{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: options.Namespace,
+			Name:      options.SystemName,
+		},
+	}
+	if !util.KubeCheck(sys) {
+		util.Logger().Fatalf(`❌ Could not find system %q in namespace %q`, sys.Name, sys.Namespace)
+	}
+	return sys
+}
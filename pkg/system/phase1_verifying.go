@@ -5,7 +5,6 @@ import (
 	"os"
 
 	"github.com/asaskevich/govalidator"
-	semver "github.com/coreos/go-semver/semver"
 	dockerref "github.com/docker/distribution/reference"
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
@@ -39,8 +38,6 @@ func (r *Reconciler) ReconcilePhaseVerifying() error {
 // and updates the status accordingly
 func (r *Reconciler) CheckSystemCR() error {
 
-	log := r.Logger.WithField("func", "CheckSystemCR")
-
 	// we assume a single system per ns here
 	if r.NooBaa.Name != options.SystemName {
 		return util.NewPersistentError("InvalidSystemName",
@@ -56,53 +53,21 @@ func (r *Reconciler) CheckSystemCR() error {
 	}
 
 	// Parse the image spec as a docker image url
-	imageRef, err := dockerref.Parse(specImage)
-
 	// If the image cannot be parsed log the incident and mark as persistent error
 	// since we don't need to retry until the spec is updated.
-	if err != nil {
+	if _, err := dockerref.Parse(specImage); err != nil {
 		return util.NewPersistentError("InvalidImage",
 			fmt.Sprintf(`Invalid image requested %q %v`, specImage, err))
 	}
 
-	// Get the image name and tag
-	imageName := ""
-	imageTag := ""
-	switch image := imageRef.(type) {
-	case dockerref.NamedTagged:
-		log.Infof("Parsed image (NamedTagged) %v", image)
-		imageName = image.Name()
-		imageTag = image.Tag()
-	case dockerref.Tagged:
-		log.Infof("Parsed image (Tagged) %v", image)
-		imageTag = image.Tag()
-	case dockerref.Named:
-		log.Infof("Parsed image (Named) %v", image)
-		imageName = image.Name()
-	default:
-		log.Infof("Parsed image (unstructured) %v", image)
-	}
-
-	if imageName == options.ContainerImageName {
-		version, err := semver.NewVersion(imageTag)
-		if err == nil {
-			log.Infof("Parsed version %q from image tag %q", version.String(), imageTag)
-			minver := semver.New(options.ContainerImageSemverLowerBound)
-			maxver := semver.New(options.ContainerImageSemverUpperBound)
-			if version.Compare(*minver) != 1 || version.Compare(*maxver) != -1 {
-				return util.NewPersistentError("InvalidImageVersion",
-					fmt.Sprintf(`Invalid image version %q not matching version constraints: >=%v, <%v`,
-						imageRef, minver, maxver))
-			}
-		} else {
-			log.Infof("Using custom image %q", imageRef.String())
-		}
-	} else {
-		log.Infof("Using custom image name %q the default is %q", imageRef.String(), options.ContainerImageName)
+	// Check the image against the compatibility matrix before it is used
+	if err := r.CheckCompatibility(specImage); err != nil {
+		return err
 	}
 
-	// Set ActualImage to be updated in the noobaa status
-	r.NooBaa.Status.ActualImage = specImage
+	// Set ActualImage to be updated in the noobaa status,
+	// changes of the image are rolled out by the upgrade steps
+	r.ReconcileUpgrade(specImage)
 
 	// Verify the endpoints spec
 	endpointsSpec := r.NooBaa.Spec.Endpoints
@@ -126,8 +91,15 @@ func (r *Reconciler) CheckSystemCR() error {
 		}
 	}
 
-	err = CheckMongoURL(r.NooBaa)
-	if err != nil {
+	if _, err := ConfigEnv(r.NooBaa.Spec.Config); err != nil {
+		return util.NewPersistentError("InvalidConfig", err.Error())
+	}
+
+	if err := ValidateLogging(r.NooBaa.Spec.Logging); err != nil {
+		return util.NewPersistentError("InvalidLogging", err.Error())
+	}
+
+	if err := CheckMongoURL(r.NooBaa); err != nil {
 		return util.NewPersistentError("InvalidMongoDbURL", fmt.Sprintf(`%s`, err))
 	}
 
@@ -89,6 +89,9 @@ func (r *Reconciler) ReconcilePhaseCreatingForMainClusters() error {
 		}
 	}
 	if err := r.ReconcileRootSecret(); err != nil {
+		if len(r.NooBaa.Spec.Security.KeyManagementService.ConnectionDetails) != 0 {
+			return &KMSError{Err: err}
+		}
 		return err
 	}
 	if err := r.UpgradeSplitDB(); err != nil {
@@ -165,13 +168,33 @@ func (r *Reconciler) SetDesiredServiceMgmt() error {
 	return nil
 }
 
-// SetDesiredServiceS3 updates the ServiceS3 as desired for reconciling
+// SetDesiredServiceS3 updates the ServiceS3 as desired for reconciling.
+// The service selects the pods of the main endpoints and of the endpoint groups by EndpointPodLabel,
+// but keeps selecting the main endpoints by noobaa-s3 until they are rolled out with the new label.
 func (r *Reconciler) SetDesiredServiceS3() error {
-	r.ServiceS3.Spec.Selector["noobaa-s3"] = r.Request.Name
+	if r.ServiceS3.Spec.Selector[EndpointPodLabel] != "" || r.isEndpointPodLabelRolledOut() {
+		delete(r.ServiceS3.Spec.Selector, "noobaa-s3")
+		r.ServiceS3.Spec.Selector[EndpointPodLabel] = r.Request.Name
+	} else {
+		r.ServiceS3.Spec.Selector["noobaa-s3"] = r.Request.Name
+	}
 	r.ServiceS3.Labels["noobaa-s3-svc"] = "true"
 	return nil
 }
 
+// isEndpointPodLabelRolledOut returns true when all the main endpoint pods have EndpointPodLabel,
+// which is also the case for a new system that did not create the endpoint deployment yet
+func (r *Reconciler) isEndpointPodLabelRolledOut() bool {
+	deployment := &appsv1.Deployment{}
+	err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: r.Request.Namespace, Name: r.DeploymentEndpoint.Name}, deployment)
+	if err != nil {
+		return errors.IsNotFound(err)
+	}
+	return deployment.Spec.Template.Labels[EndpointPodLabel] == r.Request.Name &&
+		deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == deployment.Status.Replicas
+}
+
 // SetDesiredServiceDBForMongo updates the mongodb service
 func (r *Reconciler) SetDesiredServiceDBForMongo() error {
 	r.ServiceDb.Spec.Selector["noobaa-db"] = r.Request.Name
@@ -411,13 +434,17 @@ func (r *Reconciler) SetDesiredCoreApp() error {
 		c := &podSpec.Containers[i]
 		switch c.Name {
 		case "core":
-			if c.Image != r.NooBaa.Status.ActualImage {
+			if c.Image != r.CoreImage() {
 				coreImageChanged = true
-				c.Image = r.NooBaa.Status.ActualImage
+				c.Image = r.CoreImage()
 			}
 			// adding the missing Env variable from default container
 			util.MergeEnvArrays(&c.Env, &r.DefaultCoreApp.Env)
 			r.setDesiredCoreEnv(c)
+			configEnv, _ := ConfigEnv(r.NooBaa.Spec.Config)
+			if SetConfigEnv(&r.CoreApp.Spec.Template.ObjectMeta, c, configEnv, r.DefaultCoreApp.Env) && r.CoreApp.UID != "" {
+				r.recordConfigRollout(r.CoreApp.Name)
+			}
 
 			util.ReflectEnvVariable(&c.Env, "HTTP_PROXY")
 			util.ReflectEnvVariable(&c.Env, "HTTPS_PROXY")
@@ -446,14 +473,14 @@ func (r *Reconciler) SetDesiredCoreApp() error {
 		// generate info event for the first creation of noobaa
 		if r.Recorder != nil {
 			r.Recorder.Eventf(r.NooBaa, corev1.EventTypeNormal,
-				"NooBaaImage", `Using NooBaa image %q for the creation of %q`, r.NooBaa.Status.ActualImage, r.NooBaa.Name)
+				"NooBaaImage", `Using NooBaa image %q for the creation of %q`, r.CoreImage(), r.NooBaa.Name)
 		}
 	} else {
 		if coreImageChanged {
 			// generate info event for the first creation of noobaa
 			if r.Recorder != nil {
 				r.Recorder.Eventf(r.NooBaa, corev1.EventTypeNormal,
-					"NooBaaImage", `Updating NooBaa image to %q for %q`, r.NooBaa.Status.ActualImage, r.NooBaa.Name)
+					"NooBaaImage", `Updating NooBaa image to %q for %q`, r.CoreImage(), r.NooBaa.Name)
 			}
 		}
 
@@ -502,14 +529,14 @@ func (r *Reconciler) ReconcileRGWCredentials() error {
 
 	// Try to list ceph object store users to validate that the CRD is installed in the cluster.
 	cephObjectStoreUserList := &cephv1.CephObjectStoreUserList{}
-	if !util.KubeList(cephObjectStoreUserList, &client.ListOptions{Namespace: options.Namespace}) {
+	if !util.KubeList(cephObjectStoreUserList, &client.ListOptions{Namespace: r.Request.Namespace}) {
 		r.Logger.Info("failed to list ceph objectstore user, the scrd might not be installed in the cluster")
 		return nil
 	}
 
 	// Try to list the ceph object stores.
 	cephObjectStoreList := &cephv1.CephObjectStoreList{}
-	if !util.KubeList(cephObjectStoreList, &client.ListOptions{Namespace: options.Namespace}) {
+	if !util.KubeList(cephObjectStoreList, &client.ListOptions{Namespace: r.Request.Namespace}) {
 		r.Logger.Info("failed to list ceph objectstore to use as backing store")
 		return nil
 	}
@@ -682,6 +709,18 @@ func (r *Reconciler) SetDesiredAgentProfile(profileString string) string {
 	return string(profileBytes)
 }
 
+// KMSErrorReason is the reason of the progressing condition of a system
+// that failed to get or put its root key in the external KMS
+const KMSErrorReason = "ExternalKMSError"
+
+// KMSError is a temporary error of the system with its external KMS
+type KMSError struct {
+	Err error
+}
+
+// Error is implementing the standard error type interface
+func (e *KMSError) Error() string { return e.Err.Error() }
+
 // ReconcileRootSecret choose KMS for root secret key
 func (r *Reconciler) ReconcileRootSecret() error {
 	log := r.Logger
@@ -692,14 +731,14 @@ func (r *Reconciler) ReconcileRootSecret() error {
 
 	// set noobaa root master key secret
 	if len(connectionDetails) != 0 {
-		if err := util.ValidateConnectionDetails(connectionDetails, authTokenSecretName, options.Namespace); err != nil {
+		if err := util.ValidateConnectionDetails(connectionDetails, authTokenSecretName, r.Request.Namespace); err != nil {
 			return fmt.Errorf("could not get/put key in external KMS: external kms connection details validation failed: %q", err)
 		}
 		kmsProvider := connectionDetails["KMS_PROVIDER"]
 		if util.IsVaultKMS(kmsProvider) {
 			keySecretName := "rootkeyb64-" + string(r.NooBaa.ObjectMeta.UID)
 			// reconcile root master key externally (vault)
-			c, err := util.InitVaultClient(connectionDetails, authTokenSecretName, options.Namespace)
+			c, err := util.InitVaultClient(connectionDetails, authTokenSecretName, r.Request.Namespace)
 			if err == nil {
 				secretPath, err1 := util.BuildExternalSecretPath(c, r.NooBaa.Spec.Security.KeyManagementService, string(r.NooBaa.ObjectMeta.UID))
 				if err1 != nil {
@@ -758,7 +797,7 @@ func (r *Reconciler) UpgradeSplitDB() error {
 		TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "db-noobaa-core-0",
-			Namespace: options.Namespace,
+			Namespace: r.Request.Namespace,
 		},
 	}
 	if util.KubeCheckQuiet(oldPvc) {
@@ -815,7 +854,7 @@ func (r *Reconciler) UpgradeSplitDBCreateNewPVC(oldPvc *corev1.PersistentVolumeC
 		TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "db-" + r.NooBaaMongoDB.Name + "-0",
-			Namespace: options.Namespace,
+			Namespace: r.Request.Namespace,
 		},
 		Spec: oldPvc.Spec,
 	}
@@ -840,7 +879,7 @@ func (r *Reconciler) UpgradeSplitDBDeleteOldSTS() error {
 		TypeMeta: metav1.TypeMeta{Kind: "StatefulSet"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "noobaa-core",
-			Namespace: options.Namespace,
+			Namespace: r.Request.Namespace,
 		},
 	}
 	util.KubeDelete(oldSts)
@@ -862,7 +901,7 @@ func (r *Reconciler) UpgradeSplitDBDeleteOldPVC(oldPVC *corev1.PersistentVolumeC
 // UpgradeMigrateDB performs a db upgrade between mongodb to postgres
 func (r *Reconciler) UpgradeMigrateDB() error {
 	phase := r.NooBaa.Status.UpgradePhase
-	if phase == nbv1.UpgradePhaseFinished || phase == nbv1.UpgradePhaseNone {
+	if phase == nbv1.UpgradePhaseFinished || phase == nbv1.UpgradePhaseNone || IsImageUpgradePhase(phase) {
 		return nil
 	}
 
@@ -871,7 +910,7 @@ func (r *Reconciler) UpgradeMigrateDB() error {
 		TypeMeta: metav1.TypeMeta{Kind: "StatefulSet"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "noobaa-db",
-			Namespace: options.Namespace,
+			Namespace: r.Request.Namespace,
 		},
 	}
 
@@ -925,7 +964,7 @@ func (r *Reconciler) UpgradeMigrateDB() error {
 		// when starting - restart the db pod. This is a fix for https://bugzilla.redhat.com/show_bug.cgi?id=1922113
 		r.Logger.Info("getting noobaa-db-0 pod and deleting it if init container is old")
 		dbPod := &corev1.Pod{}
-		err := r.Client.Get(r.Ctx, types.NamespacedName{Namespace: options.Namespace, Name: "noobaa-db-0"}, dbPod)
+		err := r.Client.Get(r.Ctx, types.NamespacedName{Namespace: r.Request.Namespace, Name: "noobaa-db-0"}, dbPod)
 		if err != nil {
 			r.Logger.Errorf("got error when trying to get noobaa-db-0 pod - %v", err)
 			return err
@@ -953,9 +992,12 @@ func (r *Reconciler) UpgradeMigrateDB() error {
 		corePodSelector, _ := labels.Parse("noobaa-core=" + r.Request.Name)
 		epPodList := &corev1.PodList{}
 		epPodSelector, _ := labels.Parse("noobaa-s3=" + r.Request.Name)
-		if util.KubeList(epPodList, &client.ListOptions{Namespace: options.Namespace, LabelSelector: epPodSelector}) &&
-			util.KubeList(corePodList, &client.ListOptions{Namespace: options.Namespace, LabelSelector: corePodSelector}) &&
-			(len(corePodList.Items) == 0 && len(epPodList.Items) == 0) &&
+		groupPodList := &corev1.PodList{}
+		groupPodSelector, _ := labels.Parse(EndpointGroupLabel + "," + EndpointPodLabel + "=" + r.Request.Name)
+		if util.KubeList(epPodList, &client.ListOptions{Namespace: r.Request.Namespace, LabelSelector: epPodSelector}) &&
+			util.KubeList(groupPodList, &client.ListOptions{Namespace: r.Request.Namespace, LabelSelector: groupPodSelector}) &&
+			util.KubeList(corePodList, &client.ListOptions{Namespace: r.Request.Namespace, LabelSelector: corePodSelector}) &&
+			(len(corePodList.Items) == 0 && len(epPodList.Items) == 0 && len(groupPodList.Items) == 0) &&
 			(mongoSts.Status.ReadyReplicas == 1 && r.NooBaaPostgresDB.Status.ReadyReplicas == 1) {
 			r.Logger.Infof("UpgradeMigrateDB:: system is ready for migration. setting phase to %s", nbv1.UpgradePhaseMigrate)
 			phase = nbv1.UpgradePhaseMigrate
@@ -993,7 +1035,7 @@ func (r *Reconciler) UpgradeMigrateDB() error {
 
 		oldDbPodList := &corev1.PodList{}
 		oldDbPodSelector, _ := labels.Parse("noobaa-db=" + r.Request.Name)
-		if !util.KubeList(oldDbPodList, &client.ListOptions{Namespace: options.Namespace, LabelSelector: oldDbPodSelector}) {
+		if !util.KubeList(oldDbPodList, &client.ListOptions{Namespace: r.Request.Namespace, LabelSelector: oldDbPodSelector}) {
 			return nil
 		}
 		if len(oldDbPodList.Items) == 0 {
@@ -1047,7 +1089,7 @@ func (r *Reconciler) CleanupMigrationJob() error {
 	r.Logger.Infof("UpgradeMigrateDB:: deleting migration job pods")
 	jobPods := &corev1.PodList{}
 	jobPodsSelector, _ := labels.Parse("job-name=" + r.UpgradeJob.Name)
-	if !util.KubeList(jobPods, &client.ListOptions{Namespace: options.Namespace, LabelSelector: jobPodsSelector}) {
+	if !util.KubeList(jobPods, &client.ListOptions{Namespace: r.Request.Namespace, LabelSelector: jobPodsSelector}) {
 		return nil
 	}
 
@@ -1081,10 +1123,24 @@ func (r *Reconciler) SetDesiredJobUpgradeDB() error {
 }
 
 // SetEndpointsDeploymentReplicas updates the number of replicas on the endpoints deployment
+// and on the existing endpoint groups deployments
 func (r *Reconciler) SetEndpointsDeploymentReplicas(replicas int32) error {
 	r.Logger.Infof("UpgradeMigrateDB:: setting endpoints replica count to %d", replicas)
-	return r.ReconcileObject(r.DeploymentEndpoint, func() error {
+	if err := r.ReconcileObject(r.DeploymentEndpoint, func() error {
 		r.DeploymentEndpoint.Spec.Replicas = &replicas
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	r.LoadEndpointGroups()
+	for _, group := range r.EndpointGroups {
+		if !util.KubeCheckQuiet(group.Deployment) {
+			continue
+		}
+		group.Deployment.Spec.Replicas = &replicas
+		if err := r.Client.Update(r.Ctx, group.Deployment); err != nil {
+			return err
+		}
+	}
+	return nil
 }
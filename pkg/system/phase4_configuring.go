@@ -21,7 +21,11 @@ import (
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
 	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -57,12 +61,16 @@ func (r *Reconciler) ReconcilePhaseConfiguring() error {
 	if err := r.ReconcileSystemSecrets(); err != nil {
 		return err
 	}
+	r.ReconcileCoreLogging()
 	if err := r.ReconcileObject(r.DeploymentEndpoint, r.SetDesiredDeploymentEndpoint); err != nil {
 		return err
 	}
 	if err := r.ReconcileHPAEndpoint(); err != nil {
 		return err
 	}
+	if err := r.ReconcileEndpointGroups(); err != nil {
+		return err
+	}
 	if err := r.RegisterToCluster(); err != nil {
 		return err
 	}
@@ -244,18 +252,31 @@ func (r *Reconciler) SetDesiredSecretEndpoints() error {
 
 // SetDesiredDeploymentEndpoint updates the endpoint deployment as desired for reconciling
 func (r *Reconciler) SetDesiredDeploymentEndpoint() error {
-	r.DeploymentEndpoint.Spec.Selector.MatchLabels["noobaa-s3"] = r.Request.Name
-	r.DeploymentEndpoint.Spec.Template.Labels["noobaa-s3"] = r.Request.Name
-	r.DeploymentEndpoint.Spec.Template.Labels["app"] = r.Request.Name
+	return r.setDesiredEndpointDeployment(r.DeploymentEndpoint, nil)
+}
+
+// setDesiredEndpointDeployment updates the main endpoint deployment when group is nil,
+// or the deployment of an endpoint group which overrides the region, scheduling and resources
+func (r *Reconciler) setDesiredEndpointDeployment(deployment *appsv1.Deployment, group *nbv1.EndpointGroupSpec) error {
+	r.setEndpointLabels(deployment, group)
 
 	endpointsSpec := r.NooBaa.Spec.Endpoints
-	podSpec := &r.DeploymentEndpoint.Spec.Template.Spec
+	podSpec := &deployment.Spec.Template.Spec
 	if r.NooBaa.Spec.Tolerations != nil {
 		podSpec.Tolerations = r.NooBaa.Spec.Tolerations
 	}
 	if r.NooBaa.Spec.Affinity != nil {
 		podSpec.Affinity = r.NooBaa.Spec.Affinity
 	}
+	if group != nil {
+		if group.Tolerations != nil {
+			podSpec.Tolerations = group.Tolerations
+		}
+		if group.Affinity != nil {
+			podSpec.Affinity = group.Affinity
+		}
+		podSpec.NodeSelector = group.NodeSelector
+	}
 	if r.NooBaa.Spec.ImagePullSecret == nil {
 		podSpec.ImagePullSecrets =
 			[]corev1.LocalObjectReference{}
@@ -271,10 +292,13 @@ func (r *Reconciler) SetDesiredDeploymentEndpoint() error {
 		c := &podSpec.Containers[i]
 		switch c.Name {
 		case "endpoint":
-			c.Image = r.NooBaa.Status.ActualImage
+			c.Image = r.EndpointsImage()
 			if endpointsSpec != nil && endpointsSpec.Resources != nil {
 				c.Resources = *endpointsSpec.Resources
 			}
+			if group != nil && group.Resources != nil {
+				c.Resources = *group.Resources
+			}
 			mgmtBaseAddr := ""
 			s3BaseAddr := ""
 			util.MergeEnvArrays(&c.Env, &r.DefaultCoreApp.Env)
@@ -367,14 +391,10 @@ func (r *Reconciler) SetDesiredDeploymentEndpoint() error {
 					}
 					c.Env[j].Value = fmt.Sprint(strings.Join(hosts[:], " "))
 				case "ENDPOINT_GROUP_ID":
-					c.Env[j].Value = fmt.Sprint(r.NooBaa.UID)
+					c.Env[j].Value = r.endpointGroupID(group)
 
 				case "REGION":
-					if r.NooBaa.Spec.Region != nil {
-						c.Env[j].Value = *r.NooBaa.Spec.Region
-					} else {
-						c.Env[j].Value = ""
-					}
+					c.Env[j].Value = r.endpointGroupRegion(group)
 				}
 			}
 
@@ -387,33 +407,61 @@ func (r *Reconciler) SetDesiredDeploymentEndpoint() error {
 			util.ReflectEnvVariable(&c.Env, "HTTP_PROXY")
 			util.ReflectEnvVariable(&c.Env, "HTTPS_PROXY")
 			util.ReflectEnvVariable(&c.Env, "NO_PROXY")
+
+			configEnv, _ := ConfigEnv(r.NooBaa.Spec.Config)
+			if SetConfigEnv(&deployment.Spec.Template.ObjectMeta, c, configEnv, r.DefaultDeploymentEndpoint.Env) && deployment.UID != "" {
+				r.recordConfigRollout(deployment.Name)
+			}
 		}
 	}
 	return nil
 }
 
+// setEndpointLabels sets the selector and the pod labels of the main endpoint deployment when group is nil,
+// or of an endpoint group. Only the main endpoint pods have the noobaa-s3 label, and only the group pods
+// have the group label, so the selectors of the deployments do not match the pods of each other.
+func (r *Reconciler) setEndpointLabels(deployment *appsv1.Deployment, group *nbv1.EndpointGroupSpec) {
+	if deployment.Spec.Template.Labels == nil {
+		deployment.Spec.Template.Labels = map[string]string{}
+	}
+	deployment.Spec.Template.Labels[EndpointPodLabel] = r.Request.Name
+	deployment.Spec.Template.Labels["app"] = r.Request.Name
+	if group == nil {
+		deployment.Spec.Selector.MatchLabels["noobaa-s3"] = r.Request.Name
+		deployment.Spec.Template.Labels["noobaa-s3"] = r.Request.Name
+		return
+	}
+	deployment.Spec.Selector.MatchLabels = map[string]string{
+		EndpointPodLabel:   r.Request.Name,
+		EndpointGroupLabel: group.Name,
+	}
+	delete(deployment.Spec.Template.Labels, "noobaa-s3")
+	deployment.Spec.Template.Labels[EndpointGroupLabel] = group.Name
+}
+
 // ReconcileHPAEndpoint reconcile the endpoint's HPS and report the configuration
 // back to the noobaa core
 func (r *Reconciler) ReconcileHPAEndpoint() error {
-	if err := r.ReconcileObject(r.HPAEndpoint, r.SetDesiredHPAEndpoint); err != nil {
+	hpaV2, err := isHPAV2Available()
+	if err != nil {
 		return err
 	}
-
-	max := r.HPAEndpoint.Spec.MaxReplicas
-	min := r.HPAEndpoint.Spec.MaxReplicas
-	if r.HPAEndpoint.Spec.MinReplicas != nil {
-		min = *r.HPAEndpoint.Spec.MinReplicas
+	if hpaV2 {
+		if err := r.ReconcileObject(r.HPAEndpointV2, r.SetDesiredHPAEndpointV2); err != nil {
+			return err
+		}
+	} else {
+		if err := r.ReconcileObject(r.HPAEndpoint, r.SetDesiredHPAEndpoint); err != nil {
+			return err
+		}
 	}
 
-	region := ""
-	if r.NooBaa.Spec.Region != nil {
-		region = *r.NooBaa.Spec.Region
-	}
+	min, max := r.endpointsRange()
 
 	return r.NBClient.UpdateEndpointGroupAPI(nb.UpdateEndpointGroupParams{
-		GroupName: fmt.Sprint(r.NooBaa.UID),
+		GroupName: r.endpointGroupID(nil),
 		IsRemote:  r.JoinSecret != nil,
-		Region:    region,
+		Region:    r.endpointGroupRegion(nil),
 		EndpointRange: nb.IntRange{
 			Min: min,
 			Max: max,
@@ -423,6 +471,20 @@ func (r *Reconciler) ReconcileHPAEndpoint() error {
 
 // SetDesiredHPAEndpoint updates the endpoint horizontal pod autoscaler as desired for reconciling
 func (r *Reconciler) SetDesiredHPAEndpoint() error {
+	minReplicas, maxReplicas := r.endpointsRange()
+	r.setDesiredHPA(r.HPAEndpoint, minReplicas, maxReplicas)
+	return nil
+}
+
+// SetDesiredHPAEndpointV2 updates the endpoint autoscaling/v2beta2 horizontal pod autoscaler as desired for reconciling
+func (r *Reconciler) SetDesiredHPAEndpointV2() error {
+	minReplicas, maxReplicas := r.endpointsRange()
+	r.setDesiredHPAV2(r.HPAEndpointV2, minReplicas, maxReplicas)
+	return nil
+}
+
+// endpointsRange returns the autoscaling bounds of the main endpoint deployment
+func (r *Reconciler) endpointsRange() (int32, int32) {
 	var minReplicas int32 = 1
 	var maxReplicas int32 = 2
 
@@ -431,13 +493,215 @@ func (r *Reconciler) SetDesiredHPAEndpoint() error {
 		minReplicas = endpointsSpec.MinCount
 		maxReplicas = endpointsSpec.MaxCount
 	}
+	return minReplicas, maxReplicas
+}
 
-	r.HPAEndpoint.Spec.MinReplicas = &minReplicas
-	r.HPAEndpoint.Spec.MaxReplicas = maxReplicas
+// isHPAV2Available returns true if the cluster serves autoscaling/v2beta2 which scales on
+// any metric and supports the scaling behavior, while autoscaling/v1 scales only on cpu.
+// A failed discovery returns an error to requeue, since reconciling the autoscalers
+// as autoscaling/v1 would drop the metrics and the behavior of the existing autoscalers.
+var isHPAV2Available = func() (bool, error) {
+	return util.LookupKubeAPI("autoscaling/v2beta2", "HorizontalPodAutoscaler")
+}
 
+// setDesiredHPAV2 sets the bounds, the metrics and the behavior of an endpoints autoscaler
+func (r *Reconciler) setDesiredHPAV2(hpa *autoscalingv2beta2.HorizontalPodAutoscaler, minReplicas int32, maxReplicas int32) {
+	hpa.Spec.MinReplicas = &minReplicas
+	hpa.Spec.MaxReplicas = maxReplicas
+	hpa.Spec.Metrics = []autoscalingv2beta2.MetricSpec{defaultEndpointsCPUMetric()}
+	hpa.Spec.Behavior = nil
+	endpointsSpec := r.NooBaa.Spec.Endpoints
+	if endpointsSpec != nil && endpointsSpec.Autoscaling != nil {
+		if len(endpointsSpec.Autoscaling.Metrics) > 0 {
+			hpa.Spec.Metrics = endpointsSpec.Autoscaling.Metrics
+		}
+		hpa.Spec.Behavior = endpointsSpec.Autoscaling.Behavior
+	}
+}
+
+// setDesiredHPA sets the bounds and the cpu target of an endpoints autoscaler on clusters
+// without autoscaling/v2beta2, where the other metrics and the behavior are ignored
+func (r *Reconciler) setDesiredHPA(hpa *autoscalingv1.HorizontalPodAutoscaler, minReplicas int32, maxReplicas int32) {
+	hpa.Spec.MinReplicas = &minReplicas
+	hpa.Spec.MaxReplicas = maxReplicas
+	targetCPU := *defaultEndpointsCPUMetric().Resource.Target.AverageUtilization
+	endpointsSpec := r.NooBaa.Spec.Endpoints
+	if endpointsSpec != nil && endpointsSpec.Autoscaling != nil {
+		ignored := endpointsSpec.Autoscaling.Behavior != nil
+		for _, m := range endpointsSpec.Autoscaling.Metrics {
+			if m.Type == autoscalingv2beta2.ResourceMetricSourceType && m.Resource != nil &&
+				m.Resource.Name == corev1.ResourceCPU && m.Resource.Target.AverageUtilization != nil {
+				targetCPU = *m.Resource.Target.AverageUtilization
+			} else {
+				ignored = true
+			}
+		}
+		if ignored {
+			r.Logger.Warnf("Endpoints autoscaling: autoscaling/v2beta2 is not available, scaling %q only on cpu", hpa.Name)
+		}
+	}
+	hpa.Spec.TargetCPUUtilizationPercentage = &targetCPU
+}
+
+// defaultEndpointsCPUMetric is the metric used to scale the endpoints when the spec does not set metrics
+func defaultEndpointsCPUMetric() autoscalingv2beta2.MetricSpec {
+	targetCPU := int32(80)
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: corev1.ResourceCPU,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: &targetCPU,
+			},
+		},
+	}
+}
+
+// ReconcileEndpointGroups reconciles the deployment and autoscaler of every endpoint group,
+// registers the groups to the noobaa core and deletes the groups that were removed from the spec
+func (r *Reconciler) ReconcileEndpointGroups() error {
+	r.LoadEndpointGroups()
+	names := map[string]bool{}
+	for _, group := range r.EndpointGroups {
+		if names[group.Spec.Name] {
+			return util.NewPersistentError("DuplicateEndpointGroup",
+				fmt.Sprintf("Endpoint group name %q is used more than once", group.Spec.Name))
+		}
+		names[group.Spec.Name] = true
+		if err := r.deleteOverlappingEndpointGroup(group); err != nil {
+			return err
+		}
+		if err := r.ReconcileObject(group.Deployment, func() error {
+			return r.setDesiredEndpointDeployment(group.Deployment, group.Spec)
+		}); err != nil {
+			return err
+		}
+		min, max := endpointGroupRange(group.Spec)
+		hpaV2, err := isHPAV2Available()
+		if err != nil {
+			return err
+		}
+		if hpaV2 {
+			if err := r.ReconcileObject(group.HPAV2, func() error {
+				r.setDesiredHPAV2(group.HPAV2, min, max)
+				return nil
+			}); err != nil {
+				return err
+			}
+		} else {
+			if err := r.ReconcileObject(group.HPA, func() error {
+				r.setDesiredHPA(group.HPA, min, max)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		if err := r.NBClient.UpdateEndpointGroupAPI(nb.UpdateEndpointGroupParams{
+			GroupName: r.endpointGroupID(group.Spec),
+			IsRemote:  r.JoinSecret != nil,
+			Region:    r.endpointGroupRegion(group.Spec),
+			EndpointRange: nb.IntRange{
+				Min: min,
+				Max: max,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return r.deleteRemovedEndpointGroups(names)
+}
+
+// deleteOverlappingEndpointGroup deletes the deployment of an endpoint group that selects its pods
+// by the noobaa-s3 label of the main endpoints. The selector of a deployment is immutable,
+// so the deployment is deleted and the next reconcile recreates it with the group selector.
+func (r *Reconciler) deleteOverlappingEndpointGroup(group *EndpointGroup) error {
+	existing := &appsv1.Deployment{}
+	err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: group.Deployment.Namespace, Name: group.Deployment.Name}, existing)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Spec.Selector == nil || existing.Spec.Selector.MatchLabels["noobaa-s3"] == "" {
+		return nil
+	}
+	r.Logger.Infof("Deleting endpoint group deployment %q that overlaps the main endpoints selector", existing.Name)
+	if err := r.Client.Delete(r.Ctx, existing); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return fmt.Errorf("endpoint group deployment %q is recreated with a new selector", existing.Name)
+}
+
+// deleteRemovedEndpointGroups unregisters from the noobaa core and deletes the deployments and autoscalers
+// of the endpoint groups that are owned by the system and are no longer in the spec
+func (r *Reconciler) deleteRemovedEndpointGroups(names map[string]bool) error {
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(r.Ctx, deployments, client.InNamespace(r.Request.Namespace), client.HasLabels{EndpointGroupLabel}); err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		name := d.Labels[EndpointGroupLabel]
+		if names[name] || !metav1.IsControlledBy(d, r.NooBaa) {
+			continue
+		}
+		r.Logger.Infof("Deleting removed endpoint group %q", name)
+		// the core has no api to remove an endpoint group, so the group is left without endpoints
+		if err := r.NBClient.UpdateEndpointGroupAPI(nb.UpdateEndpointGroupParams{
+			GroupName:     r.endpointGroupID(&nbv1.EndpointGroupSpec{Name: name}),
+			IsRemote:      r.JoinSecret != nil,
+			EndpointRange: nb.IntRange{Min: 0, Max: 0},
+		}); err != nil {
+			return err
+		}
+		hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: d.Name, Namespace: d.Namespace}}
+		if err := r.Client.Delete(r.Ctx, hpa); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err := r.Client.Delete(r.Ctx, d); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
 	return nil
 }
 
+// endpointGroupRange returns the autoscaling bounds of an endpoint group,
+// which defaults to a single endpoint and is never below the lower bound
+func endpointGroupRange(group *nbv1.EndpointGroupSpec) (int32, int32) {
+	min := group.MinCount
+	if min < 1 {
+		min = 1
+	}
+	max := group.MaxCount
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+// endpointGroupID returns the group name that the endpoints report to the noobaa core,
+// which is the system uid for the main endpoint deployment
+func (r *Reconciler) endpointGroupID(group *nbv1.EndpointGroupSpec) string {
+	if group == nil {
+		return fmt.Sprint(r.NooBaa.UID)
+	}
+	return fmt.Sprintf("%s-%s", r.NooBaa.UID, group.Name)
+}
+
+// endpointGroupRegion returns the region of an endpoint group,
+// which is the system region for the main endpoint deployment
+func (r *Reconciler) endpointGroupRegion(group *nbv1.EndpointGroupSpec) string {
+	if group != nil {
+		return group.Region
+	}
+	if r.NooBaa.Spec.Region != nil {
+		return *r.NooBaa.Spec.Region
+	}
+	return ""
+}
+
 // RegisterToCluster registers the noobaa client with the noobaa cluster
 func (r *Reconciler) RegisterToCluster() error {
 	// Skip if joining another NooBaa
@@ -445,7 +709,14 @@ func (r *Reconciler) RegisterToCluster() error {
 		return nil
 	}
 
-	return r.NBClient.RegisterToCluster()
+	if err := r.NBClient.RegisterToCluster(); err != nil {
+		return err
+	}
+	if GlobalStatusNotifier != nil {
+		router := &nb.APIRouterServicePort{ServiceMgmt: r.ServiceMgmt}
+		GlobalStatusNotifier.Registered(router.GetAddress("redirector_api"), r.Request.Namespace)
+	}
+	return nil
 }
 
 // ReconcileDefaultBackingStore attempts to get credentials to cloud storage using the cloud-credentials operator
@@ -829,7 +1100,7 @@ func (r *Reconciler) prepareCephBackingStore() error {
 	cephObjectStoreUserSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secretName,
-			Namespace: options.Namespace,
+			Namespace: r.Request.Namespace,
 		},
 	}
 	util.KubeCheck(cephObjectStoreUserSecret)
@@ -865,7 +1136,7 @@ func (r *Reconciler) prepareCephBackingStore() error {
 	r.DefaultBackingStore.ObjectMeta.Annotations["rgw"] = ""
 	r.DefaultBackingStore.Spec.Type = nbv1.StoreTypeS3Compatible
 	r.DefaultBackingStore.Spec.S3Compatible = &nbv1.S3CompatibleSpec{
-		Secret:           corev1.SecretReference{Name: secretName, Namespace: options.Namespace},
+		Secret:           corev1.SecretReference{Name: secretName, Namespace: r.Request.Namespace},
 		TargetBucket:     bucketName,
 		Endpoint:         endpoint,
 		SignatureVersion: nbv1.S3SignatureVersionV4,
@@ -1004,56 +1275,109 @@ func (r *Reconciler) ReconcileServiceMonitors() error {
 	return nil
 }
 
-// ReconcileReadSystem calls read_system on noobaa server and stores the result
+// ReconcileReadSystem calls read_system on noobaa server and stores the result.
+// In the operator the read is skipped when a notification refreshed the status just before,
+// and that result is used instead to update the core version and to sync the namespace stores.
 func (r *Reconciler) ReconcileReadSystem() error {
 	// Skip if joining another NooBaa
 	if r.JoinSecret != nil {
 		return nil
 	}
 
-	// update noobaa-core version in reconciler struct
+	if GlobalStatusNotifier != nil {
+		if systemInfo := GlobalStatusNotifier.FreshSystemInfo(r.Request.Namespace); systemInfo != nil {
+			r.Logger.Infof("system status is up to date from notifications, skipping read_system")
+			return r.SyncSystemInfo(systemInfo)
+		}
+	}
+
+	systemInfo, err := r.ReadSystemStatus()
+	if err != nil {
+		return err
+	}
+	return r.SyncSystemInfo(systemInfo)
+}
+
+// RefreshStatus reads the status of a ready system and updates the status of its stores and bucket classes,
+// without reconciling or creating any of the system resources. It is called by the status notifier,
+// and returns nil info when the system is not ready to be read.
+func (r *Reconciler) RefreshStatus() (*nb.SystemInfo, error) {
+	if !CheckSystem(r.NooBaa) {
+		return nil, nil
+	}
+	if r.NooBaa.Spec.JoinSecret != nil || r.NooBaa.Status.Phase != nbv1.SystemPhaseReady {
+		return nil, nil
+	}
+
+	if !util.KubeCheckQuiet(r.ServiceMgmt) {
+		return nil, fmt.Errorf("Could not load the mgmt service")
+	}
+	if err := r.InitNBClient(); err != nil {
+		return nil, err
+	}
+	if !util.KubeCheckQuiet(r.SecretOp) {
+		return nil, fmt.Errorf("Could not load the operator secret")
+	}
+	util.SecretResetStringDataFromData(r.SecretOp)
+	r.NBClient.SetAuthToken(r.SecretOp.StringData["auth_token"])
+
+	return r.ReadSystemStatus()
+}
+
+// ReadSystemStatus calls read_system and updates the status of the stores, bucket classes and system metrics
+func (r *Reconciler) ReadSystemStatus() (*nb.SystemInfo, error) {
 	systemInfo, err := r.NBClient.ReadSystemAPI()
 	if err != nil {
 		r.Logger.Errorf("failed to read system info: %v", err)
-		return err
+		return nil, err
 	}
-	r.SystemInfo = &systemInfo
+
+	// update backingstores, namespacestores and bucketclass mode
+	r.UpdateBackingStoresPhase(systemInfo.Pools)
+	r.UpdateNamespaceStoresPhase(systemInfo.NamespaceResources)
+	r.UpdateBucketClassesPhase(systemInfo.Buckets)
+
+	r.UpdateSystemMetrics(&systemInfo)
+
+	return &systemInfo, nil
+}
+
+// SyncSystemInfo updates the noobaa-core version in the reconciler from the read_system result,
+// and creates the namespace stores that noobaa-core asks to sync on upgrade
+func (r *Reconciler) SyncSystemInfo(systemInfo *nb.SystemInfo) error {
+	r.SystemInfo = systemInfo
 	r.Logger.Infof("updating noobaa-core version to %s", systemInfo.Version)
 	r.CoreVersion = systemInfo.Version
 
 	// creates namespace stores if sync is needed on upgrade
-	if len(systemInfo.NamespaceResources) > 0 {
+	if len(systemInfo.NamespaceResources) > 0 && !r.Paused {
 		if err := r.ReconcileNamespaceStores(systemInfo.NamespaceResources); err != nil {
 			r.Logger.Infof("got error on ReconcileNamespaceStores, %+v", err)
 			return err
 		}
 	}
-
-	// update backingstores, namespacestores and bucketclass mode
-	r.UpdateBackingStoresPhase(systemInfo.Pools)
-	r.UpdateNamespaceStoresPhase(systemInfo.NamespaceResources)
-	r.UpdateBucketClassesPhase(systemInfo.Buckets)
-
 	return nil
 }
 
 // UpdateBackingStoresPhase updates newPhase of backingstore after readSystem
+// The mode is patched so it does not conflict with the status updates of the backingstore reconciler.
 func (r *Reconciler) UpdateBackingStoresPhase(pools []nb.PoolInfo) {
 
 	bsList := &nbv1.BackingStoreList{
 		TypeMeta: metav1.TypeMeta{Kind: "BackingStoreList"},
 	}
-	if !util.KubeList(bsList, &client.ListOptions{Namespace: options.Namespace}) {
+	if !util.KubeList(bsList, &client.ListOptions{Namespace: r.Request.Namespace}) {
 		logrus.Errorf("not found: Backing Store list")
 	}
 	for i := range bsList.Items {
 		bs := &bsList.Items[i]
 		for _, pool := range pools {
 			if pool.Name == bs.Name && bs.Status.Mode.ModeCode != pool.Mode {
+				patch := client.MergeFrom(bs.DeepCopy())
 				bs.Status.Mode.ModeCode = pool.Mode
 				bs.Status.Mode.TimeStamp = fmt.Sprint(time.Now())
 				r.NooBaa.Status.ObservedGeneration = r.NooBaa.Generation
-				err := r.Client.Status().Update(r.Ctx, bs)
+				err := r.Client.Status().Patch(r.Ctx, bs, patch)
 				if err != nil {
 					logrus.Errorf("got error when trying to update status of backingstore %v. %v", bs.Name, err)
 				}
@@ -1063,22 +1387,24 @@ func (r *Reconciler) UpdateBackingStoresPhase(pools []nb.PoolInfo) {
 }
 
 // UpdateNamespaceStoresPhase updates newPhase of namespace resource after readSystem
+// The mode is patched so it does not conflict with the status updates of the namespacestore reconciler.
 func (r *Reconciler) UpdateNamespaceStoresPhase(namespaceResources []nb.NamespaceResourceInfo) {
 
 	nssList := &nbv1.NamespaceStoreList{
 		TypeMeta: metav1.TypeMeta{Kind: "NamespaceStoreList"},
 	}
-	if !util.KubeList(nssList, &client.ListOptions{Namespace: options.Namespace}) {
+	if !util.KubeList(nssList, &client.ListOptions{Namespace: r.Request.Namespace}) {
 		logrus.Errorf("not found: Namespace Store list")
 	}
 	for i := range nssList.Items {
 		nss := &nssList.Items[i]
 		for _, namespaceResource := range namespaceResources {
 			if namespaceResource.Name == nss.Name && nss.Status.Mode.ModeCode != namespaceResource.Mode {
+				patch := client.MergeFrom(nss.DeepCopy())
 				nss.Status.Mode.ModeCode = namespaceResource.Mode
 				nss.Status.Mode.TimeStamp = fmt.Sprint(time.Now())
 				r.NooBaa.Status.ObservedGeneration = r.NooBaa.Generation
-				err := r.Client.Status().Update(r.Ctx, nss)
+				err := r.Client.Status().Patch(r.Ctx, nss, patch)
 				if err != nil {
 					logrus.Errorf("got error when trying to update status of namespacestore %v. %v", nss.Name, err)
 				}
@@ -1088,12 +1414,13 @@ func (r *Reconciler) UpdateNamespaceStoresPhase(namespaceResources []nb.Namespac
 }
 
 // UpdateBucketClassesPhase updates newPhase of bucketclass after readSystem
+// The mode is patched so it does not conflict with the status updates of the bucketclass reconciler.
 func (r *Reconciler) UpdateBucketClassesPhase(Buckets []nb.BucketInfo) {
 
 	bucketclassList := &nbv1.BucketClassList{
 		TypeMeta: metav1.TypeMeta{Kind: "BucketClassList"},
 	}
-	if !util.KubeList(bucketclassList, &client.ListOptions{Namespace: options.Namespace}) {
+	if !util.KubeList(bucketclassList, &client.ListOptions{Namespace: r.Request.Namespace}) {
 		logrus.Errorf("not found: Backing Store list")
 	}
 	for i := range bucketclassList.Items {
@@ -1105,9 +1432,10 @@ func (r *Reconciler) UpdateBucketClassesPhase(Buckets []nb.BucketInfo) {
 				bucketTieringPolicyName = bucket.BucketClaim.BucketClass
 			}
 			if bc.Name == bucketTieringPolicyName && bucket.Tiering.Mode != bc.Status.Mode {
+				patch := client.MergeFrom(bc.DeepCopy())
 				bc.Status.Mode = bucket.Tiering.Mode
 				r.NooBaa.Status.ObservedGeneration = r.NooBaa.Generation
-				err := r.Client.Status().Update(r.Ctx, bc)
+				err := r.Client.Status().Patch(r.Ctx, bc, patch)
 				if err != nil {
 					logrus.Errorf("got error when trying to update status of bucket class %v. %v ", bc.Name, err)
 				}
@@ -1144,6 +1472,17 @@ func (r *Reconciler) ReconcileDeploymentEndpointStatus() error {
 		ReadyCount:   r.DeploymentEndpoint.Status.ReadyReplicas,
 		VirtualHosts: virtualHosts,
 	}
+	for _, group := range r.EndpointGroups {
+		readyCount := int32(0)
+		if util.KubeCheckQuiet(group.Deployment) {
+			readyCount = group.Deployment.Status.ReadyReplicas
+		}
+		r.NooBaa.Status.Endpoints.Groups = append(r.NooBaa.Status.Endpoints.Groups, nbv1.EndpointGroupStatus{
+			Name:       group.Spec.Name,
+			Region:     group.Spec.Region,
+			ReadyCount: readyCount,
+		})
+	}
 
 	return nil
 }
@@ -1158,7 +1497,7 @@ func (r *Reconciler) ReconcileNamespaceStores(namespaceResources []nb.NamespaceR
 			TypeMeta: metav1.TypeMeta{Kind: "NamespaceStore"},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      nsr.Name,
-				Namespace: options.Namespace,
+				Namespace: r.Request.Namespace,
 			},
 		}
 		if !util.KubeCheck(nsStore) {
@@ -1173,7 +1512,7 @@ func (r *Reconciler) ReconcileNamespaceStores(namespaceResources []nb.NamespaceR
 
 			o := util.KubeObject(bundle.File_deploy_internal_secret_empty_yaml)
 			secret := o.(*corev1.Secret)
-			secret.Namespace = options.Namespace
+			secret.Namespace = r.Request.Namespace
 			secret.Data = nil
 			secret.StringData = map[string]string{}
 
@@ -1268,10 +1607,10 @@ func (r *Reconciler) ReconcileNamespaceStores(namespaceResources []nb.NamespaceR
 			}
 			err = r.NBClient.SetNamespaceStoreInfo(nb.NamespaceStoreInfo{
 				Name:      nsr.Name,
-				Namespace: options.Namespace,
+				Namespace: r.Request.Namespace,
 			})
 			if err != nil {
-				logrus.Infof("couldn't update namespace store info for namespace resource %q in namespace %q", nsr.Name, options.Namespace)
+				logrus.Infof("couldn't update namespace store info for namespace resource %q in namespace %q", nsr.Name, r.Request.Namespace)
 			}
 		}
 	}
@@ -0,0 +1,250 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "test"
+
+// fakeNBClient records the endpoint groups and the debug levels that are sent to the noobaa core
+type fakeNBClient struct {
+	nb.Client
+	updates     []nb.UpdateEndpointGroupParams
+	debugLevels []nb.DebugAPISetDebugLevelParams
+}
+
+func (c *fakeNBClient) UpdateEndpointGroupAPI(params nb.UpdateEndpointGroupParams) error {
+	c.updates = append(c.updates, params)
+	return nil
+}
+
+func (c *fakeNBClient) DebugAPISetDebugLevel(params nb.DebugAPISetDebugLevelParams) error {
+	c.debugLevels = append(c.debugLevels, params)
+	return nil
+}
+
+func newFakeReconciler(t *testing.T, objs ...runtime.Object) (*Reconciler, *fakeNBClient) {
+	sys := &nbv1.NooBaa{ObjectMeta: metav1.ObjectMeta{Name: "noobaa", Namespace: testNamespace, UID: "sys-uid"}}
+	nbClient := &fakeNBClient{}
+	r := &Reconciler{
+		Request:  types.NamespacedName{Namespace: testNamespace, Name: sys.Name},
+		Client:   fake.NewFakeClient(objs...),
+		Ctx:      context.TODO(),
+		Logger:   logrus.WithField("test", t.Name()),
+		NooBaa:   sys,
+		NBClient: nbClient,
+		Scheme:   scheme.Scheme,
+	}
+	return r, nbClient
+}
+
+// newGroupDeployment returns a deployment of an endpoint group, owned by the system when owner is set
+func newGroupDeployment(name string, group string, owner bool) *appsv1.Deployment {
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: testNamespace,
+		Labels:    map[string]string{EndpointGroupLabel: group},
+	}}
+	if owner {
+		controller := true
+		d.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "noobaa.io/v1alpha1",
+			Kind:       "NooBaa",
+			Name:       "noobaa",
+			UID:        "sys-uid",
+			Controller: &controller,
+		}}
+	}
+	return d
+}
+
+func TestEndpointGroupLabels(t *testing.T) {
+	r, _ := newFakeReconciler(t)
+	main := util.KubeObject(bundle.File_deploy_internal_deployment_endpoint_yaml).(*appsv1.Deployment)
+	group := util.KubeObject(bundle.File_deploy_internal_deployment_endpoint_yaml).(*appsv1.Deployment)
+	r.setEndpointLabels(main, nil)
+	r.setEndpointLabels(group, &nbv1.EndpointGroupSpec{Name: "edge"})
+
+	mainSelector := labels.SelectorFromSet(main.Spec.Selector.MatchLabels)
+	groupSelector := labels.SelectorFromSet(group.Spec.Selector.MatchLabels)
+	mainPods := labels.Set(main.Spec.Template.Labels)
+	groupPods := labels.Set(group.Spec.Template.Labels)
+	if !mainSelector.Matches(mainPods) || !groupSelector.Matches(groupPods) {
+		t.Fatalf("expected the deployments to select their own pods, got %v %v", main.Spec.Selector, group.Spec.Selector)
+	}
+	if mainSelector.Matches(groupPods) {
+		t.Fatalf("expected the main endpoints selector %v not to match the group pods %v", mainSelector, groupPods)
+	}
+	if groupSelector.Matches(mainPods) {
+		t.Fatalf("expected the group selector %v not to match the main endpoint pods %v", groupSelector, mainPods)
+	}
+	serviceSelector := labels.SelectorFromSet(labels.Set{EndpointPodLabel: "noobaa"})
+	if !serviceSelector.Matches(mainPods) || !serviceSelector.Matches(groupPods) {
+		t.Fatalf("expected the s3 service to select all the endpoint pods, got %v %v", mainPods, groupPods)
+	}
+}
+
+func TestDeleteRemovedEndpointGroups(t *testing.T) {
+	r, nbClient := newFakeReconciler(t,
+		newGroupDeployment("noobaa-endpoint-kept", "kept", true),
+		newGroupDeployment("noobaa-endpoint-removed", "removed", true),
+		&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "noobaa-endpoint-removed", Namespace: testNamespace}},
+		newGroupDeployment("other-endpoint-removed", "removed", false),
+	)
+	if err := r.deleteRemovedEndpointGroups(map[string]bool{"kept": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, exists := range map[string]bool{
+		"noobaa-endpoint-kept":    true,
+		"noobaa-endpoint-removed": false,
+		"other-endpoint-removed":  true,
+	} {
+		err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: testNamespace, Name: name}, &appsv1.Deployment{})
+		if exists && err != nil {
+			t.Errorf("expected deployment %q to be kept, got %v", name, err)
+		}
+		if !exists && !errors.IsNotFound(err) {
+			t.Errorf("expected deployment %q to be deleted, got %v", name, err)
+		}
+	}
+	err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: testNamespace, Name: "noobaa-endpoint-removed"}, &autoscalingv1.HorizontalPodAutoscaler{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the autoscaler of the removed group to be deleted, got %v", err)
+	}
+
+	if len(nbClient.updates) != 1 {
+		t.Fatalf("expected the removed group to be unregistered once, got %+v", nbClient.updates)
+	}
+	if u := nbClient.updates[0]; u.GroupName != "sys-uid-removed" || u.EndpointRange.Max != 0 {
+		t.Fatalf("expected the removed group to be left without endpoints, got %+v", u)
+	}
+}
+
+func TestDeleteOverlappingEndpointGroup(t *testing.T) {
+	overlapping := newGroupDeployment("noobaa-endpoint-edge", "edge", true)
+	overlapping.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"noobaa-s3": "noobaa", EndpointGroupLabel: "edge"}}
+	r, _ := newFakeReconciler(t, overlapping)
+	group := &EndpointGroup{Deployment: newGroupDeployment("noobaa-endpoint-edge", "edge", true)}
+
+	if err := r.deleteOverlappingEndpointGroup(group); err == nil {
+		t.Fatalf("expected the overlapping deployment to be recreated on the next reconcile")
+	}
+	err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: testNamespace, Name: "noobaa-endpoint-edge"}, &appsv1.Deployment{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected the overlapping deployment to be deleted, got %v", err)
+	}
+	if err := r.deleteOverlappingEndpointGroup(group); err != nil {
+		t.Fatalf("expected a missing deployment to be created by the reconcile, got %v", err)
+	}
+}
+
+// setHPAV2Available fakes the discovery of autoscaling/v2beta2 until the test ends
+func setHPAV2Available(t *testing.T, available bool, err error) {
+	prev := isHPAV2Available
+	isHPAV2Available = func() (bool, error) { return available, err }
+	t.Cleanup(func() { isHPAV2Available = prev })
+}
+
+func newHPATest(t *testing.T, objs ...runtime.Object) (*Reconciler, *fakeNBClient) {
+	r, nbClient := newFakeReconciler(t, objs...)
+	r.HPAEndpoint = util.KubeObject(bundle.File_deploy_internal_hpa_endpoint_yaml).(*autoscalingv1.HorizontalPodAutoscaler)
+	r.HPAEndpoint.Namespace = testNamespace
+	r.HPAEndpointV2 = util.KubeObject(bundle.File_deploy_internal_hpav2_endpoint_yaml).(*autoscalingv2beta2.HorizontalPodAutoscaler)
+	r.HPAEndpointV2.Namespace = testNamespace
+	r.NooBaa.Spec.Endpoints = &nbv1.EndpointsSpec{
+		MinCount: 1,
+		MaxCount: 4,
+		Autoscaling: &nbv1.EndpointsAutoscalingSpec{
+			Metrics: []autoscalingv2beta2.MetricSpec{{
+				Type: autoscalingv2beta2.PodsMetricSourceType,
+				Pods: &autoscalingv2beta2.PodsMetricSource{
+					Metric: autoscalingv2beta2.MetricIdentifier{Name: "s3_requests_per_second"},
+					Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.AverageValueMetricType},
+				},
+			}},
+		},
+	}
+	return r, nbClient
+}
+
+func TestReconcileHPAEndpoint(t *testing.T) {
+	// autoscaling/v2beta2 keeps the metrics of the spec
+	setHPAV2Available(t, true, nil)
+	r, nbClient := newHPATest(t)
+	if err := r.ReconcileHPAEndpoint(); err != nil {
+		t.Fatal(err)
+	}
+	hpaV2 := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	if err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: testNamespace, Name: "noobaa-endpoint"}, hpaV2); err != nil {
+		t.Fatal(err)
+	}
+	if len(hpaV2.Spec.Metrics) != 1 || hpaV2.Spec.Metrics[0].Type != autoscalingv2beta2.PodsMetricSourceType || hpaV2.Spec.MaxReplicas != 4 {
+		t.Fatalf("expected the autoscaler to scale on the spec metrics, got %+v", hpaV2.Spec)
+	}
+	if len(nbClient.updates) != 1 || nbClient.updates[0].EndpointRange.Max != 4 {
+		t.Fatalf("expected the endpoints range to be reported to the core, got %+v", nbClient.updates)
+	}
+
+	// autoscaling/v1 scales on cpu only
+	setHPAV2Available(t, false, nil)
+	r, _ = newHPATest(t)
+	if err := r.ReconcileHPAEndpoint(); err != nil {
+		t.Fatal(err)
+	}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: testNamespace, Name: "noobaa-endpoint"}, hpa); err != nil {
+		t.Fatal(err)
+	}
+	if hpa.Spec.TargetCPUUtilizationPercentage == nil || *hpa.Spec.TargetCPUUtilizationPercentage != 80 || hpa.Spec.MaxReplicas != 4 {
+		t.Fatalf("expected the autoscaler to scale on the default cpu target, got %+v", hpa.Spec)
+	}
+}
+
+func TestReconcileHPAEndpointDiscoveryFailure(t *testing.T) {
+	// a failed discovery requeues and does not rewrite the existing autoscaler as autoscaling/v1
+	existing := util.KubeObject(bundle.File_deploy_internal_hpav2_endpoint_yaml).(*autoscalingv2beta2.HorizontalPodAutoscaler)
+	existing.Namespace = testNamespace
+	existing.Spec.Metrics = []autoscalingv2beta2.MetricSpec{{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name:   corev1.ResourceMemory,
+			Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.UtilizationMetricType},
+		},
+	}}
+	setHPAV2Available(t, false, fmt.Errorf("discovery failed"))
+	r, nbClient := newHPATest(t, existing)
+	if err := r.ReconcileHPAEndpoint(); err == nil {
+		t.Fatalf("expected the discovery failure to requeue")
+	}
+	hpaV2 := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	if err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: testNamespace, Name: "noobaa-endpoint"}, hpaV2); err != nil {
+		t.Fatal(err)
+	}
+	if len(hpaV2.Spec.Metrics) != 1 || hpaV2.Spec.Metrics[0].Resource.Name != corev1.ResourceMemory {
+		t.Fatalf("expected the existing autoscaler to be kept, got %+v", hpaV2.Spec)
+	}
+	if len(nbClient.updates) != 0 {
+		t.Fatalf("expected nothing to be reported to the core, got %+v", nbClient.updates)
+	}
+}
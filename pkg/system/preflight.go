@@ -0,0 +1,594 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
+	"github.com/noobaa/noobaa-operator/v2/pkg/crd"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PreflightResult is the result of a single preflight check
+type PreflightResult string
+
+const (
+	// PreflightPass means the cluster meets the check
+	PreflightPass PreflightResult = "Pass"
+	// PreflightWarn means the install can proceed but might not become ready
+	PreflightWarn PreflightResult = "Warn"
+	// PreflightFail means the install would get stuck and should not proceed
+	PreflightFail PreflightResult = "Fail"
+)
+
+// PreflightCheck is the outcome of a single preflight check
+type PreflightCheck struct {
+	Name    string          `json:"name"`
+	Result  PreflightResult `json:"result"`
+	Message string          `json:"message"`
+}
+
+// PreflightInputs are the cluster resources that the preflight checks analyze.
+// The probes are functions so that the checks can run without network access.
+type PreflightInputs struct {
+	NooBaa              *nbv1.NooBaa
+	StorageClasses      []storagev1.StorageClass
+	StorageClassesError error
+	Nodes               []corev1.Node
+	NodesError          error
+	SCCAvailable        bool
+	BundledCRDs         []*crd.CRD
+	ExistingCRDs        map[string]*crd.CRD
+	Secrets             map[string]bool
+	ProbeRegistry       func(host string) error
+	ProbeAddress        func(addr string) error
+}
+
+// defaultStorageClassAnnotations mark the default storage class of the cluster
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// RunPreflight loads the inputs from the cluster, runs the given checks and prints them.
+// It returns false if any of the checks failed.
+func RunPreflight(cmd *cobra.Command, run func(in *PreflightInputs) []PreflightCheck) bool {
+	log := util.Logger()
+	checks := run(LoadPreflightInputs(cmd))
+	failed := false
+	for _, c := range checks {
+		icon := "✅"
+		switch c.Result {
+		case PreflightWarn:
+			icon = "⚠️ "
+		case PreflightFail:
+			icon = "❌"
+			failed = true
+		}
+		log.Printf("%s %-4s [%s] %s", icon, c.Result, c.Name, c.Message)
+	}
+	return !failed
+}
+
+// PreflightChecks runs all the preflight checks of install on the inputs
+func PreflightChecks(in *PreflightInputs) []PreflightCheck {
+	checks := []PreflightCheck{}
+	checks = append(checks, CheckStorageClass(in)...)
+	checks = append(checks, CheckNodeResources(in))
+	checks = append(checks, CheckSCC(in))
+	checks = append(checks, CheckRegistries(in)...)
+	checks = append(checks, CheckVault(in)...)
+	checks = append(checks, CheckCRDs(in)...)
+	return checks
+}
+
+// PreflightSystemChecks runs the preflight checks of creating a system on the inputs,
+// leaving out the checks of the cluster wide resources that install creates with the operator
+func PreflightSystemChecks(in *PreflightInputs) []PreflightCheck {
+	checks := []PreflightCheck{}
+	checks = append(checks, CheckStorageClass(in)...)
+	checks = append(checks, CheckNodeResources(in))
+	checks = append(checks, CheckRegistries(in)...)
+	checks = append(checks, CheckVault(in)...)
+	return checks
+}
+
+// LoadPreflightInputs loads the preflight inputs from the cluster.
+// The system is the existing one when found, otherwise the one that install would create.
+func LoadPreflightInputs(cmd *cobra.Command) *PreflightInputs {
+	in := &PreflightInputs{
+		ExistingCRDs:  map[string]*crd.CRD{},
+		Secrets:       map[string]bool{},
+		ProbeRegistry: probeRegistry,
+		ProbeAddress:  probeAddress,
+	}
+
+	sys := &nbv1.NooBaa{
+		TypeMeta:   metav1.TypeMeta{Kind: "NooBaa"},
+		ObjectMeta: metav1.ObjectMeta{Name: options.SystemName, Namespace: options.Namespace},
+	}
+	if !util.KubeCheckQuiet(sys) {
+		sys = LoadSystemDefaults()
+		applyResourcesFlags(cmd, sys)
+	}
+	in.NooBaa = sys
+
+	// listing cluster scoped resources might be denied to the installing user,
+	// so the errors are kept for the checks to report instead of failing
+	klient := util.KubeClient()
+	scList := &storagev1.StorageClassList{TypeMeta: metav1.TypeMeta{Kind: "StorageClassList"}}
+	if err := klient.List(util.Context(), scList); err != nil {
+		in.StorageClassesError = err
+	} else {
+		in.StorageClasses = scList.Items
+	}
+
+	nodeList := &corev1.NodeList{TypeMeta: metav1.TypeMeta{Kind: "NodeList"}}
+	if err := klient.List(util.Context(), nodeList); err != nil {
+		in.NodesError = err
+	} else {
+		in.Nodes = nodeList.Items
+	}
+
+	in.SCCAvailable = util.KubeAPIAvailable("security.openshift.io/v1", "SecurityContextConstraints")
+
+	in.BundledCRDs = crd.LoadCrds().All
+	for _, c := range in.BundledCRDs {
+		existing := &crd.CRD{
+			TypeMeta:   metav1.TypeMeta{Kind: "CustomResourceDefinition"},
+			ObjectMeta: metav1.ObjectMeta{Name: c.Name},
+		}
+		if util.KubeCheckQuiet(existing) {
+			in.ExistingCRDs[c.Name] = existing
+		}
+	}
+
+	if name := sys.Spec.Security.KeyManagementService.TokenSecretName; name != "" {
+		secret := &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: options.Namespace},
+		}
+		in.Secrets[name] = util.KubeCheckQuiet(secret)
+	}
+
+	return in
+}
+
+// applyResourcesFlags applies the resources flags of system create when the command has them
+func applyResourcesFlags(cmd *cobra.Command, sys *nbv1.NooBaa) {
+	if value, _ := cmd.Flags().GetString("core-resources"); value != "" {
+		_ = json.Unmarshal([]byte(value), &sys.Spec.CoreResources)
+	}
+	if value, _ := cmd.Flags().GetString("db-resources"); value != "" {
+		_ = json.Unmarshal([]byte(value), &sys.Spec.DBResources)
+	}
+	if value, _ := cmd.Flags().GetString("endpoint-resources"); value != "" {
+		if sys.Spec.Endpoints == nil {
+			sys.Spec.Endpoints = &nbv1.EndpointsSpec{MinCount: 1, MaxCount: 1}
+		}
+		_ = json.Unmarshal([]byte(value), &sys.Spec.Endpoints.Resources)
+	}
+}
+
+// CheckStorageClass verifies that the db volume and the default pv-pool volumes can be provisioned
+func CheckStorageClass(in *PreflightInputs) []PreflightCheck {
+	const name = "storage-class"
+	spec := &in.NooBaa.Spec
+	if in.StorageClassesError != nil && spec.MongoDbURL == "" {
+		return []PreflightCheck{{name, PreflightWarn,
+			fmt.Sprintf("Could not list storage classes: %v", in.StorageClassesError)}}
+	}
+	exists := map[string]bool{}
+	defaultClass := ""
+	for i := range in.StorageClasses {
+		sc := &in.StorageClasses[i]
+		exists[sc.Name] = true
+		for _, a := range defaultStorageClassAnnotations {
+			if sc.Annotations[a] == "true" {
+				defaultClass = sc.Name
+			}
+		}
+	}
+
+	checks := []PreflightCheck{}
+	switch {
+	case spec.MongoDbURL != "":
+		checks = append(checks, PreflightCheck{name, PreflightPass, "Using an external database, no db volume is needed"})
+	case spec.DBStorageClass != nil && *spec.DBStorageClass != "":
+		if exists[*spec.DBStorageClass] {
+			checks = append(checks, PreflightCheck{name, PreflightPass,
+				fmt.Sprintf("DB storage class %q exists", *spec.DBStorageClass)})
+		} else {
+			checks = append(checks, PreflightCheck{name, PreflightFail,
+				fmt.Sprintf("DB storage class %q does not exist", *spec.DBStorageClass)})
+		}
+	case defaultClass != "":
+		checks = append(checks, PreflightCheck{name, PreflightPass,
+			fmt.Sprintf("Default storage class %q will be used for the db volume", defaultClass)})
+	default:
+		checks = append(checks, PreflightCheck{name, PreflightFail,
+			"No default storage class found, set one or use --db-storage-class"})
+	}
+
+	if spec.PVPoolDefaultStorageClass != nil && *spec.PVPoolDefaultStorageClass != "" &&
+		!exists[*spec.PVPoolDefaultStorageClass] {
+		checks = append(checks, PreflightCheck{name, PreflightWarn,
+			fmt.Sprintf("PV pool default storage class %q does not exist", *spec.PVPoolDefaultStorageClass)})
+	}
+	return checks
+}
+
+// CheckNodeResources verifies that the nodes can schedule the operator and system pods.
+// It compares with the node allocatable resources, without the requests of the pods already running.
+func CheckNodeResources(in *PreflightInputs) PreflightCheck {
+	const name = "node-resources"
+	if in.NodesError != nil {
+		return PreflightCheck{name, PreflightWarn, fmt.Sprintf("Could not list nodes: %v", in.NodesError)}
+	}
+	pods := PreflightPodRequests(in.NooBaa)
+
+	nodes := []*corev1.Node{}
+	for i := range in.Nodes {
+		node := &in.Nodes[i]
+		if !node.Spec.Unschedulable && isNodeReady(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return PreflightCheck{name, PreflightFail, "No ready schedulable nodes found"}
+	}
+
+	total := corev1.ResourceList{}
+	for _, node := range nodes {
+		addResources(total, node.Status.Allocatable)
+	}
+	required := corev1.ResourceList{}
+	podNames := make([]string, 0, len(pods))
+	for podName := range pods {
+		podNames = append(podNames, podName)
+	}
+	sort.Strings(podNames)
+	for _, podName := range podNames {
+		requests := pods[podName]
+		addResources(required, requests)
+		fits := false
+		for _, node := range nodes {
+			if fitsResources(requests, node.Status.Allocatable) {
+				fits = true
+				break
+			}
+		}
+		if !fits {
+			return PreflightCheck{name, PreflightFail,
+				fmt.Sprintf("No node has enough allocatable resources for %s which requests %s",
+					podName, formatResources(requests))}
+		}
+	}
+	if !fitsResources(required, total) {
+		return PreflightCheck{name, PreflightWarn,
+			fmt.Sprintf("The pods request %s which exceeds the nodes allocatable %s",
+				formatResources(required), formatResources(total))}
+	}
+	return PreflightCheck{name, PreflightPass,
+		fmt.Sprintf("%d nodes can allocate the requested %s", len(nodes), formatResources(required))}
+}
+
+// PreflightPodRequests returns the resource requests per pod that install creates,
+// taken from the system spec or from the bundled defaults
+func PreflightPodRequests(sys *nbv1.NooBaa) map[string]corev1.ResourceList {
+	spec := &sys.Spec
+	pods := map[string]corev1.ResourceList{}
+
+	operatorDep := util.KubeObject(bundle.File_deploy_operator_yaml).(*appsv1.Deployment)
+	pods["noobaa-operator"] = containerRequests(operatorDep.Spec.Template.Spec.Containers, nil)
+
+	coreSts := util.KubeObject(bundle.File_deploy_internal_statefulset_core_yaml).(*appsv1.StatefulSet)
+	pods["noobaa-core"] = containerRequests(coreSts.Spec.Template.Spec.Containers, spec.CoreResources)
+
+	if spec.MongoDbURL == "" {
+		dbFile := bundle.File_deploy_internal_statefulset_db_yaml
+		if spec.DBType == nbv1.DBTypePostgres {
+			dbFile = bundle.File_deploy_internal_statefulset_postgres_db_yaml
+		}
+		dbSts := util.KubeObject(dbFile).(*appsv1.StatefulSet)
+		pods["noobaa-db"] = containerRequests(dbSts.Spec.Template.Spec.Containers, spec.DBResources)
+	}
+
+	endpointDep := util.KubeObject(bundle.File_deploy_internal_deployment_endpoint_yaml).(*appsv1.Deployment)
+	var endpointResources *corev1.ResourceRequirements
+	count := 1
+	if spec.Endpoints != nil {
+		endpointResources = spec.Endpoints.Resources
+		if spec.Endpoints.MinCount > 1 {
+			count = int(spec.Endpoints.MinCount)
+		}
+	}
+	endpointRequests := containerRequests(endpointDep.Spec.Template.Spec.Containers, endpointResources)
+	for i := 0; i < count; i++ {
+		pods[fmt.Sprintf("noobaa-endpoint-%d", i+1)] = endpointRequests
+	}
+	return pods
+}
+
+// containerRequests sums the requests of the containers,
+// where the first container requests are replaced by the override when given
+func containerRequests(containers []corev1.Container, override *corev1.ResourceRequirements) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for i := range containers {
+		r := containers[i].Resources.Requests
+		if i == 0 && override != nil && override.Requests != nil {
+			r = override.Requests
+		}
+		addResources(requests, r)
+	}
+	return requests
+}
+
+func addResources(sum corev1.ResourceList, add corev1.ResourceList) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		q, ok := add[name]
+		if !ok {
+			continue
+		}
+		total := sum[name]
+		total.Add(q)
+		sum[name] = total
+	}
+}
+
+func fitsResources(requests corev1.ResourceList, allocatable corev1.ResourceList) bool {
+	for name, q := range requests {
+		if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+			continue
+		}
+		a, ok := allocatable[name]
+		if !ok {
+			a = resource.Quantity{}
+		}
+		if q.Cmp(a) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func formatResources(list corev1.ResourceList) string {
+	cpu := list[corev1.ResourceCPU]
+	memory := list[corev1.ResourceMemory]
+	return fmt.Sprintf("cpu=%s memory=%s", cpu.String(), memory.String())
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// CheckSCC reports when the cluster does not serve the SecurityContextConstraints api,
+// in which case the operator skips the SCCs and the pods rely on the cluster pod security settings
+func CheckSCC(in *PreflightInputs) PreflightCheck {
+	const name = "scc"
+	if in.SCCAvailable {
+		return PreflightCheck{name, PreflightPass, "SecurityContextConstraints API is available"}
+	}
+	return PreflightCheck{name, PreflightWarn,
+		"SecurityContextConstraints API is not available (non-OpenShift cluster), " +
+			"the SCCs will be skipped and the pods must be allowed by the namespace pod security settings"}
+}
+
+// CheckRegistries probes the registries of the images that install uses.
+// The probe runs from the CLI host, so an unreachable registry is only a warning.
+func CheckRegistries(in *PreflightInputs) []PreflightCheck {
+	const name = "image-registry"
+	images := []string{options.OperatorImage, options.NooBaaImage}
+	if in.NooBaa.Spec.Image != nil {
+		images[1] = *in.NooBaa.Spec.Image
+	}
+	if in.NooBaa.Spec.MongoDbURL == "" && in.NooBaa.Spec.DBImage != nil {
+		images = append(images, *in.NooBaa.Spec.DBImage)
+	}
+
+	checks := []PreflightCheck{}
+	probed := map[string]bool{}
+	for _, image := range images {
+		host := ImageRegistryHost(image)
+		if probed[host] {
+			continue
+		}
+		probed[host] = true
+		if err := in.ProbeRegistry(host); err != nil {
+			checks = append(checks, PreflightCheck{name, PreflightWarn,
+				fmt.Sprintf("Registry %s of image %s is unreachable: %v", host, image, err)})
+		} else {
+			checks = append(checks, PreflightCheck{name, PreflightPass,
+				fmt.Sprintf("Registry %s is reachable", host)})
+		}
+	}
+	return checks
+}
+
+// ImageRegistryHost returns the registry host of an image url,
+// where images without a registry host are pulled from docker hub
+func ImageRegistryHost(image string) string {
+	i := strings.Index(image, "/")
+	if i > 0 {
+		host := image[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			return host
+		}
+	}
+	return "registry-1.docker.io"
+}
+
+// CheckVault verifies the external KMS address and token secret when the system is configured with one
+func CheckVault(in *PreflightInputs) []PreflightCheck {
+	const name = "vault"
+	kms := &in.NooBaa.Spec.Security.KeyManagementService
+	addr := kms.ConnectionDetails["VAULT_ADDR"]
+	if addr == "" {
+		return nil
+	}
+
+	checks := []PreflightCheck{}
+	if kms.TokenSecretName != "" && !in.Secrets[kms.TokenSecretName] {
+		checks = append(checks, PreflightCheck{name, PreflightFail,
+			fmt.Sprintf("Vault token secret %q does not exist", kms.TokenSecretName)})
+	}
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		return append(checks, PreflightCheck{name, PreflightFail, fmt.Sprintf("Invalid vault address %q", addr)})
+	}
+	hostPort := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "http" {
+			hostPort = net.JoinHostPort(u.Hostname(), "80")
+		} else {
+			hostPort = net.JoinHostPort(u.Hostname(), "443")
+		}
+	}
+	if err := in.ProbeAddress(hostPort); err != nil {
+		checks = append(checks, PreflightCheck{name, PreflightWarn,
+			fmt.Sprintf("Vault %s is unreachable from this host: %v", addr, err)})
+	} else {
+		checks = append(checks, PreflightCheck{name, PreflightPass, fmt.Sprintf("Vault %s is reachable", addr)})
+	}
+	return checks
+}
+
+// CheckCRDs compares the existing CRDs with the bundled ones.
+// Install does not update existing CRDs, so a CRD that does not serve the bundled version fails,
+// and a CRD whose schema lacks fields of the bundled schema is reported as a warning, since the
+// api server prunes these fields. The schemas are not compared as a whole since the api server
+// stores them with defaults and in its own form.
+func CheckCRDs(in *PreflightInputs) []PreflightCheck {
+	const name = "crd"
+	checks := []PreflightCheck{}
+	for _, bundled := range in.BundledCRDs {
+		existing := in.ExistingCRDs[bundled.Name]
+		if existing == nil {
+			checks = append(checks, PreflightCheck{name, PreflightPass,
+				fmt.Sprintf("CRD %s will be created", bundled.Name)})
+			continue
+		}
+		result := PreflightPass
+		message := fmt.Sprintf("CRD %s exists and matches", bundled.Name)
+		for i := range bundled.Spec.Versions {
+			v := &bundled.Spec.Versions[i]
+			found := false
+			for j := range existing.Spec.Versions {
+				e := &existing.Spec.Versions[j]
+				if e.Name != v.Name {
+					continue
+				}
+				found = true
+				if !e.Served {
+					result = PreflightFail
+					message = fmt.Sprintf("CRD %s exists but does not serve version %s", bundled.Name, v.Name)
+				} else if result == PreflightPass {
+					if missing := missingCRDFields(e.Schema, v.Schema); len(missing) > 0 {
+						result = PreflightWarn
+						message = fmt.Sprintf("CRD %s version %s exists without the fields %s and will not be updated",
+							bundled.Name, v.Name, strings.Join(missing, ", "))
+					}
+				}
+			}
+			if !found {
+				result = PreflightFail
+				message = fmt.Sprintf("CRD %s exists without version %s", bundled.Name, v.Name)
+			}
+			if result == PreflightFail {
+				break
+			}
+		}
+		checks = append(checks, PreflightCheck{name, result, message})
+	}
+	return checks
+}
+
+// maxMissingCRDFields limits the fields listed in the CRD check message
+const maxMissingCRDFields = 5
+
+// missingCRDFields returns the paths of the fields of the bundled schema that the existing schema lacks
+func missingCRDFields(existing *apiextv1.CustomResourceValidation, bundled *apiextv1.CustomResourceValidation) []string {
+	if bundled == nil || bundled.OpenAPIV3Schema == nil {
+		return nil
+	}
+	if existing == nil || existing.OpenAPIV3Schema == nil {
+		return []string{"<schema>"}
+	}
+	missing := []string{}
+	addMissingSchemaFields(existing.OpenAPIV3Schema, bundled.OpenAPIV3Schema, "", &missing)
+	sort.Strings(missing)
+	if len(missing) > maxMissingCRDFields {
+		missing = append(missing[:maxMissingCRDFields], "...")
+	}
+	return missing
+}
+
+func addMissingSchemaFields(existing *apiextv1.JSONSchemaProps, bundled *apiextv1.JSONSchemaProps, path string, missing *[]string) {
+	// an existing schema that preserves unknown fields keeps any field
+	if existing.XPreserveUnknownFields != nil && *existing.XPreserveUnknownFields {
+		return
+	}
+	for key := range bundled.Properties {
+		b := bundled.Properties[key]
+		e, exists := existing.Properties[key]
+		if !exists {
+			*missing = append(*missing, path+"."+key)
+			continue
+		}
+		addMissingSchemaFields(&e, &b, path+"."+key, missing)
+	}
+	if bundled.Items != nil && bundled.Items.Schema != nil && existing.Items != nil && existing.Items.Schema != nil {
+		addMissingSchemaFields(existing.Items.Schema, bundled.Items.Schema, path+"[]", missing)
+	}
+	if bundled.AdditionalProperties != nil && bundled.AdditionalProperties.Schema != nil &&
+		existing.AdditionalProperties != nil && existing.AdditionalProperties.Schema != nil {
+		addMissingSchemaFields(existing.AdditionalProperties.Schema, bundled.AdditionalProperties.Schema, path+".*", missing)
+	}
+}
+
+// probeRegistry sends a request to the registry api and accepts any response that is not a server error,
+// since registries respond with 401 to anonymous requests
+func probeRegistry(host string) error {
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: util.InsecureHTTPTransport,
+	}
+	res, err := client.Get("https://" + host + "/v2/")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 500 {
+		return fmt.Errorf("status %s", res.Status)
+	}
+	return nil
+}
+
+func probeAddress(addr string) error {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,195 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/crd"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(name string, cpu string, memory string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func crdVersion(name string, served bool) apiextv1.CustomResourceDefinitionVersion {
+	return apiextv1.CustomResourceDefinitionVersion{
+		Name:   name,
+		Served: served,
+		Schema: &apiextv1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextv1.JSONSchemaProps{Type: "object"},
+		},
+	}
+}
+
+func testPreflightInputs() *PreflightInputs {
+	return &PreflightInputs{
+		NooBaa: &nbv1.NooBaa{},
+		StorageClasses: []storagev1.StorageClass{{ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+		}}},
+		Nodes:         []corev1.Node{testNode("node1", "8", "32Gi")},
+		SCCAvailable:  true,
+		ExistingCRDs:  map[string]*crd.CRD{},
+		Secrets:       map[string]bool{},
+		ProbeRegistry: func(host string) error { return nil },
+		ProbeAddress:  func(addr string) error { return nil },
+	}
+}
+
+func expectResult(t *testing.T, checks []PreflightCheck, expected PreflightResult) {
+	t.Helper()
+	for _, c := range checks {
+		if c.Result == expected {
+			return
+		}
+	}
+	t.Errorf("expected a %s result in %+v", expected, checks)
+}
+
+func TestPreflightPass(t *testing.T) {
+	for _, c := range PreflightChecks(testPreflightInputs()) {
+		if c.Result != PreflightPass {
+			t.Errorf("expected all checks to pass, got %+v", c)
+		}
+	}
+}
+
+func TestPreflightSystemChecks(t *testing.T) {
+	in := testPreflightInputs()
+	// the cluster wide resources are checked by install and not by system create
+	in.SCCAvailable = false
+	in.BundledCRDs = []*crd.CRD{{ObjectMeta: metav1.ObjectMeta{Name: "noobaas.noobaa.io"}}}
+	for _, c := range PreflightSystemChecks(in) {
+		if c.Name == "scc" || c.Name == "crd" {
+			t.Errorf("expected only the system checks, got %+v", c)
+		}
+	}
+}
+
+func TestCheckStorageClass(t *testing.T) {
+	in := testPreflightInputs()
+	in.StorageClasses[0].Annotations = nil
+	expectResult(t, CheckStorageClass(in), PreflightFail)
+
+	sc := "standard"
+	in.NooBaa.Spec.DBStorageClass = &sc
+	expectResult(t, CheckStorageClass(in), PreflightPass)
+
+	missing := "missing"
+	in.NooBaa.Spec.DBStorageClass = &missing
+	expectResult(t, CheckStorageClass(in), PreflightFail)
+
+	// a user that cannot list storage classes gets a warning instead of a failure
+	in.StorageClasses = nil
+	in.StorageClassesError = fmt.Errorf("forbidden")
+	checks := CheckStorageClass(in)
+	if len(checks) != 1 || checks[0].Result != PreflightWarn {
+		t.Errorf("expected a single warning, got %+v", checks)
+	}
+}
+
+func TestCheckNodeResources(t *testing.T) {
+	in := testPreflightInputs()
+	in.Nodes = []corev1.Node{testNode("small", "1", "2Gi")}
+	expectResult(t, []PreflightCheck{CheckNodeResources(in)}, PreflightFail)
+
+	in.Nodes = []corev1.Node{testNode("node1", "2", "4608Mi"), testNode("node2", "2", "4608Mi")}
+	expectResult(t, []PreflightCheck{CheckNodeResources(in)}, PreflightWarn)
+
+	in.Nodes[0].Spec.Unschedulable = true
+	in.Nodes[1].Spec.Unschedulable = true
+	expectResult(t, []PreflightCheck{CheckNodeResources(in)}, PreflightFail)
+
+	in.Nodes = nil
+	in.NodesError = fmt.Errorf("forbidden")
+	expectResult(t, []PreflightCheck{CheckNodeResources(in)}, PreflightWarn)
+}
+
+func TestImageRegistryHost(t *testing.T) {
+	cases := map[string]string{
+		"noobaa/noobaa-core:5.9.0":         "registry-1.docker.io",
+		"centos/mongodb-36-centos7":        "registry-1.docker.io",
+		"quay.io/noobaa/noobaa-core:5.9.0": "quay.io",
+		"registry:5000/noobaa/noobaa-core": "registry:5000",
+		"localhost/noobaa-core":            "localhost",
+	}
+	for image, expected := range cases {
+		if host := ImageRegistryHost(image); host != expected {
+			t.Errorf("ImageRegistryHost(%q) = %q, expected %q", image, host, expected)
+		}
+	}
+}
+
+func TestCheckVault(t *testing.T) {
+	in := testPreflightInputs()
+	kms := &in.NooBaa.Spec.Security.KeyManagementService
+	kms.ConnectionDetails = map[string]string{"VAULT_ADDR": "https://vault.example.com"}
+	kms.TokenSecretName = "vault-token"
+	probed := ""
+	in.ProbeAddress = func(addr string) error {
+		probed = addr
+		return fmt.Errorf("connection refused")
+	}
+	checks := CheckVault(in)
+	expectResult(t, checks, PreflightFail)
+	expectResult(t, checks, PreflightWarn)
+	if probed != "vault.example.com:443" {
+		t.Errorf("expected to probe the default https port, got %q", probed)
+	}
+}
+
+func TestCheckCRDs(t *testing.T) {
+	in := testPreflightInputs()
+	bundled := &crd.CRD{
+		ObjectMeta: metav1.ObjectMeta{Name: "noobaas.noobaa.io"},
+	}
+	bundled.Spec.Versions = append(bundled.Spec.Versions, crdVersion("v1alpha1", true))
+	in.BundledCRDs = []*crd.CRD{bundled}
+	expectResult(t, CheckCRDs(in), PreflightPass)
+
+	existing := bundled.DeepCopy()
+	existing.Spec.Versions[0].Name = "v1beta1"
+	in.ExistingCRDs[bundled.Name] = existing
+	expectResult(t, CheckCRDs(in), PreflightFail)
+
+	existing.Spec.Versions[0] = crdVersion("v1alpha1", true)
+	existing.Spec.Versions[0].Schema = nil
+	expectResult(t, CheckCRDs(in), PreflightWarn)
+
+	// server defaults and descriptions do not make the schemas different, missing fields do
+	bundled.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties = map[string]apiextv1.JSONSchemaProps{
+		"spec": {Type: "object", Properties: map[string]apiextv1.JSONSchemaProps{
+			"image":   {Type: "string"},
+			"logging": {Type: "object"},
+		}},
+	}
+	existing.Spec.Versions[0] = *bundled.Spec.Versions[0].DeepCopy()
+	existingSpec := existing.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+	existingSpec.Description = "defaulted by the server"
+	existing.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = existingSpec
+	expectResult(t, CheckCRDs(in), PreflightPass)
+
+	delete(existingSpec.Properties, "logging")
+	checks := CheckCRDs(in)
+	if len(checks) != 1 || checks[0].Result != PreflightWarn || !strings.Contains(checks[0].Message, ".spec.logging") {
+		t.Errorf("expected a warning on the missing field, got %+v", checks)
+	}
+}
@@ -6,10 +6,10 @@ import (
 	goruntime "runtime"
 	"strings"
 	"text/template"
-	"time"
 
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
 	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
 	"github.com/noobaa/noobaa-operator/v2/pkg/options"
 	"github.com/noobaa/noobaa-operator/v2/pkg/util"
@@ -22,6 +22,7 @@ import (
 	"github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
@@ -99,8 +100,29 @@ type Reconciler struct {
 	DeploymentEndpoint        *appsv1.Deployment
 	DefaultDeploymentEndpoint *corev1.Container
 	HPAEndpoint               *autoscalingv1.HorizontalPodAutoscaler
+	HPAEndpointV2             *autoscalingv2beta2.HorizontalPodAutoscaler
+	EndpointGroups            []*EndpointGroup
 	JoinSecret                *corev1.Secret
 	UpgradeJob                *batchv1.Job
+
+	// Paused is set when the reconcile is paused and only the status is updated
+	Paused bool
+}
+
+// EndpointGroupLabel labels the deployment, autoscaler and pods of an endpoint group with the group name
+const EndpointGroupLabel = "noobaa-endpoint-group"
+
+// EndpointPodLabel labels the pods of the main endpoint deployment and of the endpoint groups with the system name
+// and is the selector of the s3 service. The main endpoint deployment selects its pods by the noobaa-s3 label,
+// which the pods of the endpoint groups do not have, so that the deployments and autoscalers do not overlap.
+const EndpointPodLabel = "noobaa-s3-endpoint"
+
+// EndpointGroup holds the deployment and autoscaler of an additional endpoint group of the system
+type EndpointGroup struct {
+	Spec       *nbv1.EndpointGroupSpec
+	Deployment *appsv1.Deployment
+	HPA        *autoscalingv1.HorizontalPodAutoscaler
+	HPAV2      *autoscalingv2beta2.HorizontalPodAutoscaler
 }
 
 // NewReconciler initializes a reconciler to be used for loading or reconciling a noobaa system
@@ -152,6 +174,7 @@ func NewReconciler(
 		RouteS3:             util.KubeObject(bundle.File_deploy_internal_route_s3_yaml).(*routev1.Route),
 		DeploymentEndpoint:  util.KubeObject(bundle.File_deploy_internal_deployment_endpoint_yaml).(*appsv1.Deployment),
 		HPAEndpoint:         util.KubeObject(bundle.File_deploy_internal_hpa_endpoint_yaml).(*autoscalingv1.HorizontalPodAutoscaler),
+		HPAEndpointV2:       util.KubeObject(bundle.File_deploy_internal_hpav2_endpoint_yaml).(*autoscalingv2beta2.HorizontalPodAutoscaler),
 		UpgradeJob:          util.KubeObject(bundle.File_deploy_internal_job_upgrade_db_yaml).(*batchv1.Job),
 	}
 
@@ -190,6 +213,7 @@ func NewReconciler(
 	r.RouteS3.Namespace = r.Request.Namespace
 	r.DeploymentEndpoint.Namespace = r.Request.Namespace
 	r.HPAEndpoint.Namespace = r.Request.Namespace
+	r.HPAEndpointV2.Namespace = r.Request.Namespace
 	r.UpgradeJob.Namespace = r.Request.Namespace
 
 	// Set Names
@@ -227,6 +251,7 @@ func NewReconciler(
 	r.RouteS3.Name = r.ServiceS3.Name
 	r.DeploymentEndpoint.Name = r.Request.Name + "-endpoint"
 	r.HPAEndpoint.Name = r.Request.Name + "-endpoint"
+	r.HPAEndpointV2.Name = r.Request.Name + "-endpoint"
 	r.UpgradeJob.Name = r.Request.Name + "-upgrade-job"
 
 	// Set the target service for routes.
@@ -235,10 +260,11 @@ func NewReconciler(
 
 	// Set the target deployment for the horizontal auto scaler
 	r.HPAEndpoint.Spec.ScaleTargetRef.Name = r.DeploymentEndpoint.Name
+	r.HPAEndpointV2.Spec.ScaleTargetRef.Name = r.DeploymentEndpoint.Name
 
 	// Since StorageClass is global we set the name and provisioner to have unique global name
-	r.OBCStorageClass.Name = options.SubDomainNS()
-	r.OBCStorageClass.Provisioner = options.ObjectBucketProvisionerName()
+	r.OBCStorageClass.Name = options.SubDomainNSFor(r.Request.Namespace)
+	r.OBCStorageClass.Provisioner = options.ObjectBucketProvisionerNameFor(r.Request.Namespace)
 
 	r.SecretServer.StringData["jwt"] = util.RandomBase64(16)
 	r.SecretServer.StringData["server_secret"] = util.RandomHex(4)
@@ -254,10 +280,38 @@ func NewReconciler(
 	return r
 }
 
+// LoadEndpointGroups initializes the deployment and autoscaler objects
+// of the endpoint groups in the system spec
+func (r *Reconciler) LoadEndpointGroups() {
+	r.EndpointGroups = nil
+	for i := range r.NooBaa.Spec.EndpointGroups {
+		spec := &r.NooBaa.Spec.EndpointGroups[i]
+		group := &EndpointGroup{
+			Spec:       spec,
+			Deployment: util.KubeObject(bundle.File_deploy_internal_deployment_endpoint_yaml).(*appsv1.Deployment),
+			HPA:        util.KubeObject(bundle.File_deploy_internal_hpa_endpoint_yaml).(*autoscalingv1.HorizontalPodAutoscaler),
+			HPAV2:      util.KubeObject(bundle.File_deploy_internal_hpav2_endpoint_yaml).(*autoscalingv2beta2.HorizontalPodAutoscaler),
+		}
+		group.Deployment.Namespace = r.Request.Namespace
+		group.Deployment.Name = r.DeploymentEndpoint.Name + "-" + spec.Name
+		group.Deployment.Labels[EndpointGroupLabel] = spec.Name
+		group.HPA.Namespace = r.Request.Namespace
+		group.HPA.Name = group.Deployment.Name
+		group.HPA.Labels[EndpointGroupLabel] = spec.Name
+		group.HPA.Spec.ScaleTargetRef.Name = group.Deployment.Name
+		group.HPAV2.Namespace = r.Request.Namespace
+		group.HPAV2.Name = group.Deployment.Name
+		group.HPAV2.Labels[EndpointGroupLabel] = spec.Name
+		group.HPAV2.Spec.ScaleTargetRef.Name = group.Deployment.Name
+		r.EndpointGroups = append(r.EndpointGroups, group)
+	}
+}
+
 // CheckAll checks the state of all the objects controlled by the system
 func (r *Reconciler) CheckAll() {
 
 	CheckSystem(r.NooBaa)
+	r.LoadEndpointGroups()
 	util.KubeCheck(r.CoreApp)
 	util.KubeCheck(r.ServiceMgmt)
 	util.KubeCheck(r.ServiceS3)
@@ -282,6 +336,10 @@ func (r *Reconciler) CheckAll() {
 	util.KubeCheck(r.DefaultBucketClass)
 	util.KubeCheck(r.DeploymentEndpoint)
 	util.KubeCheck(r.HPAEndpoint)
+	for _, group := range r.EndpointGroups {
+		util.KubeCheck(group.Deployment)
+		util.KubeCheck(group.HPA)
+	}
 	util.KubeCheckOptional(r.DefaultBackingStore)
 	util.KubeCheckOptional(r.AWSCloudCreds)
 	util.KubeCheckOptional(r.AzureCloudCreds)
@@ -307,6 +365,8 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 
 	if !CheckSystem(r.NooBaa) {
 		log.Infof("NooBaa not found or already deleted. Skip reconcile.")
+		metrics.DeleteSystemSnapshot(r.Request.Namespace)
+		util.GlobalBackoff.Reset(r.NooBaa)
 		return res, nil
 	}
 
@@ -314,10 +374,13 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		if !util.KubeUpdate(r.NooBaa) {
 			log.Errorf("❌ NooBaa %q failed to add mandatory meta fields", r.NooBaa.Name)
 
-			res.RequeueAfter = 3 * time.Second
+			res.RequeueAfter = util.GlobalBackoff.TemporaryError(r.NooBaa, r.DependenciesVersion(),
+				fmt.Errorf("failed to add mandatory meta fields"), nil, nil)
 			return res, nil
 		}
 	}
+	r.ReconcileOperatorLogging()
+
 	if r.NooBaa.DeletionTimestamp != nil {
 		if err := util.VerifyExternalSecretsDeletion(r.NooBaa.Spec.Security.KeyManagementService, r.NooBaa.Namespace, string(r.NooBaa.ObjectMeta.UID)); err != nil {
 			log.Warnf("⏳ Temporary Error: %s", err)
@@ -343,22 +406,50 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		}
 	}
 
+	if paused, requeue := util.CheckReconcilePause(r.NooBaa, &r.NooBaa.Status.Conditions, r.Recorder); paused {
+		r.Paused = true
+		res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter, requeue)
+		if err := r.ReconcilePaused(); err != nil {
+			log.Warnf("⏳ Paused, could not read the system status: %s", err)
+		} else {
+			log.Infof("✅ Paused, updated the system status")
+		}
+		if err := r.UpdateStatus(); err != nil {
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter,
+				util.GlobalBackoff.TemporaryError(r.NooBaa, r.DependenciesVersion(), err, nil, nil))
+			log.Warnf("⏳ Temporary Error: %s", err)
+		}
+		return res, nil
+	}
+
+	// reverts update the spec, so a paused system keeps its levels until it is resumed
+	res.RequeueAfter = r.ReconcileLogLevelReverts()
+
 	err := r.ReconcilePhases()
+	backoffCounted := false
 
 	if err != nil {
 		if perr, isPERR := err.(*util.PersistentError); isPERR {
 			r.SetPhase(nbv1.SystemPhaseRejected, perr.Reason, perr.Message)
 			log.Errorf("❌ Persistent Error: %s", err)
+			util.GlobalBackoff.Reset(r.NooBaa)
 			if r.Recorder != nil {
 				r.Recorder.Eventf(r.NooBaa, corev1.EventTypeWarning, perr.Reason, perr.Message)
 			}
 		} else {
-			res.RequeueAfter = 3 * time.Second
 			// leave current phase as is
-			r.SetPhase("", "TemporaryError", err.Error())
+			reason := "TemporaryError"
+			if _, isKMS := err.(*KMSError); isKMS {
+				reason = KMSErrorReason
+			}
+			r.SetPhase("", reason, err.Error())
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter, util.GlobalBackoff.TemporaryError(
+				r.NooBaa, r.DependenciesVersion(), err, &r.NooBaa.Status.Conditions, r.Recorder))
+			backoffCounted = true
 			log.Warnf("⏳ Temporary Error: %s", err)
 		}
 	} else {
+		util.GlobalBackoff.Reset(r.NooBaa)
 		r.SetPhase(
 			nbv1.SystemPhaseReady,
 			"SystemPhaseReady",
@@ -367,16 +458,47 @@ func (r *Reconciler) Reconcile() (reconcile.Result, error) {
 		log.Infof("✅ Done")
 	}
 
+	// check the health of the upgrade steps periodically
+	if UpgradeInProgress(r.NooBaa) {
+		res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter, UpgradeRequeueInterval)
+	}
+
 	err = r.UpdateStatus()
 	// if updateStatus will fail to update the CR for any reason we will continue to requeue the reconcile
 	// until the spec status will reflect the actual status of the bucketclass
 	if err != nil {
-		res.RequeueAfter = 3 * time.Second
 		log.Warnf("⏳ Temporary Error: %s", err)
+		// a temporary error of the phases was already counted by the backoff of this reconcile
+		if !backoffCounted {
+			res.RequeueAfter = util.MinRequeueAfter(res.RequeueAfter,
+				util.GlobalBackoff.TemporaryError(r.NooBaa, r.DependenciesVersion(), err, nil, nil))
+		}
 	}
 	return res, nil
 }
 
+// DependenciesVersion returns the version of the secrets that the system spec references for the reconcile backoff -
+// the join secret, the KMS token secret and the image pull secret - so that fixing one of them resets the backoff.
+// The secrets that the operator creates for the system are not included since the reconcile itself writes them,
+// and the system spec references no other secrets (such as TLS certificates) in this version.
+func (r *Reconciler) DependenciesVersion() string {
+	deps := []metav1.Object{r.JoinSecret}
+	names := []string{r.NooBaa.Spec.Security.KeyManagementService.TokenSecretName}
+	if r.NooBaa.Spec.ImagePullSecret != nil {
+		names = append(names, r.NooBaa.Spec.ImagePullSecret.Name)
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: r.Request.Namespace, Name: name}, secret); err == nil {
+			deps = append(deps, secret)
+		}
+	}
+	return util.DependenciesVersion(deps...)
+}
+
 // VerifyObjectBucketCleanup checks if the un-installation is in mode graceful and
 // if OBs still exist in the system the operator will wait
 // and the finalizer on noobaa CR won't be removed
@@ -395,7 +517,7 @@ func (r *Reconciler) VerifyObjectBucketCleanup() error {
 		return nil
 	}
 
-	obcSelector, _ := labels.Parse("noobaa-domain=" + options.SubDomainNS())
+	obcSelector, _ := labels.Parse("noobaa-domain=" + options.SubDomainNSFor(r.Request.Namespace))
 	objectBuckets := &nbv1.ObjectBucketList{}
 	util.KubeList(objectBuckets, &client.ListOptions{LabelSelector: obcSelector})
 
@@ -432,6 +554,14 @@ func (r *Reconciler) PrintMemUsage(phase string) {
 	r.Logger.Infof("Memory Usage: Phase %q - Alloc = %v MiB  Sys = %v MiB  NumGC = %v", phase, r.bToMb(m.Alloc), r.bToMb(m.Sys), m.NumGC)
 }
 
+// PrintRPCStats prints the stats of the rpc websocket connections for diagnostics.
+func (r *Reconciler) PrintRPCStats() {
+	for _, s := range nb.GlobalRPC.GetConnStats() {
+		r.Logger.Infof("RPC Stats: %s State = %s  Pending = %d  RTT = %v  MissedPings = %d  Reconnects = %d",
+			s.Address, s.State, s.PendingRequests, s.LastRTT, s.MissedPings, s.Reconnects)
+	}
+}
+
 // ReconcilePhases runs the reconcile flow and populates System.Status.
 func (r *Reconciler) ReconcilePhases() error {
 	r.PrintMemUsage("Starting")
@@ -448,9 +578,37 @@ func (r *Reconciler) ReconcilePhases() error {
 		return err
 	}
 	r.PrintMemUsage("Finishing")
+	r.PrintRPCStats()
 	return nil
 }
 
+// ReconcilePaused keeps the status of a paused system up to date without changing any of its resources
+func (r *Reconciler) ReconcilePaused() error {
+	util.KubeCheckQuiet(r.ServiceMgmt)
+	util.KubeCheckQuiet(r.ServiceS3)
+	util.KubeCheckQuiet(r.RouteMgmt)
+	util.KubeCheckQuiet(r.RouteS3)
+	r.LoadEndpointGroups()
+
+	if r.JoinSecret == nil {
+		r.CheckServiceStatus(r.ServiceMgmt, r.RouteMgmt, &r.NooBaa.Status.Services.ServiceMgmt, "mgmt-https")
+	}
+	r.CheckServiceStatus(r.ServiceS3, r.RouteS3, &r.NooBaa.Status.Services.ServiceS3, "s3-https")
+	if err := r.InitNBClient(); err != nil {
+		return err
+	}
+	if !util.KubeCheckQuiet(r.SecretOp) {
+		return fmt.Errorf("Could not load the operator secret")
+	}
+	util.SecretResetStringDataFromData(r.SecretOp)
+	r.NBClient.SetAuthToken(r.SecretOp.StringData["auth_token"])
+
+	if err := r.ReconcileReadSystem(); err != nil {
+		return err
+	}
+	return r.ReconcileDeploymentEndpointStatus()
+}
+
 // SetPhase updates the status phase and conditions
 func (r *Reconciler) SetPhase(phase nbv1.SystemPhase, reason string, message string) {
 
@@ -0,0 +1,33 @@
+package system
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDependenciesVersion(t *testing.T) {
+	kmsToken := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kms-token", Namespace: testNamespace, UID: "kms-uid"}}
+	pullSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: testNamespace, UID: "pull-uid"}}
+	r, _ := newFakeReconciler(t, kmsToken, pullSecret)
+	if v := r.DependenciesVersion(); v != "" {
+		t.Errorf("expected no dependencies without referenced secrets, got %q", v)
+	}
+
+	r.NooBaa.Spec.Security.KeyManagementService.TokenSecretName = "kms-token"
+	r.NooBaa.Spec.ImagePullSecret = &corev1.LocalObjectReference{Name: "pull"}
+	before := r.DependenciesVersion()
+
+	if err := r.Client.Get(r.Ctx, client.ObjectKey{Namespace: testNamespace, Name: "kms-token"}, kmsToken); err != nil {
+		t.Fatal(err)
+	}
+	kmsToken.StringData = map[string]string{"token": "new"}
+	if err := r.Client.Update(r.Ctx, kmsToken); err != nil {
+		t.Fatal(err)
+	}
+	if after := r.DependenciesVersion(); after == before {
+		t.Errorf("expected a change of the kms token secret to change the dependencies version %q", before)
+	}
+}
@@ -0,0 +1,176 @@
+package system
+
+import (
+	"sync"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// StatusNotifier keeps the status of the backing stores, namespace stores and bucket classes
+// of the watched systems up to date from change notifications that noobaa-core sends to the
+// operator over the rpc websocket, after the operator registered to the cluster.
+// A notification triggers a status refresh of the system that sent it, instead of a full system reconcile,
+// and every system is also refreshed every ResyncPeriod as a safety net for missed notifications.
+// The refresh runs outside of the controller queue so it only patches the modes in the statuses, and anything
+// that creates or registers resources is requested from the controller through Reconciles.
+// The status read by a notified refresh is kept for FreshPeriod so that the next system reconcile can use it.
+type StatusNotifier struct {
+	// NewReconciler returns a reconciler for the system in the namespace
+	NewReconciler func(ns string) *Reconciler
+	// Delay coalesces a burst of notifications into a single refresh
+	Delay time.Duration
+	// ResyncPeriod is the interval between refreshes of a system without notifications
+	ResyncPeriod time.Duration
+	// FreshPeriod is how long the status read by a notified refresh can be used by a system reconcile
+	FreshPeriod time.Duration
+	Queue       workqueue.DelayingInterface
+	// Reconciles is the source of the system reconciles that the controller watches, by namespace
+	Reconciles chan event.GenericEvent
+
+	mutex     sync.Mutex
+	addresses map[string]string
+	notified  map[string]bool
+	systems   map[string]systemRead
+}
+
+// systemRead is the last read_system result of a system and the time it was read
+type systemRead struct {
+	info *nb.SystemInfo
+	time time.Time
+}
+
+// GlobalStatusNotifier is set by the operator when it handles notifications.
+// It is nil in the CLI, where every reconcile reads the system status.
+var GlobalStatusNotifier *StatusNotifier
+
+// NewStatusNotifier returns a notifier that refreshes systems with the given reconcilers
+func NewStatusNotifier(newReconciler func(ns string) *Reconciler) *StatusNotifier {
+	return &StatusNotifier{
+		NewReconciler: newReconciler,
+		Delay:         time.Second,
+		ResyncPeriod:  options.SystemStatusResyncPeriod,
+		FreshPeriod:   10 * time.Second,
+		Queue:         workqueue.NewNamedDelayingQueue("noobaa-status"),
+		Reconciles:    make(chan event.GenericEvent, 100),
+		addresses:     map[string]string{},
+		notified:      map[string]bool{},
+		systems:       map[string]systemRead{},
+	}
+}
+
+// HandleRPC is the rpc handler of requests sent by noobaa-core to the operator.
+// Requests from an unknown address refresh all the watched systems.
+func (n *StatusNotifier) HandleRPC(req *nb.RPCMessage) (interface{}, error) {
+	logrus.Debugf("RPC Handle: {Op: %s, API: %s, Method: %s, Address: %s}", req.Op, req.API, req.Method, req.Address)
+	if ns := n.namespaceOf(req.Address); ns != "" {
+		n.Notify(ns)
+		return nil, nil
+	}
+	for _, ns := range options.WatchedNamespaces() {
+		n.Notify(ns)
+	}
+	return nil, nil
+}
+
+// HandleConnect is the rpc connect handler, which reconciles the system of a reconnected address
+// in order to register to the cluster again and to catch up with changes missed while disconnected
+func (n *StatusNotifier) HandleConnect(address string) {
+	if ns := n.namespaceOf(address); ns != "" {
+		n.RequestReconcile(ns)
+	}
+}
+
+// Registered records that the system in the namespace sends its notifications from the address
+func (n *StatusNotifier) Registered(address string, ns string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.addresses[address] = ns
+}
+
+// Notify queues a status refresh of the system in the namespace
+func (n *StatusNotifier) Notify(ns string) {
+	n.mutex.Lock()
+	n.notified[ns] = true
+	n.mutex.Unlock()
+	n.Queue.AddAfter(ns, n.Delay)
+}
+
+// RequestReconcile queues a full reconcile of the system in the namespace in the controller queue.
+// The event holds only the namespace and the controller maps it to the system of the namespace.
+func (n *StatusNotifier) RequestReconcile(ns string) {
+	sys := &nbv1.NooBaa{ObjectMeta: metav1.ObjectMeta{Namespace: ns}}
+	n.Reconciles <- event.GenericEvent{Meta: sys, Object: sys}
+}
+
+// Refreshed records the system status that a notified refresh read now
+func (n *StatusNotifier) Refreshed(ns string, info *nb.SystemInfo) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.systems[ns] = systemRead{info: info, time: time.Now()}
+}
+
+// FreshSystemInfo returns the system status if a notified refresh read it within the fresh period, or nil otherwise.
+// The status is returned once, so every other reconcile reads the system again.
+func (n *StatusNotifier) FreshSystemInfo(ns string) *nb.SystemInfo {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	read, ok := n.systems[ns]
+	delete(n.systems, ns)
+	if !ok || time.Since(read.time) >= n.FreshPeriod {
+		return nil
+	}
+	return read.info
+}
+
+func (n *StatusNotifier) namespaceOf(address string) string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.addresses[address]
+}
+
+// Start implements manager.Runnable and refreshes the queued systems until stopped
+func (n *StatusNotifier) Start(stop <-chan struct{}) error {
+	go func() {
+		<-stop
+		n.Queue.ShutDown()
+	}()
+	for _, ns := range options.WatchedNamespaces() {
+		n.Queue.AddAfter(ns, n.ResyncPeriod)
+	}
+	for {
+		item, shutdown := n.Queue.Get()
+		if shutdown {
+			return nil
+		}
+		ns := item.(string)
+		n.Refresh(ns)
+		n.Queue.Done(item)
+		n.Queue.AddAfter(ns, n.ResyncPeriod)
+	}
+}
+
+// Refresh reads the status of the system in the namespace and updates the status of its stores and bucket classes
+// Only the status read by a refresh that a notification triggered is kept for the system reconcile.
+func (n *StatusNotifier) Refresh(ns string) {
+	n.mutex.Lock()
+	notified := n.notified[ns]
+	delete(n.notified, ns)
+	n.mutex.Unlock()
+
+	r := n.NewReconciler(ns)
+	info, err := r.RefreshStatus()
+	if err != nil {
+		r.Logger.Warnf("⏳ Could not refresh the system status: %s", err)
+		return
+	}
+	if notified && info != nil {
+		n.Refreshed(ns, info)
+	}
+}
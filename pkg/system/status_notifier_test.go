@@ -0,0 +1,93 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+)
+
+func queuedNamespaces(n *StatusNotifier) map[string]bool {
+	queued := map[string]bool{}
+	for n.Queue.Len() > 0 {
+		item, _ := n.Queue.Get()
+		queued[item.(string)] = true
+		n.Queue.Done(item)
+	}
+	return queued
+}
+
+func TestStatusNotifierRouting(t *testing.T) {
+	prevNamespace, prevWatched := options.Namespace, options.WatchNamespaces
+	defer func() { options.Namespace, options.WatchNamespaces = prevNamespace, prevWatched }()
+	options.Namespace = "noobaa"
+	options.WatchNamespaces = []string{"noobaa", "tenant-a", "tenant-b"}
+
+	n := NewStatusNotifier(nil)
+	defer n.Queue.ShutDown()
+	n.Delay = 0
+	n.Registered("wss://noobaa-mgmt.tenant-a.svc.cluster.local:443/rpc/", "tenant-a")
+
+	n.HandleRPC(&nb.RPCMessage{Op: "req", Address: "wss://noobaa-mgmt.tenant-a.svc.cluster.local:443/rpc/"})
+	if queued := queuedNamespaces(n); len(queued) != 1 || !queued["tenant-a"] {
+		t.Errorf("expected a notification to refresh only its system, got %v", queued)
+	}
+
+	n.HandleRPC(&nb.RPCMessage{Op: "req", Address: "wss://unknown:443/rpc/"})
+	if queued := queuedNamespaces(n); len(queued) != 3 {
+		t.Errorf("expected a notification from an unknown address to refresh all systems, got %v", queued)
+	}
+
+	n.HandleConnect("wss://unknown:443/rpc/")
+	n.HandleConnect("wss://noobaa-mgmt.tenant-a.svc.cluster.local:443/rpc/")
+	if queued := queuedNamespaces(n); len(queued) != 0 {
+		t.Errorf("expected a reconnect not to refresh the status, got %v", queued)
+	}
+	if len(n.Reconciles) != 1 {
+		t.Fatalf("expected a reconnect to reconcile only a registered system, got %d", len(n.Reconciles))
+	}
+	if e := <-n.Reconciles; e.Meta.GetNamespace() != "tenant-a" {
+		t.Errorf("expected a reconcile of tenant-a, got %q", e.Meta.GetNamespace())
+	}
+}
+
+func TestStatusNotifierFresh(t *testing.T) {
+	n := NewStatusNotifier(nil)
+	defer n.Queue.ShutDown()
+	n.FreshPeriod = time.Hour
+	if n.FreshSystemInfo("noobaa") != nil {
+		t.Errorf("expected a system that was never read to not be fresh")
+	}
+	info := &nb.SystemInfo{Version: "5.8.0"}
+	n.Refreshed("noobaa", info)
+	if n.FreshSystemInfo("noobaa") != info {
+		t.Errorf("expected a system that was just read to be fresh")
+	}
+	if n.FreshSystemInfo("noobaa") != nil {
+		t.Errorf("expected the status to be used by a single reconcile")
+	}
+	n.Refreshed("noobaa", info)
+	n.FreshPeriod = 0
+	if n.FreshSystemInfo("noobaa") != nil {
+		t.Errorf("expected the status to expire after the fresh period")
+	}
+}
+
+func TestReconcileReadSystemFromNotifier(t *testing.T) {
+	prevNotifier := GlobalStatusNotifier
+	defer func() { GlobalStatusNotifier = prevNotifier }()
+	GlobalStatusNotifier = NewStatusNotifier(nil)
+	defer GlobalStatusNotifier.Queue.ShutDown()
+	GlobalStatusNotifier.FreshPeriod = time.Hour
+
+	// the fake client has no read_system, so a fresh status must be used without reading it again
+	r, _ := newFakeReconciler(t)
+	GlobalStatusNotifier.Refreshed(r.Request.Namespace, &nb.SystemInfo{Version: "5.8.0"})
+	if err := r.ReconcileReadSystem(); err != nil {
+		t.Fatalf("ReconcileReadSystem: %v", err)
+	}
+	if r.CoreVersion != "5.8.0" || r.SystemInfo == nil {
+		t.Errorf("expected the core version from the notifier status, got %q", r.CoreVersion)
+	}
+}
@@ -43,6 +43,9 @@ func Cmd() *cobra.Command {
 		CmdList(),
 		CmdReconcile(),
 		CmdYaml(),
+		CmdSetDebug(),
+		CmdPause(),
+		CmdResume(),
 	)
 	return cmd
 }
@@ -58,6 +61,7 @@ func CmdCreate() *cobra.Command {
 	cmd.Flags().String("db-resources", "", "DB resources JSON")
 	cmd.Flags().String("endpoint-resources", "", "Endpoint resources JSON")
 	cmd.Flags().Bool("use-obc-cleanup-policy", false, "Create NooBaa system with obc cleanup policy")
+	cmd.Flags().Bool("skip-preflight", false, "Create the system even if the preflight checks fail")
 	return cmd
 }
 
@@ -201,6 +205,22 @@ func RunOperatorCreate(cmd *cobra.Command, args []string) {
 
 // RunCreate runs a CLI command
 func RunCreate(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+	skipPreflight, _ := cmd.Flags().GetBool("skip-preflight")
+	log.Printf("Preflight:")
+	if !RunPreflight(cmd, PreflightSystemChecks) {
+		if !skipPreflight {
+			log.Fatalf(`❌ Preflight checks failed, fix the failures above or create with --skip-preflight`)
+		}
+		log.Printf("⚠️  Preflight checks failed, continuing with --skip-preflight")
+	}
+	log.Printf("")
+	CreateSystem(cmd)
+}
+
+// CreateSystem creates the namespace and the system from the defaults and the flags of the command,
+// without the preflight checks, which install runs before creating the operator
+func CreateSystem(cmd *cobra.Command) {
 	log := util.Logger()
 	sys := LoadSystemDefaults()
 	ns := util.KubeObject(bundle.File_deploy_namespace_yaml).(*corev1.Namespace)
@@ -426,7 +446,7 @@ func CheckNooBaaImages(cmd *cobra.Command, sys *nbv1.NooBaa, args []string) stri
 	if sys.Status.ActualImage != "" {
 		desiredImage = sys.Status.ActualImage
 	} else if sys.Spec.Image != nil {
-		desiredImage = *sys.Spec.DBImage
+		desiredImage = *sys.Spec.Image
 	}
 	sts := util.KubeObject(bundle.File_deploy_internal_statefulset_core_yaml).(*appsv1.StatefulSet)
 	sts.Namespace = options.Namespace
@@ -505,6 +525,21 @@ func RunSystemVersionsStatus(cmd *cobra.Command, args []string) {
 	log.Printf("noobaa-image: %s\n", noobaaImage)
 	log.Printf("operator-image: %s\n", noobaaOperatorImage)
 	log.Printf("noobaa-db-image: %s\n", noobaaDbImage)
+
+	// the operator reports the compatibility of the system in its status,
+	// otherwise check the image that the CLI would install with its own version
+	compatibility := sys.Status.Compatibility
+	if !isSystemExists || compatibility == nil {
+		compatibility = DecideCompatibility(version.Version, noobaaImage, "", "")
+	}
+	log.Printf("compatibility: %s - %s\n", compatibility.Decision, compatibility.Message)
+	if u := sys.Status.Upgrade; isSystemExists && u != nil {
+		if u.Result != "" {
+			log.Printf("upgrade: %s from %q to %q - %s\n", u.Result, u.FromImage, u.ToImage, u.Message)
+		} else {
+			log.Printf("upgrade: %s from %q to %q - %s\n", sys.Status.UpgradePhase, u.FromImage, u.ToImage, u.Message)
+		}
+	}
 }
 
 // RunStatus runs a CLI command
@@ -794,9 +829,15 @@ func GetNBClient() nb.Client {
 // When isExternal is true we return  : s3 => external DNS, mgmt => port-forwarding (router)
 // When isExternal is false we return : s3 => internal DNS, mgmt => node-port
 func Connect(isExternal bool) (*Client, error) {
+	return ConnectNamespace(options.Namespace, isExternal)
+}
+
+// ConnectNamespace is like Connect for the system in the given namespace,
+// which is used by a cluster-wide operator that serves systems in several namespaces.
+func ConnectNamespace(ns string, isExternal bool) (*Client, error) {
 
 	klient := util.KubeClient()
-	sysObjKey := client.ObjectKey{Namespace: options.Namespace, Name: options.SystemName}
+	sysObjKey := client.ObjectKey{Namespace: ns, Name: options.SystemName}
 	r := NewReconciler(sysObjKey, klient, scheme.Scheme, nil)
 
 	if !CheckSystem(r.NooBaa) {
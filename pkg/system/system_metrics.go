@@ -0,0 +1,67 @@
+package system
+
+import (
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+)
+
+// quotaUnits maps the bucket quota units of noobaa-core to bytes
+var quotaUnits = map[string]float64{
+	"GIGABYTE": 1024 * 1024 * 1024,
+	"TERABYTE": 1024 * 1024 * 1024 * 1024,
+	"PETABYTE": 1024 * 1024 * 1024 * 1024 * 1024,
+}
+
+// UpdateSystemMetrics exports the buckets and pools state from the last read_system as prometheus metrics
+func (r *Reconciler) UpdateSystemMetrics(systemInfo *nb.SystemInfo) {
+	s := metrics.NewSystemSnapshot(r.Request.Namespace)
+
+	tierPools := map[string][]string{}
+	for i := range systemInfo.Tiers {
+		t := &systemInfo.Tiers[i]
+		tierPools[t.Name] = t.AttachedPools
+	}
+
+	for i := range systemInfo.Buckets {
+		b := &systemInfo.Buckets[i]
+		bucketClass := ""
+		obcNamespace := ""
+		if b.BucketClaim != nil {
+			bucketClass = b.BucketClaim.BucketClass
+			obcNamespace = b.BucketClaim.Namespace
+		}
+		var size, sizeReduced, objects, quota float64
+		if b.Data != nil {
+			size = nb.BigIntToFloat64(b.Data.Size)
+			sizeReduced = nb.BigIntToFloat64(b.Data.SizeReduced)
+		}
+		if b.NumObjects != nil {
+			objects = float64(b.NumObjects.Value)
+		}
+		if b.Quota != nil {
+			quota = float64(b.Quota.Size) * quotaUnits[b.Quota.Unit]
+		}
+		s.AddBucket(b.Name, bucketClass, obcNamespace, b.Mode, size, sizeReduced, objects, quota)
+		if b.Tiering != nil {
+			for _, t := range b.Tiering.Tiers {
+				for _, pool := range tierPools[t.Tier] {
+					s.AddBucketBackingStore(b.Name, t.Tier, pool)
+				}
+			}
+		}
+	}
+
+	for i := range systemInfo.Pools {
+		p := &systemInfo.Pools[i]
+		var total, free, used, usedOther float64
+		if p.Storage != nil {
+			total = nb.BigIntToFloat64(p.Storage.Total)
+			free = nb.BigIntToFloat64(p.Storage.Free)
+			used = nb.BigIntToFloat64(p.Storage.Used)
+			usedOther = nb.BigIntToFloat64(p.Storage.UsedOther)
+		}
+		s.AddBackingStore(p.Name, p.ResourceType, p.Mode, total, free, used, usedOther)
+	}
+
+	metrics.SetSystemSnapshot(s)
+}
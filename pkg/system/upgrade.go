@@ -0,0 +1,573 @@
+package system
+
+import (
+	"fmt"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultUpgradeStepTimeout is the time that every upgrade step has to become healthy by default
+	DefaultUpgradeStepTimeout = 10 * time.Minute
+
+	// UpgradeRequeueInterval is the interval of the reconciles that check the health of an upgrade step
+	UpgradeRequeueInterval = 10 * time.Second
+
+	// MaxUpgradeHistory is the number of completed upgrades kept in the system status
+	MaxUpgradeHistory = 10
+)
+
+// upgradeSteps are the steps of a core image upgrade in order.
+// A component runs the new image from the step that upgrades it until the upgrade is rolled back.
+var upgradeSteps = []nbv1.UpgradePhase{
+	nbv1.UpgradePhaseBackupDB,
+	nbv1.UpgradePhaseCore,
+	nbv1.UpgradePhaseEndpoints,
+	nbv1.UpgradePhaseAgents,
+}
+
+// dbSnapshotAPIVersions are the versions of the VolumeSnapshot api in order of preference
+var dbSnapshotAPIVersions = []string{
+	"snapshot.storage.k8s.io/v1",
+	"snapshot.storage.k8s.io/v1beta1",
+}
+
+// upgradeCheck checks the health of an upgrade step for the image that the step rolls to.
+// It returns false when the step is still in progress and an error when the step failed.
+type upgradeCheck func(phase nbv1.UpgradePhase, image string) (bool, error)
+
+// IsImageUpgradePhase returns true for the phases of a core image upgrade,
+// as opposed to the phases of the DB migration
+func IsImageUpgradePhase(phase nbv1.UpgradePhase) bool {
+	return phase == nbv1.UpgradePhaseRollback || upgradeStepIndex(phase) >= 0
+}
+
+// UpgradeInProgress returns true when the system is in the middle of a core image upgrade
+func UpgradeInProgress(sys *nbv1.NooBaa) bool {
+	return sys.Status.Upgrade != nil && sys.Status.Upgrade.Result == ""
+}
+
+func upgradeStepIndex(phase nbv1.UpgradePhase) int {
+	for i, step := range upgradeSteps {
+		if step == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// upgradeStepImage returns the image of the component that is upgraded in the given step
+func upgradeStepImage(sys *nbv1.NooBaa, step nbv1.UpgradePhase) string {
+	u := sys.Status.Upgrade
+	if u == nil {
+		return sys.Status.ActualImage
+	}
+	if u.Result == "" && upgradeStepIndex(sys.Status.UpgradePhase) >= upgradeStepIndex(step) {
+		return u.ToImage
+	}
+	return u.FromImage
+}
+
+// CoreImage returns the image of the core statefulset,
+// which is ahead of the actual image while the endpoints and agents are upgraded
+func (r *Reconciler) CoreImage() string {
+	return upgradeStepImage(r.NooBaa, nbv1.UpgradePhaseCore)
+}
+
+// EndpointsImage returns the image of the endpoint deployments,
+// which is ahead of the actual image while the agents are upgraded
+func (r *Reconciler) EndpointsImage() string {
+	return upgradeStepImage(r.NooBaa, nbv1.UpgradePhaseEndpoints)
+}
+
+// ReconcileUpgrade rolls a change of the core image through the system one component at a time.
+// It takes a snapshot of the DB volume, then upgrades core, the endpoints and the pv-pool agents,
+// waiting for every step to become healthy, and reverts all of them to the previous image when a step fails.
+// The actual image of the system is set to the image that the agents and the rest of the pods should use.
+func (r *Reconciler) ReconcileUpgrade(specImage string) {
+	r.stepUpgrade(specImage, r.canStartUpgrade, r.checkUpgradeStep, metav1.Now())
+}
+
+// stepUpgrade advances the upgrade state machine by at most one transition
+// canStart returns false when the image change is applied without orchestration,
+// or an error when the upgrade should wait, leaving the system on the current image.
+func (r *Reconciler) stepUpgrade(specImage string, canStart func() (bool, error), check upgradeCheck, now metav1.Time) {
+	sys := r.NooBaa
+	u := sys.Status.Upgrade
+
+	// a failed upgrade holds the system on the previous image until the image is changed
+	if u != nil && u.Result != "" {
+		if specImage == u.ToImage {
+			sys.Status.ActualImage = u.FromImage
+			return
+		}
+		sys.Status.Upgrade = nil
+		u = nil
+	}
+
+	if u == nil {
+		if sys.Status.ActualImage == "" || sys.Status.ActualImage == specImage {
+			sys.Status.ActualImage = specImage
+			return
+		}
+		start, err := canStart()
+		if err != nil {
+			r.Logger.Infof("⏳ Upgrade to %q is waiting: %s", specImage, err)
+			return
+		}
+		if !start {
+			sys.Status.ActualImage = specImage
+			return
+		}
+		u = &nbv1.UpgradeStatus{
+			FromImage:            sys.Status.ActualImage,
+			ToImage:              specImage,
+			StartTime:            now,
+			PreviousUpgradePhase: sys.Status.UpgradePhase,
+		}
+		sys.Status.Upgrade = u
+		r.upgradeEvent(corev1.EventTypeNormal, "UpgradeStarted",
+			fmt.Sprintf("Upgrading the core image from %q to %q", u.FromImage, u.ToImage))
+		r.setUpgradeStep(nbv1.UpgradePhaseBackupDB, now, "Taking a snapshot of the DB volume")
+		return
+	}
+
+	phase := sys.Status.UpgradePhase
+	if specImage != u.ToImage && phase != nbv1.UpgradePhaseRollback {
+		msg := fmt.Sprintf("The image was changed to %q during the upgrade", specImage)
+		if phase == nbv1.UpgradePhaseBackupDB {
+			// no component was upgraded yet
+			r.completeUpgrade(nbv1.UpgradeResultFailed, now, msg)
+		} else {
+			r.rollbackUpgrade(now, msg)
+		}
+		return
+	}
+
+	image := u.ToImage
+	if phase == nbv1.UpgradePhaseRollback {
+		image = u.FromImage
+	}
+	ready, err := check(phase, image)
+	timeout := r.upgradeStepTimeout()
+	if err == nil && !ready && now.Sub(u.StepStartTime.Time) > timeout {
+		err = fmt.Errorf("%s did not complete within %s", phase, timeout)
+	}
+	if err != nil {
+		switch phase {
+		case nbv1.UpgradePhaseBackupDB:
+			r.completeUpgrade(nbv1.UpgradeResultFailed, now, fmt.Sprintf("DB snapshot failed: %s", err))
+		case nbv1.UpgradePhaseRollback:
+			r.completeUpgrade(nbv1.UpgradeResultFailed, now, fmt.Sprintf("Rollback failed: %s (%s)", err, u.Message))
+		default:
+			r.rollbackUpgrade(now, fmt.Sprintf("Upgrade step failed: %s", err))
+		}
+		return
+	}
+	if !ready {
+		r.Logger.Infof("⏳ Upgrade from %q to %q: waiting for %s", u.FromImage, u.ToImage, phase)
+		sys.Status.ActualImage = upgradeStepImage(sys, nbv1.UpgradePhaseAgents)
+		return
+	}
+
+	switch phase {
+	case nbv1.UpgradePhaseBackupDB:
+		r.setUpgradeStep(nbv1.UpgradePhaseCore, now, "Rolling the core statefulset")
+	case nbv1.UpgradePhaseCore:
+		r.setUpgradeStep(nbv1.UpgradePhaseEndpoints, now, "Rolling the endpoint deployments")
+	case nbv1.UpgradePhaseEndpoints:
+		r.setUpgradeStep(nbv1.UpgradePhaseAgents, now, "Rolling the pv-pool agents")
+	case nbv1.UpgradePhaseAgents:
+		r.completeUpgrade(nbv1.UpgradeResultSucceeded, now, "")
+	case nbv1.UpgradePhaseRollback:
+		r.completeUpgrade(nbv1.UpgradeResultRolledBack, now, u.Message)
+	}
+}
+
+func (r *Reconciler) setUpgradeStep(phase nbv1.UpgradePhase, now metav1.Time, msg string) {
+	sys := r.NooBaa
+	u := sys.Status.Upgrade
+	sys.Status.UpgradePhase = phase
+	u.StepStartTime = now
+	u.Message = msg
+	sys.Status.ActualImage = upgradeStepImage(sys, nbv1.UpgradePhaseAgents)
+	r.Logger.Infof("Upgrade from %q to %q: %s", u.FromImage, u.ToImage, msg)
+	r.upgradeEvent(corev1.EventTypeNormal, "UpgradeStep", fmt.Sprintf("%s: %s", phase, msg))
+}
+
+// rollbackUpgrade reverts all the components to the previous image.
+// The operator cannot tell if the new core migrated the DB schema, so once core ran the new image
+// the rollback records that the previous image may need a manual restore of the DB from the snapshot.
+func (r *Reconciler) rollbackUpgrade(now metav1.Time, reason string) {
+	u := r.NooBaa.Status.Upgrade
+	if upgradeStepIndex(r.NooBaa.Status.UpgradePhase) >= upgradeStepIndex(nbv1.UpgradePhaseCore) {
+		restore := dbRestoreMessage(u)
+		reason = fmt.Sprintf("%s. %s", reason, restore)
+		r.Logger.Warnf("⚠️  Upgrade from %q to %q: %s", u.FromImage, u.ToImage, restore)
+		r.upgradeEvent(corev1.EventTypeWarning, "UpgradeDBRestoreMayBeNeeded", restore)
+	}
+	r.NooBaa.Status.UpgradePhase = nbv1.UpgradePhaseRollback
+	u.StepStartTime = now
+	u.Message = reason
+	r.NooBaa.Status.ActualImage = u.FromImage
+	r.Logger.Errorf("❌ Upgrade from %q to %q: %s, rolling back", u.FromImage, u.ToImage, reason)
+	r.upgradeEvent(corev1.EventTypeWarning, "UpgradeRollingBack",
+		fmt.Sprintf("Rolling back to %q: %s", u.FromImage, reason))
+}
+
+// dbRestoreMessage explains how to recover when the previous image cannot use a DB that the new core migrated
+func dbRestoreMessage(u *nbv1.UpgradeStatus) string {
+	msg := fmt.Sprintf("Core ran %q and may have migrated the DB, so if core does not become ready on %q", u.ToImage, u.FromImage)
+	if u.DBSnapshot == "" {
+		return msg + " the DB must be restored manually since no DB snapshot was taken"
+	}
+	return fmt.Sprintf("%s restore the DB volume manually from VolumeSnapshot %q", msg, u.DBSnapshot)
+}
+
+// completeUpgrade records the upgrade in the history and restores the upgrade phase of the DB migration.
+// A failed upgrade is kept in the status to hold the system on the previous image.
+func (r *Reconciler) completeUpgrade(result nbv1.UpgradeResult, now metav1.Time, msg string) {
+	sys := r.NooBaa
+	u := sys.Status.Upgrade
+	sys.Status.UpgradeHistory = append(sys.Status.UpgradeHistory, nbv1.UpgradeHistoryEntry{
+		FromImage:      u.FromImage,
+		ToImage:        u.ToImage,
+		StartTime:      u.StartTime,
+		CompletionTime: now,
+		Result:         result,
+		DBSnapshot:     u.DBSnapshot,
+		Message:        msg,
+	})
+	if n := len(sys.Status.UpgradeHistory); n > MaxUpgradeHistory {
+		r.pruneDBSnapshots(sys.Status.UpgradeHistory[:n-MaxUpgradeHistory])
+		sys.Status.UpgradeHistory = sys.Status.UpgradeHistory[n-MaxUpgradeHistory:]
+	}
+	sys.Status.UpgradePhase = u.PreviousUpgradePhase
+
+	if result == nbv1.UpgradeResultSucceeded {
+		sys.Status.Upgrade = nil
+		sys.Status.ActualImage = u.ToImage
+		r.Logger.Infof("✅ Upgrade from %q to %q succeeded", u.FromImage, u.ToImage)
+		r.upgradeEvent(corev1.EventTypeNormal, "UpgradeSucceeded",
+			fmt.Sprintf("Upgraded the core image from %q to %q", u.FromImage, u.ToImage))
+		return
+	}
+	u.Result = result
+	u.Message = msg
+	sys.Status.ActualImage = u.FromImage
+	r.Logger.Errorf("❌ Upgrade from %q to %q %s: %s", u.FromImage, u.ToImage, result, msg)
+	r.upgradeEvent(corev1.EventTypeWarning, "Upgrade"+string(result),
+		fmt.Sprintf("Upgrade to %q %s, the system stays on %q until the image is changed: %s",
+			u.ToImage, result, u.FromImage, msg))
+}
+
+func (r *Reconciler) upgradeEvent(eventType string, reason string, msg string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(r.NooBaa, eventType, reason, msg)
+	}
+}
+
+func (r *Reconciler) upgradeStepTimeout() time.Duration {
+	spec := r.NooBaa.Spec.Upgrade
+	if spec != nil && spec.StepTimeout != nil && spec.StepTimeout.Duration > 0 {
+		return spec.StepTimeout.Duration
+	}
+	return DefaultUpgradeStepTimeout
+}
+
+// canStartUpgrade returns true when the image change should be orchestrated,
+// which requires a core statefulset and no pending DB migration.
+// It returns an error while the core pods are not ready, since the DB snapshot
+// and the health of the steps are only meaningful when starting from a ready core.
+func (r *Reconciler) canStartUpgrade() (bool, error) {
+	if r.NooBaa.Spec.DBType == nbv1.DBTypePostgres {
+		phase := r.NooBaa.Status.UpgradePhase
+		if phase != nbv1.UpgradePhaseNone && phase != nbv1.UpgradePhaseFinished {
+			return false, nil
+		}
+	}
+	sts := r.CoreApp.DeepCopy()
+	if !util.KubeCheckQuiet(sts) {
+		return false, nil
+	}
+	if !isStatefulSetReady(sts) {
+		return false, fmt.Errorf("core statefulset %q has %d/%d ready replicas",
+			sts.Name, sts.Status.ReadyReplicas, statefulSetReplicas(sts))
+	}
+	return true, nil
+}
+
+func (r *Reconciler) checkUpgradeStep(phase nbv1.UpgradePhase, image string) (bool, error) {
+	switch phase {
+	case nbv1.UpgradePhaseBackupDB:
+		return r.reconcileDBSnapshot()
+	case nbv1.UpgradePhaseCore:
+		return r.checkCoreImage(image), nil
+	case nbv1.UpgradePhaseEndpoints:
+		return r.checkEndpointsImage(image), nil
+	case nbv1.UpgradePhaseAgents:
+		return r.checkAgentsImage(image), nil
+	case nbv1.UpgradePhaseRollback:
+		return r.checkCoreImage(image) && r.checkEndpointsImage(image) && r.checkAgentsImage(image), nil
+	}
+	return false, fmt.Errorf("Unknown upgrade phase %q", phase)
+}
+
+// checkCoreImage returns true when all the core pods run the image and are ready
+func (r *Reconciler) checkCoreImage(image string) bool {
+	sts := r.CoreApp.DeepCopy()
+	if !util.KubeCheckQuiet(sts) {
+		return false
+	}
+	for i := range sts.Spec.Template.Spec.Containers {
+		c := &sts.Spec.Template.Spec.Containers[i]
+		if c.Name == "core" && c.Image != image {
+			return false
+		}
+	}
+	return sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.UpdatedReplicas == statefulSetReplicas(sts) &&
+		isStatefulSetReady(sts) &&
+		sts.Status.CurrentRevision == sts.Status.UpdateRevision
+}
+
+// isStatefulSetReady returns true when all the replicas of the statefulset are ready
+func isStatefulSetReady(sts *appsv1.StatefulSet) bool {
+	replicas := statefulSetReplicas(sts)
+	return replicas > 0 && sts.Status.ReadyReplicas == replicas
+}
+
+func statefulSetReplicas(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.Replicas != nil {
+		return *sts.Spec.Replicas
+	}
+	return 1
+}
+
+// checkEndpointsImage returns true when the endpoint deployment and the deployments of the groups
+// completed the rollout of the image and all their pods are available
+func (r *Reconciler) checkEndpointsImage(image string) bool {
+	r.LoadEndpointGroups()
+	deployments := []*appsv1.Deployment{r.DeploymentEndpoint}
+	for _, group := range r.EndpointGroups {
+		deployments = append(deployments, group.Deployment)
+	}
+	for _, desired := range deployments {
+		deployment := desired.DeepCopy()
+		if !util.KubeCheckQuiet(deployment) {
+			continue
+		}
+		for i := range deployment.Spec.Template.Spec.Containers {
+			c := &deployment.Spec.Template.Spec.Containers[i]
+			if c.Name == "endpoint" && c.Image != image {
+				return false
+			}
+		}
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if deployment.Status.ObservedGeneration < deployment.Generation ||
+			deployment.Status.UpdatedReplicas < replicas ||
+			deployment.Status.Replicas != deployment.Status.UpdatedReplicas ||
+			deployment.Status.AvailableReplicas != deployment.Status.UpdatedReplicas {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAgentsImage returns true when the pv-pool agents of the system run the image
+// and the agents that were restarted since the step started are ready.
+// Agents that the upgrade did not restart, like an agent that was already unready, do not hold the step.
+func (r *Reconciler) checkAgentsImage(image string) bool {
+	since := r.NooBaa.Status.Upgrade.StepStartTime
+	storesList := &nbv1.BackingStoreList{}
+	if err := r.Client.List(r.Ctx, storesList, client.InNamespace(r.Request.Namespace)); err != nil {
+		return false
+	}
+	for i := range storesList.Items {
+		bs := &storesList.Items[i]
+		if bs.Spec.Type != nbv1.StoreTypePVPool {
+			continue
+		}
+		podsList := &corev1.PodList{}
+		if err := r.Client.List(r.Ctx, podsList, client.InNamespace(r.Request.Namespace), client.MatchingLabels{"pool": bs.Name}); err != nil {
+			return false
+		}
+		for j := range podsList.Items {
+			pod := &podsList.Items[j]
+			if !metav1.IsControlledBy(pod, bs) {
+				continue
+			}
+			if len(pod.Spec.Containers) == 0 || pod.Spec.Containers[0].Image != image {
+				return false
+			}
+			if pod.CreationTimestamp.Before(&since) {
+				continue
+			}
+			ready := false
+			for _, cond := range pod.Status.Conditions {
+				if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+					ready = true
+				}
+			}
+			if !ready {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// reconcileDBSnapshot creates a VolumeSnapshot of the DB volume and returns true when it is ready to use.
+// The snapshot is skipped when it is disabled, when the DB is external,
+// or when the cluster does not serve the VolumeSnapshot api.
+func (r *Reconciler) reconcileDBSnapshot() (bool, error) {
+	u := r.NooBaa.Status.Upgrade
+	spec := r.NooBaa.Spec.Upgrade
+
+	if u.DBSnapshot == "" {
+		skip := ""
+		if spec != nil && spec.DisableDBSnapshot {
+			skip = "DB snapshot is disabled"
+		} else if r.NooBaa.Spec.MongoDbURL != "" {
+			skip = "DB is external"
+		}
+		if skip != "" {
+			u.Message = skip
+			r.Logger.Infof("Upgrade from %q to %q: %s", u.FromImage, u.ToImage, skip)
+			return true, nil
+		}
+
+		dbSts := r.NooBaaMongoDB
+		if r.NooBaa.Spec.DBType == nbv1.DBTypePostgres {
+			dbSts = r.NooBaaPostgresDB
+		}
+		pvcName := fmt.Sprintf("%s-%s-0", dbSts.Spec.VolumeClaimTemplates[0].Name, dbSts.Name)
+		name := fmt.Sprintf("%s-upgrade-%s", pvcName, u.StartTime.UTC().Format("20060102150405"))
+		className := ""
+		if spec != nil {
+			className = spec.DBSnapshotClass
+		}
+		created, err := r.createDBSnapshot(name, pvcName, className)
+		if err != nil {
+			// retry until the step times out
+			r.Logger.Warnf("⏳ Upgrade from %q to %q: could not create DB snapshot %q: %s", u.FromImage, u.ToImage, name, err)
+			return false, nil
+		}
+		if !created {
+			u.Message = "VolumeSnapshot api is not available, skipping the DB snapshot"
+			r.Logger.Warnf("⚠️  Upgrade from %q to %q: %s", u.FromImage, u.ToImage, u.Message)
+			r.upgradeEvent(corev1.EventTypeWarning, "UpgradeDBSnapshotSkipped", u.Message)
+			return true, nil
+		}
+		u.DBSnapshot = name
+		u.Message = fmt.Sprintf("Waiting for DB snapshot %q of volume %q", name, pvcName)
+		r.Logger.Infof("Upgrade from %q to %q: created DB snapshot %q", u.FromImage, u.ToImage, name)
+		return false, nil
+	}
+
+	snapshot, err := r.getDBSnapshot(u.DBSnapshot)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, fmt.Errorf("VolumeSnapshot %q was deleted", u.DBSnapshot)
+		}
+		r.Logger.Warnf("⏳ Upgrade from %q to %q: could not read DB snapshot %q: %s", u.FromImage, u.ToImage, u.DBSnapshot, err)
+		return false, nil
+	}
+	if msg, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+		return false, fmt.Errorf("VolumeSnapshot %q: %s", u.DBSnapshot, msg)
+	}
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	return ready, nil
+}
+
+// createDBSnapshot creates a VolumeSnapshot of the pvc with the first served version of the api.
+// It returns false when no version of the VolumeSnapshot api is served.
+func (r *Reconciler) createDBSnapshot(name string, pvcName string, className string) (bool, error) {
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": pvcName},
+	}
+	if className != "" {
+		spec["volumeSnapshotClassName"] = className
+	}
+	for _, apiVersion := range dbSnapshotAPIVersions {
+		snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		snapshot.SetAPIVersion(apiVersion)
+		snapshot.SetKind("VolumeSnapshot")
+		snapshot.SetNamespace(r.Request.Namespace)
+		snapshot.SetName(name)
+		// the snapshot is not owned by the system so that it is kept as a backup
+		snapshot.SetLabels(map[string]string{"app": "noobaa"})
+		err := r.Client.Create(r.Ctx, snapshot)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// getDBSnapshot reads a VolumeSnapshot with the first served version of the api
+func (r *Reconciler) getDBSnapshot(name string) (*unstructured.Unstructured, error) {
+	var err error
+	for _, apiVersion := range dbSnapshotAPIVersions {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetAPIVersion(apiVersion)
+		snapshot.SetKind("VolumeSnapshot")
+		err = r.Client.Get(r.Ctx, types.NamespacedName{Namespace: r.Request.Namespace, Name: name}, snapshot)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		return snapshot, err
+	}
+	return nil, err
+}
+
+// deleteDBSnapshot deletes a VolumeSnapshot with the first served version of the api
+func (r *Reconciler) deleteDBSnapshot(name string) error {
+	for _, apiVersion := range dbSnapshotAPIVersions {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetAPIVersion(apiVersion)
+		snapshot.SetKind("VolumeSnapshot")
+		snapshot.SetNamespace(r.Request.Namespace)
+		snapshot.SetName(name)
+		err := r.Client.Delete(r.Ctx, snapshot)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// pruneDBSnapshots deletes the DB snapshots of the upgrades that drop out of the history
+func (r *Reconciler) pruneDBSnapshots(entries []nbv1.UpgradeHistoryEntry) {
+	for _, entry := range entries {
+		if entry.DBSnapshot == "" {
+			continue
+		}
+		if err := r.deleteDBSnapshot(entry.DBSnapshot); err != nil {
+			r.Logger.Warnf("⚠️  Could not delete DB snapshot %q of the upgrade to %q: %s", entry.DBSnapshot, entry.ToImage, err)
+			continue
+		}
+		r.Logger.Infof("Deleted DB snapshot %q of the upgrade to %q", entry.DBSnapshot, entry.ToImage)
+	}
+}
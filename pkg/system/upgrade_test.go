@@ -0,0 +1,326 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	upgradeFrom = "noobaa/noobaa-core:5.8.0"
+	upgradeTo   = "noobaa/noobaa-core:5.9.0"
+)
+
+// upgradeTest drives the upgrade state machine with fake health checks
+type upgradeTest struct {
+	t       *testing.T
+	r       *Reconciler
+	now     time.Time
+	healthy map[nbv1.UpgradePhase]bool
+	failed  map[nbv1.UpgradePhase]bool
+}
+
+func newUpgradeTest(t *testing.T) *upgradeTest {
+	sys := &nbv1.NooBaa{}
+	sys.Status.ActualImage = upgradeFrom
+	sys.Status.UpgradePhase = nbv1.UpgradePhaseNone
+	return &upgradeTest{
+		t:       t,
+		r:       &Reconciler{NooBaa: sys, Logger: logrus.WithField("test", t.Name())},
+		now:     time.Now(),
+		healthy: map[nbv1.UpgradePhase]bool{},
+		failed:  map[nbv1.UpgradePhase]bool{},
+	}
+}
+
+func (ut *upgradeTest) step(specImage string) {
+	check := func(phase nbv1.UpgradePhase, image string) (bool, error) {
+		if ut.failed[phase] {
+			return false, fmt.Errorf("%s failed", phase)
+		}
+		return ut.healthy[phase], nil
+	}
+	ut.r.stepUpgrade(specImage, func() (bool, error) { return true, nil }, check, metav1.NewTime(ut.now))
+}
+
+func (ut *upgradeTest) expect(phase nbv1.UpgradePhase, core string, endpoints string, actual string) {
+	ut.t.Helper()
+	sys := ut.r.NooBaa
+	if sys.Status.UpgradePhase != phase {
+		ut.t.Fatalf("expected upgrade phase %q got %q", phase, sys.Status.UpgradePhase)
+	}
+	if got := ut.r.CoreImage(); got != core {
+		ut.t.Fatalf("%s: expected core image %q got %q", phase, core, got)
+	}
+	if got := ut.r.EndpointsImage(); got != endpoints {
+		ut.t.Fatalf("%s: expected endpoints image %q got %q", phase, endpoints, got)
+	}
+	if sys.Status.ActualImage != actual {
+		ut.t.Fatalf("%s: expected actual image %q got %q", phase, actual, sys.Status.ActualImage)
+	}
+}
+
+func TestUpgradeSucceeded(t *testing.T) {
+	ut := newUpgradeTest(t)
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseBackupDB, upgradeFrom, upgradeFrom, upgradeFrom)
+
+	// every step waits for its health check
+	for _, step := range []struct {
+		phase     nbv1.UpgradePhase
+		core      string
+		endpoints string
+		actual    string
+	}{
+		{nbv1.UpgradePhaseBackupDB, upgradeFrom, upgradeFrom, upgradeFrom},
+		{nbv1.UpgradePhaseCore, upgradeTo, upgradeFrom, upgradeFrom},
+		{nbv1.UpgradePhaseEndpoints, upgradeTo, upgradeTo, upgradeFrom},
+		{nbv1.UpgradePhaseAgents, upgradeTo, upgradeTo, upgradeTo},
+	} {
+		ut.step(upgradeTo)
+		ut.expect(step.phase, step.core, step.endpoints, step.actual)
+		ut.healthy[step.phase] = true
+	}
+	ut.step(upgradeTo)
+
+	sys := ut.r.NooBaa
+	ut.expect(nbv1.UpgradePhaseNone, upgradeTo, upgradeTo, upgradeTo)
+	if sys.Status.Upgrade != nil {
+		t.Fatalf("expected the upgrade status to be cleared, got %+v", sys.Status.Upgrade)
+	}
+	if len(sys.Status.UpgradeHistory) != 1 || sys.Status.UpgradeHistory[0].Result != nbv1.UpgradeResultSucceeded {
+		t.Fatalf("expected a succeeded upgrade in the history, got %+v", sys.Status.UpgradeHistory)
+	}
+}
+
+func TestUpgradeRollback(t *testing.T) {
+	ut := newUpgradeTest(t)
+	ut.healthy[nbv1.UpgradePhaseBackupDB] = true
+	ut.step(upgradeTo)
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseCore, upgradeTo, upgradeFrom, upgradeFrom)
+
+	// the core step times out
+	ut.now = ut.now.Add(DefaultUpgradeStepTimeout + time.Second)
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseRollback, upgradeFrom, upgradeFrom, upgradeFrom)
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseRollback, upgradeFrom, upgradeFrom, upgradeFrom)
+
+	ut.healthy[nbv1.UpgradePhaseRollback] = true
+	ut.step(upgradeTo)
+	sys := ut.r.NooBaa
+	ut.expect(nbv1.UpgradePhaseNone, upgradeFrom, upgradeFrom, upgradeFrom)
+	if sys.Status.Upgrade == nil || sys.Status.Upgrade.Result != nbv1.UpgradeResultRolledBack {
+		t.Fatalf("expected a rolled back upgrade, got %+v", sys.Status.Upgrade)
+	}
+	if len(sys.Status.UpgradeHistory) != 1 || sys.Status.UpgradeHistory[0].Result != nbv1.UpgradeResultRolledBack {
+		t.Fatalf("expected a rolled back upgrade in the history, got %+v", sys.Status.UpgradeHistory)
+	}
+	if !strings.Contains(sys.Status.Upgrade.Message, "may have migrated the DB") {
+		t.Fatalf("expected the rollback after core ran the new image to note a DB restore, got %q", sys.Status.Upgrade.Message)
+	}
+
+	// the system is held on the previous image until the image is changed
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseNone, upgradeFrom, upgradeFrom, upgradeFrom)
+	ut.step(upgradeFrom)
+	ut.expect(nbv1.UpgradePhaseNone, upgradeFrom, upgradeFrom, upgradeFrom)
+	if sys.Status.Upgrade != nil {
+		t.Fatalf("expected the hold to be cleared, got %+v", sys.Status.Upgrade)
+	}
+}
+
+func TestUpgradeFailures(t *testing.T) {
+	// a failed DB snapshot does not change any component
+	ut := newUpgradeTest(t)
+	ut.failed[nbv1.UpgradePhaseBackupDB] = true
+	ut.step(upgradeTo)
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseNone, upgradeFrom, upgradeFrom, upgradeFrom)
+	if u := ut.r.NooBaa.Status.Upgrade; u == nil || u.Result != nbv1.UpgradeResultFailed {
+		t.Fatalf("expected a failed upgrade, got %+v", u)
+	}
+
+	// a failed step rolls back, and a failed rollback holds the previous image
+	ut = newUpgradeTest(t)
+	ut.healthy[nbv1.UpgradePhaseBackupDB] = true
+	ut.healthy[nbv1.UpgradePhaseCore] = true
+	ut.failed[nbv1.UpgradePhaseEndpoints] = true
+	for i := 0; i < 4; i++ {
+		ut.step(upgradeTo)
+	}
+	ut.expect(nbv1.UpgradePhaseRollback, upgradeFrom, upgradeFrom, upgradeFrom)
+	ut.failed[nbv1.UpgradePhaseRollback] = true
+	ut.step(upgradeTo)
+	if u := ut.r.NooBaa.Status.Upgrade; u == nil || u.Result != nbv1.UpgradeResultFailed {
+		t.Fatalf("expected a failed rollback, got %+v", u)
+	}
+}
+
+func TestUpgradeImageChanged(t *testing.T) {
+	const other = "noobaa/noobaa-core:5.9.1"
+	ut := newUpgradeTest(t)
+	ut.healthy[nbv1.UpgradePhaseBackupDB] = true
+	ut.healthy[nbv1.UpgradePhaseRollback] = true
+	ut.step(upgradeTo)
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseCore, upgradeTo, upgradeFrom, upgradeFrom)
+
+	// changing the image during the upgrade rolls back and then upgrades to the new image
+	ut.step(other)
+	ut.expect(nbv1.UpgradePhaseRollback, upgradeFrom, upgradeFrom, upgradeFrom)
+	ut.step(other)
+	ut.step(other)
+	ut.expect(nbv1.UpgradePhaseBackupDB, upgradeFrom, upgradeFrom, upgradeFrom)
+	if u := ut.r.NooBaa.Status.Upgrade; u == nil || u.ToImage != other || u.Result != "" {
+		t.Fatalf("expected an upgrade to %q, got %+v", other, u)
+	}
+}
+
+func TestUpgradeNotOrchestrated(t *testing.T) {
+	// a new system and a system that cannot start an upgrade use the image at once
+	ut := newUpgradeTest(t)
+	ut.r.NooBaa.Status.ActualImage = ""
+	ut.step(upgradeTo)
+	ut.expect(nbv1.UpgradePhaseNone, upgradeTo, upgradeTo, upgradeTo)
+
+	ut = newUpgradeTest(t)
+	ut.r.stepUpgrade(upgradeTo, func() (bool, error) { return false, nil }, nil, metav1.Now())
+	ut.expect(nbv1.UpgradePhaseNone, upgradeTo, upgradeTo, upgradeTo)
+}
+
+func TestUpgradeWaitsForReadyCore(t *testing.T) {
+	ut := newUpgradeTest(t)
+	notReady := func() (bool, error) { return false, fmt.Errorf("core is not ready") }
+	ut.r.stepUpgrade(upgradeTo, notReady, nil, metav1.Now())
+	ut.expect(nbv1.UpgradePhaseNone, upgradeFrom, upgradeFrom, upgradeFrom)
+	if ut.r.NooBaa.Status.Upgrade != nil {
+		t.Fatalf("expected the upgrade not to start before core is ready, got %+v", ut.r.NooBaa.Status.Upgrade)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if isStatefulSetReady(sts) {
+		t.Errorf("expected a statefulset without ready replicas not to be ready")
+	}
+	sts.Status.ReadyReplicas = 1
+	if !isStatefulSetReady(sts) {
+		t.Errorf("expected a statefulset with its single replica ready to be ready")
+	}
+}
+
+func TestUpgradeHistoryLimit(t *testing.T) {
+	ut := newUpgradeTest(t)
+	ut.failed[nbv1.UpgradePhaseBackupDB] = true
+	for i := 0; i < MaxUpgradeHistory+3; i++ {
+		// a new image clears the hold of the previous failure
+		image := fmt.Sprintf("noobaa/noobaa-core:5.9.%d", i)
+		ut.step(image)
+		ut.step(image)
+	}
+	history := ut.r.NooBaa.Status.UpgradeHistory
+	if len(history) != MaxUpgradeHistory {
+		t.Fatalf("expected %d history entries got %d", MaxUpgradeHistory, len(history))
+	}
+	if !IsImageUpgradePhase(nbv1.UpgradePhaseRollback) || IsImageUpgradePhase(nbv1.UpgradePhaseMigrate) {
+		t.Fatalf("expected only the image upgrade phases to be reported")
+	}
+}
+
+// newAgentPod returns a pv-pool agent pod of the store, created at the given time and controlled by the store when owned
+func newAgentPod(name string, bs *nbv1.BackingStore, owned bool, image string, ready bool, created time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         testNamespace,
+			Labels:            map[string]string{"pool": bs.Name},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "noobaa-agent", Image: image}}},
+	}
+	if owned {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "noobaa.io/v1alpha1",
+			Kind:       "BackingStore",
+			Name:       bs.Name,
+			UID:        bs.UID,
+			Controller: &controller,
+		}}
+	}
+	if ready {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	return pod
+}
+
+func TestCheckAgentsImage(t *testing.T) {
+	stepStart := time.Now().Add(-time.Minute)
+	bs := &nbv1.BackingStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-store", Namespace: testNamespace, UID: "bs-uid"},
+		Spec:       nbv1.BackingStoreSpec{Type: nbv1.StoreTypePVPool},
+	}
+	restarted := newAgentPod("pv-store-noobaa-pod-1", bs, true, upgradeTo, false, stepStart.Add(time.Second))
+	r, _ := newFakeReconciler(t,
+		bs,
+		restarted,
+		// an agent that was unready before the step started is not waited for
+		newAgentPod("pv-store-noobaa-pod-2", bs, true, upgradeTo, false, stepStart.Add(-time.Hour)),
+		// pods with the pool label that the store does not control are ignored
+		newAgentPod("other", bs, false, upgradeFrom, false, stepStart),
+	)
+	r.NooBaa.Status.Upgrade = &nbv1.UpgradeStatus{StepStartTime: metav1.NewTime(stepStart)}
+
+	if r.checkAgentsImage(upgradeTo) {
+		t.Fatalf("expected an agent that was restarted by the upgrade to be waited for until ready")
+	}
+	restarted.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	if err := r.Client.Update(r.Ctx, restarted); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+	if !r.checkAgentsImage(upgradeTo) {
+		t.Fatalf("expected the agents of the upgrade to be ready")
+	}
+	if r.checkAgentsImage(upgradeFrom) {
+		t.Fatalf("expected agents that run another image to hold the step")
+	}
+}
+
+func TestUpgradeHistoryPrunesDBSnapshots(t *testing.T) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetAPIVersion(dbSnapshotAPIVersions[0])
+	snapshot.SetKind("VolumeSnapshot")
+	snapshot.SetNamespace(testNamespace)
+	snapshot.SetName("db-upgrade-0")
+	r, _ := newFakeReconciler(t, snapshot)
+	sys := r.NooBaa
+	for i := 0; i < MaxUpgradeHistory; i++ {
+		sys.Status.UpgradeHistory = append(sys.Status.UpgradeHistory, nbv1.UpgradeHistoryEntry{
+			DBSnapshot: fmt.Sprintf("db-upgrade-%d", i),
+			Result:     nbv1.UpgradeResultSucceeded,
+		})
+	}
+	sys.Status.Upgrade = &nbv1.UpgradeStatus{FromImage: upgradeFrom, ToImage: upgradeTo, DBSnapshot: "db-upgrade-new"}
+	r.completeUpgrade(nbv1.UpgradeResultSucceeded, metav1.Now(), "")
+
+	if n := len(sys.Status.UpgradeHistory); n != MaxUpgradeHistory || sys.Status.UpgradeHistory[0].DBSnapshot != "db-upgrade-1" {
+		t.Fatalf("expected the oldest upgrade to drop out of the history, got %d entries", n)
+	}
+	got := &unstructured.Unstructured{}
+	got.SetAPIVersion(dbSnapshotAPIVersions[0])
+	got.SetKind("VolumeSnapshot")
+	err := r.Client.Get(r.Ctx, types.NamespacedName{Namespace: testNamespace, Name: "db-upgrade-0"}, got)
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected the snapshot of the dropped upgrade to be deleted, got %v", err)
+	}
+}
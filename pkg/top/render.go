@@ -0,0 +1,340 @@
+package top
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Snapshot is the state of the system loaded on every refresh
+type Snapshot struct {
+	Time          time.Time
+	NooBaa        *nbv1.NooBaa
+	HPA           *autoscalingv1.HorizontalPodAutoscaler
+	BackingStores []nbv1.BackingStore
+	SystemInfo    *nb.SystemInfo
+	Events        []corev1.Event
+	Errors        []string
+	// Loading is set on the placeholder snapshot that is shown until the first load completes
+	Loading bool
+}
+
+// Pane is a selectable list in the main view
+type Pane int
+
+const (
+	// PaneStores is the backing stores list
+	PaneStores Pane = iota
+	// PaneBuckets is the buckets list
+	PaneBuckets
+)
+
+// View is the current screen of the dashboard
+type View int
+
+const (
+	// ViewMain shows the system summary with the stores and buckets lists
+	ViewMain View = iota
+	// ViewStore shows the details of the selected backing store
+	ViewStore
+	// ViewBucket shows the details of the selected bucket
+	ViewBucket
+)
+
+// State is the navigation state of the dashboard
+type State struct {
+	View        View
+	Pane        Pane
+	StoreIndex  int
+	BucketIndex int
+}
+
+// MaxBuckets is the number of busiest buckets shown in the main view
+const MaxBuckets = 10
+
+// MaxEvents is the number of recent warning events shown in the main view
+const MaxEvents = 5
+
+// BusiestBuckets returns the buckets sorted by data size and then by number of objects
+func BusiestBuckets(sysInfo *nb.SystemInfo) []*nb.BucketInfo {
+	if sysInfo == nil {
+		return nil
+	}
+	buckets := []*nb.BucketInfo{}
+	for i := range sysInfo.Buckets {
+		buckets = append(buckets, &sysInfo.Buckets[i])
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		si, sj := bucketSize(buckets[i]), bucketSize(buckets[j])
+		if si != sj {
+			return si > sj
+		}
+		return bucketObjects(buckets[i]) > bucketObjects(buckets[j])
+	})
+	if len(buckets) > MaxBuckets {
+		buckets = buckets[:MaxBuckets]
+	}
+	return buckets
+}
+
+// RecentWarnings returns the most recent warning events
+func RecentWarnings(events []corev1.Event) []*corev1.Event {
+	warnings := []*corev1.Event{}
+	for i := range events {
+		if events[i].Type == corev1.EventTypeWarning {
+			warnings = append(warnings, &events[i])
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return eventTime(warnings[i]).After(eventTime(warnings[j]))
+	})
+	if len(warnings) > MaxEvents {
+		warnings = warnings[:MaxEvents]
+	}
+	return warnings
+}
+
+// Render returns the lines of the current view
+func Render(snap *Snapshot, state *State) []string {
+	switch state.View {
+	case ViewStore:
+		if state.StoreIndex < len(snap.BackingStores) {
+			return renderStore(snap, &snap.BackingStores[state.StoreIndex])
+		}
+	case ViewBucket:
+		buckets := BusiestBuckets(snap.SystemInfo)
+		if state.BucketIndex < len(buckets) {
+			return renderBucket(snap, buckets[state.BucketIndex])
+		}
+	}
+	return renderMain(snap, state)
+}
+
+func renderMain(snap *Snapshot, state *State) []string {
+	lines := []string{}
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	add("NooBaa top - %s  (q: quit, tab: switch list, ↑/↓: select, enter: details, r: refresh)",
+		snap.Time.Format("15:04:05"))
+	add("")
+
+	if snap.Loading {
+		add("System: loading...")
+	} else if snap.NooBaa == nil {
+		add("System: not found")
+	} else {
+		sys := snap.NooBaa
+		add("System: %s/%s  Phase: %s  Image: %s", sys.Namespace, sys.Name, sys.Status.Phase, sys.Status.ActualImage)
+		readyCount := int32(0)
+		if sys.Status.Endpoints != nil {
+			readyCount = sys.Status.Endpoints.ReadyCount
+		}
+		if snap.HPA != nil {
+			minReplicas := int32(1)
+			if snap.HPA.Spec.MinReplicas != nil {
+				minReplicas = *snap.HPA.Spec.MinReplicas
+			}
+			add("Endpoints: %d ready (HPA min %d max %d, current %d desired %d)",
+				readyCount, minReplicas, snap.HPA.Spec.MaxReplicas,
+				snap.HPA.Status.CurrentReplicas, snap.HPA.Status.DesiredReplicas)
+		} else {
+			add("Endpoints: %d ready", readyCount)
+		}
+	}
+	if snap.SystemInfo != nil {
+		add("Core version: %s  Buckets: %d  Pools: %d", snap.SystemInfo.Version,
+			len(snap.SystemInfo.Buckets), len(snap.SystemInfo.Pools))
+	}
+	for _, e := range snap.Errors {
+		add("Error: %s", e)
+	}
+
+	add("")
+	add("%s BACKING STORES", paneMarker(state, PaneStores))
+	add("  %-30s %-14s %-10s %-20s %10s %10s %10s", "NAME", "TYPE", "PHASE", "MODE", "TOTAL", "FREE", "USED")
+	for i := range snap.BackingStores {
+		bs := &snap.BackingStores[i]
+		total, free, used := "", "", ""
+		if pool := findPool(snap.SystemInfo, bs.Name); pool != nil && pool.Storage != nil {
+			total = nb.BigIntToHumanBytes(pool.Storage.Total)
+			free = nb.BigIntToHumanBytes(pool.Storage.Free)
+			used = nb.BigIntToHumanBytes(pool.Storage.Used)
+		}
+		add("%s %-30s %-14s %-10s %-20s %10s %10s %10s",
+			cursor(state, PaneStores, i), bs.Name, bs.Spec.Type, bs.Status.Phase, bs.Status.Mode.ModeCode, total, free, used)
+	}
+
+	add("")
+	add("%s BUSIEST BUCKETS", paneMarker(state, PaneBuckets))
+	add("  %-40s %-20s %12s %12s", "NAME", "MODE", "SIZE", "OBJECTS")
+	for i, b := range BusiestBuckets(snap.SystemInfo) {
+		size := ""
+		if b.Data != nil {
+			size = nb.BigIntToHumanBytes(b.Data.Size)
+		}
+		add("%s %-40s %-20s %12s %12d", cursor(state, PaneBuckets, i), b.Name, b.Mode, size, bucketObjects(b))
+	}
+
+	add("")
+	add("  RECENT WARNINGS")
+	for _, e := range RecentWarnings(snap.Events) {
+		add("  %-8s %-30s %-25s %s", eventAge(snap, e), e.InvolvedObject.Kind+"/"+e.InvolvedObject.Name, e.Reason, e.Message)
+	}
+	return lines
+}
+
+func renderStore(snap *Snapshot, bs *nbv1.BackingStore) []string {
+	lines := []string{}
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	add("BackingStore %s  (esc: back, q: quit)", bs.Name)
+	add("")
+	add("  %-22s : %s", "Type", bs.Spec.Type)
+	add("  %-22s : %s", "Phase", bs.Status.Phase)
+	add("  %-22s : %s", "Mode", bs.Status.Mode.ModeCode)
+	add("  %-22s : %s", "Mode Time", bs.Status.Mode.TimeStamp)
+	add("  %-22s : %s", "Age", snap.Time.Sub(bs.CreationTimestamp.Time).Round(time.Second))
+	for _, c := range bs.Status.Conditions {
+		add("  %-22s : %s %s %s", "Condition "+string(c.Type), c.Status, c.Reason, c.Message)
+	}
+	pool := findPool(snap.SystemInfo, bs.Name)
+	if pool != nil {
+		add("")
+		add("  %-22s : %s", "Resource Type", pool.ResourceType)
+		add("  %-22s : %s", "Pool Mode", pool.Mode)
+		if pool.CloudInfo != nil {
+			add("  %-22s : %s", "Endpoint", pool.CloudInfo.Endpoint)
+			add("  %-22s : %s", "Target Bucket", pool.CloudInfo.TargetBucket)
+		}
+		if pool.Hosts != nil {
+			add("  %-22s : %d/%d", "Hosts", pool.Hosts.Count, pool.Hosts.ConfiguredCount)
+		}
+		if pool.Storage != nil {
+			add("  %-22s : %s", "Total", nb.BigIntToHumanBytes(pool.Storage.Total))
+			add("  %-22s : %s", "Free", nb.BigIntToHumanBytes(pool.Storage.Free))
+			add("  %-22s : %s", "Used", nb.BigIntToHumanBytes(pool.Storage.Used))
+			add("  %-22s : %s", "Used Other", nb.BigIntToHumanBytes(pool.Storage.UsedOther))
+		}
+		add("")
+		add("  BUCKETS USING THIS STORE")
+		for i := range snap.SystemInfo.Buckets {
+			b := &snap.SystemInfo.Buckets[i]
+			for _, p := range bucketPools(snap.SystemInfo, b) {
+				if p == bs.Name {
+					add("  %s", b.Name)
+					break
+				}
+			}
+		}
+	}
+	return lines
+}
+
+func renderBucket(snap *Snapshot, b *nb.BucketInfo) []string {
+	lines := []string{}
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	add("Bucket %s  (esc: back, q: quit)", b.Name)
+	add("")
+	add("  %-22s : %s", "Type", b.BucketType)
+	add("  %-22s : %s", "Mode", b.Mode)
+	if b.BucketClaim != nil {
+		add("  %-22s : %s", "OBC Namespace", b.BucketClaim.Namespace)
+		add("  %-22s : %s", "OBC BucketClass", b.BucketClaim.BucketClass)
+	}
+	if b.PolicyModes != nil {
+		add("  %-22s : %s", "ResiliencyStatus", b.PolicyModes.ResiliencyStatus)
+		add("  %-22s : %s", "QuotaStatus", b.PolicyModes.QuotaStatus)
+	}
+	add("  %-22s : %d", "Num Objects", bucketObjects(b))
+	if b.Data != nil {
+		add("  %-22s : %s", "Data Size", nb.BigIntToHumanBytes(b.Data.Size))
+		add("  %-22s : %s", "Data Size Reduced", nb.BigIntToHumanBytes(b.Data.SizeReduced))
+		add("  %-22s : %s", "Data Space Avail", nb.BigIntToHumanBytes(b.Data.AvailableForUpload))
+	}
+	if b.Quota != nil {
+		add("  %-22s : %d %s", "Quota", b.Quota.Size, b.Quota.Unit)
+	}
+	add("  %-22s : %s", "Stores", strings.Join(bucketPools(snap.SystemInfo, b), ", "))
+	return lines
+}
+
+func paneMarker(state *State, pane Pane) string {
+	if state.Pane == pane {
+		return "▶"
+	}
+	return " "
+}
+
+func cursor(state *State, pane Pane, i int) string {
+	if state.Pane != pane {
+		return " "
+	}
+	if (pane == PaneStores && state.StoreIndex == i) || (pane == PaneBuckets && state.BucketIndex == i) {
+		return ">"
+	}
+	return " "
+}
+
+func findPool(sysInfo *nb.SystemInfo, name string) *nb.PoolInfo {
+	if sysInfo == nil {
+		return nil
+	}
+	for i := range sysInfo.Pools {
+		if sysInfo.Pools[i].Name == name {
+			return &sysInfo.Pools[i]
+		}
+	}
+	return nil
+}
+
+// bucketPools returns the pools attached to the tiers of the bucket tiering policy
+func bucketPools(sysInfo *nb.SystemInfo, b *nb.BucketInfo) []string {
+	pools := []string{}
+	if sysInfo == nil || b.Tiering == nil {
+		return pools
+	}
+	for _, item := range b.Tiering.Tiers {
+		for i := range sysInfo.Tiers {
+			if sysInfo.Tiers[i].Name == item.Tier {
+				pools = append(pools, sysInfo.Tiers[i].AttachedPools...)
+			}
+		}
+	}
+	return pools
+}
+
+func bucketSize(b *nb.BucketInfo) float64 {
+	if b.Data == nil {
+		return 0
+	}
+	return nb.BigIntToFloat64(b.Data.Size)
+}
+
+func bucketObjects(b *nb.BucketInfo) int64 {
+	if b.NumObjects == nil {
+		return 0
+	}
+	return b.NumObjects.Value
+}
+
+func eventTime(e *corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	return e.EventTime.Time
+}
+
+func eventAge(snap *Snapshot, e *corev1.Event) string {
+	return snap.Time.Sub(eventTime(e)).Round(time.Second).String()
+}
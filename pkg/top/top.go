@@ -0,0 +1,271 @@
+package top
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+	"github.com/noobaa/noobaa-operator/v2/pkg/options"
+	"github.com/noobaa/noobaa-operator/v2/pkg/system"
+	"github.com/noobaa/noobaa-operator/v2/pkg/util"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Key is a keyboard input of the dashboard
+type Key string
+
+// Keys that the dashboard handles, other keys are ignored
+const (
+	KeyUp      Key = "up"
+	KeyDown    Key = "down"
+	KeyEnter   Key = "enter"
+	KeyBack    Key = "back"
+	KeyTab     Key = "tab"
+	KeyRefresh Key = "refresh"
+	KeyQuit    Key = "quit"
+)
+
+// Cmd returns a CLI command
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Live dashboard of the system, stores and buckets",
+		Run:   RunTop,
+		Args:  cobra.NoArgs,
+	}
+	cmd.Flags().Duration("interval", 5*time.Second, "Refresh interval")
+	cmd.Flags().Bool("once", false, "Print a single snapshot and exit (default when not running in a terminal)")
+	return cmd
+}
+
+// RunTop runs a CLI command
+func RunTop(cmd *cobra.Command, args []string) {
+	log := util.Logger()
+	interval, _ := cmd.Flags().GetDuration("interval")
+	once, _ := cmd.Flags().GetBool("once")
+	if interval <= 0 {
+		log.Fatalf(`❌ Invalid refresh interval %s`, interval)
+	}
+
+	// connect before switching the terminal since connecting logs progress lines
+	var nbClient nb.Client
+	sysClient, err := system.Connect(true)
+	if err != nil {
+		log.Warnf("⏳ Could not connect to the system, showing only kubernetes resources: %s", err)
+	} else {
+		nbClient = sysClient.NBClient
+	}
+
+	stdin := int(os.Stdin.Fd())
+	if once || !terminal.IsTerminal(stdin) {
+		for _, line := range Render(Load(nbClient), &State{}) {
+			fmt.Println(line)
+		}
+		return
+	}
+
+	oldState, err := terminal.MakeRaw(stdin)
+	if err != nil {
+		log.Fatalf(`❌ Could not set the terminal to raw mode: %s`, err)
+	}
+	// switch to the alternate screen and hide the cursor, and restore both on exit
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		util.Panic(terminal.Restore(stdin, oldState))
+	}()
+
+	keys := make(chan Key)
+	go ReadKeys(os.Stdin, keys)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// loading runs in the background so that a slow or unreachable system does not block the keys,
+	// and a new load starts only after the previous one sent its snapshot
+	snaps := make(chan *Snapshot, 1)
+	loading := false
+	load := func() {
+		if loading {
+			return
+		}
+		loading = true
+		go func() { snaps <- Load(nbClient) }()
+	}
+
+	state := &State{}
+	snap := &Snapshot{Time: time.Now(), Loading: true}
+	load()
+	draw(snap, state)
+	for {
+		select {
+		case <-signals:
+			return
+		case snap = <-snaps:
+			loading = false
+		case <-ticker.C:
+			load()
+			continue
+		case key := <-keys:
+			if key == KeyQuit {
+				return
+			}
+			if key == KeyRefresh {
+				load()
+			}
+			HandleKey(snap, state, key)
+		}
+		draw(snap, state)
+	}
+}
+
+// Load reads the system CR, the endpoints HPA, the backing stores, the events and the system info.
+// Errors are kept in the snapshot so that the dashboard keeps running when the system is not reachable.
+func Load(nbClient nb.Client) *Snapshot {
+	klient := util.KubeClient()
+	ctx := util.Context()
+	snap := &Snapshot{Time: time.Now()}
+
+	sys := &nbv1.NooBaa{}
+	err := klient.Get(ctx, client.ObjectKey{Namespace: options.Namespace, Name: options.SystemName}, sys)
+	if err == nil {
+		snap.NooBaa = sys
+	} else {
+		snap.Errors = append(snap.Errors, err.Error())
+	}
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if klient.Get(ctx, client.ObjectKey{Namespace: options.Namespace, Name: options.SystemName + "-endpoint"}, hpa) == nil {
+		snap.HPA = hpa
+	}
+
+	bsList := &nbv1.BackingStoreList{}
+	if err := klient.List(ctx, bsList, &client.ListOptions{Namespace: options.Namespace}); err == nil {
+		snap.BackingStores = bsList.Items
+	} else {
+		snap.Errors = append(snap.Errors, err.Error())
+	}
+
+	eventList := &corev1.EventList{}
+	if err := klient.List(ctx, eventList, &client.ListOptions{Namespace: options.Namespace}); err == nil {
+		snap.Events = eventList.Items
+	}
+
+	if nbClient != nil {
+		sysInfo, err := nbClient.ReadSystemAPI()
+		if err == nil {
+			snap.SystemInfo = &sysInfo
+		} else {
+			snap.Errors = append(snap.Errors, err.Error())
+		}
+	}
+
+	return snap
+}
+
+// HandleKey updates the navigation state by the key
+func HandleKey(snap *Snapshot, state *State, key Key) {
+	numBuckets := len(BusiestBuckets(snap.SystemInfo))
+	switch key {
+	case KeyTab:
+		if state.View == ViewMain {
+			state.Pane = (state.Pane + 1) % 2
+		}
+	case KeyUp:
+		if state.View == ViewMain && state.Pane == PaneStores && state.StoreIndex > 0 {
+			state.StoreIndex--
+		}
+		if state.View == ViewMain && state.Pane == PaneBuckets && state.BucketIndex > 0 {
+			state.BucketIndex--
+		}
+	case KeyDown:
+		if state.View == ViewMain && state.Pane == PaneStores && state.StoreIndex < len(snap.BackingStores)-1 {
+			state.StoreIndex++
+		}
+		if state.View == ViewMain && state.Pane == PaneBuckets && state.BucketIndex < numBuckets-1 {
+			state.BucketIndex++
+		}
+	case KeyEnter:
+		if state.View == ViewMain && state.Pane == PaneStores && state.StoreIndex < len(snap.BackingStores) {
+			state.View = ViewStore
+		}
+		if state.View == ViewMain && state.Pane == PaneBuckets && state.BucketIndex < numBuckets {
+			state.View = ViewBucket
+		}
+	case KeyBack:
+		state.View = ViewMain
+	}
+}
+
+// ReadKeys reads the terminal input in raw mode and sends the keys to the channel
+func ReadKeys(f *os.File, keys chan<- Key) {
+	buf := make([]byte, 16)
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			keys <- KeyQuit
+			return
+		}
+		if key := ParseKey(buf[:n]); key != "" {
+			keys <- key
+		}
+	}
+}
+
+// ParseKey translates the bytes of a single terminal input to a key
+func ParseKey(input []byte) Key {
+	switch string(input) {
+	case "\x1b[A", "k":
+		return KeyUp
+	case "\x1b[B", "j":
+		return KeyDown
+	case "\r", "\n", "\x1b[C", "l":
+		return KeyEnter
+	case "\x1b", "\x7f", "\x1b[D", "h":
+		return KeyBack
+	case "\t":
+		return KeyTab
+	case "r":
+		return KeyRefresh
+	case "q", "\x03":
+		return KeyQuit
+	}
+	return ""
+}
+
+// draw clears the screen and prints the current view truncated to the terminal size
+func draw(snap *Snapshot, state *State) {
+	width, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 120, 40
+	}
+	lines := Render(snap, state)
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	for i, line := range lines {
+		runes := []rune(line)
+		if len(runes) > width {
+			line = string(runes[:width])
+		}
+		sb.WriteString(line)
+		if i < len(lines)-1 {
+			// raw mode does not translate newlines to carriage returns
+			sb.WriteString("\r\n")
+		}
+	}
+	fmt.Print(sb.String())
+}
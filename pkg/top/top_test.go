@@ -0,0 +1,70 @@
+package top
+
+import (
+	"testing"
+
+	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
+	"github.com/noobaa/noobaa-operator/v2/pkg/nb"
+)
+
+func TestBusiestBuckets(t *testing.T) {
+	sysInfo := &nb.SystemInfo{}
+	for _, name := range []string{"empty", "big", "many-objects", "small"} {
+		b := nb.BucketInfo{Name: name}
+		b.NumObjects = &struct {
+			LastUpdate int64 `json:"last_update"`
+			Value      int64 `json:"value"`
+		}{}
+		switch name {
+		case "big":
+			b.NumObjects.Value = 1
+		case "many-objects":
+			b.NumObjects.Value = 1000
+		case "small":
+			b.NumObjects.Value = 10
+		}
+		sysInfo.Buckets = append(sysInfo.Buckets, b)
+	}
+	sysInfo.Buckets[1].Data = &struct {
+		AvailableForUpload *nb.BigInt `json:"available_for_upload,omitempty"`
+		Free               *nb.BigInt `json:"free,omitempty"`
+		LastUpdate         int64      `json:"last_update"`
+		Size               *nb.BigInt `json:"size,omitempty"`
+		SizeReduced        *nb.BigInt `json:"size_reduced,omitempty"`
+	}{Size: &nb.BigInt{N: 1 << 30}}
+
+	expected := []string{"big", "many-objects", "small", "empty"}
+	buckets := BusiestBuckets(sysInfo)
+	if len(buckets) != len(expected) {
+		t.Fatalf("expected %d buckets, got %d", len(expected), len(buckets))
+	}
+	for i, name := range expected {
+		if buckets[i].Name != name {
+			t.Errorf("bucket %d: expected %q, got %q", i, name, buckets[i].Name)
+		}
+	}
+}
+
+func TestHandleKey(t *testing.T) {
+	snap := &Snapshot{
+		BackingStores: []nbv1.BackingStore{{}, {}},
+		SystemInfo:    &nb.SystemInfo{Buckets: []nb.BucketInfo{{Name: "b1"}}},
+	}
+	state := &State{}
+	for _, input := range []string{"j", "j", "\x1b[B"} {
+		HandleKey(snap, state, ParseKey([]byte(input)))
+	}
+	if state.StoreIndex != 1 {
+		t.Fatalf("expected store index to stop at the last store, got %d", state.StoreIndex)
+	}
+	HandleKey(snap, state, KeyEnter)
+	if state.View != ViewStore {
+		t.Fatalf("expected store view, got %d", state.View)
+	}
+	HandleKey(snap, state, ParseKey([]byte("\x1b")))
+	HandleKey(snap, state, KeyTab)
+	HandleKey(snap, state, KeyEnter)
+	if state.View != ViewBucket || state.BucketIndex != 0 {
+		t.Fatalf("expected bucket view of the first bucket, got %+v", state)
+	}
+}
@@ -0,0 +1,153 @@
+package util
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// ReconcileBackoff tracks the consecutive temporary errors of every reconciled object
+// in order to requeue it with exponential backoff instead of a fixed interval.
+// When the same error repeats Budget times in a row the object is considered stuck,
+// its Degraded condition is set and a single warning event is recorded.
+// The backoff of an object is reset when it reconciles successfully, when its spec changes (generation),
+// or when one of its dependencies changes, which the reconciler reports as a dependencies version string.
+type ReconcileBackoff struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter float64
+	Budget int
+
+	mutex    sync.Mutex
+	failures map[string]*reconcileFailures
+}
+
+type reconcileFailures struct {
+	count      int
+	repeats    int
+	message    string
+	generation int64
+	deps       string
+}
+
+// GlobalBackoff is the backoff shared by all the reconcilers of the operator
+var GlobalBackoff = NewReconcileBackoff()
+
+// NewReconcileBackoff returns a backoff that starts from the previous fixed 3 seconds requeue
+func NewReconcileBackoff() *ReconcileBackoff {
+	return &ReconcileBackoff{
+		Base:     3 * time.Second,
+		Max:      5 * time.Minute,
+		Jitter:   0.2,
+		Budget:   5,
+		failures: map[string]*reconcileFailures{},
+	}
+}
+
+// TemporaryError records a temporary error of the object and returns the time to requeue it.
+// deps should change whenever a dependency of the object changes, see DependenciesVersion.
+// Once the error repeats Budget times the Degraded condition is set in the given conditions.
+func (b *ReconcileBackoff) TemporaryError(
+	obj runtime.Object,
+	deps string,
+	err error,
+	conditions *[]conditionsv1.Condition,
+	recorder record.EventRecorder,
+) time.Duration {
+
+	delay, repeats := b.Failure(obj, deps, err.Error())
+	if repeats >= b.Budget && conditions != nil {
+		SetDegradedCondition(conditions, "RepeatedTemporaryError", fmt.Sprintf("Failed %d times in a row: %s", repeats, err))
+		if repeats == b.Budget && recorder != nil {
+			recorder.Eventf(obj, corev1.EventTypeWarning, "RepeatedTemporaryError",
+				"Failed %d times in a row, retrying every %s: %s", repeats, b.Max, err)
+		}
+	}
+	return delay
+}
+
+// Failure records a failure of the object and returns the backoff delay
+// and the number of times in a row that the same failure message was recorded
+func (b *ReconcileBackoff) Failure(obj runtime.Object, deps string, message string) (time.Duration, int) {
+	key, generation := backoffKey(obj)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	f := b.failures[key]
+	if f == nil || f.generation != generation || f.deps != deps {
+		f = &reconcileFailures{generation: generation, deps: deps}
+		b.failures[key] = f
+	}
+	f.count++
+	if message == f.message {
+		f.repeats++
+	} else {
+		f.message = message
+		f.repeats = 1
+	}
+	return b.Delay(f.count), f.repeats
+}
+
+// Delay returns the jittered backoff delay after the given number of failures
+func (b *ReconcileBackoff) Delay(count int) time.Duration {
+	delay := b.Max
+	if count < 32 {
+		if d := b.Base << uint(count-1); d > 0 && d < b.Max {
+			delay = d
+		}
+	}
+	if b.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * b.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// Reset forgets the failures of the object after a successful reconcile or when it was deleted
+func (b *ReconcileBackoff) Reset(obj runtime.Object) {
+	key, _ := backoffKey(obj)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.failures, key)
+}
+
+// MinRequeueAfter returns the earliest of two requeue delays, where zero means no requeue
+func MinRequeueAfter(a time.Duration, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// DependenciesVersion returns a string that changes when any of the objects changes,
+// using the generation when it is set and the resource version otherwise (e.g for secrets).
+// The status of the dependencies is not included since it changes on every reconcile.
+func DependenciesVersion(objs ...metav1.Object) string {
+	versions := []string{}
+	for _, obj := range objs {
+		if reflect.ValueOf(obj).IsNil() || obj.GetUID() == "" {
+			continue
+		}
+		version := obj.GetResourceVersion()
+		if obj.GetGeneration() > 0 {
+			version = fmt.Sprint(obj.GetGeneration())
+		}
+		versions = append(versions, fmt.Sprintf("%s:%s", obj.GetUID(), version))
+	}
+	return strings.Join(versions, ",")
+}
+
+func backoffKey(obj runtime.Object) (string, int64) {
+	objMeta, err := meta.Accessor(obj)
+	Panic(err)
+	kind := reflect.TypeOf(obj).Elem().Name()
+	return fmt.Sprintf("%s/%s/%s", kind, objMeta.GetNamespace(), objMeta.GetName()), objMeta.GetGeneration()
+}
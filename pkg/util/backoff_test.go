@@ -0,0 +1,103 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReconcileBackoffDelay(t *testing.T) {
+	b := NewReconcileBackoff()
+	b.Jitter = 0
+	expected := []time.Duration{3 * time.Second, 6 * time.Second, 12 * time.Second, 24 * time.Second}
+	for i, d := range expected {
+		if delay := b.Delay(i + 1); delay != d {
+			t.Errorf("expected delay %s after %d failures, got %s", d, i+1, delay)
+		}
+	}
+	for _, count := range []int{8, 40, 1000} {
+		if delay := b.Delay(count); delay != b.Max {
+			t.Errorf("expected the delay to be capped after %d failures, got %s", count, delay)
+		}
+	}
+	b.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		if delay := b.Delay(1); delay < 2400*time.Millisecond || delay > 3600*time.Millisecond {
+			t.Fatalf("expected a jittered delay around 3s, got %s", delay)
+		}
+	}
+}
+
+func TestReconcileBackoffReset(t *testing.T) {
+	b := NewReconcileBackoff()
+	b.Jitter = 0
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "noobaa", Name: "test", Generation: 1}}
+
+	b.Failure(obj, "deps-1", "error")
+	if delay, _ := b.Failure(obj, "deps-1", "error"); delay != 6*time.Second {
+		t.Errorf("expected the second failure to back off, got %s", delay)
+	}
+	if delay, _ := b.Failure(obj, "deps-2", "error"); delay != b.Base {
+		t.Errorf("expected a dependency change to reset the backoff, got %s", delay)
+	}
+	obj.Generation = 2
+	if delay, _ := b.Failure(obj, "deps-2", "error"); delay != b.Base {
+		t.Errorf("expected a spec change to reset the backoff, got %s", delay)
+	}
+	b.Reset(obj)
+	if delay, _ := b.Failure(obj, "deps-2", "error"); delay != b.Base {
+		t.Errorf("expected a success to reset the backoff, got %s", delay)
+	}
+}
+
+func TestReconcileBackoffBudget(t *testing.T) {
+	b := NewReconcileBackoff()
+	recorder := record.NewFakeRecorder(10)
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "noobaa", Name: "test"}}
+	conditions := []conditionsv1.Condition{}
+
+	b.TemporaryError(obj, "", fmt.Errorf("other error"), &conditions, recorder)
+	for i := 1; i < b.Budget; i++ {
+		b.TemporaryError(obj, "", fmt.Errorf("same error"), &conditions, recorder)
+	}
+	if conditionsv1.IsStatusConditionTrue(conditions, conditionsv1.ConditionDegraded) {
+		t.Errorf("expected no degraded condition before the budget of identical errors")
+	}
+	for i := 0; i < 3; i++ {
+		b.TemporaryError(obj, "", fmt.Errorf("same error"), &conditions, recorder)
+	}
+	if !conditionsv1.IsStatusConditionTrue(conditions, conditionsv1.ConditionDegraded) {
+		t.Errorf("expected a degraded condition after the budget of identical errors")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected a single event on escalation, got %d", len(recorder.Events))
+	}
+}
+
+func TestMinRequeueAfter(t *testing.T) {
+	for _, c := range []struct{ a, b, expected time.Duration }{
+		{0, 0, 0},
+		{0, time.Second, time.Second},
+		{time.Second, 0, time.Second},
+		{time.Minute, time.Second, time.Second},
+		{time.Second, time.Minute, time.Second},
+	} {
+		if got := MinRequeueAfter(c.a, c.b); got != c.expected {
+			t.Errorf("MinRequeueAfter(%s, %s) = %s, expected %s", c.a, c.b, got, c.expected)
+		}
+	}
+}
+
+func TestDependenciesVersion(t *testing.T) {
+	var missing *corev1.Secret
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{UID: "s", ResourceVersion: "10"}}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{UID: "c", ResourceVersion: "20", Generation: 3}}
+	if v := DependenciesVersion(missing, secret, cm); v != "s:10,c:3" {
+		t.Errorf("unexpected dependencies version %q", v)
+	}
+}
@@ -0,0 +1,149 @@
+package util
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// kubeCache is the informers cache that KubeGet and KubeList read from in the operator.
+// It is not set in the CLI, where all the reads go directly to the api server.
+var kubeCache struct {
+	mutex      sync.RWMutex
+	reader     client.Reader
+	namespaces map[string]bool
+	kinds      map[schema.GroupVersionKind]bool
+}
+
+// SetKubeCache makes KubeGet and KubeList read the given kinds in the given namespaces from the reader,
+// which should be the informers cache of the manager where the controllers already watch these kinds.
+// Other reads, like secrets, cluster scoped objects and other namespaces, keep going to the api server.
+// Reads that must see the result of a previous write in the same reconcile should use KubeListDirect.
+func SetKubeCache(reader client.Reader, namespaces []string, kinds ...runtime.Object) {
+	kubeCache.mutex.Lock()
+	defer kubeCache.mutex.Unlock()
+	kubeCache.reader = reader
+	kubeCache.namespaces = map[string]bool{}
+	for _, ns := range namespaces {
+		kubeCache.namespaces[ns] = true
+	}
+	kubeCache.kinds = map[schema.GroupVersionKind]bool{}
+	for _, obj := range kinds {
+		gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+		Panic(err)
+		kubeCache.kinds[gvk] = true
+	}
+}
+
+// kubeReader returns the reader and source for an object or list in the namespace,
+// which is the cache if the kind and namespace are cached and the api client otherwise
+func kubeReader(obj runtime.Object, namespace string) (client.Reader, string) {
+	kubeCache.mutex.RLock()
+	defer kubeCache.mutex.RUnlock()
+	if kubeCache.reader == nil || !kubeCache.namespaces[namespace] {
+		return KubeClient(), metrics.KubeSourceAPI
+	}
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil {
+		return KubeClient(), metrics.KubeSourceAPI
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	if !kubeCache.kinds[gvk] {
+		return KubeClient(), metrics.KubeSourceAPI
+	}
+	return kubeCache.reader, metrics.KubeSourceCache
+}
+
+// kubeKind returns the kind of an object or list for the requests metric
+func kubeKind(obj runtime.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil {
+		return "Unknown"
+	}
+	return strings.TrimSuffix(gvk.Kind, "List")
+}
+
+// countingClient counts the requests of the wrapped client in the kube requests metric.
+// The reads are counted with the given source and the writes always go to the api server.
+type countingClient struct {
+	client.Client
+	readSource string
+}
+
+// NewCountingClient wraps the client to count its requests in the kube requests metric,
+// where readSource is the source label of its reads - KubeSourceAPI or KubeSourceCache
+func NewCountingClient(c client.Client, readSource string) client.Client {
+	return &countingClient{Client: c, readSource: readSource}
+}
+
+// Get implements client.Reader
+func (c *countingClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	metrics.ObserveKubeRequest("get", kubeKind(obj), c.readSource)
+	return c.Client.Get(ctx, key, obj)
+}
+
+// List implements client.Reader
+func (c *countingClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	metrics.ObserveKubeRequest("list", kubeKind(list), c.readSource)
+	return c.Client.List(ctx, list, opts...)
+}
+
+// Create implements client.Writer
+func (c *countingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	metrics.ObserveKubeRequest("create", kubeKind(obj), metrics.KubeSourceAPI)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Delete implements client.Writer
+func (c *countingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	metrics.ObserveKubeRequest("delete", kubeKind(obj), metrics.KubeSourceAPI)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// Update implements client.Writer
+func (c *countingClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	metrics.ObserveKubeRequest("update", kubeKind(obj), metrics.KubeSourceAPI)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.Writer
+func (c *countingClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	metrics.ObserveKubeRequest("patch", kubeKind(obj), metrics.KubeSourceAPI)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// DeleteAllOf implements client.Writer
+func (c *countingClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	metrics.ObserveKubeRequest("deletecollection", kubeKind(obj), metrics.KubeSourceAPI)
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient
+func (c *countingClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status()}
+}
+
+// countingStatusWriter counts the status writes of the wrapped status writer
+type countingStatusWriter struct {
+	client.StatusWriter
+}
+
+// Update implements client.StatusWriter
+func (w *countingStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	metrics.ObserveKubeRequest("update_status", kubeKind(obj), metrics.KubeSourceAPI)
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.StatusWriter
+func (w *countingStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	metrics.ObserveKubeRequest("patch_status", kubeKind(obj), metrics.KubeSourceAPI)
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
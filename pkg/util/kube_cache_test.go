@@ -0,0 +1,80 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func setTestKubeClients(t *testing.T) {
+	api := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "noobaa", Name: "noobaa-core-0"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "noobaa", Name: "noobaa-admin"}},
+	)
+	cached := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "noobaa", Name: "noobaa-core-0"}},
+	)
+	prevClient := lazyClient
+	lazyClient = NewCountingClient(api, metrics.KubeSourceAPI)
+	SetKubeCache(cached, []string{"noobaa"}, &corev1.Pod{})
+	t.Cleanup(func() {
+		lazyClient = prevClient
+		SetKubeCache(nil, nil)
+	})
+}
+
+func TestKubeReader(t *testing.T) {
+	setTestKubeClients(t)
+	cases := []struct {
+		obj       runtime.Object
+		namespace string
+		source    string
+	}{
+		{&corev1.Pod{}, "noobaa", metrics.KubeSourceCache},
+		{&corev1.PodList{}, "noobaa", metrics.KubeSourceCache},
+		{&corev1.Pod{}, "other", metrics.KubeSourceAPI},
+		{&corev1.Secret{}, "noobaa", metrics.KubeSourceAPI},
+		{&corev1.SecretList{}, "noobaa", metrics.KubeSourceAPI},
+	}
+	for _, c := range cases {
+		if _, source := kubeReader(c.obj, c.namespace); source != c.source {
+			t.Errorf("expected %T in %q to be read from %s, got %s", c.obj, c.namespace, c.source, source)
+		}
+	}
+}
+
+func TestKubeRequestsCounted(t *testing.T) {
+	setTestKubeClients(t)
+	podsFromCache := metrics.KubeRequests.WithLabelValues("get", "Pod", metrics.KubeSourceCache)
+	secretsFromAPI := metrics.KubeRequests.WithLabelValues("get", "Secret", metrics.KubeSourceAPI)
+	podListsFromAPI := metrics.KubeRequests.WithLabelValues("list", "Pod", metrics.KubeSourceAPI)
+	beforeCache := testutil.ToFloat64(podsFromCache)
+	beforeAPI := testutil.ToFloat64(secretsFromAPI)
+	beforeDirect := testutil.ToFloat64(podListsFromAPI)
+
+	if !KubeCheckQuiet(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "noobaa", Name: "noobaa-core-0"}}) {
+		t.Errorf("expected the pod to be found in the cache")
+	}
+	if !KubeCheckQuiet(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "noobaa", Name: "noobaa-admin"}}) {
+		t.Errorf("expected the secret to be found in the api server")
+	}
+	KubeListDirect(&corev1.PodList{}, client.InNamespace("noobaa"))
+
+	if d := testutil.ToFloat64(podsFromCache) - beforeCache; d != 1 {
+		t.Errorf("expected 1 pod get from the cache, got %v", d)
+	}
+	if d := testutil.ToFloat64(secretsFromAPI) - beforeAPI; d != 1 {
+		t.Errorf("expected 1 secret get from the api server, got %v", d)
+	}
+	if d := testutil.ToFloat64(podListsFromAPI) - beforeDirect; d != 1 {
+		t.Errorf("expected 1 direct pod list from the api server, got %v", d)
+	}
+}
@@ -0,0 +1,157 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+	sigyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// OutputTable is the default human readable table output
+	OutputTable = ""
+	// OutputWide is the table output with additional columns
+	OutputWide = "wide"
+	// OutputJSON prints the full objects as json
+	OutputJSON = "json"
+	// OutputYAML prints the full objects as yaml
+	OutputYAML = "yaml"
+	// OutputCustomColumnsPrefix prefixes the columns spec of the custom columns output
+	OutputCustomColumnsPrefix = "custom-columns="
+)
+
+// Output is the output format of the list and status commands
+// which is selected by the -o/--output flag.
+type Output struct {
+	Format  string
+	Columns []OutputColumn
+}
+
+// OutputColumn is a single column of the custom columns output
+type OutputColumn struct {
+	Header string
+	Path   *jsonpath.JSONPath
+}
+
+// AddOutputFlag adds the -o/--output flag to a list or status command
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", OutputTable,
+		"Output format. One of: json|yaml|wide|custom-columns=<HEADER>:<JSONPATH>[,<HEADER>:<JSONPATH>...]")
+}
+
+// GetOutput parses the -o/--output flag of the command and exits on invalid formats
+func GetOutput(cmd *cobra.Command) *Output {
+	format, _ := cmd.Flags().GetString("output")
+	o, err := ParseOutput(format)
+	if err != nil {
+		log.Fatalf(`❌ %s`, err)
+	}
+	return o
+}
+
+// ParseOutput parses an output format such as "json" or "custom-columns=NAME:.metadata.name"
+func ParseOutput(format string) (*Output, error) {
+	switch format {
+	case OutputTable, OutputWide, OutputJSON, OutputYAML:
+		return &Output{Format: format}, nil
+	}
+	if !strings.HasPrefix(format, OutputCustomColumnsPrefix) {
+		return nil, fmt.Errorf("Unsupported output format %q, expected one of: json|yaml|wide|custom-columns=...", format)
+	}
+	spec := strings.TrimPrefix(format, OutputCustomColumnsPrefix)
+	o := &Output{Format: OutputCustomColumnsPrefix}
+	for _, col := range strings.Split(spec, ",") {
+		parts := strings.SplitN(col, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("Invalid custom column %q, expected <HEADER>:<JSONPATH>", col)
+		}
+		path := parts[1]
+		if !strings.HasPrefix(path, "{") {
+			path = "{" + path + "}"
+		}
+		jp := jsonpath.New(parts[0]).AllowMissingKeys(true)
+		if err := jp.Parse(path); err != nil {
+			return nil, fmt.Errorf("Invalid custom column %q: %s", col, err)
+		}
+		o.Columns = append(o.Columns, OutputColumn{Header: parts[0], Path: jp})
+	}
+	return o, nil
+}
+
+// IsWide returns true if the table output should add the wide columns
+func (o *Output) IsWide() bool {
+	return o.Format == OutputWide
+}
+
+// IsStructured returns true for the formats that print the objects instead of the tables and logs
+func (o *Output) IsStructured() bool {
+	return o.Format != OutputTable && o.Format != OutputWide
+}
+
+// Print prints the object in a structured format and returns false for the table formats
+// which are left for the caller to print. For custom columns every item of the object
+// is printed as a row, where list objects (with items) and slices have a row per item.
+func (o *Output) Print(obj interface{}) bool {
+	switch o.Format {
+	case OutputJSON:
+		output, err := json.MarshalIndent(obj, "", "  ")
+		Panic(err)
+		fmt.Println(string(output))
+	case OutputYAML:
+		output, err := sigyaml.Marshal(obj)
+		Panic(err)
+		fmt.Print(string(output))
+	case OutputCustomColumnsPrefix:
+		table, err := o.CustomColumnsTable(obj)
+		Panic(err)
+		fmt.Print(table.String())
+	default:
+		return false
+	}
+	return true
+}
+
+// CustomColumnsTable evaluates the custom columns on the items of the object
+func (o *Output) CustomColumnsTable(obj interface{}) (*PrintTable, error) {
+	output, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(output, &generic); err != nil {
+		return nil, err
+	}
+	items := []interface{}{generic}
+	if list, isList := generic.([]interface{}); isList {
+		items = list
+	} else if m, isMap := generic.(map[string]interface{}); isMap {
+		if list, hasItems := m["items"].([]interface{}); hasItems {
+			items = list
+		}
+	}
+	headers := []string{}
+	for _, c := range o.Columns {
+		headers = append(headers, c.Header)
+	}
+	table := (&PrintTable{}).AddRow(headers...)
+	for _, item := range items {
+		row := []string{}
+		for _, c := range o.Columns {
+			var buf bytes.Buffer
+			if err := c.Path.Execute(&buf, item); err != nil {
+				return nil, err
+			}
+			value := buf.String()
+			if value == "" {
+				value = "<none>"
+			}
+			row = append(row, value)
+		}
+		table.AddRow(row...)
+	}
+	return table, nil
+}
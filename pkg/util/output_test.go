@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	for _, format := range []string{"", "wide", "json", "yaml", "custom-columns=NAME:.metadata.name,PHASE:{.status.phase}"} {
+		if _, err := ParseOutput(format); err != nil {
+			t.Errorf("ParseOutput(%q) failed: %s", format, err)
+		}
+	}
+	for _, format := range []string{"table", "custom-columns=", "custom-columns=NAME", "custom-columns=NAME:{.metadata"} {
+		if _, err := ParseOutput(format); err == nil {
+			t.Errorf("ParseOutput(%q) expected to fail", format)
+		}
+	}
+}
+
+func TestCustomColumnsTable(t *testing.T) {
+	o, err := ParseOutput("custom-columns=NAME:.metadata.name,PHASE:.status.phase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "bs1"},
+				"status":   map[string]interface{}{"phase": "Ready"},
+			},
+			map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "bs2"},
+			},
+		},
+	}
+	table, err := o.CustomColumnsTable(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"NAME   PHASE    \n" +
+		"bs1    Ready    \n" +
+		"bs2    <none>   \n"
+	if table.String() != expected {
+		t.Fatalf("unexpected table:\n%q\nexpected:\n%q", table.String(), expected)
+	}
+}
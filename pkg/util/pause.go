@@ -0,0 +1,106 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// ReconcilePausedAnnotation pauses the reconcile of a NooBaa, BackingStore or BucketClass.
+	// The value is "true" to pause until the annotation is removed,
+	// or an RFC3339 time to resume the reconcile automatically at that time.
+	ReconcilePausedAnnotation = "noobaa.io/reconcile-paused"
+
+	// ConditionReconcilePaused is the condition type that reports a paused reconcile
+	ConditionReconcilePaused conditionsv1.ConditionType = "ReconcilePaused"
+)
+
+// ParseReconcilePause parses the value of the pause annotation and returns the time
+// when the pause expires, which is zero when it does not expire
+func ParseReconcilePause(value string) (time.Time, error) {
+	if value == "true" {
+		return time.Time{}, nil
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(`Invalid %s annotation %q, expected "true" or an RFC3339 time`, ReconcilePausedAnnotation, value)
+	}
+	return until, nil
+}
+
+// CheckReconcilePause checks the pause annotation of a resource and updates its paused condition.
+// An expired pause annotation is removed from the resource. Returns true if the reconcile is paused,
+// and the time to requeue when the pause expires, which is 0 when it does not expire.
+// A resource that is being deleted is never paused, so that its finalizers are handled.
+func CheckReconcilePause(
+	obj runtime.Object,
+	conditions *[]conditionsv1.Condition,
+	recorder record.EventRecorder,
+) (bool, time.Duration) {
+
+	objMeta, err := meta.Accessor(obj)
+	Panic(err)
+	value, paused := objMeta.GetAnnotations()[ReconcilePausedAnnotation]
+	if objMeta.GetDeletionTimestamp() != nil {
+		paused = false
+	}
+
+	until := time.Time{}
+	if paused {
+		until, err = ParseReconcilePause(value)
+		if err != nil {
+			// an invalid value still means that someone asked to pause
+			log.Warnf("⏳ %s, pausing until the annotation is removed", err)
+		}
+	}
+
+	if paused && !until.IsZero() && !time.Now().Before(until) {
+		annotations := objMeta.GetAnnotations()
+		delete(annotations, ReconcilePausedAnnotation)
+		objMeta.SetAnnotations(annotations)
+		if !KubeUpdate(obj) {
+			log.Errorf("❌ Failed to remove the expired %s annotation of %q", ReconcilePausedAnnotation, objMeta.GetName())
+			return true, 3 * time.Second
+		}
+		paused = false
+	}
+
+	if !paused {
+		if conditionsv1.FindStatusCondition(*conditions, ConditionReconcilePaused) != nil {
+			conditionsv1.RemoveStatusCondition(conditions, ConditionReconcilePaused)
+			log.Infof("Reconcile resumed")
+			if recorder != nil {
+				recorder.Eventf(obj, corev1.EventTypeNormal, "ReconcileResumed", "Reconcile resumed")
+			}
+		}
+		return false, 0
+	}
+
+	message := "Reconcile is paused until the annotation is removed"
+	requeue := time.Duration(0)
+	if !until.IsZero() {
+		message = fmt.Sprintf("Reconcile is paused until %s", until.Format(time.RFC3339))
+		requeue = time.Until(until)
+	}
+	if !conditionsv1.IsStatusConditionTrue(*conditions, ConditionReconcilePaused) {
+		log.Infof("%s", message)
+		if recorder != nil {
+			recorder.Eventf(obj, corev1.EventTypeWarning, "ReconcilePaused", message)
+		}
+	}
+	conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
+		LastHeartbeatTime: metav1.NewTime(time.Now()),
+		Type:              ConditionReconcilePaused,
+		Status:            corev1.ConditionTrue,
+		Reason:            "ReconcilePaused",
+		Message:           message,
+	})
+	return true, requeue
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseReconcilePause(t *testing.T) {
+	until, err := ParseReconcilePause("true")
+	if err != nil || !until.IsZero() {
+		t.Errorf("expected true to pause without expiry, got %v %v", until, err)
+	}
+	until, err = ParseReconcilePause("2021-03-01T10:00:00Z")
+	if err != nil || !until.Equal(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected an expiry time, got %v %v", until, err)
+	}
+	for _, value := range []string{"", "yes", "30m", "2021-03-01"} {
+		if _, err := ParseReconcilePause(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestCheckReconcilePause(t *testing.T) {
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:        "test",
+		Annotations: map[string]string{ReconcilePausedAnnotation: "true"},
+	}}
+	conditions := []conditionsv1.Condition{}
+
+	paused, requeue := CheckReconcilePause(obj, &conditions, nil)
+	if !paused || requeue != 0 {
+		t.Fatalf("expected paused without requeue, got %v %s", paused, requeue)
+	}
+	if !conditionsv1.IsStatusConditionTrue(conditions, ConditionReconcilePaused) {
+		t.Errorf("expected the paused condition to be true")
+	}
+
+	until := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	obj.Annotations[ReconcilePausedAnnotation] = until
+	paused, requeue = CheckReconcilePause(obj, &conditions, nil)
+	if !paused || requeue <= 59*time.Minute || requeue > time.Hour {
+		t.Errorf("expected paused for an hour, got %v %s", paused, requeue)
+	}
+
+	now := metav1.Now()
+	obj.DeletionTimestamp = &now
+	if paused, _ = CheckReconcilePause(obj, &conditions, nil); paused {
+		t.Errorf("expected a deleted resource not to be paused")
+	}
+	if conditionsv1.FindStatusCondition(conditions, ConditionReconcilePaused) != nil {
+		t.Errorf("expected the paused condition to be removed")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -27,6 +28,7 @@ import (
 	nbapis "github.com/noobaa/noobaa-operator/v2/pkg/apis"
 	nbv1 "github.com/noobaa/noobaa-operator/v2/pkg/apis/noobaa/v1alpha1"
 	"github.com/noobaa/noobaa-operator/v2/pkg/bundle"
+	"github.com/noobaa/noobaa-operator/v2/pkg/metrics"
 	routev1 "github.com/openshift/api/route/v1"
 	secv1 "github.com/openshift/api/security/v1"
 	cloudcredsv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
@@ -37,6 +39,7 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -81,6 +84,9 @@ var (
 	lazyConfig *rest.Config
 	lazyClient client.Client
 
+	apiAvailable      = map[string]bool{}
+	apiAvailableMutex sync.Mutex
+
 	// InsecureHTTPTransport is a global insecure http transport
 	InsecureHTTPTransport = &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
@@ -98,6 +104,7 @@ func init() {
 	Panic(routev1.AddToScheme(scheme.Scheme))
 	Panic(secv1.AddToScheme(scheme.Scheme))
 	Panic(autoscalingv1.AddToScheme(scheme.Scheme))
+	Panic(autoscalingv2beta2.AddToScheme(scheme.Scheme))
 }
 
 // KubeConfig loads kubernetes client config from default locations (flags, user dir, etc)
@@ -147,15 +154,57 @@ func KubeClient() client.Client {
 	if lazyClient == nil {
 		config := KubeConfig()
 		mapper, _ := MapperProvider(config)
-		var err error
-		lazyClient, err = client.New(config, client.Options{Mapper: mapper, Scheme: scheme.Scheme})
+		c, err := client.New(config, client.Options{Mapper: mapper, Scheme: scheme.Scheme})
 		if err != nil {
 			log.Fatalf("KubeClient: %v", err)
 		}
+		lazyClient = NewCountingClient(c, metrics.KubeSourceAPI)
 	}
 	return lazyClient
 }
 
+// KubeAPIAvailable returns true if the cluster serves the kind in the api group version,
+// for example "autoscaling/v2beta2" and "HorizontalPodAutoscaler".
+// The result is cached since the served apis only change on cluster upgrades.
+// Discovery errors are logged and reported as not available, use LookupKubeAPI to handle them.
+func KubeAPIAvailable(groupVersion string, kind string) bool {
+	available, err := LookupKubeAPI(groupVersion, kind)
+	if err != nil {
+		log.Errorf("KubeAPIAvailable: %s %v", groupVersion, err)
+	}
+	return available
+}
+
+// LookupKubeAPI is like KubeAPIAvailable but returns the discovery errors,
+// which are not cached since they might be temporary
+func LookupKubeAPI(groupVersion string, kind string) (bool, error) {
+	key := groupVersion + "/" + kind
+	apiAvailableMutex.Lock()
+	defer apiAvailableMutex.Unlock()
+	if available, cached := apiAvailable[key]; cached {
+		return available, nil
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(KubeConfig())
+	if err != nil {
+		return false, err
+	}
+	available := false
+	resources, err := dc.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	if err == nil {
+		for _, r := range resources.APIResources {
+			if r.Kind == kind {
+				available = true
+				break
+			}
+		}
+	}
+	apiAvailable[key] = available
+	return available, nil
+}
+
 // KubeObject loads a text yaml/json to a kubernets object.
 func KubeObject(text string) runtime.Object {
 	// Decode text (yaml/json) to kube api object
@@ -451,22 +500,42 @@ func KubeCheckQuiet(obj runtime.Object) bool {
 }
 
 // KubeGet gets a runtime.Object, fills the given object and returns the name and kind
-// returns error on failure
+// returns error on failure.
+// In the operator it reads from the informers cache when the kind is cached, see SetKubeCache.
 func KubeGet(obj runtime.Object) (name string, kind string, err error) {
-	klient := KubeClient()
 	objKey := ObjectKey(obj)
+	reader, source := kubeReader(obj, objKey.Namespace)
 	name = objKey.Name
 	gvk := obj.GetObjectKind().GroupVersionKind()
 	kind = gvk.Kind
-	err = klient.Get(ctx, objKey, obj)
+	if source == metrics.KubeSourceCache {
+		metrics.ObserveKubeRequest("get", kubeKind(obj), source)
+	}
+	err = reader.Get(ctx, objKey, obj)
 	return name, kind, err
 }
 
 // KubeList returns a list of objects.
+// In the operator it reads from the informers cache when the kind is cached, see SetKubeCache.
 func KubeList(list runtime.Object, options ...client.ListOption) bool {
-	klient := KubeClient()
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(options)
+	reader, source := kubeReader(list, listOptions.Namespace)
+	if source == metrics.KubeSourceCache {
+		metrics.ObserveKubeRequest("list", kubeKind(list), source)
+	}
+	return kubeList(reader, list, options...)
+}
+
+// KubeListDirect is like KubeList but always reads from the api server,
+// for lists that must include the objects created earlier in the same reconcile
+func KubeListDirect(list runtime.Object, options ...client.ListOption) bool {
+	return kubeList(KubeClient(), list, options...)
+}
+
+func kubeList(reader client.Reader, list runtime.Object, options ...client.ListOption) bool {
 	gvk := list.GetObjectKind().GroupVersionKind()
-	err := klient.List(ctx, list, options...)
+	err := reader.List(ctx, list, options...)
 	if err == nil {
 		return true
 	}
@@ -532,6 +601,12 @@ func GetPodStatusLine(pod *corev1.Pod) string {
 
 // GetPodLogs info
 func GetPodLogs(pod corev1.Pod) (map[string]io.ReadCloser, error) {
+	return GetPodLogsWithOptions(pod, corev1.PodLogOptions{})
+}
+
+// GetPodLogsWithOptions returns the logs of the pod containers using the log options.
+// if opts.Container is set only that container logs are returned, otherwise all the containers.
+func GetPodLogsWithOptions(pod corev1.Pod, opts corev1.PodLogOptions) (map[string]io.ReadCloser, error) {
 	allContainers := append(pod.Spec.InitContainers, pod.Spec.Containers...)
 	config := KubeConfig()
 	clientset, err := kubernetes.NewForConfig(config)
@@ -542,7 +617,11 @@ func GetPodLogs(pod corev1.Pod) (map[string]io.ReadCloser, error) {
 
 	containerMap := make(map[string]io.ReadCloser)
 	for _, container := range allContainers {
-		podLogOpts := corev1.PodLogOptions{Container: container.Name}
+		if opts.Container != "" && opts.Container != container.Name {
+			continue
+		}
+		podLogOpts := opts
+		podLogOpts.Container = container.Name
 		req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts)
 		podLogs, err := req.Stream(ctx)
 		if err != nil {
@@ -642,6 +721,75 @@ func InitLogger() {
 	})
 }
 
+// SetLogLevel sets the level of the logrus logger by name, or the default level when empty.
+// Returns true if the level was changed.
+func SetLogLevel(name string) (bool, error) {
+	level := logrus.DebugLevel
+	if name != "" {
+		l, err := logrus.ParseLevel(name)
+		if err != nil {
+			return false, err
+		}
+		level = l
+	}
+	if logrus.GetLevel() == level {
+		return false, nil
+	}
+	logrus.SetLevel(level)
+	return true, nil
+}
+
+// SetLogFormat sets the format of the logrus logger to text (default) or json.
+// Returns true if the format was changed.
+func SetLogFormat(format string) (bool, error) {
+	_, isJSON := logrus.StandardLogger().Formatter.(*logrus.JSONFormatter)
+	switch format {
+	case "", "text":
+		if !isJSON {
+			return false, nil
+		}
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	case "json":
+		if isJSON {
+			return false, nil
+		}
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return false, fmt.Errorf("Invalid log format %q, expected text or json", format)
+	}
+	return true, nil
+}
+
+// NewLogger returns a logger that writes to the output and hooks of the standard logger with its own level and format.
+// An empty level or format keeps the one of the standard logger.
+func NewLogger(level string, format string) (*logrus.Logger, error) {
+	std := logrus.StandardLogger()
+	logger := logrus.New()
+	logger.Out = std.Out
+	logger.Hooks = std.Hooks
+	logger.Formatter = std.Formatter
+	logger.ReportCaller = std.ReportCaller
+	logger.ExitFunc = std.ExitFunc
+	logger.SetLevel(std.GetLevel())
+	if level != "" {
+		l, err := logrus.ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		logger.SetLevel(l)
+	}
+	switch format {
+	case "":
+	case "text":
+		logger.Formatter = &logrus.TextFormatter{}
+	case "json":
+		logger.Formatter = &logrus.JSONFormatter{}
+	default:
+		return nil, fmt.Errorf("Invalid log format %q, expected text or json", format)
+	}
+	return logger, nil
+}
+
 // Logger returns a default logger
 func Logger() *logrus.Entry {
 	return log
@@ -846,6 +994,17 @@ func SetErrorCondition(conditions *[]conditionsv1.Condition, reason string, mess
 	})
 }
 
+// SetDegradedCondition sets only the degraded condition, keeping the other conditions of the current phase
+func SetDegradedCondition(conditions *[]conditionsv1.Condition, reason string, message string) {
+	conditionsv1.SetStatusCondition(conditions, conditionsv1.Condition{
+		LastHeartbeatTime: metav1.NewTime(time.Now()),
+		Type:              conditionsv1.ConditionDegraded,
+		Status:            corev1.ConditionTrue,
+		Reason:            reason,
+		Message:           message,
+	})
+}
+
 // IsAWSPlatform returns true if this cluster is running on AWS
 func IsAWSPlatform() bool {
 	nodesList := &corev1.NodeList{}
@@ -1235,6 +1394,19 @@ func GetWatchNamespace() (string, error) {
 	return ns, nil
 }
 
+// ParseWatchNamespaces splits a comma separated list of namespaces, like the value of WATCH_NAMESPACE,
+// and returns the unique non empty names in their original order
+func ParseWatchNamespaces(value string) []string {
+	namespaces := []string{}
+	for _, ns := range strings.Split(value, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns != "" && !Contains(ns, namespaces) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
 ///////////////////////////////////
 /////////// VAULT UTILS ///////////
 ///////////////////////////////////